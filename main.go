@@ -1,18 +1,41 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"go-gallery/src/commons/configurator"
+	passwordValidator "go-gallery/src/commons/utils/validations/password"
+	userEntity "go-gallery/src/domain/entities/user"
 	"go-gallery/src/infrastructure/auth"
+	"go-gallery/src/infrastructure/auth/oauth"
+	adminController "go-gallery/src/infrastructure/controller/admin"
+	avatarController "go-gallery/src/infrastructure/controller/avatar"
+	exportController "go-gallery/src/infrastructure/controller/export"
+	healthController "go-gallery/src/infrastructure/controller/health"
 	imageController "go-gallery/src/infrastructure/controller/image"
+	metricsController "go-gallery/src/infrastructure/controller/metrics"
+	"go-gallery/src/infrastructure/controller/middlewares"
+	oauthController "go-gallery/src/infrastructure/controller/oauth"
 	swaggerController "go-gallery/src/infrastructure/controller/swagger"
 	userController "go-gallery/src/infrastructure/controller/user"
 	userMiddleware "go-gallery/src/infrastructure/controller/user/middlewares"
 	log "go-gallery/src/infrastructure/logger"
+	"go-gallery/src/infrastructure/metrics"
+	"go-gallery/src/infrastructure/tracing"
+	"os"
+	"os/signal"
 	"runtime/debug"
+	"syscall"
+	"time"
 
+	accountDeletionService "go-gallery/src/service/accountDeletion"
+	attemptService "go-gallery/src/service/attempt"
+	auditService "go-gallery/src/service/audit"
+	avatarService "go-gallery/src/service/avatar"
 	codeGeneratorService "go-gallery/src/service/codeGenerator"
 	emailService "go-gallery/src/service/email"
+	exportService "go-gallery/src/service/export"
+	healthService "go-gallery/src/service/health"
 	imageService "go-gallery/src/service/image"
 	userService "go-gallery/src/service/user"
 
@@ -23,6 +46,9 @@ import (
 
 var logger log.Logger
 
+// Time given to the exporter to send the pending spans before exiting
+const TRACING_SHUTDOWN_TIMEOUT = 5 * time.Second
+
 // @title						GoGallery
 // @version v1.1.2
 // @description				API for managing photo uploads, with authentication
@@ -45,15 +71,37 @@ func main() {
 	logger.Info("Initializing EmailSender service...")
 	emailSenderService := emailService.NewEmailSenderService(dependencyContainer.GetEmailSenderRepository())
 
+	// Select the algorithm used to hash new passwords, existing hashes are upgraded on login
+	userEntity.SetPasswordHasher(userEntity.NewPasswordHasher(configuration.GetPasswordHashingConfiguration()))
+
 	logger.Info("Initializing User service...")
-	userService := userService.NewUserService(dependencyContainer.GetUserRepository())
+	userService := userService.NewUserService(dependencyContainer.GetUserRepository(), configuration.GetSessionConfiguration().ValidationCacheTTL)
+
+	// Grant the administrator role to the configured users
+	for _, username := range configuration.GetAdminConfiguration().BootstrapUsernames {
+		if _, err := userService.UpdateRole(username, userEntity.ROLE_ADMIN); err != nil {
+			logger.Warning("Could not grant the administrator role", log.F("username", username), log.F("error", err.Message))
+		}
+	}
 
 	logger.Info("Initializing Code Generator service...")
 	codeGeneratorService := codeGeneratorService.NewCodeGeneratorService(dependencyContainer.GetCodeGeneratorRepository())
 
+	logger.Info("Initializing Attempt service...")
+	attemptService := attemptService.NewAttemptService(dependencyContainer.GetAttemptRepository(), configuration.GetBruteForceConfiguration())
+	auditService := auditService.NewAuditService(dependencyContainer.GetAuditRepository())
+
 	logger.Info("Initializing Image service...")
 	imageService := imageService.NewImageService(dependencyContainer.GetImageRepository(), dependencyContainer.GetThumbnailImageRepository())
 
+	logger.Info("Initializing Avatar service...")
+	avatarService := avatarService.NewAvatarService(dependencyContainer.GetAvatarRepository(), imageService, configuration.GetPublicURL())
+
+	logger.Info("Initializing Account Deletion service...")
+	accountDeletionService := accountDeletionService.NewAccountDeletionService(userService, imageService, avatarService, emailSenderService,
+		auth.NewDeletionCancelManager(configuration.GetJWTSecret()), configuration.GetAccountDeletionConfiguration())
+	dependencyContainer.RegisterCloser("AccountDeletionService", accountDeletionService)
+
 	logger.Info("Starting controller configuration...")
 
 	logger.Info("Setting up CORS middleware...")
@@ -65,13 +113,22 @@ func main() {
 		},
 	}))
 
+	// Count the requests and measure their duration for the /metrics endpoint
+	app.Use(middlewares.Metrics())
+
+	// Open a span per request that continues the trace received in the traceparent header
+	app.Use(middlewares.Tracing())
+
+	// Every request gets an identifier that is added to its logs and returned in the X-Request-ID header
+	app.Use(middlewares.RequestLogger())
+
 	// Add Recover middleware to handle panics and provide custom stack trace handler
 	app.Use(recover.New(recover.Config{
 		EnableStackTrace: true,
 		StackTraceHandler: func(c *fiber.Ctx, e any) {
 			// Log the panic with stack trace
 			stackTrace := string(debug.Stack())
-			logger.Panic(fmt.Sprintf("Recovered from panic: %v\nStack Trace: %s", e, stackTrace))
+			middlewares.Logger(c).Panic(fmt.Sprintf("Recovered from panic: %v\nStack Trace: %s", e, stackTrace))
 
 			// Respond with a generic error message
 			c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -80,6 +137,29 @@ func main() {
 		},
 	}))
 
+	// Configure the liveness, readiness and version routes used by the orchestrator
+	logger.Info("Setting up health routes...")
+	healthService := healthService.NewHealthService(dependencyContainer.HealthCheckers(), configuration.GetHealthConfiguration().Timeout)
+	healthController := healthController.NewHealthController(healthService)
+	healthGroup := app.Group("/api")
+	healthController.SetUpRoutes(healthGroup)
+
+	// Configure the Prometheus metrics route, on its own listener when METRICS_ADDRESS is set
+	metricsConfiguration := configuration.GetMetricsConfiguration()
+	var metricsApp *fiber.App
+	if metricsConfiguration.Enabled() {
+		logger.Info("Setting up metrics route...")
+		metricsController := metricsController.NewMetricsController(metrics.Handler(), metricsConfiguration.Token)
+		metricsRouter := app
+		if metricsConfiguration.Address != "" {
+			metricsApp = fiber.New(fiber.Config{DisableStartupMessage: true})
+			metricsRouter = metricsApp
+		}
+		metricsController.SetUpRoutes(metricsRouter.Group("/metrics"))
+	} else {
+		logger.Warning("Metrics are not exposed, set METRICS_ADDRESS or METRICS_TOKEN to publish them")
+	}
+
 	// Configure routes for Swagger documentation
 	logger.Info("Setting up Swagger documentation routes...")
 	docsController := swaggerController.NewSwaggerController(configuration.GetSwaggerConfiguration())
@@ -91,24 +171,129 @@ func main() {
 	tokenManager := auth.NewJWTTokenManager(configuration.GetJWTSecret())
 	jwtMiddleware := userMiddleware.NewJWTMiddleware(tokenManager, userService)
 
+	// Load the password policy and the optional list of breached passwords
+	logger.Info("Initializing password validator...")
+	passwordPolicy := configuration.GetPasswordPolicyConfiguration()
+	var breachedPasswords *passwordValidator.BreachedList
+	if passwordPolicy.BreachedListFile != "" {
+		list, err := passwordValidator.LoadBreachedList(passwordPolicy.BreachedListFile)
+		if err != nil {
+			logger.Panic("Could not load the breached password list", log.F("error", err.Error()))
+			panic(err)
+		}
+		logger.Info("Loaded breached password hashes", log.F("count", list.Size()))
+		breachedPasswords = list
+	}
+	validator := passwordValidator.NewValidator(passwordPolicy, breachedPasswords)
+
 	// Configure user authentication routes
 	logger.Info("Setting up user authentication routes...")
-	authController := userController.NewAuthController(userService, emailSenderService, imageService, codeGeneratorService, jwtMiddleware)
+	authController := userController.NewAuthController(userService, emailSenderService, accountDeletionService, codeGeneratorService, attemptService, auditService, jwtMiddleware,
+		validator, auth.NewPasswordResetManager(configuration.GetJWTSecret(), configuration.GetRecoveryConfiguration().LinkExpiration),
+		configuration.GetVerificationConfiguration(), configuration.GetEmailChangeConfiguration(), configuration.GetRecoveryConfiguration())
 	authGroup := app.Group("/api/auth")
 	authController.SetUpRoutes(authGroup)
 
+	// Configure external identity provider routes
+	logger.Info("Setting up OAuth login routes...")
+	oauthProviders := oauth.NewProviders(configuration.GetOAuthConfiguration())
+	oauthController := oauthController.NewOAuthController(userService, jwtMiddleware, oauthProviders,
+		oauth.NewStateManager(configuration.GetJWTSecret()), configuration.GetOAuthConfiguration())
+	oauthGroup := app.Group("/api/auth/oauth")
+	oauthController.SetUpRoutes(oauthGroup)
+
 	// Configure image routes protected by JWT
 	logger.Info("Setting up image routes protected by JWT...")
-	imageController := imageController.NewImageController(imageService, userService)
+	imageController := imageController.NewImageController(imageService, userService, auditService)
 	imageGroup := app.Group("/api/image")
 	imageGroup.Use(jwtMiddleware.Handler())
+	if !configuration.GetVerificationConfiguration().AllowUnverifiedGallery {
+		imageGroup.Use(jwtMiddleware.VerifiedHandler())
+	}
+	// Read-only users can browse their gallery but not modify it
+	imageGroup.Use(jwtMiddleware.WriteRoleHandler(userEntity.ROLE_USER, userEntity.ROLE_ADMIN))
 	imageController.SetUpRoutes(imageGroup)
 
+	// Configure the avatar routes, avatars are served publicly
+	logger.Info("Setting up avatar routes...")
+	avatarController := avatarController.NewAvatarController(avatarService, jwtMiddleware, configuration.GetVerificationConfiguration())
+	avatarGroup := app.Group("/api/avatar")
+	avatarController.SetUpRoutes(avatarGroup)
+
+	// Configure the account data export routes
+	logger.Info("Setting up export routes...")
+	exportService := exportService.NewExportService(dependencyContainer.GetExportRepository(), userService, imageService, emailSenderService,
+		auth.NewExportLinkManager(configuration.GetJWTSecret()), configuration.GetExportConfiguration())
+	dependencyContainer.RegisterCloser("ExportService", exportService)
+	exportController := exportController.NewExportController(exportService, jwtMiddleware, configuration.GetVerificationConfiguration())
+	exportGroup := app.Group("/api/export")
+	exportController.SetUpRoutes(exportGroup)
+
+	// Configure the administration routes, restricted to the admin role
+	logger.Info("Setting up admin routes...")
+	adminController := adminController.NewAdminController(userService, imageService, avatarService, auditService, emailSenderService)
+	adminGroup := app.Group("/api/admin")
+	adminGroup.Use(jwtMiddleware.Handler(), jwtMiddleware.RoleHandler(userEntity.ROLE_ADMIN))
+	adminController.SetUpRoutes(adminGroup)
+
+	// Stop accepting requests on SIGINT or SIGTERM and give the in-flight ones some time to finish
+	shutdownConfiguration := configuration.GetShutdownConfiguration()
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		logger.Info("Shutting down the server, waiting for the in-flight requests...", log.F("timeout", shutdownConfiguration.RequestTimeout.String()))
+		if err := app.ShutdownWithTimeout(shutdownConfiguration.RequestTimeout); err != nil {
+			logger.Error("Error shutting down the server", log.F("error", err.Error()))
+		}
+		if metricsApp != nil {
+			if err := metricsApp.ShutdownWithTimeout(shutdownConfiguration.RequestTimeout); err != nil {
+				logger.Error("Error shutting down the metrics server", log.F("error", err.Error()))
+			}
+		}
+	}()
+
+	if metricsApp != nil {
+		go func() {
+			logger.Info("Starting the metrics server...", log.F("address", metricsConfiguration.Address))
+			if err := metricsApp.Listen(metricsConfiguration.Address); err != nil {
+				logger.Error("Failed to start the metrics server", log.F("error", err.Error()))
+			}
+		}()
+	}
+
 	// Start the server and listen on the configured port
 	port := configuration.GetPort()
-	logger.Info("Starting the server on port: " + port + "...")
-	err := app.Listen(":" + port)
-	if err != nil {
-		logger.Panic("Failed to start the server: " + err.Error())
+	logger.Info("Starting the server...", log.F("port", port))
+	exitCode := 0
+	if err := app.Listen(":" + port); err != nil {
+		// No signal was received, so there is no drain to wait for
+		logger.Error("Failed to start the server", log.F("error", err.Error()))
+		exitCode = 1
+	} else {
+		// Listen returns as soon as the listener is closed, the in-flight requests are still being drained
+		<-shutdownDone
+	}
+
+	logger.Info("Server stopped")
+
+	// Dependencies are closed in reverse order, so the background workers stop before the repositories they use
+	dependencyCtx, cancelDependencies := context.WithTimeout(context.Background(), shutdownConfiguration.DependencyTimeout)
+	if err := dependencyContainer.Close(dependencyCtx); err != nil {
+		logger.Error("Some dependencies were not closed cleanly", log.F("error", err.Error()))
+	}
+	cancelDependencies()
+
+	// Tracing and logging were started first, so they are the last to stop
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), TRACING_SHUTDOWN_TIMEOUT)
+	if err := tracing.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error exporting the pending traces", log.F("error", err.Error()))
+	}
+	cancel()
+	if err := log.Close(); err != nil {
+		fmt.Println("Error closing the logger: " + err.Error())
 	}
+	os.Exit(exitCode)
 }
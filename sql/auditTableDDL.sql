@@ -0,0 +1,14 @@
+-- Identifiers start with the timestamp, the C collation keeps their order byte by byte
+CREATE TABLE IF NOT EXISTS audit_events (
+    id VARCHAR(64) COLLATE "C" PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target VARCHAR(255),
+    ip VARCHAR(64),
+    user_agent TEXT,
+    outcome VARCHAR(16) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action, id DESC);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS verification_codes (
+    code_key VARCHAR(255) PRIMARY KEY,
+    code_hash VARCHAR(64) NOT NULL,
+    expiration TIMESTAMPTZ NOT NULL,
+    attempts INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_codes_expiration ON verification_codes (expiration);
@@ -3,5 +3,70 @@ CREATE TABLE IF NOT EXISTS users (
     password VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL,
     lastname VARCHAR(255),
-    firstname VARCHAR(255)
+    firstname VARCHAR(255),
+    verified BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- Existing accounts created before email verification are considered verified
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ALTER COLUMN verified SET DEFAULT FALSE;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Increased on every password reset to revoke the JWT issued until then
+ALTER TABLE users ADD COLUMN IF NOT EXISTS session_version BIGINT NOT NULL DEFAULT 0;
+
+-- Emails are stored in lowercase and each one belongs to a single account, recovery and social login look users up by it.
+-- Accounts whose emails only differ in their case keep one owner, see USER_REPOSITORY in the README
+CREATE TABLE IF NOT EXISTS user_email_conflicts (
+    username VARCHAR(255) PRIMARY KEY REFERENCES users(username) ON DELETE CASCADE,
+    original_email VARCHAR(255) NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+WITH ranked AS (
+    SELECT username, email,
+        ROW_NUMBER() OVER (PARTITION BY LOWER(email) ORDER BY verified DESC, (email = LOWER(email)) DESC, username) AS position
+    FROM users
+), conflicts AS (
+    INSERT INTO user_email_conflicts (username, original_email)
+    SELECT username, email FROM ranked WHERE position > 1
+    ON CONFLICT (username) DO UPDATE SET original_email = EXCLUDED.original_email, detected_at = NOW()
+    RETURNING username
+)
+UPDATE users SET email = 'conflict+' || MD5(users.username) || '@invalid'
+FROM conflicts WHERE users.username = conflicts.username;
+
+UPDATE users SET email = LOWER(email) WHERE email <> LOWER(email);
+
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email));
+
+-- Set while the account waits for its deletion, the purge worker removes it after this moment
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ;
+
+-- Set by the purge worker before removing anything, the deletion of a purging account can no longer be cancelled
+ALTER TABLE users ADD COLUMN IF NOT EXISTS purging BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS email_changes (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+    revert_token_hash VARCHAR(64),
+    revert_expiration TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_changes_revert_token_hash ON email_changes (revert_token_hash);
+
+UPDATE email_changes SET old_email = LOWER(old_email), new_email = LOWER(new_email)
+WHERE old_email <> LOWER(old_email) OR new_email <> LOWER(new_email);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    PRIMARY KEY (provider, subject)
 );
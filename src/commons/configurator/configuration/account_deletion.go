@@ -0,0 +1,38 @@
+package configuration
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_ACCOUNT_DELETION_GRACE_PERIOD   int = 168
+	DEFAULT_ACCOUNT_DELETION_PURGE_INTERVAL int = 60
+)
+
+// AccountDeletionConfiguration agrupa la configuración del periodo de gracia antes de borrar una cuenta
+type AccountDeletionConfiguration struct {
+	GracePeriod   time.Duration
+	PurgeInterval time.Duration
+	CancelURL     string
+}
+
+func createAccountDeletionConfiguration(args map[string]string, publicURL string) AccountDeletionConfiguration {
+	// Hours the account is kept after the deletion is confirmed
+	gracePeriod, err := strconv.Atoi(args["ACCOUNT_DELETION_GRACE_PERIOD"])
+	if err != nil || gracePeriod <= 0 {
+		gracePeriod = DEFAULT_ACCOUNT_DELETION_GRACE_PERIOD
+	}
+
+	// Minutes between two runs of the purge of the expired accounts
+	purgeInterval, err := strconv.Atoi(args["ACCOUNT_DELETION_PURGE_INTERVAL"])
+	if err != nil || purgeInterval <= 0 {
+		purgeInterval = DEFAULT_ACCOUNT_DELETION_PURGE_INTERVAL
+	}
+
+	return AccountDeletionConfiguration{
+		GracePeriod:   time.Duration(gracePeriod) * time.Hour,
+		PurgeInterval: time.Duration(purgeInterval) * time.Minute,
+		CancelURL:     publicURL + "/api/auth/cancel-delete",
+	}
+}
@@ -0,0 +1,29 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAccountDeletionConfiguration(t *testing.T) {
+	conf := createAccountDeletionConfiguration(map[string]string{
+		"ACCOUNT_DELETION_GRACE_PERIOD":   "72",
+		"ACCOUNT_DELETION_PURGE_INTERVAL": "15",
+	}, "https://gallery.example.com")
+
+	assert.Equal(t, 72*time.Hour, conf.GracePeriod)
+	assert.Equal(t, 15*time.Minute, conf.PurgeInterval)
+	assert.Equal(t, "https://gallery.example.com/api/auth/cancel-delete", conf.CancelURL)
+}
+
+func TestCreateAccountDeletionConfigurationDefaults(t *testing.T) {
+	conf := createAccountDeletionConfiguration(map[string]string{
+		"ACCOUNT_DELETION_GRACE_PERIOD":   "-1",
+		"ACCOUNT_DELETION_PURGE_INTERVAL": "abc",
+	}, "http://localhost:3000")
+
+	assert.Equal(t, 168*time.Hour, conf.GracePeriod)
+	assert.Equal(t, time.Hour, conf.PurgeInterval)
+}
@@ -0,0 +1,20 @@
+package configuration
+
+import "strings"
+
+// AdminConfiguration agrupa la configuración del área de administración
+type AdminConfiguration struct {
+	// Usuarios que reciben el rol de administrador al arrancar la aplicación
+	BootstrapUsernames []string
+}
+
+func createAdminConfiguration(args map[string]string) AdminConfiguration {
+	var usernames []string
+	for _, username := range strings.Split(args["ADMIN_USERNAMES"], ",") {
+		if username = strings.TrimSpace(username); username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+
+	return AdminConfiguration{BootstrapUsernames: usernames}
+}
@@ -0,0 +1,15 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAdminConfiguration(t *testing.T) {
+	conf := createAdminConfiguration(map[string]string{"ADMIN_USERNAMES": " alice, ,bob "})
+	assert.Equal(t, []string{"alice", "bob"}, conf.BootstrapUsernames)
+
+	conf = createAdminConfiguration(map[string]string{})
+	assert.Empty(t, conf.BootstrapUsernames)
+}
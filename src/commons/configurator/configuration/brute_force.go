@@ -0,0 +1,59 @@
+package configuration
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_BRUTE_FORCE_MAX_FAILURES     int = 5
+	DEFAULT_BRUTE_FORCE_IP_MAX_FAILURES  int = 50
+	DEFAULT_BRUTE_FORCE_BASE_DELAY       int = 1
+	DEFAULT_BRUTE_FORCE_MAX_DELAY        int = 300
+	DEFAULT_BRUTE_FORCE_LOCKOUT_DURATION int = 15
+)
+
+// BruteForceConfiguration define el backoff exponencial y el bloqueo aplicado tras intentos fallidos
+type BruteForceConfiguration struct {
+	MaxFailures     int
+	IPMaxFailures   int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+}
+
+func createBruteForceConfiguration(args map[string]string) BruteForceConfiguration {
+	maxFailures, err := strconv.Atoi(args["BRUTE_FORCE_MAX_FAILURES"])
+	if err != nil || maxFailures <= 0 {
+		maxFailures = DEFAULT_BRUTE_FORCE_MAX_FAILURES
+	}
+
+	// Many users can share an IP behind a NAT or a proxy, so its counter tolerates more failures
+	ipMaxFailures, err := strconv.Atoi(args["BRUTE_FORCE_IP_MAX_FAILURES"])
+	if err != nil || ipMaxFailures <= 0 {
+		ipMaxFailures = DEFAULT_BRUTE_FORCE_IP_MAX_FAILURES
+	}
+
+	baseDelay, err := strconv.Atoi(args["BRUTE_FORCE_BASE_DELAY"])
+	if err != nil || baseDelay < 0 {
+		baseDelay = DEFAULT_BRUTE_FORCE_BASE_DELAY
+	}
+
+	maxDelay, err := strconv.Atoi(args["BRUTE_FORCE_MAX_DELAY"])
+	if err != nil || maxDelay < 0 {
+		maxDelay = DEFAULT_BRUTE_FORCE_MAX_DELAY
+	}
+
+	lockoutDuration, err := strconv.Atoi(args["BRUTE_FORCE_LOCKOUT_DURATION"])
+	if err != nil || lockoutDuration <= 0 {
+		lockoutDuration = DEFAULT_BRUTE_FORCE_LOCKOUT_DURATION
+	}
+
+	return BruteForceConfiguration{
+		MaxFailures:     maxFailures,
+		IPMaxFailures:   ipMaxFailures,
+		BaseDelay:       time.Duration(baseDelay) * time.Second,
+		MaxDelay:        time.Duration(maxDelay) * time.Second,
+		LockoutDuration: time.Duration(lockoutDuration) * time.Minute,
+	}
+}
@@ -3,6 +3,7 @@ package configuration
 import (
 	"go-gallery/src/commons/configurator/version"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/swagger"
@@ -20,13 +21,30 @@ type Configuration struct {
 	timestamp            time.Time
 	port                 string
 	jwtSecret            string
+	publicURL            string
 	swaggerConfiguration swagger.Config
+
+	verificationConfiguration VerificationConfiguration
+	emailChangeConfiguration  EmailChangeConfiguration
+	bruteForceConfiguration   BruteForceConfiguration
+	oauthConfiguration        OAuthConfiguration
+	adminConfiguration        AdminConfiguration
+	sessionConfiguration      SessionConfiguration
+	passwordPolicy            PasswordPolicyConfiguration
+	passwordHashing           PasswordHashingConfiguration
+	recoveryConfiguration     RecoveryConfiguration
+	exportConfiguration       ExportConfiguration
+	accountDeletion           AccountDeletionConfiguration
+	healthConfiguration       HealthConfiguration
+	metricsConfiguration      MetricsConfiguration
+	shutdownConfiguration     ShutdownConfiguration
 }
 
 func Instance(args map[string]string) *Configuration {
 	if configuration == nil {
 		timestamp := time.Now()
 		miliseconds := timestamp.UnixMilli()
+		publicURL := createPublicURL(args)
 
 		configuration = &Configuration{
 			serviceName:          serviceName,
@@ -36,7 +54,23 @@ func Instance(args map[string]string) *Configuration {
 			args:                 args,
 			port:                 args["GO_GALLERY_API_PORT"],
 			jwtSecret:            args["JWT_SECRET"],
+			publicURL:            publicURL,
 			swaggerConfiguration: createSwaggerConfiguration(),
+
+			verificationConfiguration: createVerificationConfiguration(args),
+			emailChangeConfiguration:  createEmailChangeConfiguration(args, publicURL),
+			bruteForceConfiguration:   createBruteForceConfiguration(args),
+			oauthConfiguration:        createOAuthConfiguration(args, publicURL),
+			adminConfiguration:        createAdminConfiguration(args),
+			sessionConfiguration:      createSessionConfiguration(args),
+			passwordPolicy:            createPasswordPolicyConfiguration(args),
+			passwordHashing:           createPasswordHashingConfiguration(args),
+			recoveryConfiguration:     createRecoveryConfiguration(args, publicURL),
+			exportConfiguration:       createExportConfiguration(args, publicURL),
+			accountDeletion:           createAccountDeletionConfiguration(args, publicURL),
+			healthConfiguration:       createHealthConfiguration(args),
+			metricsConfiguration:      createMetricsConfiguration(args),
+			shutdownConfiguration:     createShutdownConfiguration(args),
 		}
 
 		return configuration
@@ -58,6 +92,15 @@ func createSwaggerConfiguration() swagger.Config {
 	}
 }
 
+// URL pública del servidor utilizada para construir los enlaces enviados por correo
+func createPublicURL(args map[string]string) string {
+	publicURL := strings.TrimSuffix(args["GO_GALLERY_PUBLIC_URL"], "/")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + args["GO_GALLERY_API_PORT"]
+	}
+	return publicURL
+}
+
 func (conf *Configuration) GetVersion() string {
 	return conf.version
 }
@@ -93,3 +136,63 @@ func (conf *Configuration) GetPort() string {
 func (conf *Configuration) GetJWTSecret() string {
 	return conf.jwtSecret
 }
+
+func (conf *Configuration) GetVerificationConfiguration() VerificationConfiguration {
+	return conf.verificationConfiguration
+}
+
+func (conf *Configuration) GetPublicURL() string {
+	return conf.publicURL
+}
+
+func (conf *Configuration) GetEmailChangeConfiguration() EmailChangeConfiguration {
+	return conf.emailChangeConfiguration
+}
+
+func (conf *Configuration) GetBruteForceConfiguration() BruteForceConfiguration {
+	return conf.bruteForceConfiguration
+}
+
+func (conf *Configuration) GetOAuthConfiguration() OAuthConfiguration {
+	return conf.oauthConfiguration
+}
+
+func (conf *Configuration) GetAdminConfiguration() AdminConfiguration {
+	return conf.adminConfiguration
+}
+
+func (conf *Configuration) GetSessionConfiguration() SessionConfiguration {
+	return conf.sessionConfiguration
+}
+
+func (conf *Configuration) GetPasswordPolicyConfiguration() PasswordPolicyConfiguration {
+	return conf.passwordPolicy
+}
+
+func (conf *Configuration) GetPasswordHashingConfiguration() PasswordHashingConfiguration {
+	return conf.passwordHashing
+}
+
+func (conf *Configuration) GetRecoveryConfiguration() RecoveryConfiguration {
+	return conf.recoveryConfiguration
+}
+
+func (conf *Configuration) GetExportConfiguration() ExportConfiguration {
+	return conf.exportConfiguration
+}
+
+func (conf *Configuration) GetAccountDeletionConfiguration() AccountDeletionConfiguration {
+	return conf.accountDeletion
+}
+
+func (conf *Configuration) GetHealthConfiguration() HealthConfiguration {
+	return conf.healthConfiguration
+}
+
+func (conf *Configuration) GetMetricsConfiguration() MetricsConfiguration {
+	return conf.metricsConfiguration
+}
+
+func (conf *Configuration) GetShutdownConfiguration() ShutdownConfiguration {
+	return conf.shutdownConfiguration
+}
@@ -0,0 +1,26 @@
+package configuration
+
+import (
+	"strconv"
+	"time"
+)
+
+const DEFAULT_EMAIL_CHANGE_REVERT_WINDOW int = 72
+
+// EmailChangeConfiguration agrupa la configuración del flujo de cambio de correo electrónico
+type EmailChangeConfiguration struct {
+	PublicURL    string
+	RevertWindow time.Duration
+}
+
+func createEmailChangeConfiguration(args map[string]string, publicURL string) EmailChangeConfiguration {
+	revertWindow, err := strconv.Atoi(args["EMAIL_CHANGE_REVERT_WINDOW"])
+	if err != nil || revertWindow <= 0 {
+		revertWindow = DEFAULT_EMAIL_CHANGE_REVERT_WINDOW
+	}
+
+	return EmailChangeConfiguration{
+		PublicURL:    publicURL,
+		RevertWindow: time.Duration(revertWindow) * time.Hour,
+	}
+}
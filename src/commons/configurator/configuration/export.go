@@ -0,0 +1,40 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_EXPORT_LINK_EXPIRATION int = 24
+	DEFAULT_EXPORT_DIRECTORY_NAME      = "go-gallery-exports"
+)
+
+// ExportConfiguration agrupa la configuración de la exportación de los datos de la cuenta
+type ExportConfiguration struct {
+	Directory      string
+	DownloadURL    string
+	LinkExpiration time.Duration
+}
+
+func createExportConfiguration(args map[string]string, publicURL string) ExportConfiguration {
+	directory := strings.TrimSpace(args["EXPORT_DIRECTORY"])
+	if directory == "" {
+		directory = filepath.Join(os.TempDir(), DEFAULT_EXPORT_DIRECTORY_NAME)
+	}
+
+	// Hours the download link sent by email remains valid
+	linkExpiration, err := strconv.Atoi(args["EXPORT_LINK_EXPIRATION"])
+	if err != nil || linkExpiration <= 0 {
+		linkExpiration = DEFAULT_EXPORT_LINK_EXPIRATION
+	}
+
+	return ExportConfiguration{
+		Directory:      directory,
+		DownloadURL:    publicURL + "/api/export/download",
+		LinkExpiration: time.Duration(linkExpiration) * time.Hour,
+	}
+}
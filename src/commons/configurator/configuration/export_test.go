@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateExportConfiguration(t *testing.T) {
+	conf := createExportConfiguration(map[string]string{
+		"EXPORT_DIRECTORY":       "/data/exports",
+		"EXPORT_LINK_EXPIRATION": "48",
+	}, "https://gallery.example.com")
+
+	assert.Equal(t, "/data/exports", conf.Directory)
+	assert.Equal(t, 48*time.Hour, conf.LinkExpiration)
+	assert.Equal(t, "https://gallery.example.com/api/export/download", conf.DownloadURL)
+}
+
+func TestCreateExportConfigurationDefaults(t *testing.T) {
+	conf := createExportConfiguration(map[string]string{"EXPORT_LINK_EXPIRATION": "0"}, "http://localhost:3000")
+
+	assert.Equal(t, filepath.Join(os.TempDir(), DEFAULT_EXPORT_DIRECTORY_NAME), conf.Directory)
+	assert.Equal(t, 24*time.Hour, conf.LinkExpiration)
+}
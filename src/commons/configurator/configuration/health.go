@@ -0,0 +1,25 @@
+package configuration
+
+import (
+	"strconv"
+	"time"
+)
+
+const DEFAULT_HEALTH_CHECK_TIMEOUT int = 2
+
+// HealthConfiguration define cuánto espera la comprobación de disponibilidad a cada dependencia
+type HealthConfiguration struct {
+	Timeout time.Duration
+}
+
+func createHealthConfiguration(args map[string]string) HealthConfiguration {
+	// A dependency that does not answer in time is reported as unavailable
+	timeout, err := strconv.Atoi(args["HEALTH_CHECK_TIMEOUT"])
+	if err != nil || timeout <= 0 {
+		timeout = DEFAULT_HEALTH_CHECK_TIMEOUT
+	}
+
+	return HealthConfiguration{
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+}
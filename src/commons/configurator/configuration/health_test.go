@@ -0,0 +1,19 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateHealthConfiguration(t *testing.T) {
+	conf := createHealthConfiguration(map[string]string{"HEALTH_CHECK_TIMEOUT": "5"})
+	assert.Equal(t, 5*time.Second, conf.Timeout)
+
+	conf = createHealthConfiguration(map[string]string{"HEALTH_CHECK_TIMEOUT": "0"})
+	assert.Equal(t, 2*time.Second, conf.Timeout)
+
+	conf = createHealthConfiguration(map[string]string{})
+	assert.Equal(t, 2*time.Second, conf.Timeout)
+}
@@ -0,0 +1,24 @@
+package configuration
+
+import "strings"
+
+// MetricsConfiguration define dónde se publica /metrics y con qué token se protege
+type MetricsConfiguration struct {
+	// Address es la dirección de un servidor aparte para las métricas, vacía las sirve en el puerto de la API
+	Address string
+	// Token es el token Bearer que debe enviar Prometheus, vacío no exige autenticación
+	Token string
+}
+
+func createMetricsConfiguration(args map[string]string) MetricsConfiguration {
+	return MetricsConfiguration{
+		Address: strings.TrimSpace(args["METRICS_ADDRESS"]),
+		Token:   strings.TrimSpace(args["METRICS_TOKEN"]),
+	}
+}
+
+// Enabled indica si las métricas se publican. En el puerto público de la API solo se publican con token,
+// para no exponerlas a cualquiera que alcance el servicio
+func (c MetricsConfiguration) Enabled() bool {
+	return c.Address != "" || c.Token != ""
+}
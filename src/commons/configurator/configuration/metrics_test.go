@@ -0,0 +1,20 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateMetricsConfiguration(t *testing.T) {
+	conf := createMetricsConfiguration(map[string]string{})
+	assert.False(t, conf.Enabled(), "Metrics must not be exposed on the public port without a token")
+
+	conf = createMetricsConfiguration(map[string]string{"METRICS_ADDRESS": " 127.0.0.1:9464 "})
+	assert.Equal(t, "127.0.0.1:9464", conf.Address)
+	assert.True(t, conf.Enabled())
+
+	conf = createMetricsConfiguration(map[string]string{"METRICS_TOKEN": "scrape-token"})
+	assert.Equal(t, "scrape-token", conf.Token)
+	assert.True(t, conf.Enabled())
+}
@@ -0,0 +1,82 @@
+package configuration
+
+import "strings"
+
+const (
+	OAUTH_PROVIDER_TYPE_OIDC   string = "oidc"
+	OAUTH_PROVIDER_TYPE_GITHUB string = "github"
+	GOOGLE_ISSUER_URL          string = "https://accounts.google.com"
+)
+
+// OAuthProviderConfiguration define un proveedor externo de identidad
+type OAuthProviderConfiguration struct {
+	Name         string
+	Type         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OAuthConfiguration agrupa los proveedores de inicio de sesión externos configurados
+type OAuthConfiguration struct {
+	Providers          []OAuthProviderConfiguration
+	SuccessRedirectURL string
+}
+
+// Solo se habilitan los proveedores que tengan configurado un client id
+func createOAuthConfiguration(args map[string]string, publicURL string) OAuthConfiguration {
+	var providers []OAuthProviderConfiguration
+
+	if args["OAUTH_GOOGLE_CLIENT_ID"] != "" {
+		providers = append(providers, OAuthProviderConfiguration{
+			Name:         "google",
+			Type:         OAUTH_PROVIDER_TYPE_OIDC,
+			IssuerURL:    GOOGLE_ISSUER_URL,
+			ClientID:     args["OAUTH_GOOGLE_CLIENT_ID"],
+			ClientSecret: args["OAUTH_GOOGLE_CLIENT_SECRET"],
+			Scopes:       []string{"openid", "email", "profile"},
+		})
+	}
+
+	if args["OAUTH_GITHUB_CLIENT_ID"] != "" {
+		providers = append(providers, OAuthProviderConfiguration{
+			Name:         "github",
+			Type:         OAUTH_PROVIDER_TYPE_GITHUB,
+			ClientID:     args["OAUTH_GITHUB_CLIENT_ID"],
+			ClientSecret: args["OAUTH_GITHUB_CLIENT_SECRET"],
+			Scopes:       []string{"read:user", "user:email"},
+		})
+	}
+
+	if args["OAUTH_OIDC_CLIENT_ID"] != "" && args["OAUTH_OIDC_ISSUER_URL"] != "" {
+		name := args["OAUTH_OIDC_NAME"]
+		if name == "" {
+			name = OAUTH_PROVIDER_TYPE_OIDC
+		}
+
+		scopes := strings.Fields(strings.ReplaceAll(args["OAUTH_OIDC_SCOPES"], ",", " "))
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+
+		providers = append(providers, OAuthProviderConfiguration{
+			Name:         strings.ToLower(name),
+			Type:         OAUTH_PROVIDER_TYPE_OIDC,
+			IssuerURL:    strings.TrimSuffix(args["OAUTH_OIDC_ISSUER_URL"], "/"),
+			ClientID:     args["OAUTH_OIDC_CLIENT_ID"],
+			ClientSecret: args["OAUTH_OIDC_CLIENT_SECRET"],
+			Scopes:       scopes,
+		})
+	}
+
+	for i := range providers {
+		providers[i].RedirectURL = publicURL + "/api/auth/oauth/" + providers[i].Name + "/callback"
+	}
+
+	return OAuthConfiguration{
+		Providers:          providers,
+		SuccessRedirectURL: args["OAUTH_SUCCESS_REDIRECT_URL"],
+	}
+}
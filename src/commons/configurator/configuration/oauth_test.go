@@ -0,0 +1,42 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateOAuthConfigurationWithoutProviders(t *testing.T) {
+	conf := createOAuthConfiguration(map[string]string{}, "http://localhost:3000")
+
+	assert.Empty(t, conf.Providers)
+}
+
+func TestCreateOAuthConfigurationFromArgs(t *testing.T) {
+	conf := createOAuthConfiguration(map[string]string{
+		"OAUTH_GOOGLE_CLIENT_ID":     "google-id",
+		"OAUTH_GOOGLE_CLIENT_SECRET": "google-secret",
+		"OAUTH_GITHUB_CLIENT_ID":     "github-id",
+		"OAUTH_OIDC_NAME":            "Keycloak",
+		"OAUTH_OIDC_ISSUER_URL":      "http://localhost:8080/realms/gallery/",
+		"OAUTH_OIDC_CLIENT_ID":       "gallery",
+		"OAUTH_OIDC_SCOPES":          "openid,email",
+		"OAUTH_SUCCESS_REDIRECT_URL": "http://localhost:5173",
+	}, "http://localhost:3000")
+
+	assert.Len(t, conf.Providers, 3)
+	assert.Equal(t, "http://localhost:5173", conf.SuccessRedirectURL)
+
+	google := conf.Providers[0]
+	assert.Equal(t, OAUTH_PROVIDER_TYPE_OIDC, google.Type)
+	assert.Equal(t, GOOGLE_ISSUER_URL, google.IssuerURL)
+	assert.Equal(t, "http://localhost:3000/api/auth/oauth/google/callback", google.RedirectURL)
+
+	assert.Equal(t, OAUTH_PROVIDER_TYPE_GITHUB, conf.Providers[1].Type)
+
+	generic := conf.Providers[2]
+	assert.Equal(t, "keycloak", generic.Name)
+	assert.Equal(t, "http://localhost:8080/realms/gallery", generic.IssuerURL)
+	assert.Equal(t, []string{"openid", "email"}, generic.Scopes)
+	assert.Equal(t, "http://localhost:3000/api/auth/oauth/keycloak/callback", generic.RedirectURL)
+}
@@ -0,0 +1,66 @@
+package configuration
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	PASSWORD_HASH_BCRYPT   string = "bcrypt"
+	PASSWORD_HASH_ARGON2ID string = "argon2id"
+
+	DEFAULT_BCRYPT_COST        int = 10
+	MIN_BCRYPT_COST            int = 4
+	MAX_BCRYPT_COST            int = 31
+	DEFAULT_ARGON2_MEMORY      int = 64 * 1024
+	DEFAULT_ARGON2_ITERATIONS  int = 3
+	DEFAULT_ARGON2_PARALLELISM int = 2
+	MAX_ARGON2_MEMORY          int = 1024 * 1024
+	MAX_ARGON2_ITERATIONS      int = 64
+	MAX_ARGON2_PARALLELISM     int = 255
+)
+
+// PasswordHashingConfiguration define el algoritmo y los parámetros con los que se guardan las contraseñas nuevas
+type PasswordHashingConfiguration struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+func createPasswordHashingConfiguration(args map[string]string) PasswordHashingConfiguration {
+	algorithm := strings.ToLower(strings.TrimSpace(args["PASSWORD_HASH_ALGORITHM"]))
+	if algorithm != PASSWORD_HASH_ARGON2ID {
+		algorithm = PASSWORD_HASH_BCRYPT
+	}
+
+	bcryptCost, err := strconv.Atoi(args["BCRYPT_COST"])
+	if err != nil || bcryptCost < MIN_BCRYPT_COST || bcryptCost > MAX_BCRYPT_COST {
+		bcryptCost = DEFAULT_BCRYPT_COST
+	}
+
+	// Memory is given in KiB, as in the argon2 parameters
+	memory, err := strconv.Atoi(args["ARGON2_MEMORY"])
+	if err != nil || memory <= 0 || memory > MAX_ARGON2_MEMORY {
+		memory = DEFAULT_ARGON2_MEMORY
+	}
+
+	iterations, err := strconv.Atoi(args["ARGON2_ITERATIONS"])
+	if err != nil || iterations <= 0 || iterations > MAX_ARGON2_ITERATIONS {
+		iterations = DEFAULT_ARGON2_ITERATIONS
+	}
+
+	parallelism, err := strconv.Atoi(args["ARGON2_PARALLELISM"])
+	if err != nil || parallelism <= 0 || parallelism > MAX_ARGON2_PARALLELISM {
+		parallelism = DEFAULT_ARGON2_PARALLELISM
+	}
+
+	return PasswordHashingConfiguration{
+		Algorithm:         algorithm,
+		BcryptCost:        bcryptCost,
+		Argon2Memory:      uint32(memory),
+		Argon2Iterations:  uint32(iterations),
+		Argon2Parallelism: uint8(parallelism),
+	}
+}
@@ -0,0 +1,49 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePasswordHashingConfigurationDefaults(t *testing.T) {
+	conf := createPasswordHashingConfiguration(map[string]string{})
+
+	assert.Equal(t, PASSWORD_HASH_BCRYPT, conf.Algorithm)
+	assert.Equal(t, 10, conf.BcryptCost)
+	assert.Equal(t, uint32(65536), conf.Argon2Memory)
+	assert.Equal(t, uint32(3), conf.Argon2Iterations)
+	assert.Equal(t, uint8(2), conf.Argon2Parallelism)
+}
+
+func TestCreatePasswordHashingConfiguration(t *testing.T) {
+	conf := createPasswordHashingConfiguration(map[string]string{
+		"PASSWORD_HASH_ALGORITHM": " Argon2id ",
+		"BCRYPT_COST":             "12",
+		"ARGON2_MEMORY":           "19456",
+		"ARGON2_ITERATIONS":       "2",
+		"ARGON2_PARALLELISM":      "1",
+	})
+
+	assert.Equal(t, PASSWORD_HASH_ARGON2ID, conf.Algorithm)
+	assert.Equal(t, 12, conf.BcryptCost)
+	assert.Equal(t, uint32(19456), conf.Argon2Memory)
+	assert.Equal(t, uint32(2), conf.Argon2Iterations)
+	assert.Equal(t, uint8(1), conf.Argon2Parallelism)
+}
+
+func TestCreatePasswordHashingConfigurationInvalidValues(t *testing.T) {
+	conf := createPasswordHashingConfiguration(map[string]string{
+		"PASSWORD_HASH_ALGORITHM": "md5",
+		"BCRYPT_COST":             "40",
+		"ARGON2_MEMORY":           "4194304",
+		"ARGON2_ITERATIONS":       "1000",
+		"ARGON2_PARALLELISM":      "300",
+	})
+
+	assert.Equal(t, PASSWORD_HASH_BCRYPT, conf.Algorithm)
+	assert.Equal(t, 10, conf.BcryptCost)
+	assert.Equal(t, uint32(65536), conf.Argon2Memory)
+	assert.Equal(t, uint32(3), conf.Argon2Iterations)
+	assert.Equal(t, uint8(2), conf.Argon2Parallelism)
+}
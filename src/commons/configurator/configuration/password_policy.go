@@ -0,0 +1,54 @@
+package configuration
+
+import (
+	"strconv"
+)
+
+const (
+	DEFAULT_PASSWORD_MIN_LENGTH int = 8
+	// bcrypt ignores everything after the first 72 bytes
+	DEFAULT_PASSWORD_MAX_LENGTH int = 72
+)
+
+// PasswordPolicyConfiguration define las reglas que deben cumplir las contraseñas al registrarse, actualizarlas o recuperarlas
+type PasswordPolicyConfiguration struct {
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSpecial   bool
+	ForbidUserData   bool
+	BreachedListFile string
+}
+
+func createPasswordPolicyConfiguration(args map[string]string) PasswordPolicyConfiguration {
+	minLength, err := strconv.Atoi(args["PASSWORD_MIN_LENGTH"])
+	if err != nil || minLength <= 0 {
+		minLength = DEFAULT_PASSWORD_MIN_LENGTH
+	}
+
+	maxLength, err := strconv.Atoi(args["PASSWORD_MAX_LENGTH"])
+	if err != nil || maxLength < minLength {
+		maxLength = max(DEFAULT_PASSWORD_MAX_LENGTH, minLength)
+	}
+
+	return PasswordPolicyConfiguration{
+		MinLength:        minLength,
+		MaxLength:        maxLength,
+		RequireUppercase: parseBoolArg(args["PASSWORD_REQUIRE_UPPERCASE"], true),
+		RequireLowercase: parseBoolArg(args["PASSWORD_REQUIRE_LOWERCASE"], false),
+		RequireDigit:     parseBoolArg(args["PASSWORD_REQUIRE_DIGIT"], false),
+		RequireSpecial:   parseBoolArg(args["PASSWORD_REQUIRE_SPECIAL"], true),
+		ForbidUserData:   parseBoolArg(args["PASSWORD_FORBID_USER_DATA"], true),
+		BreachedListFile: args["PASSWORD_BREACHED_LIST_FILE"],
+	}
+}
+
+func parseBoolArg(value string, defaultValue bool) bool {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
@@ -0,0 +1,39 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePasswordPolicyConfigurationDefaults(t *testing.T) {
+	conf := createPasswordPolicyConfiguration(map[string]string{})
+
+	assert.Equal(t, 8, conf.MinLength)
+	assert.Equal(t, 72, conf.MaxLength)
+	assert.True(t, conf.RequireUppercase)
+	assert.False(t, conf.RequireLowercase)
+	assert.False(t, conf.RequireDigit)
+	assert.True(t, conf.RequireSpecial)
+	assert.True(t, conf.ForbidUserData)
+	assert.Empty(t, conf.BreachedListFile)
+}
+
+func TestCreatePasswordPolicyConfiguration(t *testing.T) {
+	conf := createPasswordPolicyConfiguration(map[string]string{
+		"PASSWORD_MIN_LENGTH":         "12",
+		"PASSWORD_MAX_LENGTH":         "10",
+		"PASSWORD_REQUIRE_UPPERCASE":  "false",
+		"PASSWORD_REQUIRE_DIGIT":      "true",
+		"PASSWORD_FORBID_USER_DATA":   "invalid",
+		"PASSWORD_BREACHED_LIST_FILE": "/data/breached.txt",
+	})
+
+	assert.Equal(t, 12, conf.MinLength)
+	// A maximum below the minimum is ignored
+	assert.Equal(t, 72, conf.MaxLength)
+	assert.False(t, conf.RequireUppercase)
+	assert.True(t, conf.RequireDigit)
+	assert.True(t, conf.ForbidUserData)
+	assert.Equal(t, "/data/breached.txt", conf.BreachedListFile)
+}
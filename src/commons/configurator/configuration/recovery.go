@@ -0,0 +1,33 @@
+package configuration
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DEFAULT_PASSWORD_RESET_LINK_EXPIRATION int = 30
+
+// RecoveryConfiguration agrupa la configuración de la recuperación de contraseña mediante enlace
+type RecoveryConfiguration struct {
+	ResetURL       string
+	LinkExpiration time.Duration
+}
+
+func createRecoveryConfiguration(args map[string]string, publicURL string) RecoveryConfiguration {
+	// Page of the frontend that receives the token and shows the new password form
+	resetURL := strings.TrimSpace(args["PASSWORD_RESET_URL"])
+	if resetURL == "" {
+		resetURL = publicURL + "/reset-password"
+	}
+
+	linkExpiration, err := strconv.Atoi(args["PASSWORD_RESET_LINK_EXPIRATION"])
+	if err != nil || linkExpiration <= 0 {
+		linkExpiration = DEFAULT_PASSWORD_RESET_LINK_EXPIRATION
+	}
+
+	return RecoveryConfiguration{
+		ResetURL:       resetURL,
+		LinkExpiration: time.Duration(linkExpiration) * time.Minute,
+	}
+}
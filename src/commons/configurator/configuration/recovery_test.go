@@ -0,0 +1,21 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRecoveryConfiguration(t *testing.T) {
+	conf := createRecoveryConfiguration(map[string]string{}, "http://localhost:3000")
+	assert.Equal(t, "http://localhost:3000/reset-password", conf.ResetURL)
+	assert.Equal(t, 30*time.Minute, conf.LinkExpiration)
+
+	conf = createRecoveryConfiguration(map[string]string{
+		"PASSWORD_RESET_URL":             "https://gallery.example.com/reset",
+		"PASSWORD_RESET_LINK_EXPIRATION": "10",
+	}, "http://localhost:3000")
+	assert.Equal(t, "https://gallery.example.com/reset", conf.ResetURL)
+	assert.Equal(t, 10*time.Minute, conf.LinkExpiration)
+}
@@ -0,0 +1,25 @@
+package configuration
+
+import (
+	"strconv"
+	"time"
+)
+
+const DEFAULT_SESSION_VALIDATION_CACHE_TTL int = 30
+
+// SessionConfiguration define durante cuánto tiempo se reutiliza la validación del usuario de un JWT
+type SessionConfiguration struct {
+	ValidationCacheTTL time.Duration
+}
+
+func createSessionConfiguration(args map[string]string) SessionConfiguration {
+	// Zero disables the cache, so only negative or invalid values fall back to the default
+	cacheTTL, err := strconv.Atoi(args["SESSION_VALIDATION_CACHE_TTL"])
+	if err != nil || cacheTTL < 0 {
+		cacheTTL = DEFAULT_SESSION_VALIDATION_CACHE_TTL
+	}
+
+	return SessionConfiguration{
+		ValidationCacheTTL: time.Duration(cacheTTL) * time.Second,
+	}
+}
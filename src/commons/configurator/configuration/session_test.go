@@ -0,0 +1,22 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSessionConfiguration(t *testing.T) {
+	conf := createSessionConfiguration(map[string]string{"SESSION_VALIDATION_CACHE_TTL": "10"})
+	assert.Equal(t, 10*time.Second, conf.ValidationCacheTTL)
+
+	conf = createSessionConfiguration(map[string]string{"SESSION_VALIDATION_CACHE_TTL": "0"})
+	assert.Equal(t, time.Duration(0), conf.ValidationCacheTTL)
+
+	conf = createSessionConfiguration(map[string]string{"SESSION_VALIDATION_CACHE_TTL": "-1"})
+	assert.Equal(t, 30*time.Second, conf.ValidationCacheTTL)
+
+	conf = createSessionConfiguration(map[string]string{})
+	assert.Equal(t, 30*time.Second, conf.ValidationCacheTTL)
+}
@@ -0,0 +1,36 @@
+package configuration
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_SHUTDOWN_REQUEST_TIMEOUT    int = 15
+	DEFAULT_SHUTDOWN_DEPENDENCY_TIMEOUT int = 10
+)
+
+// ShutdownConfiguration define cuánto se espera al apagar la aplicación a las peticiones en curso
+// y al cierre de las dependencias (conexiones, tareas en segundo plano...)
+type ShutdownConfiguration struct {
+	RequestTimeout    time.Duration
+	DependencyTimeout time.Duration
+}
+
+func createShutdownConfiguration(args map[string]string) ShutdownConfiguration {
+	// Requests still running when the timeout expires are cut off
+	requestTimeout, err := strconv.Atoi(args["SHUTDOWN_REQUEST_TIMEOUT"])
+	if err != nil || requestTimeout <= 0 {
+		requestTimeout = DEFAULT_SHUTDOWN_REQUEST_TIMEOUT
+	}
+
+	dependencyTimeout, err := strconv.Atoi(args["SHUTDOWN_DEPENDENCY_TIMEOUT"])
+	if err != nil || dependencyTimeout <= 0 {
+		dependencyTimeout = DEFAULT_SHUTDOWN_DEPENDENCY_TIMEOUT
+	}
+
+	return ShutdownConfiguration{
+		RequestTimeout:    time.Duration(requestTimeout) * time.Second,
+		DependencyTimeout: time.Duration(dependencyTimeout) * time.Second,
+	}
+}
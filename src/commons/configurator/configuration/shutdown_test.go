@@ -0,0 +1,22 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateShutdownConfiguration(t *testing.T) {
+	conf := createShutdownConfiguration(map[string]string{"SHUTDOWN_REQUEST_TIMEOUT": "30", "SHUTDOWN_DEPENDENCY_TIMEOUT": "5"})
+	assert.Equal(t, 30*time.Second, conf.RequestTimeout)
+	assert.Equal(t, 5*time.Second, conf.DependencyTimeout)
+
+	conf = createShutdownConfiguration(map[string]string{"SHUTDOWN_REQUEST_TIMEOUT": "-1", "SHUTDOWN_DEPENDENCY_TIMEOUT": "abc"})
+	assert.Equal(t, 15*time.Second, conf.RequestTimeout)
+	assert.Equal(t, 10*time.Second, conf.DependencyTimeout)
+
+	conf = createShutdownConfiguration(map[string]string{})
+	assert.Equal(t, 15*time.Second, conf.RequestTimeout)
+	assert.Equal(t, 10*time.Second, conf.DependencyTimeout)
+}
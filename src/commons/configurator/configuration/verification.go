@@ -0,0 +1,48 @@
+package configuration
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_VERIFICATION_RESEND_MAX    int = 3
+	DEFAULT_VERIFICATION_RESEND_WINDOW int = 15
+)
+
+// VerificationConfiguration agrupa las restricciones aplicadas a las cuentas cuyo email no ha sido verificado
+type VerificationConfiguration struct {
+	AllowUnverifiedLogin   bool
+	AllowUnverifiedGallery bool
+	ResendMax              int
+	ResendWindow           time.Duration
+}
+
+func createVerificationConfiguration(args map[string]string) VerificationConfiguration {
+	allowLogin, err := strconv.ParseBool(args["UNVERIFIED_ACCOUNT_ALLOW_LOGIN"])
+	if err != nil {
+		allowLogin = false
+	}
+
+	allowGallery, err := strconv.ParseBool(args["UNVERIFIED_ACCOUNT_ALLOW_GALLERY"])
+	if err != nil {
+		allowGallery = false
+	}
+
+	resendMax, err := strconv.Atoi(args["VERIFICATION_RESEND_MAX"])
+	if err != nil || resendMax <= 0 {
+		resendMax = DEFAULT_VERIFICATION_RESEND_MAX
+	}
+
+	resendWindow, err := strconv.Atoi(args["VERIFICATION_RESEND_WINDOW"])
+	if err != nil || resendWindow <= 0 {
+		resendWindow = DEFAULT_VERIFICATION_RESEND_WINDOW
+	}
+
+	return VerificationConfiguration{
+		AllowUnverifiedLogin:   allowLogin,
+		AllowUnverifiedGallery: allowGallery,
+		ResendMax:              resendMax,
+		ResendWindow:           time.Duration(resendWindow) * time.Minute,
+	}
+}
@@ -0,0 +1,43 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateVerificationConfigurationDefaults(t *testing.T) {
+	conf := createVerificationConfiguration(map[string]string{})
+
+	assert.False(t, conf.AllowUnverifiedLogin)
+	assert.False(t, conf.AllowUnverifiedGallery)
+	assert.Equal(t, DEFAULT_VERIFICATION_RESEND_MAX, conf.ResendMax)
+	assert.Equal(t, time.Duration(DEFAULT_VERIFICATION_RESEND_WINDOW)*time.Minute, conf.ResendWindow)
+}
+
+func TestCreateVerificationConfigurationFromArgs(t *testing.T) {
+	conf := createVerificationConfiguration(map[string]string{
+		"UNVERIFIED_ACCOUNT_ALLOW_LOGIN":   "true",
+		"UNVERIFIED_ACCOUNT_ALLOW_GALLERY": "true",
+		"VERIFICATION_RESEND_MAX":          "5",
+		"VERIFICATION_RESEND_WINDOW":       "30",
+	})
+
+	assert.True(t, conf.AllowUnverifiedLogin)
+	assert.True(t, conf.AllowUnverifiedGallery)
+	assert.Equal(t, 5, conf.ResendMax)
+	assert.Equal(t, 30*time.Minute, conf.ResendWindow)
+}
+
+func TestCreateVerificationConfigurationInvalidValues(t *testing.T) {
+	conf := createVerificationConfiguration(map[string]string{
+		"UNVERIFIED_ACCOUNT_ALLOW_LOGIN": "maybe",
+		"VERIFICATION_RESEND_MAX":        "-1",
+		"VERIFICATION_RESEND_WINDOW":     "abc",
+	})
+
+	assert.False(t, conf.AllowUnverifiedLogin)
+	assert.Equal(t, DEFAULT_VERIFICATION_RESEND_MAX, conf.ResendMax)
+	assert.Equal(t, time.Duration(DEFAULT_VERIFICATION_RESEND_WINDOW)*time.Minute, conf.ResendWindow)
+}
@@ -5,7 +5,14 @@ import (
 	"go-gallery/src/commons/configurator/configuration"
 	dependency_container "go-gallery/src/commons/dependency-container"
 	dependency_dictionary "go-gallery/src/commons/dependency-container/dependency-dictionary"
-	"go-gallery/src/infrastructure/logger"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	log "go-gallery/src/infrastructure/logger"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+	postgresClient "go-gallery/src/infrastructure/postgres"
+	imageRepository "go-gallery/src/infrastructure/repository/image"
+	thumbnailImageRepository "go-gallery/src/infrastructure/repository/image/thumbnailImage"
+	userRepository "go-gallery/src/infrastructure/repository/user"
+	"go-gallery/src/infrastructure/tracing"
 
 	"os"
 	"strings"
@@ -19,8 +26,18 @@ func LoadConfiguration() (configuration.Configuration, dependency_container.Depe
 	logger := buildLogger(configuration)
 
 	logger.Info("Loading configuration...")
-	logger.Info(fmt.Sprintf("Session id established: %v", configuration.GetSessionId()))
-	logger.Info(fmt.Sprintf("Start date: %v", configuration.GetTimestamp().String()))
+	logger.Info("Session id established", log.F("session_id", configuration.GetSessionId()))
+	logger.Info("Start date", log.F("timestamp", configuration.GetTimestamp().String()))
+
+	// Traces are only exported when TRACING_EXPORTER is set, otherwise the no-op provider is kept
+	tracingSettings := tracing.ParseTracingSettings(configuration.GetArgs())
+	tracingSettings.ServiceName = configuration.GetServiceName()
+	tracingSettings.ServiceVersion = configuration.GetVersion()
+	if err := tracing.Init(tracingSettings); err != nil {
+		logger.Warning("Could not initialize the tracing exporter, traces are disabled", log.F("error", err.Error()))
+	} else {
+		logger.Info("Tracing initialized", log.F("exporter", tracingSettings.Exporter))
+	}
 
 	dependencyContainer := buildDependencyContainer(configuration)
 
@@ -44,10 +61,12 @@ func loadArgsEnvConfiguration() map[string]string {
 	return envConfig
 }
 
-func buildLogger(conf *configuration.Configuration) logger.Logger {
-	var loggers []logger.Logger
+func buildLogger(conf *configuration.Configuration) log.Logger {
+	var loggers []log.Logger
 
-	consoleLogger := logger.NewConsoleLogger()
+	// The console prints colored text unless JSON lines are requested
+	consoleLogger := log.NewConsoleLoggerWithFormat(conf.GetArg("LOGGER_FORMAT"))
+	consoleLogger.SetLevel(log.ParseLevelOrDefault(conf.GetArg("LOGGER_LEVEL")))
 	loggers = append(loggers, consoleLogger)
 
 	loggerKey := conf.GetArg("LOGGER_TYPE")
@@ -56,33 +75,79 @@ func buildLogger(conf *configuration.Configuration) logger.Logger {
 		loggers = append(loggers, loggerDependency)
 	}
 
-	compositeLogger := logger.NewCompositeLogger(loggers...)
-	return logger.Init(compositeLogger)
+	compositeLogger := log.NewCompositeLogger(loggers...)
+	// Messages are written by a background worker, its queue size and overflow policy are configurable
+	instance := log.InitWithSettings(compositeLogger, log.ParseAsyncSettings(conf.GetArgs()))
+
+	// Unknown levels fall back to INFO, the warning makes a typo visible
+	for _, key := range []string{"LOGGER_LEVEL", "LOGGER_FILE_LEVEL"} {
+		if level := conf.GetArg(key); level != "" {
+			if _, ok := loggerEntity.ParseLevel(level); !ok {
+				instance.Warning("Unknown log level, using the default", log.F("key", key), log.F("level", level), log.F("default", log.DEFAULT_LOG_LEVEL.String()))
+			}
+		}
+	}
+
+	return instance
 }
 
 func buildDependencyContainer(conf *configuration.Configuration) *dependency_container.DependencyContainer {
 	args := conf.GetArgs()
 	dp := dependency_container.Instance()
 
+	// Every MongoDB repository shares one client and its connection pool. It connects the first time a
+	// repository needs it and, being registered first, it is closed after all of them
+	mongoProvider := mongoClient.NewProvider(mongoClient.ParseSettings(args))
+	dp.RegisterCloser("MongoDBClient", mongoProvider)
+
+	// The PostgreSQL repositories share one connection pool in the same way
+	postgresProvider := postgresClient.NewProvider(postgresClient.ParseSettings(args))
+	dp.RegisterCloser("PostgreSQLPool", postgresProvider)
+
 	emailSenderRepositoryKey := conf.GetArg("EMAIL_SENDER_REPOSITORY")
 	emailSenderRepositoryDependency := dependency_dictionary.FindEmailSenderDependency(emailSenderRepositoryKey, args)
 	dp.SetEmailSenderRepository(emailSenderRepositoryDependency)
 
+	// The operations of the user, image and thumbnail repositories are timed for the /metrics endpoint
 	userRepositoryKey := conf.GetArg("USER_REPOSITORY")
-	userRepositoryDependency := dependency_dictionary.FindUserDependency(userRepositoryKey, args)
-	dp.SetUserRepository(userRepositoryDependency)
+	userRepositoryDependency := dependency_dictionary.FindUserDependency(userRepositoryKey, args, mongoProvider, postgresProvider)
+	dp.SetUserRepository(userRepository.NewUserMetricsRepository(userRepositoryDependency))
 
 	imageRepositoryKey := conf.GetArg("IMAGE_REPOSITORY")
-	imageRepositoryDependency := dependency_dictionary.FindImageDependency(imageRepositoryKey, args)
-	dp.SetImageRepository(imageRepositoryDependency)
+	imageRepositoryDependency := dependency_dictionary.FindImageDependency(imageRepositoryKey, args, mongoProvider)
+	dp.SetImageRepository(imageRepository.NewImageMetricsRepository(imageRepositoryDependency))
 
 	thumbnailImageRepositoryKey := conf.GetArg("THUMBNAIL_IMAGE_REPOSITORY")
-	thumbnailImageRepositoryDependency := dependency_dictionary.FindThumbnailImageDependency(thumbnailImageRepositoryKey, args)
-	dp.SetThumbnailImageRepository(thumbnailImageRepositoryDependency)
+	thumbnailImageRepositoryDependency := dependency_dictionary.FindThumbnailImageDependency(thumbnailImageRepositoryKey, args, mongoProvider)
+	dp.SetThumbnailImageRepository(thumbnailImageRepository.NewThumbnailImageMetricsRepository(thumbnailImageRepositoryDependency))
 
 	codeGeneratorRepositoryKey := conf.GetArg("CODE_GENERATOR_REPOSITORY")
-	codeGeneratorRepositoryDependency := dependency_dictionary.FindCodeGeneratorDependency(codeGeneratorRepositoryKey, args)
+	codeGeneratorRepositoryDependency := dependency_dictionary.FindCodeGeneratorDependency(codeGeneratorRepositoryKey, args, mongoProvider, postgresProvider)
 	dp.SetCodeGeneratorRepository(codeGeneratorRepositoryDependency)
 
+	attemptRepositoryKey := conf.GetArg("ATTEMPT_REPOSITORY")
+	attemptRepositoryDependency := dependency_dictionary.FindAttemptDependency(attemptRepositoryKey, args)
+	dp.SetAttemptRepository(attemptRepositoryDependency)
+
+	exportRepositoryKey := conf.GetArg("EXPORT_REPOSITORY")
+	exportRepositoryDependency := dependency_dictionary.FindExportDependency(exportRepositoryKey, args)
+	dp.SetExportRepository(exportRepositoryDependency)
+
+	avatarRepositoryKey := conf.GetArg("AVATAR_REPOSITORY")
+	avatarRepositoryDependency := dependency_dictionary.FindAvatarDependency(avatarRepositoryKey, args, mongoProvider)
+	dp.SetAvatarRepository(avatarRepositoryDependency)
+
+	auditRepositoryKey := conf.GetArg("AUDIT_REPOSITORY")
+	auditRepositoryDependency := dependency_dictionary.FindAuditDependency(auditRepositoryKey, args, mongoProvider, postgresProvider)
+	dp.SetAuditRepository(auditRepositoryDependency)
+
+	// The repositories connect while they are built, so each database is only checked if one of them uses it
+	if mongoProvider.Connected() {
+		dp.RegisterHealthChecker("MongoDB", mongoProvider)
+	}
+	if postgresProvider.Connected() {
+		dp.RegisterHealthChecker("PostgreSQL", postgresProvider)
+	}
+
 	return dp
 }
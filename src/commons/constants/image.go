@@ -21,3 +21,10 @@ const (
 	THUMBNAIL_WIDTH  int = 200
 	THUMBNAIL_HEIGHT int = 200
 )
+
+// Constantes de los tamaños en los que se generan los avatares cuadrados
+const (
+	AVATAR_SMALL_SIZE  int = 64
+	AVATAR_MEDIUM_SIZE int = 128
+	AVATAR_LARGE_SIZE  int = 256
+)
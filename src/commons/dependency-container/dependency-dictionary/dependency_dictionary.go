@@ -2,38 +2,49 @@ package dependency_dictionary
 
 import (
 	"go-gallery/src/infrastructure/logger"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+	postgresClient "go-gallery/src/infrastructure/postgres"
+	attemptRepository "go-gallery/src/infrastructure/repository/attempt"
+	auditRepository "go-gallery/src/infrastructure/repository/audit"
+	avatarRepository "go-gallery/src/infrastructure/repository/avatar"
 	codeGeneratorRepository "go-gallery/src/infrastructure/repository/codeGenerator"
 	emailSenderRepository "go-gallery/src/infrastructure/repository/emailSender"
+	exportRepository "go-gallery/src/infrastructure/repository/export"
 	imageRepository "go-gallery/src/infrastructure/repository/image"
 	thumbnailImageRepository "go-gallery/src/infrastructure/repository/image/thumbnailImage"
 	userRepository "go-gallery/src/infrastructure/repository/user"
 )
 
+// FindLoggerDependency devuelve el logger que se añade al de consola, nil si solo se usa la consola
 func FindLoggerDependency(code string, args map[string]string) logger.Logger {
-	//TODO implementar cuando tengamos el de fichero
-	return nil
+	switch code {
+	case logger.FileLoggerKey:
+		return logger.NewFileLogger(args)
+	default:
+		return nil
+	}
 }
 
-func FindImageDependency(code string, args map[string]string) imageRepository.ImageRepository {
+func FindImageDependency(code string, args map[string]string, mongoProvider *mongoClient.Provider) imageRepository.ImageRepository {
 	switch code {
 	default:
-		return imageRepository.NewImageMongoDBRepository(args)
+		return imageRepository.NewImageMongoDBRepository(mongoProvider)
 	}
 }
 
-func FindThumbnailImageDependency(code string, args map[string]string) thumbnailImageRepository.ThumbnailImageRepository {
+func FindThumbnailImageDependency(code string, args map[string]string, mongoProvider *mongoClient.Provider) thumbnailImageRepository.ThumbnailImageRepository {
 	switch code {
 	default:
-		return thumbnailImageRepository.NewThumbnailImageMongoDBRepository(args)
+		return thumbnailImageRepository.NewThumbnailImageMongoDBRepository(mongoProvider)
 	}
 }
 
-func FindUserDependency(code string, args map[string]string) userRepository.UserRepository {
+func FindUserDependency(code string, args map[string]string, mongoProvider *mongoClient.Provider, postgresProvider *postgresClient.Provider) userRepository.UserRepository {
 	switch code {
 	case userRepository.UserMongoDBRepositoryKey:
-		return userRepository.NewUserMongoDBRepository(args)
+		return userRepository.NewUserMongoDBRepository(mongoProvider)
 	default:
-		return userRepository.NewUserPostgreSQLRepository(args)
+		return userRepository.NewUserPostgreSQLRepository(postgresProvider)
 	}
 }
 
@@ -44,9 +55,49 @@ func FindEmailSenderDependency(code string, args map[string]string) emailSenderR
 	}
 }
 
-func FindCodeGeneratorDependency(code string, args map[string]string) codeGeneratorRepository.CodeGeneratorRepository {
+func FindCodeGeneratorDependency(code string, args map[string]string, mongoProvider *mongoClient.Provider, postgresProvider *postgresClient.Provider) codeGeneratorRepository.CodeGeneratorRepository {
 	switch code {
+	case codeGeneratorRepository.CodeGeneratorMongoDBRepositoryKey:
+		return codeGeneratorRepository.NewCodeGeneratorMongoDBRepository(args, mongoProvider)
+	case codeGeneratorRepository.CodeGeneratorPostgreSQLRepositoryKey:
+		return codeGeneratorRepository.NewCodeGeneratorPostgreSQLRepository(args, postgresProvider)
+	case codeGeneratorRepository.CodeGeneratorRedisRepositoryKey:
+		return codeGeneratorRepository.NewCodeGeneratorRedisRepository(args)
 	default:
 		return codeGeneratorRepository.NewCodeGeneratorMemory(args)
 	}
 }
+
+func FindAttemptDependency(code string, args map[string]string) attemptRepository.AttemptRepository {
+	switch code {
+	default:
+		return attemptRepository.NewAttemptMemoryRepository(args)
+	}
+}
+
+func FindExportDependency(code string, args map[string]string) exportRepository.ExportRepository {
+	switch code {
+	default:
+		return exportRepository.NewExportMemoryRepository(args)
+	}
+}
+
+func FindAvatarDependency(code string, args map[string]string, mongoProvider *mongoClient.Provider) avatarRepository.AvatarRepository {
+	switch code {
+	case avatarRepository.AvatarMemoryRepositoryKey:
+		return avatarRepository.NewAvatarMemoryRepository(args)
+	default:
+		return avatarRepository.NewAvatarMongoDBRepository(mongoProvider)
+	}
+}
+
+func FindAuditDependency(code string, args map[string]string, mongoProvider *mongoClient.Provider, postgresProvider *postgresClient.Provider) auditRepository.AuditRepository {
+	switch code {
+	case auditRepository.AuditMongoDBRepositoryKey:
+		return auditRepository.NewAuditMongoDBRepository(mongoProvider)
+	case auditRepository.AuditMemoryRepositoryKey:
+		return auditRepository.NewAuditMemoryRepository(args)
+	default:
+		return auditRepository.NewAuditPostgreSQLRepository(postgresProvider)
+	}
+}
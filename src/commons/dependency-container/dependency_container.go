@@ -3,8 +3,12 @@ package dependency_container
 import (
 	"fmt"
 	log "go-gallery/src/infrastructure/logger"
+	attemptRepository "go-gallery/src/infrastructure/repository/attempt"
+	auditRepository "go-gallery/src/infrastructure/repository/audit"
+	avatarRepository "go-gallery/src/infrastructure/repository/avatar"
 	codeGeneratorRepository "go-gallery/src/infrastructure/repository/codeGenerator"
 	emailSenderRepository "go-gallery/src/infrastructure/repository/emailSender"
+	exportRepository "go-gallery/src/infrastructure/repository/export"
 	imageRepository "go-gallery/src/infrastructure/repository/image"
 	thumbnailImageRepository "go-gallery/src/infrastructure/repository/image/thumbnailImage"
 	userRepository "go-gallery/src/infrastructure/repository/user"
@@ -16,6 +20,12 @@ type DependencyContainer struct {
 	thumbnailImageRepository thumbnailImageRepository.ThumbnailImageRepository
 	codeGeneratorRepository  codeGeneratorRepository.CodeGeneratorRepository
 	emailSenderRepository    emailSenderRepository.EmailSenderRepository
+	attemptRepository        attemptRepository.AttemptRepository
+	exportRepository         exportRepository.ExportRepository
+	avatarRepository         avatarRepository.AvatarRepository
+	auditRepository          auditRepository.AuditRepository
+	closers                  []namedCloser
+	healthCheckers           map[string]HealthChecker
 }
 
 var dependencyContainer *DependencyContainer
@@ -40,7 +50,8 @@ func (dp *DependencyContainer) GetUserRepository() userRepository.UserRepository
 
 func (dp *DependencyContainer) SetUserRepository(userDependency userRepository.UserRepository) {
 	dp.userRepository = userDependency
-	logger.Info(fmt.Sprintf("Dependency UserRepository has been set. Implementation: %T", userDependency))
+	logger.Info("Dependency has been set", log.F("dependency", "UserRepository"), log.F("implementation", fmt.Sprintf("%T", userDependency)))
+	dp.RegisterCloser("UserRepository", userDependency)
 }
 
 func (dp *DependencyContainer) GetImageRepository() imageRepository.ImageRepository {
@@ -52,7 +63,8 @@ func (dp *DependencyContainer) GetImageRepository() imageRepository.ImageReposit
 
 func (dp *DependencyContainer) SetImageRepository(imageDependency imageRepository.ImageRepository) {
 	dp.imageRepository = imageDependency
-	logger.Info(fmt.Sprintf("Dependency ImageRepository has been set. Implementation: %T", imageDependency))
+	logger.Info("Dependency has been set", log.F("dependency", "ImageRepository"), log.F("implementation", fmt.Sprintf("%T", imageDependency)))
+	dp.RegisterCloser("ImageRepository", imageDependency)
 }
 
 func (dp *DependencyContainer) GetEmailSenderRepository() emailSenderRepository.EmailSenderRepository {
@@ -64,12 +76,14 @@ func (dp *DependencyContainer) GetEmailSenderRepository() emailSenderRepository.
 
 func (dp *DependencyContainer) SetEmailSenderRepository(emailDependency emailSenderRepository.EmailSenderRepository) {
 	dp.emailSenderRepository = emailDependency
-	logger.Info(fmt.Sprintf("Dependency EmailSenderRepository has been set. Implementation: %T", emailDependency))
+	logger.Info("Dependency has been set", log.F("dependency", "EmailSenderRepository"), log.F("implementation", fmt.Sprintf("%T", emailDependency)))
+	dp.RegisterCloser("EmailSenderRepository", emailDependency)
 }
 
 func (dp *DependencyContainer) SetThumbnailImageRepository(thumbnailImageDependency thumbnailImageRepository.ThumbnailImageRepository) {
 	dp.thumbnailImageRepository = thumbnailImageDependency
-	logger.Info(fmt.Sprintf("Dependency ThumbnailImageRepository has been set. Implementation: %T", thumbnailImageDependency))
+	logger.Info("Dependency has been set", log.F("dependency", "ThumbnailImageRepository"), log.F("implementation", fmt.Sprintf("%T", thumbnailImageDependency)))
+	dp.RegisterCloser("ThumbnailImageRepository", thumbnailImageDependency)
 }
 
 func (dp *DependencyContainer) GetThumbnailImageRepository() thumbnailImageRepository.ThumbnailImageRepository {
@@ -81,7 +95,8 @@ func (dp *DependencyContainer) GetThumbnailImageRepository() thumbnailImageRepos
 
 func (dp *DependencyContainer) SetCodeGeneratorRepository(codeGeneratorDependency codeGeneratorRepository.CodeGeneratorRepository) {
 	dp.codeGeneratorRepository = codeGeneratorDependency
-	logger.Info(fmt.Sprintf("Dependency CodeGeneratorRepository has been set. Implementation: %T", codeGeneratorDependency))
+	logger.Info("Dependency has been set", log.F("dependency", "CodeGeneratorRepository"), log.F("implementation", fmt.Sprintf("%T", codeGeneratorDependency)))
+	dp.RegisterCloser("CodeGeneratorRepository", codeGeneratorDependency)
 }
 
 func (dp *DependencyContainer) GetCodeGeneratorRepository() codeGeneratorRepository.CodeGeneratorRepository {
@@ -90,3 +105,55 @@ func (dp *DependencyContainer) GetCodeGeneratorRepository() codeGeneratorReposit
 	}
 	panic("Dependency ThumbnailImageRepository not found.")
 }
+
+func (dp *DependencyContainer) SetAttemptRepository(attemptDependency attemptRepository.AttemptRepository) {
+	dp.attemptRepository = attemptDependency
+	logger.Info("Dependency has been set", log.F("dependency", "AttemptRepository"), log.F("implementation", fmt.Sprintf("%T", attemptDependency)))
+	dp.RegisterCloser("AttemptRepository", attemptDependency)
+}
+
+func (dp *DependencyContainer) GetAttemptRepository() attemptRepository.AttemptRepository {
+	if dp.attemptRepository != nil {
+		return dp.attemptRepository
+	}
+	panic("Dependency AttemptRepository not found.")
+}
+
+func (dp *DependencyContainer) SetExportRepository(exportDependency exportRepository.ExportRepository) {
+	dp.exportRepository = exportDependency
+	logger.Info("Dependency has been set", log.F("dependency", "ExportRepository"), log.F("implementation", fmt.Sprintf("%T", exportDependency)))
+	dp.RegisterCloser("ExportRepository", exportDependency)
+}
+
+func (dp *DependencyContainer) GetExportRepository() exportRepository.ExportRepository {
+	if dp.exportRepository != nil {
+		return dp.exportRepository
+	}
+	panic("Dependency ExportRepository not found.")
+}
+
+func (dp *DependencyContainer) SetAvatarRepository(avatarDependency avatarRepository.AvatarRepository) {
+	dp.avatarRepository = avatarDependency
+	logger.Info("Dependency has been set", log.F("dependency", "AvatarRepository"), log.F("implementation", fmt.Sprintf("%T", avatarDependency)))
+	dp.RegisterCloser("AvatarRepository", avatarDependency)
+}
+
+func (dp *DependencyContainer) GetAvatarRepository() avatarRepository.AvatarRepository {
+	if dp.avatarRepository != nil {
+		return dp.avatarRepository
+	}
+	panic("Dependency AvatarRepository not found.")
+}
+
+func (dp *DependencyContainer) SetAuditRepository(auditDependency auditRepository.AuditRepository) {
+	dp.auditRepository = auditDependency
+	logger.Info("Dependency has been set", log.F("dependency", "AuditRepository"), log.F("implementation", fmt.Sprintf("%T", auditDependency)))
+	dp.RegisterCloser("AuditRepository", auditDependency)
+}
+
+func (dp *DependencyContainer) GetAuditRepository() auditRepository.AuditRepository {
+	if dp.auditRepository != nil {
+		return dp.auditRepository
+	}
+	panic("Dependency AuditRepository not found.")
+}
@@ -0,0 +1,46 @@
+package dependency_container
+
+import "context"
+
+// HealthChecker lo implementan las dependencias que pueden comprobar si su servicio externo
+// (MongoDB, PostgreSQL, Redis...) está disponible. Las implementaciones en memoria no lo implementan
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// RegisterHealthChecker añade una conexión compartida por varios repositorios, como el cliente de MongoDB,
+// para que se compruebe una sola vez con su propio nombre en lugar de una vez por repositorio
+func (dp *DependencyContainer) RegisterHealthChecker(name string, checker HealthChecker) {
+	if dp.healthCheckers == nil {
+		dp.healthCheckers = make(map[string]HealthChecker)
+	}
+	dp.healthCheckers[name] = checker
+}
+
+// HealthCheckers devuelve, por nombre de dependencia, las conexiones compartidas y los repositorios configurados
+// que saben comprobar su estado
+func (dp *DependencyContainer) HealthCheckers() map[string]HealthChecker {
+	dependencies := map[string]any{
+		"UserRepository":           dp.userRepository,
+		"ImageRepository":          dp.imageRepository,
+		"ThumbnailImageRepository": dp.thumbnailImageRepository,
+		"CodeGeneratorRepository":  dp.codeGeneratorRepository,
+		"EmailSenderRepository":    dp.emailSenderRepository,
+		"AttemptRepository":        dp.attemptRepository,
+		"ExportRepository":         dp.exportRepository,
+		"AvatarRepository":         dp.avatarRepository,
+		"AuditRepository":          dp.auditRepository,
+	}
+
+	checkers := make(map[string]HealthChecker)
+	for name, checker := range dp.healthCheckers {
+		checkers[name] = checker
+	}
+	for name, dependency := range dependencies {
+		// Decorators such as the metrics ones are skipped so the implementation behind them is checked
+		if checker, ok := unwrap(dependency).(HealthChecker); ok {
+			checkers[name] = checker
+		}
+	}
+	return checkers
+}
@@ -0,0 +1,25 @@
+package dependency_container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type healthCheckerStub struct{}
+
+func (h *healthCheckerStub) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func TestHealthCheckersListSharedConnectionsOnce(t *testing.T) {
+	shared := &healthCheckerStub{}
+	dp := new(DependencyContainer)
+	dp.RegisterHealthChecker("MongoDB", shared)
+
+	checkers := dp.HealthCheckers()
+
+	assert.Len(t, checkers, 1)
+	assert.Same(t, shared, checkers["MongoDB"])
+}
@@ -0,0 +1,58 @@
+package dependency_container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "go-gallery/src/infrastructure/logger"
+)
+
+// Closer lo implementan las dependencias que mantienen conexiones o tareas en segundo plano
+// (clientes de MongoDB, pools de PostgreSQL, limpiezas periódicas...) y deben liberarlas al apagar la aplicación
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+type namedCloser struct {
+	name   string
+	closer Closer
+}
+
+// RegisterCloser añade una dependencia a las que se cierran al apagar la aplicación.
+// Los repositorios se registran solos al asignarlos, los servicios con tareas en segundo plano se registran a mano
+func (dp *DependencyContainer) RegisterCloser(name string, dependency any) {
+	closer, ok := unwrap(dependency).(Closer)
+	if !ok {
+		return
+	}
+	dp.closers = append(dp.closers, namedCloser{name: name, closer: closer})
+}
+
+// Close cierra las dependencias registradas en orden inverso al de su registro, así las que se crearon
+// después, y pueden usar a las anteriores, se cierran antes. Un error no impide cerrar el resto
+func (dp *DependencyContainer) Close(ctx context.Context) error {
+	var errs []error
+	for i := len(dp.closers) - 1; i >= 0; i-- {
+		dependency := dp.closers[i]
+		if err := dependency.closer.Close(ctx); err != nil {
+			logger.Error("Error closing dependency", log.F("dependency", dependency.name), log.F("error", err.Error()))
+			errs = append(errs, fmt.Errorf("%s: %w", dependency.name, err))
+			continue
+		}
+		logger.Info("Dependency has been closed", log.F("dependency", dependency.name))
+	}
+	dp.closers = nil
+	return errors.Join(errs...)
+}
+
+// unwrap skips decorators such as the metrics ones and returns the implementation behind them
+func unwrap(dependency any) any {
+	for {
+		decorator, ok := dependency.(interface{ Unwrap() any })
+		if !ok {
+			return dependency
+		}
+		dependency = decorator.Unwrap()
+	}
+}
@@ -0,0 +1,68 @@
+package dependency_container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	log "go-gallery/src/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closerStub struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (c *closerStub) Close(ctx context.Context) error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+type decoratorStub struct {
+	inner any
+}
+
+func (d *decoratorStub) Unwrap() any {
+	return d.inner
+}
+
+func TestCloseInReverseOrder(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	logger = log.Instance()
+	var closed []string
+	dp := new(DependencyContainer)
+
+	dp.RegisterCloser("first", &closerStub{name: "first", closed: &closed})
+	dp.RegisterCloser("second", &decoratorStub{inner: &closerStub{name: "second", closed: &closed}})
+	dp.RegisterCloser("ignored", struct{}{})
+	dp.RegisterCloser("third", &closerStub{name: "third", closed: &closed})
+
+	err := dp.Close(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"third", "second", "first"}, closed)
+}
+
+func TestCloseContinuesAfterAnError(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	logger = log.Instance()
+	var closed []string
+	failure := errors.New("connection reset")
+	dp := new(DependencyContainer)
+
+	dp.RegisterCloser("first", &closerStub{name: "first", closed: &closed})
+	dp.RegisterCloser("second", &closerStub{name: "second", closed: &closed, err: failure})
+
+	err := dp.Close(context.Background())
+
+	assert.ErrorIs(t, err, failure)
+	assert.Contains(t, err.Error(), "second")
+	assert.Equal(t, []string{"second", "first"}, closed)
+
+	// The dependencies are only closed once
+	assert.NoError(t, dp.Close(context.Background()))
+	assert.Len(t, closed, 2)
+}
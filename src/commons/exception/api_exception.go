@@ -10,6 +10,9 @@ type ApiException struct {
 	// Mensaje de error
 	// example "Solicitud incorrecta"
 	Message string `json:"message"  example:"Solicitud incorrecta"`
+
+	// Detalle de cada una de las reglas incumplidas, si las hay
+	Details []string `json:"details,omitempty"  example:"The password must contain at least one digit"`
 }
 
 func NewApiException(status int, message string) *ApiException {
@@ -18,3 +21,11 @@ func NewApiException(status int, message string) *ApiException {
 		Message: message,
 	}
 }
+
+func NewApiExceptionWithDetails(status int, message string, details []string) *ApiException {
+	return &ApiException{
+		Status:  status,
+		Message: message,
+		Details: details,
+	}
+}
@@ -3,6 +3,7 @@ package utilsImage
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"image"
 
 	_ "image/jpeg"  
@@ -14,6 +15,15 @@ import (
 	"golang.org/x/image/draw"
 )
 
+var ErrInvalidCropBox = errors.New("the crop box is outside the image")
+
+// CropBox es el cuadrado de la imagen original que se recorta, un tamaño 0 usa el mayor cuadrado centrado
+type CropBox struct {
+	X    int
+	Y    int
+	Size int
+}
+
 // ResizeImage redimensiona la imagen y la convierte a WebP.
 func ResizeImage(input []byte, width, height int) ([]byte, error) {
 	// Decodificar la imagen de entrada
@@ -22,14 +32,59 @@ func ResizeImage(input []byte, width, height int) ([]byte, error) {
 		return nil, err
 	}
 
+	return scaleToWebP(img, img.Bounds(), width, height)
+}
+
+// CropSquareImage recorta el cuadrado indicado y lo redimensiona a WebP en cada uno de los tamaños.
+func CropSquareImage(input []byte, crop CropBox, sizes []int) (map[int][]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+
+	area, err := cropArea(img.Bounds(), crop)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[int][]byte, len(sizes))
+	for _, size := range sizes {
+		webp, err := scaleToWebP(img, area, size, size)
+		if err != nil {
+			return nil, err
+		}
+		rendered[size] = webp
+	}
+
+	return rendered, nil
+}
+
+func cropArea(bounds image.Rectangle, crop CropBox) (image.Rectangle, error) {
+	if crop.Size == 0 && crop.X == 0 && crop.Y == 0 {
+		side := min(bounds.Dx(), bounds.Dy())
+		origin := bounds.Min.Add(image.Pt((bounds.Dx()-side)/2, (bounds.Dy()-side)/2))
+		return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(side, side))}, nil
+	}
+
+	// The crop box is relative to the top left corner of the image
+	origin := bounds.Min.Add(image.Pt(crop.X, crop.Y))
+	area := image.Rectangle{Min: origin, Max: origin.Add(image.Pt(crop.Size, crop.Size))}
+	if crop.X < 0 || crop.Y < 0 || crop.Size <= 0 || !area.In(bounds) {
+		return image.Rectangle{}, ErrInvalidCropBox
+	}
+
+	return area, nil
+}
+
+func scaleToWebP(img image.Image, area image.Rectangle, width, height int) ([]byte, error) {
 	// Crear una nueva imagen en blanco para el redimensionamiento
 	dst := image.NewRGBA(image.Rect(0, 0, width, height))
 
 	// Redimensionar la imagen
-	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, area, draw.Over, nil)
 
 	var buf bytes.Buffer
-	err = nativewebp.Encode(&buf, dst, nil)
+	err := nativewebp.Encode(&buf, dst, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,10 @@
 package utilsImage
 
 import (
+	"bytes"
 	"go-gallery/src/commons/constants"
+	"image"
+	"image/png"
 	"os"
 	"path/filepath"
 	"slices"
@@ -56,6 +59,33 @@ func TestResizeImagesError(t *testing.T) {
 	assert.Error(t, err, "Se esperaba un error al intentar redimensionar la imagen")
 }
 
+func TestCropSquareImage(t *testing.T) {
+	source := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, source))
+
+	rendered, err := CropSquareImage(buf.Bytes(), CropBox{X: 50, Y: 20, Size: 150}, []int{64, 128})
+	require.NoError(t, err)
+	assert.Len(t, rendered, 2)
+	for size, webp := range rendered {
+		img, _, err := image.Decode(bytes.NewReader(webp))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, size, size), img.Bounds())
+	}
+
+	_, err = CropSquareImage(buf.Bytes(), CropBox{X: 200, Y: 0, Size: 150}, []int{64})
+	assert.ErrorIs(t, err, ErrInvalidCropBox)
+}
+
+func TestCropAreaDefaultsToCenteredSquare(t *testing.T) {
+	area, err := cropArea(image.Rect(0, 0, 300, 200), CropBox{})
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(50, 0, 250, 200), area)
+
+	_, err = cropArea(image.Rect(0, 0, 300, 200), CropBox{X: -1, Y: 0, Size: 10})
+	assert.ErrorIs(t, err, ErrInvalidCropBox)
+}
+
 func TestEncondeImageToBase64(t *testing.T) {
 	data := []byte("testdata")
 	b64 := EncondeImageToBase64(data)
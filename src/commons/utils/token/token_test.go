@@ -0,0 +1,25 @@
+package utilsToken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateToken(t *testing.T) {
+	token, err := GenerateToken(32)
+
+	assert.NoError(t, err)
+	assert.Len(t, token, 64)
+
+	other, _ := GenerateToken(32)
+	assert.NotEqual(t, token, other, "expected two different tokens")
+}
+
+func TestHashToken(t *testing.T) {
+	hash := HashToken("token")
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashToken("token"))
+	assert.NotEqual(t, hash, HashToken("other-token"))
+}
@@ -0,0 +1,99 @@
+package passwordValidator
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// Same prefix length as the k-anonymity range API of Have I Been Pwned
+	HASH_PREFIX_LENGTH int = 5
+	SHA1_HEX_LENGTH    int = 40
+)
+
+// BreachedList guarda los SHA-1 de contraseñas filtradas agrupados por los primeros caracteres del hash, igual que las
+// descargas por rangos de Have I Been Pwned, de forma que nunca se guarda ni se consulta la contraseña en claro
+type BreachedList struct {
+	buckets map[string]map[string]struct{}
+	size    int
+}
+
+// LoadBreachedList lee un fichero con un hash SHA-1 en hexadecimal por línea, opcionalmente seguido de :<ocurrencias>
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadBreachedList(file)
+}
+
+// ReadBreachedList admite líneas vacías y comentarios empezando por #, cualquier otra línea que no sea un hash es un error
+func ReadBreachedList(reader io.Reader) (*BreachedList, error) {
+	list := &BreachedList{buckets: make(map[string]map[string]struct{})}
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != SHA1_HEX_LENGTH {
+			return nil, fmt.Errorf("invalid SHA-1 hash on line %d", lineNumber)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("invalid SHA-1 hash on line %d", lineNumber)
+		}
+
+		list.add(hash)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (l *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	bucket, ok := l.buckets[hash[:HASH_PREFIX_LENGTH]]
+	if !ok {
+		return false
+	}
+
+	_, found := bucket[hash[HASH_PREFIX_LENGTH:]]
+	return found
+}
+
+func (l *BreachedList) Size() int {
+	return l.size
+}
+
+func (l *BreachedList) add(hash string) {
+	prefix, suffix := hash[:HASH_PREFIX_LENGTH], hash[HASH_PREFIX_LENGTH:]
+
+	bucket, ok := l.buckets[prefix]
+	if !ok {
+		bucket = make(map[string]struct{})
+		l.buckets[prefix] = bucket
+	}
+
+	if _, exists := bucket[suffix]; !exists {
+		bucket[suffix] = struct{}{}
+		l.size++
+	}
+}
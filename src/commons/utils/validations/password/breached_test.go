@@ -0,0 +1,49 @@
+package passwordValidator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBreachedList(t *testing.T) {
+	content := strings.Join([]string{
+		"# Comments and empty lines are ignored",
+		"",
+		"32ca9fc1a0f5b6330e3f4c8c1bbecde9bedb9573",
+		"32CA9FC1A0F5B6330E3F4C8C1BBECDE9BEDB9573:7",
+		// SHA-1 of "password"
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824",
+	}, "\n")
+
+	list, err := ReadBreachedList(strings.NewReader(content))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, list.Size())
+	assert.True(t, list.Contains("Password1!"))
+	assert.True(t, list.Contains("password"))
+	assert.False(t, list.Contains("Password"))
+}
+
+func TestReadBreachedListInvalidLine(t *testing.T) {
+	_, err := ReadBreachedList(strings.NewReader("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\nnot-a-hash\n"))
+	assert.EqualError(t, err, "invalid SHA-1 hash on line 2")
+
+	_, err = ReadBreachedList(strings.NewReader("ZZAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\n"))
+	assert.EqualError(t, err, "invalid SHA-1 hash on line 1")
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\n"), 0o600))
+
+	list, err := LoadBreachedList(path)
+	assert.NoError(t, err)
+	assert.True(t, list.Contains("password"))
+
+	_, err = LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
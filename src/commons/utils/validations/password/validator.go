@@ -0,0 +1,107 @@
+package passwordValidator
+
+import (
+	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
+	"go-gallery/src/commons/exception"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+const (
+	MIN_LENGTH_MSG string = "The password must be at least %d characters long"
+	MAX_LENGTH_MSG string = "The password must be at most %d characters long"
+	UPPERCASE_MSG  string = "The password must contain at least one uppercase letter"
+	LOWERCASE_MSG  string = "The password must contain at least one lowercase letter"
+	DIGIT_MSG      string = "The password must contain at least one digit"
+	SPECIAL_MSG    string = "The password must contain at least one special character"
+	USER_DATA_MSG  string = "The password must not contain the username or the email"
+	BREACHED_MSG   string = "The password has appeared in a data breach, choose a different one"
+
+	// Shorter usernames or email names would reject too many valid passwords
+	MIN_USER_DATA_LENGTH int = 3
+)
+
+// Validator comprueba las contraseñas contra la política configurada y, si se ha cargado, la lista de contraseñas filtradas
+type Validator struct {
+	policy   configuration.PasswordPolicyConfiguration
+	breached *BreachedList
+}
+
+func NewValidator(policy configuration.PasswordPolicyConfiguration, breached *BreachedList) *Validator {
+	return &Validator{
+		policy:   policy,
+		breached: breached,
+	}
+}
+
+// Validate devuelve un error 400 cuyo mensaje es la primera regla incumplida y cuyos detalles las incluyen todas
+func (v *Validator) Validate(password, username, email string) *exception.ApiException {
+	failures := v.FailedRules(password, username, email)
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return exception.NewApiExceptionWithDetails(http.StatusBadRequest, failures[0], failures)
+}
+
+func (v *Validator) FailedRules(password, username, email string) []string {
+	failures := []string{}
+
+	if len(password) < v.policy.MinLength {
+		failures = append(failures, fmt.Sprintf(MIN_LENGTH_MSG, v.policy.MinLength))
+	}
+	if len(password) > v.policy.MaxLength {
+		failures = append(failures, fmt.Sprintf(MAX_LENGTH_MSG, v.policy.MaxLength))
+	}
+
+	var hasUppercase, hasLowercase, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUppercase = true
+		case unicode.IsLower(r):
+			hasLowercase = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			hasSpecial = true
+		}
+	}
+
+	if v.policy.RequireUppercase && !hasUppercase {
+		failures = append(failures, UPPERCASE_MSG)
+	}
+	if v.policy.RequireLowercase && !hasLowercase {
+		failures = append(failures, LOWERCASE_MSG)
+	}
+	if v.policy.RequireDigit && !hasDigit {
+		failures = append(failures, DIGIT_MSG)
+	}
+	if v.policy.RequireSpecial && !hasSpecial {
+		failures = append(failures, SPECIAL_MSG)
+	}
+	if v.policy.ForbidUserData && containsUserData(password, username, email) {
+		failures = append(failures, USER_DATA_MSG)
+	}
+	if v.breached != nil && v.breached.Contains(password) {
+		failures = append(failures, BREACHED_MSG)
+	}
+
+	return failures
+}
+
+// The comparison ignores case, and only the part before the @ of the email is used
+func containsUserData(password, username, email string) bool {
+	password = strings.ToLower(password)
+	localPart, _, _ := strings.Cut(email, "@")
+
+	for _, value := range []string{username, localPart} {
+		if len(value) >= MIN_USER_DATA_LENGTH && strings.Contains(password, strings.ToLower(value)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,83 @@
+package passwordValidator
+
+import (
+	"go-gallery/src/commons/configurator/configuration"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func defaultPolicy() configuration.PasswordPolicyConfiguration {
+	return configuration.PasswordPolicyConfiguration{
+		MinLength:        8,
+		MaxLength:        72,
+		RequireUppercase: true,
+		RequireSpecial:   true,
+		ForbidUserData:   true,
+	}
+}
+
+func TestValidateValidPassword(t *testing.T) {
+	validator := NewValidator(defaultPolicy(), nil)
+	assert.Nil(t, validator.Validate("ValidPass123!", "validuser", "validuser@example.com"))
+}
+
+func TestValidateListsEveryFailedRule(t *testing.T) {
+	policy := defaultPolicy()
+	policy.RequireDigit = true
+	validator := NewValidator(policy, nil)
+
+	err := validator.Validate("short", "alice", "alice@example.com")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Status)
+	assert.Equal(t, "The password must be at least 8 characters long", err.Message)
+	assert.Equal(t, []string{
+		"The password must be at least 8 characters long",
+		UPPERCASE_MSG,
+		DIGIT_MSG,
+		SPECIAL_MSG,
+	}, err.Details)
+}
+
+func TestValidateMaxLength(t *testing.T) {
+	validator := NewValidator(defaultPolicy(), nil)
+
+	err := validator.Validate("A!"+strings.Repeat("a", 71), "alice", "alice@example.com")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"The password must be at most 72 characters long"}, err.Details)
+}
+
+func TestValidateUserData(t *testing.T) {
+	validator := NewValidator(defaultPolicy(), nil)
+
+	err := validator.Validate("My-ALICE-Pass", "alice", "someone@example.com")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{USER_DATA_MSG}, err.Details)
+
+	err = validator.Validate("Secret-wonderland", "alice", "wonderland@example.com")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{USER_DATA_MSG}, err.Details)
+
+	// Very short usernames are not checked
+	assert.Nil(t, validator.Validate("Al-Password", "al", "al@example.com"))
+
+	policy := defaultPolicy()
+	policy.ForbidUserData = false
+	assert.Nil(t, NewValidator(policy, nil).Validate("My-ALICE-Pass", "alice", "alice@example.com"))
+}
+
+func TestValidateBreachedPassword(t *testing.T) {
+	// SHA-1 of "Password1!"
+	breached, errList := ReadBreachedList(strings.NewReader("32CA9FC1A0F5B6330E3F4C8C1BBECDE9BEDB9573:42\n"))
+	assert.NoError(t, errList)
+	validator := NewValidator(defaultPolicy(), breached)
+
+	err := validator.Validate("Password1!", "alice", "alice@example.com")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{BREACHED_MSG}, err.Details)
+
+	assert.Nil(t, validator.Validate("Password2!", "alice", "alice@example.com"))
+}
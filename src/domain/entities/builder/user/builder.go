@@ -4,7 +4,7 @@ import (
 	"go-gallery/src/commons/exception"
 	userEntity "go-gallery/src/domain/entities/user"
 	userDTO "go-gallery/src/infrastructure/dto/user"
-	"strings"
+	"time"
 )
 
 type UserBuilder struct {
@@ -13,6 +13,15 @@ type UserBuilder struct {
 	email     string
 	lastname  string
 	firstname string
+	role      string
+	verified  bool
+	disabled  bool
+
+	// Solo las contraseñas leídas de la base de datos están hasheadas, las recibidas en una petición nunca
+	passwordHashed bool
+
+	sessionVersion      int64
+	deletionScheduledAt *time.Time
 }
 
 func NewUserBuilder() *UserBuilder {
@@ -25,6 +34,11 @@ func (b *UserBuilder) FromDTO(dto *userDTO.UserDTO) *UserBuilder {
 	b.email = dto.Email
 	b.lastname = dto.Lastname
 	b.firstname = dto.Firstname
+	b.role = dto.Role
+	b.verified = dto.Verified
+	b.disabled = dto.Disabled
+	b.sessionVersion = dto.SessionVersion
+	b.deletionScheduledAt = dto.DeletionScheduledAt
 
 	return b
 }
@@ -35,7 +49,7 @@ func (b *UserBuilder) Build() (*userEntity.User, *exception.BuilderException) {
 		return nil, err
 	}
 
-	if !b.isHashed(b.password) {
+	if !b.passwordHashed {
 		hashedPassword, err := userEntity.HashPassword(b.password)
 		if err != nil {
 			return nil, exception.NewBuilderException("password", "Error al hashear la contraseña")
@@ -43,7 +57,7 @@ func (b *UserBuilder) Build() (*userEntity.User, *exception.BuilderException) {
 		b.password = hashedPassword
 	}
 
-	return userEntity.NewUser(b.username, b.password, b.email, b.lastname, b.firstname), nil
+	return userEntity.NewUser(b.username, b.password, b.email, b.lastname, b.firstname, b.role, b.verified, b.disabled, b.sessionVersion, b.deletionScheduledAt), nil
 }
 
 func (b *UserBuilder) validateUser() *exception.BuilderException {
@@ -63,11 +77,16 @@ func (b *UserBuilder) validateUser() *exception.BuilderException {
 		return exception.NewBuilderException("username", "El campo 'firstname' no debe estar vacio")
 	}
 
-	return nil
-}
+	// Users stored before roles existed get the default role
+	if b.role == "" {
+		b.role = userEntity.ROLE_USER
+	}
+
+	if !userEntity.IsValidRole(b.role) {
+		return exception.NewBuilderException("role", "El campo 'role' no es válido")
+	}
 
-func (b *UserBuilder) isHashed(password string) bool {
-	return strings.HasPrefix(password, "$2a$") || strings.HasPrefix(password, "$2b$") || len(password) == 60
+	return nil
 }
 
 func (b *UserBuilder) SetUsername(username string) *UserBuilder {
@@ -94,3 +113,34 @@ func (b *UserBuilder) SetFirstname(firstname string) *UserBuilder {
 	b.firstname = firstname
 	return b
 }
+
+// SetPasswordHashed indica que la contraseña es el hash guardado y no debe volver a hashearse
+func (b *UserBuilder) SetPasswordHashed(hashed bool) *UserBuilder {
+	b.passwordHashed = hashed
+	return b
+}
+
+func (b *UserBuilder) SetVerified(verified bool) *UserBuilder {
+	b.verified = verified
+	return b
+}
+
+func (b *UserBuilder) SetRole(role string) *UserBuilder {
+	b.role = role
+	return b
+}
+
+func (b *UserBuilder) SetDisabled(disabled bool) *UserBuilder {
+	b.disabled = disabled
+	return b
+}
+
+func (b *UserBuilder) SetSessionVersion(sessionVersion int64) *UserBuilder {
+	b.sessionVersion = sessionVersion
+	return b
+}
+
+func (b *UserBuilder) SetDeletionScheduledAt(deletionScheduledAt *time.Time) *UserBuilder {
+	b.deletionScheduledAt = deletionScheduledAt
+	return b
+}
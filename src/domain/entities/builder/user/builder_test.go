@@ -0,0 +1,61 @@
+package userBuilder
+
+import (
+	userEntity "go-gallery/src/domain/entities/user"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildKeepsStoredHashes(t *testing.T) {
+	argon2Hash, _ := (&userEntity.Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}).Hash("Secret-Pass1")
+	bcryptHash, _ := (&userEntity.BcryptHasher{Cost: 4}).Hash("Secret-Pass1")
+
+	for _, hash := range []string{argon2Hash, bcryptHash} {
+		user, err := NewUserBuilder().FromDTO(&userDTO.UserDTO{
+			Username:  "alice",
+			Password:  hash,
+			Email:     "alice@example.com",
+			Firstname: "Alice",
+		}).SetPasswordHashed(true).Build()
+
+		assert.Nil(t, err)
+		assert.Equal(t, hash, user.GetPassword())
+		assert.NoError(t, user.CheckPasswordIntegrity("Secret-Pass1"))
+	}
+}
+
+func TestBuildHashesPlainPasswords(t *testing.T) {
+	user, err := NewUserBuilder().FromDTO(&userDTO.UserDTO{
+		Username:  "alice",
+		Password:  "Secret-Pass1",
+		Email:     "alice@example.com",
+		Firstname: "Alice",
+	}).Build()
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, "Secret-Pass1", user.GetPassword())
+	assert.NoError(t, user.CheckPasswordIntegrity("Secret-Pass1"))
+}
+
+func TestBuildHashesPasswordsThatLookLikeHashes(t *testing.T) {
+	// A password chosen by the user is never trusted as a hash, whatever its format
+	for _, password := range []string{
+		"$argon2id$v=19$m=4294967295,t=4294967295,p=1$c2FsdA$a2V5",
+		"$2a$04$" + strings.Repeat("a", 53),
+		strings.Repeat("b", 60),
+	} {
+		user, err := NewUserBuilder().FromDTO(&userDTO.UserDTO{
+			Username:  "alice",
+			Password:  password,
+			Email:     "alice@example.com",
+			Firstname: "Alice",
+		}).Build()
+
+		assert.Nil(t, err)
+		assert.NotEqual(t, password, user.GetPassword())
+		assert.NoError(t, user.CheckPasswordIntegrity(password))
+	}
+}
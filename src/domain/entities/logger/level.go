@@ -1,18 +1,26 @@
 package loggerEntity
 
-// Definición del tipo LogLevel
+import "strings"
+
+// Definición del tipo LogLevel, ordenado de menor a mayor gravedad
 type LogLevel uint8
 
 const (
-	INFO LogLevel = iota + 1
+	TRACE LogLevel = iota + 1
+	DEBUG
+	INFO
+	SUCCESS
 	WARNING
 	ERROR
-	SUCCESS
 	PANIC
 )
 
 func Name(logLevel LogLevel) string {
 	switch logLevel {
+	case TRACE:
+		return "TRACE"
+	case DEBUG:
+		return "DEBUG"
 	case INFO:
 		return "INFO"
 	case WARNING:
@@ -32,3 +40,13 @@ func Name(logLevel LogLevel) string {
 func (l LogLevel) String() string {
 	return Name(l)
 }
+
+// ParseLevel obtiene el nivel a partir de su nombre sin distinguir mayúsculas
+func ParseLevel(name string) (LogLevel, bool) {
+	for level := TRACE; level <= PANIC; level++ {
+		if strings.EqualFold(strings.TrimSpace(name), Name(level)) {
+			return level, true
+		}
+	}
+	return 0, false
+}
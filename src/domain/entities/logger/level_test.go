@@ -11,6 +11,8 @@ func TestName(t *testing.T) {
 		level    LogLevel
 		expected string
 	}{
+		{TRACE, "TRACE"},
+		{DEBUG, "DEBUG"},
 		{INFO, "INFO"},
 		{WARNING, "WARNING"},
 		{ERROR, "ERROR"},
@@ -33,6 +35,8 @@ func TestString(t *testing.T) {
 		level    LogLevel
 		expected string
 	}{
+		{TRACE, "TRACE"},
+		{DEBUG, "DEBUG"},
 		{INFO, "INFO"},
 		{WARNING, "WARNING"},
 		{ERROR, "ERROR"},
@@ -47,3 +51,38 @@ func TestString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected LogLevel
+	}{
+		{"TRACE", TRACE},
+		{"debug", DEBUG},
+		{" Info ", INFO},
+		{"warning", WARNING},
+		{"ERROR", ERROR},
+		{"panic", PANIC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := ParseLevel(tt.name)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, actual, "they should be equal")
+		})
+	}
+
+	_, ok := ParseLevel("verbose")
+	assert.False(t, ok)
+	_, ok = ParseLevel("")
+	assert.False(t, ok)
+}
+
+func TestLevelOrder(t *testing.T) {
+	assert.Less(t, TRACE, DEBUG)
+	assert.Less(t, DEBUG, INFO)
+	assert.Less(t, INFO, WARNING)
+	assert.Less(t, WARNING, ERROR)
+	assert.Less(t, ERROR, PANIC)
+}
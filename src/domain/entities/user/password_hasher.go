@@ -0,0 +1,183 @@
+package userEntity
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	ARGON2ID_PREFIX      string = "$argon2id$"
+	ARGON2_SALT_LENGTH   int    = 16
+	ARGON2_KEY_LENGTH    uint32 = 32
+	ARGON2_MAX_KEY_BYTES int    = 64
+	ARGON2_HASH_TEMPLATE string = "$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s"
+)
+
+var (
+	ErrPasswordMismatch  = errors.New("password does not match")
+	ErrUnknownHashFormat = errors.New("unknown password hash format")
+)
+
+// PasswordHasher genera los hashes de las contraseñas nuevas. Los hashes describen su algoritmo y parámetros, por lo que
+// cualquier hash guardado se puede comprobar aunque se haya cambiado el algoritmo configurado
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+
+	// NeedsRehash indica si el hash se generó con otro algoritmo o con parámetros distintos a los configurados
+	NeedsRehash(hash string) bool
+}
+
+var passwordHasher PasswordHasher = &BcryptHasher{Cost: bcrypt.DefaultCost}
+
+// SetPasswordHasher cambia el algoritmo usado por HashPassword, se llama una única vez al arrancar
+func SetPasswordHasher(hasher PasswordHasher) {
+	passwordHasher = hasher
+}
+
+func NewPasswordHasher(conf configuration.PasswordHashingConfiguration) PasswordHasher {
+	if conf.Algorithm == configuration.PASSWORD_HASH_ARGON2ID {
+		return &Argon2idHasher{
+			Memory:      conf.Argon2Memory,
+			Iterations:  conf.Argon2Iterations,
+			Parallelism: conf.Argon2Parallelism,
+		}
+	}
+	return &BcryptHasher{Cost: conf.BcryptCost}
+}
+
+// VerifyPassword compares the password with a hash of any of the supported algorithms
+func VerifyPassword(hash, password string) error {
+	switch {
+	case strings.HasPrefix(hash, ARGON2ID_PREFIX):
+		return verifyArgon2id(hash, password)
+	case isBcryptHash(hash):
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrPasswordMismatch
+			}
+			return err
+		}
+		return nil
+	default:
+		return ErrUnknownHashFormat
+	}
+}
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	if !isBcryptHash(hash) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+// Argon2idHasher usa el formato PHC $argon2id$v=19$m=<KiB>,t=<iteraciones>,p=<hilos>$<salt>$<hash> en base64 sin relleno
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, ARGON2_SALT_LENGTH)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, ARGON2_KEY_LENGTH)
+
+	return fmt.Sprintf(ARGON2_HASH_TEMPLATE, argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, err := parseArgon2id(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.version != argon2.Version || params.memory != h.Memory || params.iterations != h.Iterations ||
+		params.parallelism != h.Parallelism || uint32(len(params.key)) != ARGON2_KEY_LENGTH
+}
+
+type argon2idParams struct {
+	version     int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func parseArgon2id(hash string) (*argon2idParams, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHashFormat
+	}
+
+	params := new(argon2idParams)
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &params.version); err != nil {
+		return nil, ErrUnknownHashFormat
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, ErrUnknownHashFormat
+	}
+	// The same limits as the configuration, so a tampered hash can neither exhaust the memory nor make argon2 panic
+	if params.memory == 0 || params.memory > uint32(configuration.MAX_ARGON2_MEMORY) ||
+		params.iterations == 0 || params.iterations > uint32(configuration.MAX_ARGON2_ITERATIONS) || params.parallelism == 0 {
+		return nil, ErrUnknownHashFormat
+	}
+
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrUnknownHashFormat
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(params.key) == 0 || len(params.key) > ARGON2_MAX_KEY_BYTES {
+		return nil, ErrUnknownHashFormat
+	}
+
+	return params, nil
+}
+
+func verifyArgon2id(hash, password string) error {
+	params, err := parseArgon2id(hash)
+	if err != nil {
+		return err
+	}
+	if params.version != argon2.Version {
+		return ErrUnknownHashFormat
+	}
+
+	key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+	if subtle.ConstantTimeCompare(key, params.key) != 1 {
+		return ErrPasswordMismatch
+	}
+
+	return nil
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
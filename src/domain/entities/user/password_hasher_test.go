@@ -0,0 +1,102 @@
+package userEntity
+
+import (
+	"go-gallery/src/commons/configurator/configuration"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := &BcryptHasher{Cost: 5}
+
+	hash, err := hasher.Hash("Secret-Pass1")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$05$"))
+
+	assert.NoError(t, VerifyPassword(hash, "Secret-Pass1"))
+	assert.ErrorIs(t, VerifyPassword(hash, "Other-Pass1"), ErrPasswordMismatch)
+
+	assert.False(t, hasher.NeedsRehash(hash))
+	assert.True(t, (&BcryptHasher{Cost: 6}).NeedsRehash(hash))
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := &Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+	hash, err := hasher.Hash("Secret-Pass1")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	assert.NoError(t, VerifyPassword(hash, "Secret-Pass1"))
+	assert.ErrorIs(t, VerifyPassword(hash, "Other-Pass1"), ErrPasswordMismatch)
+
+	// Every hash has its own salt
+	otherHash, _ := hasher.Hash("Secret-Pass1")
+	assert.NotEqual(t, hash, otherHash)
+
+	assert.False(t, hasher.NeedsRehash(hash))
+	assert.True(t, (&Argon2idHasher{Memory: 2048, Iterations: 1, Parallelism: 1}).NeedsRehash(hash))
+	assert.True(t, (&Argon2idHasher{Memory: 1024, Iterations: 2, Parallelism: 1}).NeedsRehash(hash))
+}
+
+func TestHasherMigration(t *testing.T) {
+	bcryptHash, _ := (&BcryptHasher{Cost: 4}).Hash("Secret-Pass1")
+	argon2Hasher := &Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+	argon2Hash, _ := argon2Hasher.Hash("Secret-Pass1")
+
+	// Hashes of the previous algorithm can still be verified but must be rehashed
+	assert.NoError(t, VerifyPassword(bcryptHash, "Secret-Pass1"))
+	assert.True(t, argon2Hasher.NeedsRehash(bcryptHash))
+	assert.True(t, (&BcryptHasher{Cost: 4}).NeedsRehash(argon2Hash))
+}
+
+func TestVerifyPasswordUnknownFormat(t *testing.T) {
+	assert.ErrorIs(t, VerifyPassword("Secret-Pass1", "Secret-Pass1"), ErrUnknownHashFormat)
+	assert.ErrorIs(t, VerifyPassword("$argon2id$v=19$m=1024,t=1$c2FsdA$a2V5", "Secret-Pass1"), ErrUnknownHashFormat)
+	assert.ErrorIs(t, VerifyPassword("$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5", "Secret-Pass1"), ErrUnknownHashFormat)
+}
+
+func TestVerifyPasswordRejectsArgon2idParamsOutOfBounds(t *testing.T) {
+	for _, params := range []string{
+		"m=0,t=1,p=1",
+		"m=4294967295,t=1,p=1",
+		"m=1024,t=0,p=1",
+		"m=1024,t=4294967295,p=1",
+		"m=1024,t=1,p=0",
+	} {
+		hash := "$argon2id$v=19$" + params + "$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+		assert.ErrorIs(t, VerifyPassword(hash, "Secret-Pass1"), ErrUnknownHashFormat, params)
+	}
+
+	longKey := strings.Repeat("a2V5", 30)
+	assert.ErrorIs(t, VerifyPassword("$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$"+longKey, "Secret-Pass1"), ErrUnknownHashFormat)
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	hasher := NewPasswordHasher(configuration.PasswordHashingConfiguration{Algorithm: configuration.PASSWORD_HASH_BCRYPT, BcryptCost: 12})
+	assert.Equal(t, &BcryptHasher{Cost: 12}, hasher)
+
+	hasher = NewPasswordHasher(configuration.PasswordHashingConfiguration{
+		Algorithm:         configuration.PASSWORD_HASH_ARGON2ID,
+		Argon2Memory:      19456,
+		Argon2Iterations:  2,
+		Argon2Parallelism: 1,
+	})
+	assert.Equal(t, &Argon2idHasher{Memory: 19456, Iterations: 2, Parallelism: 1}, hasher)
+}
+
+func TestUserNeedsPasswordRehash(t *testing.T) {
+	defer SetPasswordHasher(passwordHasher)
+
+	SetPasswordHasher(&BcryptHasher{Cost: 4})
+	hash, _ := HashPassword("Secret-Pass1")
+	user := NewUser("alice", hash, "alice@example.com", "", "", ROLE_USER, true, false, 0, nil)
+
+	assert.NoError(t, user.CheckPasswordIntegrity("Secret-Pass1"))
+	assert.False(t, user.NeedsPasswordRehash())
+
+	SetPasswordHasher(&BcryptHasher{Cost: 5})
+	assert.True(t, user.NeedsPasswordRehash())
+}
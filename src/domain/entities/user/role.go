@@ -0,0 +1,15 @@
+package userEntity
+
+const (
+	ROLE_USER      string = "user"
+	ROLE_ADMIN     string = "admin"
+	ROLE_READ_ONLY string = "read-only"
+)
+
+func IsValidRole(role string) bool {
+	switch role {
+	case ROLE_USER, ROLE_ADMIN, ROLE_READ_ONLY:
+		return true
+	}
+	return false
+}
@@ -1,6 +1,9 @@
 package userEntity
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"strings"
+	"time"
+)
 
 type User struct {
 	username  string
@@ -8,33 +11,51 @@ type User struct {
 	email     string
 	lastname  string
 	firstname string
+	role      string
+	verified  bool
+	disabled  bool
+
+	// Increased every time the sessions of the user are revoked, tokens with an older version are rejected
+	sessionVersion int64
+
+	// Moment after which the account is purged, nil when no deletion has been requested
+	deletionScheduledAt *time.Time
 }
 
-func NewUser(username, password, email, lastname, firstname string) *User {
+func NewUser(username, password, email, lastname, firstname, role string, verified, disabled bool, sessionVersion int64, deletionScheduledAt *time.Time) *User {
 	user := &User{
 		username:  username,
 		email:     email,
 		password:  password,
 		lastname:  lastname,
 		firstname: firstname,
+		role:      role,
+		verified:  verified,
+		disabled:  disabled,
+
+		sessionVersion:      sessionVersion,
+		deletionScheduledAt: deletionScheduledAt,
 	}
 	return user
 }
 
+// NormalizeEmail devuelve el correo en minúsculas y sin espacios, la forma en la que se guarda y se busca,
+// así Alice@example.com y alice@example.com son la misma cuenta
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func (u *User) CheckPasswordIntegrity(password string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(u.password), []byte(password))
-	if err != nil {
-		return err
-	}
-	return nil
+	return VerifyPassword(u.password, password)
+}
+
+// Tells whether the stored hash should be replaced after a successful login because the configured algorithm changed
+func (u *User) NeedsPasswordRehash() bool {
+	return passwordHasher.NeedsRehash(u.password)
 }
 
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedPassword), nil
+	return passwordHasher.Hash(password)
 }
 
 func (u *User) GetUsername() string {
@@ -56,3 +77,27 @@ func (u *User) GetLastname() string {
 func (u *User) GetFirstname() string {
 	return u.firstname
 }
+
+func (u *User) IsVerified() bool {
+	return u.verified
+}
+
+func (u *User) GetRole() string {
+	return u.role
+}
+
+func (u *User) IsDisabled() bool {
+	return u.disabled
+}
+
+func (u *User) GetSessionVersion() int64 {
+	return u.sessionVersion
+}
+
+func (u *User) GetDeletionScheduledAt() *time.Time {
+	return u.deletionScheduledAt
+}
+
+func (u *User) IsScheduledForDeletion() bool {
+	return u.deletionScheduledAt != nil
+}
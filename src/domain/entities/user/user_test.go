@@ -19,3 +19,17 @@ func TestHashPasswordWithEmptyString(t *testing.T) {
 
 	assert.Error(t, err, "Se esperaba un error al intentar hashear una contraseña vacía")
 }
+
+func TestIsValidRole(t *testing.T) {
+	assert.True(t, IsValidRole(ROLE_USER))
+	assert.True(t, IsValidRole(ROLE_ADMIN))
+	assert.True(t, IsValidRole(ROLE_READ_ONLY))
+	assert.False(t, IsValidRole(""))
+	assert.False(t, IsValidRole("superuser"))
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "alice@example.com", NormalizeEmail("Alice@Example.COM"))
+	assert.Equal(t, "alice@example.com", NormalizeEmail("  alice@example.com\n"))
+	assert.Equal(t, "", NormalizeEmail(""))
+}
@@ -0,0 +1,39 @@
+package auth
+
+import "time"
+
+const deletionCancelPurpose string = "deletion-cancel"
+
+// DeletionCancelClaims son los datos incluidos en el enlace para cancelar el borrado de una cuenta
+type DeletionCancelClaims struct {
+	Username            string `json:"username"`
+	DeletionScheduledAt int64  `json:"deletion_scheduled_at"`
+}
+
+func (c *DeletionCancelClaims) expiresAt() int64 { return c.DeletionScheduledAt }
+func (c *DeletionCancelClaims) complete() bool   { return c.Username != "" }
+
+// DeletionCancelManager firma los enlaces para cancelar el borrado de una cuenta. El enlace
+// caduca al borrarse la cuenta y sólo coincide con el borrado programado que lo generó
+type DeletionCancelManager struct {
+	signer signedTokenManager
+}
+
+func NewDeletionCancelManager(secret string) *DeletionCancelManager {
+	return &DeletionCancelManager{signer: newSignedTokenManager(secret, deletionCancelPurpose)}
+}
+
+func (m *DeletionCancelManager) Create(username string, deletionScheduledAt time.Time) (string, error) {
+	return m.signer.encode(&DeletionCancelClaims{
+		Username:            username,
+		DeletionScheduledAt: deletionScheduledAt.Unix(),
+	}, "")
+}
+
+func (m *DeletionCancelManager) Verify(token string) (*DeletionCancelClaims, error) {
+	claims := new(DeletionCancelClaims)
+	if err := m.signer.decode(token, "", claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import "time"
+
+const exportLinkPurpose string = "export-download"
+
+// ExportLinkClaims son los datos incluidos en el enlace de descarga de una exportación
+type ExportLinkClaims struct {
+	ExportID   string `json:"export_id"`
+	Username   string `json:"username"`
+	Expiration int64  `json:"expiration"`
+}
+
+func (c *ExportLinkClaims) expiresAt() int64 { return c.Expiration }
+func (c *ExportLinkClaims) complete() bool   { return c.ExportID != "" }
+
+// ExportLinkManager firma los enlaces de descarga de las exportaciones. El enlace sólo
+// puede usarse una vez porque la exportación se marca como descargada al servirla
+type ExportLinkManager struct {
+	signer signedTokenManager
+}
+
+func NewExportLinkManager(secret string) *ExportLinkManager {
+	return &ExportLinkManager{signer: newSignedTokenManager(secret, exportLinkPurpose)}
+}
+
+func (m *ExportLinkManager) Create(exportID, username string, expiration time.Time) (string, error) {
+	return m.signer.encode(&ExportLinkClaims{
+		ExportID:   exportID,
+		Username:   username,
+		Expiration: expiration.Unix(),
+	}, "")
+}
+
+func (m *ExportLinkManager) Verify(token string) (*ExportLinkClaims, error) {
+	claims := new(ExportLinkClaims)
+	if err := m.signer.decode(token, "", claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
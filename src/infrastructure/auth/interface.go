@@ -6,6 +6,6 @@ import (
 )
 
 type TokenManager interface {
-	CreateToken(username, email string) (string, *exception.ApiException)
+	CreateToken(username, email, role string, sessionVersion int64) (string, *exception.ApiException)
 	ValidateToken(tokenString string) (*userDTO.JwtClaimsDTO, *exception.ApiException)
 }
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"go-gallery/src/commons/exception"
+	userEntity "go-gallery/src/domain/entities/user"
 	userDTO "go-gallery/src/infrastructure/dto/user"
 	log "go-gallery/src/infrastructure/logger"
 	"time"
@@ -24,11 +25,13 @@ func NewJWTTokenManager(secret string) *JWTTokenManager {
 	return &JWTTokenManager{secret: secret}
 }
 
-func (j *JWTTokenManager) CreateToken(username, email string) (string, *exception.ApiException) {
+func (j *JWTTokenManager) CreateToken(username, email, role string, sessionVersion int64) (string, *exception.ApiException) {
 	// Create the JWT claims, including the user and expiration
 	claims := jtoken.MapClaims{
 		"username": username,
 		"email":    email,
+		"role":     role,
+		"sv":       sessionVersion,
 		"exp":      time.Now().Add(JWT_EXPIRATION_HOURS).Unix(), // Expiration date of the token
 		"iat":      time.Now().Unix(),                           // Issued date of the token
 	}
@@ -39,7 +42,7 @@ func (j *JWTTokenManager) CreateToken(username, email string) (string, *exceptio
 	// Sign the token
 	t, err := token.SignedString([]byte(j.secret))
 	if err != nil {
-		logger.Error("Failed to sign JWT token: " + err.Error())
+		logger.Error("Failed to sign JWT token", log.F("error", err.Error()))
 		return "", exception.NewApiException(500, "Error creating JWT token")
 	}
 
@@ -68,15 +71,26 @@ func (j *JWTTokenManager) ValidateToken(tokenString string) (*userDTO.JwtClaimsD
 	iat, okIat := claims["iat"].(float64)
 	exp, okExp := claims["exp"].(float64)
 
+	// Tokens issued before roles existed belong to regular users
+	role, okRole := claims["role"].(string)
+	if !okRole {
+		role = userEntity.ROLE_USER
+	}
+
+	// Tokens issued before sessions could be revoked have the initial version
+	sessionVersion, _ := claims["sv"].(float64)
+
 	if !ok || !okEmail || !okIat || !okExp {
 		logger.Error("Error in JWT claims")
 		return nil, exception.NewApiException(500, "Error in JWT claims")
 	}
 
 	return &userDTO.JwtClaimsDTO{
-		Username:   username,
-		Email:      email,
-		IssuedAt:   int64(iat),
-		Expiration: int64(exp),
+		Username:       username,
+		Email:          email,
+		Role:           role,
+		SessionVersion: int64(sessionVersion),
+		IssuedAt:       int64(iat),
+		Expiration:     int64(exp),
 	}, nil
 }
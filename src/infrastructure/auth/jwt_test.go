@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go-gallery/src/commons/exception"
+	userEntity "go-gallery/src/domain/entities/user"
 	log "go-gallery/src/infrastructure/logger"
 
 	jtoken "github.com/golang-jwt/jwt/v5"
@@ -24,7 +25,7 @@ func beforeAll() (*JWTTokenManager, string) {
 
 // Helper function to create a valid token
 func createValidToken(manager *JWTTokenManager, username, email string) (string, *exception.ApiException) {
-	token, apiErr := manager.CreateToken(username, email)
+	token, apiErr := manager.CreateToken(username, email, userEntity.ROLE_ADMIN, 3)
 	if apiErr != nil {
 		return "", apiErr
 	}
@@ -47,6 +48,8 @@ func TestCreateTokenAndValidateSuccess(t *testing.T) {
 	assert.NotNil(t, claims)
 	assert.Equal(t, username, claims.Username)
 	assert.Equal(t, email, claims.Email)
+	assert.Equal(t, userEntity.ROLE_ADMIN, claims.Role)
+	assert.Equal(t, int64(3), claims.SessionVersion)
 
 	assert.WithinDuration(t, time.Now(), time.Unix(claims.IssuedAt, 0), 5*time.Second)
 	assert.True(t, claims.Expiration > claims.IssuedAt)
@@ -102,6 +105,24 @@ func TestValidateTokenInvalidClaims(t *testing.T) {
 	assert.Contains(t, apiErr.Message, "Error in JWT claims")
 }
 
+func TestValidateTokenWithoutRole(t *testing.T) {
+	manager, secret := beforeAll()
+
+	token := jtoken.NewWithClaims(jtoken.SigningMethodHS256, jtoken.MapClaims{
+		"username": "testuser",
+		"email":    "test@example.com",
+		"exp":      time.Now().Add(time.Hour).Unix(),
+		"iat":      time.Now().Unix(),
+	})
+	tokenString, err := token.SignedString([]byte(secret))
+	assert.NoError(t, err)
+
+	claims, apiErr := manager.ValidateToken(tokenString)
+	assert.Nil(t, apiErr)
+	assert.Equal(t, userEntity.ROLE_USER, claims.Role)
+	assert.Equal(t, int64(0), claims.SessionVersion)
+}
+
 type CustomClaims struct {
 	Foo string `json:"foo"`
 	Bar string `json:"bar"`
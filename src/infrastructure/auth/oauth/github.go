@@ -0,0 +1,105 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"go-gallery/src/commons/configurator/configuration"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	GITHUB_AUTH_URL  string = "https://github.com/login/oauth/authorize"
+	GITHUB_TOKEN_URL string = "https://github.com/login/oauth/access_token"
+	GITHUB_API_URL   string = "https://api.github.com"
+)
+
+type githubUser struct {
+	Id    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// GitHubProvider implements the OAuth2 flow of GitHub, which does not support OpenID Connect,
+// so the identity is read from its REST API
+type GitHubProvider struct {
+	config configuration.OAuthProviderConfiguration
+	client *http.Client
+
+	authURL  string
+	tokenURL string
+	apiURL   string
+}
+
+func NewGitHubProvider(config configuration.OAuthProviderConfiguration) *GitHubProvider {
+	return &GitHubProvider{
+		config:   config,
+		client:   &http.Client{Timeout: HTTP_TIMEOUT},
+		authURL:  GITHUB_AUTH_URL,
+		tokenURL: GITHUB_TOKEN_URL,
+		apiURL:   GITHUB_API_URL,
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return p.config.Name
+}
+
+// GitHub ignores the nonce, the state and PKCE protect the flow
+func (p *GitHubProvider) AuthCodeURL(state, codeChallenge, nonce string) (string, error) {
+	params := url.Values{
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {CODE_CHALLENGE_METHOD},
+	}
+
+	return appendQuery(p.authURL, params), nil
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error) {
+	token, err := exchangeCode(ctx, p.client, p.tokenURL, p.config, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	user := new(githubUser)
+	if err := getJSON(ctx, p.client, p.apiURL+"/user", token.AccessToken, user); err != nil {
+		return nil, err
+	}
+	if user.Id == 0 {
+		return nil, errors.New("the GitHub user does not contain an id")
+	}
+
+	var emails []githubEmail
+	if err := getJSON(ctx, p.client, p.apiURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &ExternalIdentity{
+		Provider: p.config.Name,
+		Subject:  strconv.FormatInt(user.Id, 10),
+		Username: user.Login,
+	}
+	identity.Firstname, identity.Lastname, _ = strings.Cut(strings.TrimSpace(user.Name), " ")
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"go-gallery/src/commons/configurator/configuration"
+	log "go-gallery/src/infrastructure/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockGitHubServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != VALID_CODE || r.Form.Get("code_verifier") == "" {
+			json.NewEncoder(w).Encode(tokenResponse{Error: "bad_verification_code"})
+			return
+		}
+		json.NewEncoder(w).Encode(tokenResponse{AccessToken: "access-token"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(githubUser{Id: 42, Login: "octocat", Name: "Mona Lisa"})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]githubEmail{
+			{Email: "secondary@example.com", Verified: true},
+			{Email: "octocat@example.com", Primary: true, Verified: true},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitHubExchange(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	server := newMockGitHubServer(t)
+
+	provider := NewGitHubProvider(configuration.OAuthProviderConfiguration{Name: "github", ClientID: CLIENT_ID})
+	provider.tokenURL = server.URL + "/token"
+	provider.apiURL = server.URL
+
+	identity, err := provider.Exchange(context.Background(), VALID_CODE, "verifier", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "42", identity.Subject)
+	assert.Equal(t, "octocat", identity.Username)
+	assert.Equal(t, "octocat@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Mona", identity.Firstname)
+	assert.Equal(t, "Lisa", identity.Lastname)
+
+	_, err = provider.Exchange(context.Background(), "wrong-code", "verifier", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,20 @@
+package oauth
+
+import "context"
+
+// ExternalIdentity is the identity of a user authenticated by an external provider
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	Firstname     string
+	Lastname      string
+}
+
+type Provider interface {
+	Name() string
+	AuthCodeURL(state, codeChallenge, nonce string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
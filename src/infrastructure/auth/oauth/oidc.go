@@ -0,0 +1,321 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
+	log "go-gallery/src/infrastructure/logger"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jtoken "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DISCOVERY_PATH   string        = "/.well-known/openid-configuration"
+	HTTP_TIMEOUT     time.Duration = 10 * time.Second
+	ID_TOKEN_LEEWAY  time.Duration = 30 * time.Second
+	MAX_RESPONSE_LEN int64         = 1 << 20
+)
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IdToken     string `json:"id_token"`
+	Error       string `json:"error"`
+}
+
+// OIDCProvider implements the authorization code flow against any OpenID Connect issuer,
+// the discovery document and signing keys are fetched lazily and cached
+type OIDCProvider struct {
+	config configuration.OAuthProviderConfiguration
+	client *http.Client
+
+	mutex     sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]*rsa.PublicKey
+}
+
+func NewOIDCProvider(config configuration.OAuthProviderConfiguration) *OIDCProvider {
+	return &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: HTTP_TIMEOUT},
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *OIDCProvider) AuthCodeURL(state, codeChallenge, nonce string) (string, error) {
+	discovery, err := p.getDiscovery(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {CODE_CHALLENGE_METHOD},
+	}
+
+	return appendQuery(discovery.AuthorizationEndpoint, params), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(ctx, p.client, discovery.TokenEndpoint, p.config, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.IdToken == "" {
+		return nil, errors.New("the token response does not contain an id_token")
+	}
+
+	claims, err := p.verifyIdToken(ctx, token.IdToken, discovery.Issuer, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errors.New("the id_token does not contain a subject")
+	}
+
+	identity := &ExternalIdentity{
+		Provider:      p.config.Name,
+		Subject:       subject,
+		Email:         stringClaim(claims, "email"),
+		EmailVerified: boolClaim(claims, "email_verified"),
+		Username:      stringClaim(claims, "preferred_username"),
+		Firstname:     stringClaim(claims, "given_name"),
+		Lastname:      stringClaim(claims, "family_name"),
+	}
+
+	return identity, nil
+}
+
+func (p *OIDCProvider) verifyIdToken(ctx context.Context, idToken, issuer, nonce string) (jtoken.MapClaims, error) {
+	claims := jtoken.MapClaims{}
+	_, err := jtoken.ParseWithClaims(idToken, claims, func(token *jtoken.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, kid)
+	},
+		jtoken.WithValidMethods([]string{"RS256"}),
+		jtoken.WithIssuer(issuer),
+		jtoken.WithAudience(p.config.ClientID),
+		jtoken.WithExpirationRequired(),
+		jtoken.WithLeeway(ID_TOKEN_LEEWAY),
+		jtoken.WithTimeFunc(NowFunc),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if stringClaim(claims, "nonce") != nonce {
+		return nil, errors.New("the id_token nonce does not match")
+	}
+
+	return claims, nil
+}
+
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discovery := new(discoveryDocument)
+	if err := getJSON(ctx, p.client, p.config.IssuerURL+DISCOVERY_PATH, "", discovery); err != nil {
+		log.FromContext(ctx).Error("Error loading OpenID configuration", log.F("provider", p.config.Name), log.F("error", err.Error()))
+		return nil, err
+	}
+
+	if discovery.Issuer != p.config.IssuerURL {
+		return nil, fmt.Errorf("the discovered issuer %s does not match %s", discovery.Issuer, p.config.IssuerURL)
+	}
+
+	p.discovery = discovery
+	return discovery, nil
+}
+
+// Returns the signing key with the given id, the key set is reloaded once when the key is unknown to support rotation
+func (p *OIDCProvider) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mutex.Lock()
+	key, found := p.keys[kid]
+	p.mutex.Unlock()
+	if found {
+		return key, nil
+	}
+
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, p.client, discovery.JwksURI, "", &jwks); err != nil {
+		log.FromContext(ctx).Error("Error loading signing keys", log.F("provider", p.config.Name), log.F("error", err.Error()))
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		publicKey, err := jwk.rsaPublicKey()
+		if err != nil {
+			log.FromContext(ctx).Warning("Ignoring invalid signing key", log.F("kid", jwk.Kid), log.F("provider", p.config.Name), log.F("error", err.Error()))
+			continue
+		}
+		keys[jwk.Kid] = publicKey
+	}
+
+	p.mutex.Lock()
+	p.keys = keys
+	p.mutex.Unlock()
+
+	key, found = keys[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %s", kid)
+	}
+
+	return key, nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func exchangeCode(ctx context.Context, client *http.Client, tokenEndpoint string, config configuration.OAuthProviderConfiguration,
+	code, codeVerifier string) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {config.RedirectURL},
+		"client_id":     {config.ClientID},
+		"client_secret": {config.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	token := new(tokenResponse)
+	if err := json.NewDecoder(http.MaxBytesReader(nil, resp.Body, MAX_RESPONSE_LEN)).Decode(token); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || token.Error != "" || token.AccessToken == "" {
+		return nil, fmt.Errorf("token exchange failed with status %d: %s", resp.StatusCode, token.Error)
+	}
+
+	return token, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, endpoint, accessToken string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, endpoint)
+	}
+
+	return json.NewDecoder(http.MaxBytesReader(nil, resp.Body, MAX_RESPONSE_LEN)).Decode(target)
+}
+
+func appendQuery(endpoint string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+	return endpoint + separator + params.Encode()
+}
+
+func stringClaim(claims jtoken.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// Some providers send email_verified as a string
+func boolClaim(claims jtoken.MapClaims, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"go-gallery/src/commons/configurator/configuration"
+	log "go-gallery/src/infrastructure/logger"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jtoken "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	CLIENT_ID     = "gallery"
+	CLIENT_SECRET = "secret"
+	VALID_CODE    = "valid-code"
+	NONCE         = "nonce"
+)
+
+// mockOIDCServer is a minimal OpenID Connect provider that issues an id_token for VALID_CODE
+// when the PKCE verifier matches the challenge of the authorization request
+type mockOIDCServer struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	keyId         string
+	signingKey    *rsa.PrivateKey
+	codeChallenge string
+	claims        jtoken.MapClaims
+}
+
+func newMockOIDCServer(t *testing.T) *mockOIDCServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	mock := &mockOIDCServer{key: key, keyId: "key-1", signingKey: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc(DISCOVERY_PATH, mock.discovery)
+	mux.HandleFunc("/jwks", mock.jwks)
+	mux.HandleFunc("/token", mock.token)
+	mock.server = httptest.NewServer(mux)
+	t.Cleanup(mock.server.Close)
+
+	mock.claims = jtoken.MapClaims{
+		"iss":            mock.server.URL,
+		"aud":            CLIENT_ID,
+		"sub":            "12345",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"given_name":     "Juan",
+		"family_name":    "Bricio",
+		"nonce":          NONCE,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+	}
+
+	return mock
+}
+
+func (m *mockOIDCServer) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(discoveryDocument{
+		Issuer:                m.server.URL,
+		AuthorizationEndpoint: m.server.URL + "/authorize",
+		TokenEndpoint:         m.server.URL + "/token",
+		JwksURI:               m.server.URL + "/jwks",
+	})
+}
+
+func (m *mockOIDCServer) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys": []jsonWebKey{{
+			Kty: "RSA",
+			Kid: m.keyId,
+			N:   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *mockOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	if r.Form.Get("code") != VALID_CODE || r.Form.Get("client_secret") != CLIENT_SECRET ||
+		CodeChallenge(r.Form.Get("code_verifier")) != m.codeChallenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+		return
+	}
+
+	token := jtoken.NewWithClaims(jtoken.SigningMethodRS256, m.claims)
+	token.Header["kid"] = m.keyId
+	idToken, _ := token.SignedString(m.signingKey)
+
+	json.NewEncoder(w).Encode(tokenResponse{AccessToken: "access-token", IdToken: idToken})
+}
+
+func newTestProvider(mock *mockOIDCServer) *OIDCProvider {
+	log.Init(log.NewConsoleLogger())
+	return NewOIDCProvider(configuration.OAuthProviderConfiguration{
+		Name:         "mock",
+		Type:         configuration.OAUTH_PROVIDER_TYPE_OIDC,
+		IssuerURL:    mock.server.URL,
+		ClientID:     CLIENT_ID,
+		ClientSecret: CLIENT_SECRET,
+		RedirectURL:  "http://localhost:3000/api/auth/oauth/mock/callback",
+		Scopes:       []string{"openid", "email"},
+	})
+}
+
+// Simulates the redirect to the provider, which keeps the code challenge of the request
+func authorize(t *testing.T, mock *mockOIDCServer, provider *OIDCProvider, verifier string) {
+	authURL, err := provider.AuthCodeURL("state", CodeChallenge(verifier), NONCE)
+	assert.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, mock.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, CODE_CHALLENGE_METHOD, parsed.Query().Get("code_challenge_method"))
+	assert.Equal(t, CLIENT_ID, parsed.Query().Get("client_id"))
+	mock.codeChallenge = parsed.Query().Get("code_challenge")
+}
+
+func TestOIDCExchange(t *testing.T) {
+	mock := newMockOIDCServer(t)
+	provider := newTestProvider(mock)
+	authorize(t, mock, provider, "verifier")
+
+	identity, err := provider.Exchange(context.Background(), VALID_CODE, "verifier", NONCE)
+	assert.NoError(t, err)
+	assert.Equal(t, "mock", identity.Provider)
+	assert.Equal(t, "12345", identity.Subject)
+	assert.Equal(t, "user@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Juan", identity.Firstname)
+	assert.Equal(t, "Bricio", identity.Lastname)
+}
+
+func TestOIDCExchangeWrongVerifier(t *testing.T) {
+	mock := newMockOIDCServer(t)
+	provider := newTestProvider(mock)
+	authorize(t, mock, provider, "verifier")
+
+	_, err := provider.Exchange(context.Background(), VALID_CODE, "other-verifier", NONCE)
+	assert.Error(t, err)
+}
+
+func TestOIDCExchangeWrongNonce(t *testing.T) {
+	mock := newMockOIDCServer(t)
+	provider := newTestProvider(mock)
+	authorize(t, mock, provider, "verifier")
+
+	_, err := provider.Exchange(context.Background(), VALID_CODE, "verifier", "other-nonce")
+	assert.Error(t, err)
+}
+
+func TestOIDCExchangeInvalidIdToken(t *testing.T) {
+	mock := newMockOIDCServer(t)
+	provider := newTestProvider(mock)
+	authorize(t, mock, provider, "verifier")
+
+	// Wrong audience
+	mock.claims["aud"] = "other-client"
+	_, err := provider.Exchange(context.Background(), VALID_CODE, "verifier", NONCE)
+	assert.Error(t, err)
+
+	// Expired token
+	mock.claims["aud"] = CLIENT_ID
+	mock.claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = provider.Exchange(context.Background(), VALID_CODE, "verifier", NONCE)
+	assert.Error(t, err)
+
+}
+
+func TestOIDCExchangeKeyRotation(t *testing.T) {
+	mock := newMockOIDCServer(t)
+	provider := newTestProvider(mock)
+	authorize(t, mock, provider, "verifier")
+
+	_, err := provider.Exchange(context.Background(), VALID_CODE, "verifier", NONCE)
+	assert.NoError(t, err)
+
+	// The key set is reloaded when the token uses an unknown key id
+	mock.key, _ = rsa.GenerateKey(rand.Reader, 2048)
+	mock.signingKey = mock.key
+	mock.keyId = "key-2"
+	_, err = provider.Exchange(context.Background(), VALID_CODE, "verifier", NONCE)
+	assert.NoError(t, err)
+
+	// A token signed with a key that is not published is rejected
+	mock.signingKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	_, err = provider.Exchange(context.Background(), VALID_CODE, "verifier", NONCE)
+	assert.Error(t, err)
+}
@@ -0,0 +1,23 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	utilsToken "go-gallery/src/commons/utils/token"
+)
+
+const (
+	CODE_CHALLENGE_METHOD string = "S256"
+	CODE_VERIFIER_BYTES   int    = 32
+)
+
+// Generates a random PKCE code verifier (RFC 7636), its hex encoding only uses unreserved characters
+func NewCodeVerifier() (string, error) {
+	return utilsToken.GenerateToken(CODE_VERIFIER_BYTES)
+}
+
+// Derives the S256 code challenge sent in the authorization request
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,19 @@
+package oauth
+
+import "go-gallery/src/commons/configurator/configuration"
+
+// Builds the providers enabled in the configuration indexed by name
+func NewProviders(oauthConfiguration configuration.OAuthConfiguration) map[string]Provider {
+	providers := make(map[string]Provider)
+
+	for _, providerConfiguration := range oauthConfiguration.Providers {
+		switch providerConfiguration.Type {
+		case configuration.OAUTH_PROVIDER_TYPE_GITHUB:
+			providers[providerConfiguration.Name] = NewGitHubProvider(providerConfiguration)
+		default:
+			providers[providerConfiguration.Name] = NewOIDCProvider(providerConfiguration)
+		}
+	}
+
+	return providers
+}
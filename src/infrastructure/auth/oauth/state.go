@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// NowFunc allows tests to control the current time
+var NowFunc = time.Now
+
+// State keeps the data of an authorization request between the login redirect and the callback
+type State struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	Expiration   int64  `json:"expiration"`
+}
+
+// StateManager signs the state stored in the browser cookie so it cannot be tampered with
+type StateManager struct {
+	secret []byte
+}
+
+func NewStateManager(secret string) *StateManager {
+	return &StateManager{secret: []byte(secret)}
+}
+
+func (m *StateManager) Encode(state *State) (string, error) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + m.sign(encoded), nil
+}
+
+func (m *StateManager) Decode(value string) (*State, error) {
+	encoded, signature, found := strings.Cut(value, ".")
+	if !found {
+		return nil, errors.New("malformed state")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(m.sign(encoded))) {
+		return nil, errors.New("invalid state signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	state := new(State)
+	if err := json.Unmarshal(payload, state); err != nil {
+		return nil, err
+	}
+
+	if NowFunc().Unix() > state.Expiration {
+		return nil, errors.New("state expired")
+	}
+
+	return state, nil
+}
+
+func (m *StateManager) sign(encoded string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,62 @@
+package oauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newState() *State {
+	return &State{
+		Provider:     "google",
+		State:        "state",
+		CodeVerifier: "verifier",
+		Nonce:        "nonce",
+		Expiration:   time.Now().Add(time.Minute).Unix(),
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	manager := NewStateManager("secret")
+
+	encoded, err := manager.Encode(newState())
+	assert.NoError(t, err)
+
+	decoded, err := manager.Decode(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, newState().CodeVerifier, decoded.CodeVerifier)
+	assert.Equal(t, newState().Nonce, decoded.Nonce)
+}
+
+func TestStateRejectsTampering(t *testing.T) {
+	encoded, _ := NewStateManager("secret").Encode(newState())
+
+	_, err := NewStateManager("other-secret").Decode(encoded)
+	assert.Error(t, err)
+
+	_, err = NewStateManager("secret").Decode("x" + encoded)
+	assert.Error(t, err)
+
+	_, err = NewStateManager("secret").Decode("malformed")
+	assert.Error(t, err)
+}
+
+func TestStateExpired(t *testing.T) {
+	manager := NewStateManager("secret")
+	encoded, _ := manager.Encode(newState())
+
+	NowFunc = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	defer func() { NowFunc = time.Now }()
+
+	_, err := manager.Decode(encoded)
+	assert.Error(t, err)
+}
+
+func TestCodeChallenge(t *testing.T) {
+	assert.Equal(t, "6BhnmbuEeCOJxsmssQm_dI-YOeUdxJbyicrRsr0t-s0", CodeChallenge("dBjftJeZ4CVP-mB92K3uVdk2pkIEpBHhCzsnOc0kuX8"))
+
+	verifier, err := NewCodeVerifier()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(verifier), 43)
+}
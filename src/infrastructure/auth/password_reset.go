@@ -0,0 +1,71 @@
+package auth
+
+import (
+	utilsToken "go-gallery/src/commons/utils/token"
+	"time"
+)
+
+const (
+	PASSWORD_RESET_NONCE_BYTES int    = 16
+	passwordResetPurpose       string = "password-reset"
+)
+
+// NowFunc allows tests to control the current time
+var NowFunc = time.Now
+
+// PasswordResetClaims son los datos incluidos en el enlace de recuperación de contraseña
+type PasswordResetClaims struct {
+	Username   string `json:"username"`
+	Expiration int64  `json:"expiration"`
+	Nonce      string `json:"nonce"`
+}
+
+func (c *PasswordResetClaims) expiresAt() int64 { return c.Expiration }
+func (c *PasswordResetClaims) complete() bool   { return c.Username != "" }
+
+// PasswordResetManager firma los enlaces de recuperación junto con el hash de la contraseña actual, así el enlace deja de
+// ser válido en cuanto se cambia la contraseña y cada enlace sólo puede usarse una vez sin guardar nada en el servidor
+type PasswordResetManager struct {
+	signer     signedTokenManager
+	expiration time.Duration
+}
+
+func NewPasswordResetManager(secret string, expiration time.Duration) *PasswordResetManager {
+	return &PasswordResetManager{signer: newSignedTokenManager(secret, passwordResetPurpose), expiration: expiration}
+}
+
+func (m *PasswordResetManager) Create(username, passwordHash string) (string, *PasswordResetClaims, error) {
+	nonce, err := utilsToken.GenerateToken(PASSWORD_RESET_NONCE_BYTES)
+	if err != nil {
+		return "", nil, err
+	}
+
+	claims := &PasswordResetClaims{
+		Username:   username,
+		Expiration: NowFunc().Add(m.expiration).Unix(),
+		Nonce:      nonce,
+	}
+
+	token, err := m.signer.encode(claims, passwordHash)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// Username reads the user of the token without checking it, so its current password hash can be looked up for Verify
+func (m *PasswordResetManager) Username(token string) (string, error) {
+	claims := new(PasswordResetClaims)
+	if err := decodeUnverified(token, claims); err != nil {
+		return "", err
+	}
+	return claims.Username, nil
+}
+
+func (m *PasswordResetManager) Verify(token, passwordHash string) (*PasswordResetClaims, error) {
+	claims := new(PasswordResetClaims)
+	if err := m.signer.decode(token, passwordHash, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMalformedToken = errors.New("malformed signed token")
+	ErrInvalidToken   = errors.New("invalid signed token")
+	ErrExpiredToken   = errors.New("signed token expired")
+)
+
+// signedClaims son los datos de un token firmado
+type signedClaims interface {
+	// expiresAt devuelve el instante unix a partir del cual el token deja de ser válido
+	expiresAt() int64
+
+	// complete indica si están los campos obligatorios
+	complete() bool
+}
+
+// signedTokenManager firma los datos en el formato <base64url(json)>.<HMAC-SHA256>. El propósito forma parte de la firma,
+// así un token emitido para un uso no se acepta en otro aunque compartan el secreto
+type signedTokenManager struct {
+	secret  []byte
+	purpose string
+}
+
+func newSignedTokenManager(secret, purpose string) signedTokenManager {
+	return signedTokenManager{secret: []byte(secret), purpose: purpose + ":"}
+}
+
+// encode firma los datos, el binding es un valor del servidor que invalida el token en cuanto cambia
+func (m signedTokenManager) encode(claims signedClaims, binding string) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + m.sign(encoded, binding), nil
+}
+
+// decode comprueba la firma y la caducidad del token antes de devolver sus datos en claims
+func (m signedTokenManager) decode(token, binding string, claims signedClaims) error {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return ErrMalformedToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(m.sign(encoded, binding))) {
+		return ErrInvalidToken
+	}
+
+	if err := decodeSignedClaims(encoded, claims); err != nil {
+		return err
+	}
+
+	if NowFunc().Unix() >= claims.expiresAt() {
+		return ErrExpiredToken
+	}
+
+	return nil
+}
+
+// decodeUnverified lee los datos sin comprobar la firma, solo sirve para buscar el binding con el que verificar el token
+func decodeUnverified(token string, claims signedClaims) error {
+	encoded, _, found := strings.Cut(token, ".")
+	if !found {
+		return ErrMalformedToken
+	}
+	return decodeSignedClaims(encoded, claims)
+}
+
+func (m signedTokenManager) sign(encoded, binding string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(m.purpose + encoded))
+	if binding != "" {
+		mac.Write([]byte("." + binding))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func decodeSignedClaims(encoded string, claims signedClaims) error {
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrMalformedToken
+	}
+
+	if err := json.Unmarshal(payload, claims); err != nil || !claims.complete() {
+		return ErrMalformedToken
+	}
+
+	return nil
+}
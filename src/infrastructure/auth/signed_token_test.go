@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClaims struct {
+	Subject    string `json:"subject"`
+	Expiration int64  `json:"expiration"`
+}
+
+func (c *testClaims) expiresAt() int64 { return c.Expiration }
+func (c *testClaims) complete() bool   { return c.Subject != "" }
+
+func newTestToken(t *testing.T, manager signedTokenManager, subject, binding string) string {
+	token, err := manager.encode(&testClaims{Subject: subject, Expiration: time.Now().Add(time.Hour).Unix()}, binding)
+	require.NoError(t, err)
+	return token
+}
+
+func TestSignedTokenRoundTrip(t *testing.T) {
+	manager := newSignedTokenManager("mySecretKey", "test")
+	token := newTestToken(t, manager, "alice", "")
+
+	claims := new(testClaims)
+	assert.NoError(t, manager.decode(token, "", claims))
+	assert.Equal(t, "alice", claims.Subject)
+
+	unverified := new(testClaims)
+	assert.NoError(t, decodeUnverified(token, unverified))
+	assert.Equal(t, claims, unverified)
+}
+
+func TestSignedTokenTampered(t *testing.T) {
+	manager := newSignedTokenManager("mySecretKey", "test")
+	token := newTestToken(t, manager, "alice", "")
+	otherToken := newTestToken(t, manager, "bob", "")
+
+	// Payload of one token with the signature of another
+	encoded, _, _ := strings.Cut(token, ".")
+	_, signature, _ := strings.Cut(otherToken, ".")
+	assert.ErrorIs(t, manager.decode(encoded+"."+signature, "", new(testClaims)), ErrInvalidToken)
+
+	// Signed with another secret
+	assert.ErrorIs(t, newSignedTokenManager("otherSecret", "test").decode(token, "", new(testClaims)), ErrInvalidToken)
+
+	// A token signed for another purpose with the same secret is rejected
+	assert.ErrorIs(t, newSignedTokenManager("mySecretKey", "other").decode(token, "", new(testClaims)), ErrInvalidToken)
+}
+
+func TestSignedTokenBoundToValue(t *testing.T) {
+	manager := newSignedTokenManager("mySecretKey", "test")
+	token := newTestToken(t, manager, "alice", "$2a$10$hash")
+
+	assert.NoError(t, manager.decode(token, "$2a$10$hash", new(testClaims)))
+	assert.ErrorIs(t, manager.decode(token, "$2a$10$newHash", new(testClaims)), ErrInvalidToken)
+	assert.ErrorIs(t, manager.decode(token, "", new(testClaims)), ErrInvalidToken)
+}
+
+func TestSignedTokenMalformed(t *testing.T) {
+	manager := newSignedTokenManager("mySecretKey", "test")
+
+	assert.ErrorIs(t, manager.decode("withoutSignature", "", new(testClaims)), ErrMalformedToken)
+	assert.ErrorIs(t, decodeUnverified("!!.signature", new(testClaims)), ErrMalformedToken)
+
+	// Correctly signed but without the mandatory fields
+	token, err := manager.encode(&testClaims{Expiration: time.Now().Add(time.Hour).Unix()}, "")
+	require.NoError(t, err)
+	assert.ErrorIs(t, manager.decode(token, "", new(testClaims)), ErrMalformedToken)
+}
+
+func TestSignedTokenExpired(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	NowFunc = func() time.Time { return now }
+	defer func() { NowFunc = time.Now }()
+
+	manager := newSignedTokenManager("mySecretKey", "test")
+	token, err := manager.encode(&testClaims{Subject: "alice", Expiration: now.Add(time.Hour).Unix()}, "")
+	require.NoError(t, err)
+
+	NowFunc = func() time.Time { return now.Add(time.Hour - time.Second) }
+	assert.NoError(t, manager.decode(token, "", new(testClaims)))
+
+	NowFunc = func() time.Time { return now.Add(time.Hour) }
+	assert.ErrorIs(t, manager.decode(token, "", new(testClaims)), ErrExpiredToken)
+}
+
+func TestPasswordResetToken(t *testing.T) {
+	manager := NewPasswordResetManager("mySecretKey", 30*time.Minute)
+
+	token, created, err := manager.Create("alice", "$2a$10$hash")
+	assert.NoError(t, err)
+
+	username, err := manager.Username(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", username)
+
+	claims, err := manager.Verify(token, "$2a$10$hash")
+	assert.NoError(t, err)
+	assert.Equal(t, created, claims)
+
+	// The link stops working once the password changes
+	_, err = manager.Verify(token, "$2a$10$newHash")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Every link is different even for the same user
+	otherToken, _, _ := manager.Create("alice", "$2a$10$hash")
+	assert.NotEqual(t, token, otherToken)
+}
+
+func TestExportLink(t *testing.T) {
+	manager := NewExportLinkManager("mySecretKey")
+	expiration := time.Now().Add(time.Hour)
+
+	token, err := manager.Create("export-1", "alice", expiration)
+	assert.NoError(t, err)
+
+	claims, err := manager.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, &ExportLinkClaims{ExportID: "export-1", Username: "alice", Expiration: expiration.Unix()}, claims)
+
+	// Links of other purposes signed with the same secret are not valid downloads
+	resetToken, _, _ := NewPasswordResetManager("mySecretKey", time.Hour).Create("alice", "")
+	_, err = manager.Verify(resetToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestDeletionCancelExpiresWithTheDeletion(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	NowFunc = func() time.Time { return now }
+	defer func() { NowFunc = time.Now }()
+
+	manager := NewDeletionCancelManager("mySecretKey")
+	token, err := manager.Create("alice", now.Add(time.Hour))
+	assert.NoError(t, err)
+
+	claims, err := manager.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, &DeletionCancelClaims{Username: "alice", DeletionScheduledAt: now.Add(time.Hour).Unix()}, claims)
+
+	NowFunc = func() time.Time { return now.Add(time.Hour) }
+	_, err = manager.Verify(token)
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
@@ -0,0 +1,566 @@
+package adminController
+
+import (
+	"fmt"
+	"go-gallery/src/commons/exception"
+	utilsToken "go-gallery/src/commons/utils/token"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	userEntity "go-gallery/src/domain/entities/user"
+	"strconv"
+	"time"
+
+	"go-gallery/src/infrastructure/dto"
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	loggerDTO "go-gallery/src/infrastructure/dto/logger"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	emailTemplate "go-gallery/src/infrastructure/repository/emailSender/template"
+	auditService "go-gallery/src/service/audit"
+	avatarService "go-gallery/src/service/avatar"
+	emailService "go-gallery/src/service/email"
+	imageService "go-gallery/src/service/image"
+	userService "go-gallery/src/service/user"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	INVALID_AUTHENTIFICATION_MSG string = "User not authenticated"
+	INVALID_REQUEST_MSG          string = "Invalid JSON in the request body"
+	INVALID_ROLE_MSG             string = "Invalid role, the allowed roles are user, admin and read-only"
+	SELF_MODIFICATION_MSG        string = "Administrators cannot change their own role, disable or delete their own account"
+	INVALID_DATE_MSG             string = "Invalid date, the expected format is RFC 3339 (2006-01-02T15:04:05Z)"
+	INVALID_LOG_LEVEL_MSG        string = "Invalid log level, the allowed levels are TRACE, DEBUG, INFO, WARNING, ERROR and PANIC"
+	UNKNOWN_LOG_SINK_MSG         string = "Unknown log sink"
+	RANDOM_PASSWORD_BYTES        int    = 32
+	DEFAULT_PAGE_SIZE            int64  = 20
+	MAX_PAGE_SIZE                int64  = 100
+)
+
+var logger log.Logger
+
+type AdminController struct {
+	userService        *userService.UserService
+	imageService       *imageService.ImageService
+	avatarService      *avatarService.AvatarService
+	auditService       *auditService.AuditService
+	emailSenderService *emailService.EmailSenderService
+}
+
+func NewAdminController(userService *userService.UserService, imageService *imageService.ImageService, avatarService *avatarService.AvatarService,
+	auditService *auditService.AuditService, emailSenderService *emailService.EmailSenderService) *AdminController {
+	logger = log.Instance()
+	return &AdminController{
+		userService:        userService,
+		imageService:       imageService,
+		avatarService:      avatarService,
+		auditService:       auditService,
+		emailSenderService: emailSenderService,
+	}
+}
+
+func (c *AdminController) SetUpRoutes(router fiber.Router) {
+	// Users
+	router.Get("/users", c.listUsers)
+	router.Get("/users/:username", c.getUser)
+	router.Delete("/users/:username", c.deleteUser)
+	router.Put("/users/:username/role", c.updateRole)
+	router.Post("/users/:username/disable", c.disableUser)
+	router.Post("/users/:username/enable", c.enableUser)
+	router.Post("/users/:username/force-password-reset", c.forcePasswordReset)
+
+	// Storage
+	router.Get("/storage", c.storageUsage)
+
+	// Audit
+	router.Get("/audit", c.listAuditEvents)
+
+	// Logging
+	router.Get("/logging/levels", c.logLevels)
+	router.Put("/logging/levels", c.updateLogLevel)
+	router.Get("/logging/queue", c.logQueueStats)
+}
+
+// @Summary		Listar usuarios
+// @Description	Obtiene una lista paginada de usuarios ordenada por nombre de usuario, usando paginación por cursor (lastUsername y pageSize). El parámetro search filtra por nombre de usuario, correo, nombre o apellido sin distinguir mayúsculas. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Param			search			query	string	false	"Texto a buscar en el nombre de usuario, correo, nombre o apellido"
+// @Param			lastUsername	query	string	false	"Último nombre de usuario recibido para la paginación"
+// @Param			pageSize		query	int		false	"Cantidad de usuarios a devolver (por defecto 20, máximo 100)"
+// @Security		CookieAuth
+// @Success		200	{object}	userDTO.UserCursorDTO	"Lista de usuarios con el último nombre de usuario para poder realizar paginación"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/admin/users [get]
+func (c *AdminController) listUsers(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	search := ctx.Query("search")
+	lastUsername := ctx.Query("lastUsername")
+	pageSizeParam := ctx.Query("pageSize")
+
+	logger.Info("GET /admin/users called", log.F("search", search), log.F("last_username", lastUsername), log.F("page_size", pageSizeParam))
+
+	users, err := c.userService.FindAll(search, lastUsername, parsePageSize(pageSizeParam))
+	if err != nil {
+		logger.Error("Error listing users", log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(users)
+}
+
+// @Summary		Consultar un usuario
+// @Description	Devuelve los datos de un usuario junto con su número de imágenes y el almacenamiento que ocupan. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Param			username	path	string	true	"Nombre de usuario"
+// @Security		CookieAuth
+// @Success		200	{object}	userDTO.UserDetailDTO	"Datos del usuario"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		404	{object}	exception.ApiException	"Usuario no encontrado"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/admin/users/{username} [get]
+func (c *AdminController) getUser(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	username := ctx.Params("username")
+	logger.Info("GET /admin/users/:username called", log.F("username", username))
+
+	user, err := c.userService.FindByUsername(username)
+	if err != nil {
+		logger.Error("Error finding user", log.F("username", username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	report, err := c.imageService.StorageUsage(ctx.UserContext(), username)
+	if err != nil {
+		logger.Error("Error calculating storage usage of user", log.F("username", username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(&userDTO.UserDetailDTO{
+		User:       userDTO.ToUserSummary(user),
+		Images:     report.TotalImages,
+		TotalBytes: report.TotalBytes,
+	})
+}
+
+// @Summary		Eliminar un usuario
+// @Description	Elimina la cuenta de un usuario junto con todas sus imágenes y miniaturas. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Param			username	path	string	true	"Nombre de usuario"
+// @Security		CookieAuth
+// @Success		200	{object}	dto.MessageResponseDTO	"Usuario eliminado"
+// @Failure		400	{object}	exception.ApiException	"No puedes eliminar tu propia cuenta"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		404	{object}	exception.ApiException	"Usuario no encontrado"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/admin/users/{username} [delete]
+func (c *AdminController) deleteUser(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	username := ctx.Params("username")
+	logger.Info("DELETE /admin/users/:username called", log.F("username", username))
+
+	if errSelf := checkNotSelf(ctx, username); errSelf != nil {
+		return ctx.Status(errSelf.Status).JSON(errSelf)
+	}
+
+	// Check that the user exists before deleting anything
+	if _, err := c.userService.FindByUsername(username); err != nil {
+		logger.Error("Error finding user", log.F("username", username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	if _, err := c.imageService.DeleteAll(ctx.UserContext(), &imageDTO.ImageDeleteRequestDTO{Owner: username}); err != nil {
+		logger.Error("Error deleting all images of user", log.F("username", username), log.F("error", err.Message))
+		c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_USER_DELETE, username, false)
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	if _, err := c.avatarService.Delete(username); err != nil {
+		logger.Error("Error deleting avatar of user", log.F("username", username), log.F("error", err.Message))
+		c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_USER_DELETE, username, false)
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	if _, err := c.userService.DeleteByUsername(username); err != nil {
+		logger.Error("Error deleting user", log.F("username", username), log.F("error", err.Message))
+		c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_USER_DELETE, username, false)
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_USER_DELETE, username, true)
+	logger.Info("User and all their images have been deleted", log.F("username", username))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("The user %s and all their images have been deleted", username),
+	})
+}
+
+// @Summary		Cambiar el rol de un usuario
+// @Description	Asigna el rol user, admin o read-only a un usuario. Requiere rol de administrador.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			username	path	string						true	"Nombre de usuario"
+// @Param			request		body	userDTO.UserRoleUpdateDTO	true	"Nuevo rol"
+// @Security		CookieAuth
+// @Success		200	{object}	dto.MessageResponseDTO	"Rol actualizado"
+// @Failure		400	{object}	exception.ApiException	"Rol no válido"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		404	{object}	exception.ApiException	"Usuario no encontrado"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/admin/users/{username}/role [put]
+func (c *AdminController) updateRole(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	username := ctx.Params("username")
+	logger.Info("PUT /admin/users/:username/role called", log.F("username", username))
+
+	request := new(userDTO.UserRoleUpdateDTO)
+	if err := ctx.BodyParser(request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_REQUEST_MSG))
+	}
+
+	if !userEntity.IsValidRole(request.Role) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_ROLE_MSG))
+	}
+
+	if errSelf := checkNotSelf(ctx, username); errSelf != nil {
+		return ctx.Status(errSelf.Status).JSON(errSelf)
+	}
+
+	// The target keeps the new role, the event would not say what changed otherwise
+	target := username + ":" + request.Role
+	if _, err := c.userService.UpdateRole(username, request.Role); err != nil {
+		logger.Error("Error changing role of user", log.F("username", username), log.F("error", err.Message))
+		c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_ROLE_CHANGE, target, false)
+		return ctx.Status(err.Status).JSON(err)
+	}
+	c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_ROLE_CHANGE, target, true)
+
+	logger.Info("Role of user changed", log.F("username", username), log.F("role", request.Role))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("The role of user %s has been changed to %s", username, request.Role),
+	})
+}
+
+// @Summary		Deshabilitar una cuenta
+// @Description	Deshabilita la cuenta de un usuario impidiendo que inicie sesión. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Param			username	path	string	true	"Nombre de usuario"
+// @Security		CookieAuth
+// @Success		200	{object}	dto.MessageResponseDTO	"Cuenta deshabilitada"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		404	{object}	exception.ApiException	"Usuario no encontrado"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/admin/users/{username}/disable [post]
+func (c *AdminController) disableUser(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	username := ctx.Params("username")
+	logger.Info("POST /admin/users/:username/disable called", log.F("username", username))
+
+	if errSelf := checkNotSelf(ctx, username); errSelf != nil {
+		return ctx.Status(errSelf.Status).JSON(errSelf)
+	}
+
+	if _, err := c.userService.SetDisabled(username, true); err != nil {
+		logger.Error("Error disabling user", log.F("username", username), log.F("error", err.Message))
+		c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_USER_DISABLE, username, false)
+		return ctx.Status(err.Status).JSON(err)
+	}
+	c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_USER_DISABLE, username, true)
+
+	logger.Info("User has been disabled", log.F("username", username))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("The account of user %s has been disabled", username),
+	})
+}
+
+// @Summary		Reactivar una cuenta
+// @Description	Vuelve a habilitar la cuenta de un usuario deshabilitado. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Param			username	path	string	true	"Nombre de usuario"
+// @Security		CookieAuth
+// @Success		200	{object}	dto.MessageResponseDTO	"Cuenta reactivada"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		404	{object}	exception.ApiException	"Usuario no encontrado"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/admin/users/{username}/enable [post]
+func (c *AdminController) enableUser(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	username := ctx.Params("username")
+	logger.Info("POST /admin/users/:username/enable called", log.F("username", username))
+
+	if _, err := c.userService.SetDisabled(username, false); err != nil {
+		logger.Error("Error enabling user", log.F("username", username), log.F("error", err.Message))
+		c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_USER_ENABLE, username, false)
+		return ctx.Status(err.Status).JSON(err)
+	}
+	c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_USER_ENABLE, username, true)
+
+	logger.Info("User has been enabled", log.F("username", username))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("The account of user %s has been enabled", username),
+	})
+}
+
+// @Summary		Forzar el restablecimiento de la contraseña
+// @Description	Sustituye la contraseña de un usuario por una aleatoria y le envía un correo indicándole que use la recuperación de contraseña para elegir una nueva. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Param			username	path	string	true	"Nombre de usuario"
+// @Security		CookieAuth
+// @Success		200	{object}	dto.MessageResponseDTO	"Contraseña restablecida"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		404	{object}	exception.ApiException	"Usuario no encontrado"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/admin/users/{username}/force-password-reset [post]
+func (c *AdminController) forcePasswordReset(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	username := ctx.Params("username")
+	logger.Info("POST /admin/users/:username/force-password-reset called", log.F("username", username))
+
+	user, err := c.userService.FindByUsername(username)
+	if err != nil {
+		logger.Error("Error finding user", log.F("username", username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	// Nobody knows the new password, so the user has to go through the password recovery
+	password, errToken := utilsToken.GenerateToken(RANDOM_PASSWORD_BYTES)
+	if errToken != nil {
+		logger.Error("Error generating random password", log.F("error", errToken.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, "Internal server error"))
+	}
+
+	if _, err := c.userService.Update(&userDTO.UserDTO{Username: username, Password: password}); err != nil {
+		logger.Error("Error resetting password of user", log.F("username", username), log.F("error", err.Message))
+		c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_PASSWORD_RESET, username, false)
+		return ctx.Status(err.Status).JSON(err)
+	}
+	c.audit(ctx, auditDTO.AUDIT_ACTION_ADMIN_PASSWORD_RESET, username, true)
+
+	if _, err := c.userService.RevokeSessions(username); err != nil {
+		logger.Error("Error revoking the sessions of user", log.F("username", username), log.F("error", err.Message))
+	}
+
+	if errEmail := c.emailSenderService.SendEmail(username, user.Email, emailTemplate.PasswordResetTemplate{}); errEmail != nil {
+		logger.Error("Error sending password reset email", log.F("username", username), log.F("error", errEmail.Error()))
+	}
+
+	logger.Info("Password of user has been reset", log.F("username", username))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("The password of user %s has been reset and the user has been notified by email", username),
+	})
+}
+
+// @Summary		Consultar el almacenamiento
+// @Description	Devuelve el número de imágenes y los bytes ocupados por imágenes y miniaturas de cada usuario, junto con el total. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Security		CookieAuth
+// @Success		200	{object}	imageDTO.StorageUsageReportDTO	"Almacenamiento ocupado"
+// @Failure		401	{object}	exception.ApiException			"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException			"No tienes permisos para realizar esta acción"
+// @Failure		500	{object}	exception.ApiException			"Ha ocurrido un error inesperado"
+// @Router			/admin/storage [get]
+func (c *AdminController) storageUsage(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("GET /admin/storage called")
+
+	report, err := c.imageService.StorageUsage(ctx.UserContext(), "")
+	if err != nil {
+		logger.Error("Error calculating storage usage", log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(report)
+}
+
+// @Summary		Consultar el registro de auditoría
+// @Description	Obtiene los eventos de auditoría del más reciente al más antiguo, filtrados por usuario, acción, resultado y periodo, usando paginación por cursor (lastId y pageSize). Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Param			actor		query	string	false	"Usuario que realizó la acción"
+// @Param			action		query	string	false	"Acción realizada, por ejemplo login o image_delete"
+// @Param			outcome		query	string	false	"Resultado de la acción (success o failure)"
+// @Param			from		query	string	false	"Fecha inicial incluida, en formato RFC 3339"
+// @Param			to			query	string	false	"Fecha final excluida, en formato RFC 3339"
+// @Param			lastId		query	string	false	"Identificador del último evento recibido para la paginación"
+// @Param			pageSize	query	int		false	"Cantidad de eventos a devolver (por defecto 20, máximo 100)"
+// @Security		CookieAuth
+// @Success		200	{object}	auditDTO.AuditCursorDTO	"Eventos encontrados"
+// @Failure		400	{object}	exception.ApiException	"Filtros no válidos"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/admin/audit [get]
+func (c *AdminController) listAuditEvents(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("GET /admin/audit called")
+
+	from, errFrom := parseOptionalDate(ctx.Query("from"))
+	to, errTo := parseOptionalDate(ctx.Query("to"))
+	if errFrom != nil || errTo != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_DATE_MSG))
+	}
+
+	filter := &auditDTO.AuditFilterDTO{
+		Actor:    ctx.Query("actor"),
+		Action:   ctx.Query("action"),
+		Outcome:  ctx.Query("outcome"),
+		From:     from,
+		To:       to,
+		LastId:   ctx.Query("lastId"),
+		PageSize: parsePageSize(ctx.Query("pageSize")),
+	}
+
+	events, err := c.auditService.Find(filter)
+	if err != nil {
+		logger.Error("Error listing audit events", log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(events)
+}
+
+// @Summary		Consultar los niveles de log
+// @Description	Devuelve el nivel mínimo que escribe cada destino de los logs. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Security		CookieAuth
+// @Success		200	{object}	loggerDTO.LogLevelsDTO	"Nivel de cada destino"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Router			/admin/logging/levels [get]
+func (c *AdminController) logLevels(ctx *fiber.Ctx) error {
+	log.FromContext(ctx.UserContext()).Info("GET /admin/logging/levels called")
+	return ctx.Status(fiber.StatusOK).JSON(loggerDTO.ToLogLevelsDTO(log.Levels()))
+}
+
+// @Summary		Cambiar el nivel de log
+// @Description	Cambia sin reiniciar el nivel mínimo que escribe un destino de los logs (console o file). El cambio no se conserva al reiniciar. Requiere rol de administrador.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			request	body	loggerDTO.LogLevelUpdateDTO	true	"Destino y nuevo nivel"
+// @Security		CookieAuth
+// @Success		200	{object}	loggerDTO.LogLevelsDTO	"Nivel de cada destino tras el cambio"
+// @Failure		400	{object}	exception.ApiException	"Nivel no válido"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException	"No tienes permisos para realizar esta acción"
+// @Failure		404	{object}	exception.ApiException	"Destino de logs no encontrado"
+// @Router			/admin/logging/levels [put]
+func (c *AdminController) updateLogLevel(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("PUT /admin/logging/levels called")
+
+	request := new(loggerDTO.LogLevelUpdateDTO)
+	if err := ctx.BodyParser(request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_REQUEST_MSG))
+	}
+
+	level, ok := loggerEntity.ParseLevel(request.Level)
+	if !ok {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_LOG_LEVEL_MSG))
+	}
+
+	previous, exists := log.Levels()[request.Sink]
+	if !exists {
+		return ctx.Status(fiber.StatusNotFound).JSON(exception.NewApiException(fiber.StatusNotFound, UNKNOWN_LOG_SINK_MSG))
+	}
+
+	// Logged before the change so it is written even when the new level hides INFO messages
+	logger.Warning("Log level changed", log.F("sink", request.Sink), log.F("previous", previous.String()), log.F("level", level.String()))
+	if err := log.SetLevel(request.Sink, level); err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(exception.NewApiException(fiber.StatusNotFound, UNKNOWN_LOG_SINK_MSG))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(loggerDTO.ToLogLevelsDTO(log.Levels()))
+}
+
+// @Summary		Consultar la cola de logs
+// @Description	Devuelve la ocupación de la cola de mensajes de log y cuántos se han descartado por encontrarla llena. Requiere rol de administrador.
+// @Tags			admin
+// @Produce		json
+// @Security		CookieAuth
+// @Success		200	{object}	loggerDTO.LogQueueStatsDTO	"Estado de la cola"
+// @Failure		401	{object}	exception.ApiException		"Usuario no autenticado"
+// @Failure		403	{object}	exception.ApiException		"No tienes permisos para realizar esta acción"
+// @Failure		404	{object}	exception.ApiException		"El logger no usa una cola"
+// @Router			/admin/logging/queue [get]
+func (c *AdminController) logQueueStats(ctx *fiber.Ctx) error {
+	log.FromContext(ctx.UserContext()).Info("GET /admin/logging/queue called")
+
+	stats, ok := log.QueueStats()
+	if !ok {
+		return ctx.Status(fiber.StatusNotFound).JSON(exception.NewApiException(fiber.StatusNotFound, "The logger does not use a queue"))
+	}
+	return ctx.Status(fiber.StatusOK).JSON(loggerDTO.ToLogQueueStatsDTO(stats))
+}
+
+// Prevents administrators from locking themselves out of the admin area or deleting their own account
+// Records an administrative action on an account, the actor is the administrator that made the request
+func (c *AdminController) audit(ctx *fiber.Ctx, action, target string, success bool) {
+	actor := ""
+	if claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO); ok {
+		actor = claims.Username
+	}
+	c.auditService.Record(ctx.UserContext(), actor, action, target, ctx.IP(), ctx.Get(fiber.HeaderUserAgent), success)
+}
+
+func checkNotSelf(ctx *fiber.Ctx, username string) *exception.ApiException {
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error(INVALID_AUTHENTIFICATION_MSG)
+		return exception.NewApiException(fiber.StatusUnauthorized, INVALID_AUTHENTIFICATION_MSG)
+	}
+
+	if claims.Username == username {
+		logger.Warning("Administrator tried to modify their own account", log.F("username", username))
+		return exception.NewApiException(fiber.StatusBadRequest, SELF_MODIFICATION_MSG)
+	}
+
+	return nil
+}
+
+// The page size must be positive, by default DEFAULT_PAGE_SIZE and never above MAX_PAGE_SIZE
+func parsePageSize(pageSizeParam string) int64 {
+	pageSize := DEFAULT_PAGE_SIZE
+	if parsedPageSize, err := strconv.ParseInt(pageSizeParam, 10, 64); err == nil && parsedPageSize > 0 {
+		pageSize = min(parsedPageSize, MAX_PAGE_SIZE)
+	}
+	return pageSize
+}
+
+// An empty value means the period is not limited on that side
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
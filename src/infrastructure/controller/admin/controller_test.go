@@ -0,0 +1,64 @@
+package adminController
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	auditRepository "go-gallery/src/infrastructure/repository/audit"
+	"go-gallery/src/infrastructure/repository/user/userTest"
+	auditService "go-gallery/src/service/audit"
+	userService "go-gallery/src/service/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminApp(t *testing.T) (*fiber.App, *auditService.AuditService) {
+	log.Init(log.NewConsoleLogger())
+
+	repository := userTest.NewRepository(&userDTO.UserDTO{Username: "alice", Password: "alice-password", Email: "alice@example.com", Role: "user"})
+	audit := auditService.NewAuditService(auditRepository.NewAuditMemoryRepository(nil))
+	controller := NewAdminController(userService.NewUserService(repository, 0), nil, nil, audit, nil)
+
+	app := fiber.New()
+	app.Use(func(ctx *fiber.Ctx) error {
+		ctx.Locals("user", &userDTO.JwtClaimsDTO{Username: "root", Role: "admin"})
+		return ctx.Next()
+	})
+	controller.SetUpRoutes(app.Group("/api/admin"))
+	return app, audit
+}
+
+func TestAdministrativeActionsAreAudited(t *testing.T) {
+	app, audit := newAdminApp(t)
+
+	response, err := app.Test(httptest.NewRequest("POST", "/api/admin/users/alice/disable", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, response.StatusCode)
+
+	request := httptest.NewRequest("PUT", "/api/admin/users/alice/role", strings.NewReader(`{"role":"read-only"}`))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	response, err = app.Test(request)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, response.StatusCode)
+
+	response, err = app.Test(httptest.NewRequest("POST", "/api/admin/users/nobody/enable", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, response.StatusCode)
+
+	events, errFind := audit.Find(&auditDTO.AuditFilterDTO{Actor: "root"})
+	require.Nil(t, errFind)
+	require.Len(t, events.Events, 3)
+	assert.Equal(t, auditDTO.AUDIT_ACTION_ADMIN_USER_ENABLE, events.Events[0].Action)
+	assert.Equal(t, "nobody", events.Events[0].Target)
+	assert.Equal(t, auditDTO.AUDIT_OUTCOME_FAILURE, events.Events[0].Outcome)
+	assert.Equal(t, auditDTO.AUDIT_ACTION_ADMIN_ROLE_CHANGE, events.Events[1].Action)
+	assert.Equal(t, "alice:read-only", events.Events[1].Target)
+	assert.Equal(t, auditDTO.AUDIT_ACTION_ADMIN_USER_DISABLE, events.Events[2].Action)
+	assert.Equal(t, auditDTO.AUDIT_OUTCOME_SUCCESS, events.Events[2].Outcome)
+}
@@ -0,0 +1,219 @@
+package avatarController
+
+import (
+	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
+	"go-gallery/src/commons/constants"
+	"go-gallery/src/commons/exception"
+	"net/http"
+	"strconv"
+
+	imageHandler "go-gallery/src/infrastructure/controller/image/handler"
+	userMiddleware "go-gallery/src/infrastructure/controller/user/middlewares"
+	"go-gallery/src/infrastructure/dto"
+	avatarDTO "go-gallery/src/infrastructure/dto/avatar"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	avatarService "go-gallery/src/service/avatar"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	INVALID_AUTHENTIFICATION_MSG string = "User not authenticated"
+	INVALID_AVATAR_REQUEST_MSG   string = "Invalid avatar request"
+	AVATAR_CACHE_CONTROL         string = "public, max-age=86400"
+)
+
+var logger log.Logger
+
+type AvatarController struct {
+	avatarService *avatarService.AvatarService
+	jwtMiddleware *userMiddleware.JWTMiddleware
+
+	verificationConfiguration configuration.VerificationConfiguration
+}
+
+func NewAvatarController(avatarService *avatarService.AvatarService, jwtMiddleware *userMiddleware.JWTMiddleware,
+	verificationConfiguration configuration.VerificationConfiguration) *AvatarController {
+	logger = log.Instance()
+	return &AvatarController{
+		avatarService:             avatarService,
+		jwtMiddleware:             jwtMiddleware,
+		verificationConfiguration: verificationConfiguration,
+	}
+}
+
+func (c *AvatarController) SetUpRoutes(router fiber.Router) {
+	protected := []fiber.Handler{c.jwtMiddleware.Handler()}
+	if !c.verificationConfiguration.AllowUnverifiedGallery {
+		protected = append(protected, c.jwtMiddleware.VerifiedHandler())
+	}
+	router.Put("/", append(protected, c.uploadAvatar)...)
+	router.Put("/from-image", append(protected, c.avatarFromImage)...)
+	router.Delete("/", append(protected, c.deleteAvatar)...)
+
+	// Avatars are public so they can be shown next to the user anywhere
+	router.Get("/:username", c.getAvatar)
+}
+
+//	@Summary		Subir un avatar
+//	@Description	Genera el avatar del usuario autenticado a partir de un fichero, recortando el cuadrado indicado y redimensionándolo a cada uno de los tamaños disponibles. Sin recorte se usa el mayor cuadrado centrado
+//	@Tags			avatar
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file	formData	file	true	"Archivo de imagen a subir (jpeg, jpg, png, webp)"
+//	@Param			x		formData	int		false	"Coordenada horizontal de la esquina superior izquierda del recorte"
+//	@Param			y		formData	int		false	"Coordenada vertical de la esquina superior izquierda del recorte"
+//	@Param			size	formData	int		false	"Lado del cuadrado recortado"
+//	@Security		CookieAuth
+//	@Success		200	{object}	avatarDTO.AvatarResponseDTO	"Direcciones del avatar generado"
+//	@Failure		400	{object}	exception.ApiException		"La imagen no es válida o el recorte está fuera de la imagen"
+//	@Failure		401	{object}	exception.ApiException		"Usuario no autenticado"
+//	@Failure		500	{object}	exception.ApiException		"Ha ocurrido un error inesperado"
+//	@Router			/avatar [put]
+func (c *AvatarController) uploadAvatar(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("PUT /avatar called")
+
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error(INVALID_AUTHENTIFICATION_MSG)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, INVALID_AUTHENTIFICATION_MSG))
+	}
+
+	fileInput, errForm := ctx.FormFile("file")
+	if errForm != nil {
+		logger.Error("Failed to get avatar file from form data", log.F("error", errForm.Error()))
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Error getting image from form"))
+	}
+
+	crop := new(avatarDTO.AvatarCropDTO)
+	if err := ctx.BodyParser(crop); err != nil {
+		logger.Error("Invalid crop box in avatar request", log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_AVATAR_REQUEST_MSG))
+	}
+
+	file, errFile := imageHandler.ProcessImageFile(ctx.UserContext(), fileInput, claims.Username)
+	if errFile != nil {
+		logger.Error("Error processing avatar file", log.F("error", errFile.Message))
+		return ctx.Status(errFile.Status).JSON(errFile)
+	}
+
+	response, err := c.avatarService.SetFromUpload(ctx.UserContext(), claims.Username, file.RawContentFile, *crop)
+	if err != nil {
+		logger.Error("Error setting avatar", log.F("username", claims.Username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+//	@Summary		Usar una imagen de la galería como avatar
+//	@Description	Genera el avatar del usuario autenticado a partir de una de sus imágenes, recortando el cuadrado indicado y redimensionándolo a cada uno de los tamaños disponibles. Sin recorte se usa el mayor cuadrado centrado
+//	@Tags			avatar
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	avatarDTO.AvatarFromImageRequestDTO	true	"Imagen de la galería y recorte"
+//	@Security		CookieAuth
+//	@Success		200	{object}	avatarDTO.AvatarResponseDTO	"Direcciones del avatar generado"
+//	@Failure		400	{object}	exception.ApiException		"Petición no válida o el recorte está fuera de la imagen"
+//	@Failure		401	{object}	exception.ApiException		"Usuario no autenticado"
+//	@Failure		404	{object}	exception.ApiException		"Imagen no encontrada"
+//	@Failure		500	{object}	exception.ApiException		"Ha ocurrido un error inesperado"
+//	@Router			/avatar/from-image [put]
+func (c *AvatarController) avatarFromImage(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("PUT /avatar/from-image called")
+
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error(INVALID_AUTHENTIFICATION_MSG)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, INVALID_AUTHENTIFICATION_MSG))
+	}
+
+	request := new(avatarDTO.AvatarFromImageRequestDTO)
+	if err := ctx.BodyParser(request); err != nil || request.ImageID == "" {
+		logger.Error("Invalid JSON in avatar from image request")
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_AVATAR_REQUEST_MSG))
+	}
+
+	response, err := c.avatarService.SetFromImage(ctx.UserContext(), claims.Username, request)
+	if err != nil {
+		logger.Error("Error setting avatar from image", log.F("username", claims.Username), log.F("image_id", request.ImageID), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+//	@Summary		Eliminar el avatar
+//	@Description	Elimina el avatar del usuario autenticado
+//	@Tags			avatar
+//	@Produce		json
+//	@Security		CookieAuth
+//	@Success		200	{object}	dto.MessageResponseDTO	"Avatar eliminado"
+//	@Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+//	@Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+//	@Router			/avatar [delete]
+func (c *AvatarController) deleteAvatar(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("DELETE /avatar called")
+
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error(INVALID_AUTHENTIFICATION_MSG)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, INVALID_AUTHENTIFICATION_MSG))
+	}
+
+	if _, err := c.avatarService.Delete(claims.Username); err != nil {
+		logger.Error("Error deleting avatar", log.F("username", claims.Username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	logger.Info("Avatar deleted", log.F("username", claims.Username))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: "The avatar has been deleted.",
+	})
+}
+
+//	@Summary		Obtener el avatar de un usuario
+//	@Description	Devuelve el avatar público de un usuario en formato WebP. La respuesta puede guardarse en caché y se revalida con su ETag
+//	@Tags			avatar
+//	@Produce		image/webp
+//	@Param			username	path	string	true	"Nombre del usuario"
+//	@Param			size		query	int		false	"Lado del avatar en píxeles: 64, 128 o 256 (por defecto 128)"
+//	@Success		200	{file}		file					"Avatar del usuario"
+//	@Success		304	"El avatar no ha cambiado"
+//	@Failure		400	{object}	exception.ApiException	"Tamaño no disponible"
+//	@Failure		404	{object}	exception.ApiException	"El usuario no tiene avatar"
+//	@Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+//	@Router			/avatar/{username} [get]
+func (c *AvatarController) getAvatar(ctx *fiber.Ctx) error {
+	username := ctx.Params("username")
+
+	size, errSize := strconv.Atoi(ctx.Query("size", strconv.Itoa(constants.AVATAR_MEDIUM_SIZE)))
+	if errSize != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, avatarService.INVALID_AVATAR_SIZE_MSG))
+	}
+
+	content, updatedAt, err := c.avatarService.Find(username, size)
+	if err != nil {
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	etag := fmt.Sprintf(`"%d-%d"`, updatedAt.UnixMilli(), size)
+	ctx.Set(fiber.HeaderCacheControl, AVATAR_CACHE_CONTROL)
+	ctx.Set(fiber.HeaderETag, etag)
+	ctx.Set(fiber.HeaderLastModified, updatedAt.UTC().Format(http.TimeFormat))
+
+	if ctx.Get(fiber.HeaderIfNoneMatch) == etag {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "image/webp")
+	return ctx.Status(fiber.StatusOK).Send(content)
+}
@@ -0,0 +1,155 @@
+package exportController
+
+import (
+	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
+	"go-gallery/src/commons/exception"
+	"os"
+
+	userMiddleware "go-gallery/src/infrastructure/controller/user/middlewares"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	exportService "go-gallery/src/service/export"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	INVALID_AUTHENTIFICATION_MSG string = "User not authenticated"
+	EXPORT_TOKEN_REQUIRED_MSG    string = "The download token is required"
+	EXPORT_FILE_ERROR_MSG        string = "Error reading the export file"
+)
+
+var logger log.Logger
+
+type ExportController struct {
+	exportService *exportService.ExportService
+	jwtMiddleware *userMiddleware.JWTMiddleware
+
+	verificationConfiguration configuration.VerificationConfiguration
+}
+
+func NewExportController(exportService *exportService.ExportService, jwtMiddleware *userMiddleware.JWTMiddleware,
+	verificationConfiguration configuration.VerificationConfiguration) *ExportController {
+	logger = log.Instance()
+	return &ExportController{
+		exportService:             exportService,
+		jwtMiddleware:             jwtMiddleware,
+		verificationConfiguration: verificationConfiguration,
+	}
+}
+
+func (c *ExportController) SetUpRoutes(router fiber.Router) {
+	// The download link is sent by email and is authenticated by its signature
+	router.Get("/download", c.download)
+
+	protected := []fiber.Handler{c.jwtMiddleware.Handler()}
+	if !c.verificationConfiguration.AllowUnverifiedGallery {
+		protected = append(protected, c.jwtMiddleware.VerifiedHandler())
+	}
+	router.Post("/", append(protected, c.requestExport)...)
+	router.Get("/:id", append(protected, c.getExport)...)
+}
+
+//	@Summary		Solicitar una exportación de los datos de la cuenta
+//	@Description	Genera en segundo plano un zip con el perfil, las imágenes originales con su nombre, las miniaturas y los metadatos en JSON. Cuando está listo se envía por correo un enlace de descarga de un solo uso.
+//	@Tags			export
+//	@Produce		json
+//	@Security		CookieAuth
+//	@Success		202	{object}	exportDTO.ExportJobDTO	"Exportación registrada, puede consultarse su progreso"
+//	@Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+//	@Failure		403	{object}	exception.ApiException	"El correo electrónico de la cuenta no ha sido verificado"
+//	@Failure		409	{object}	exception.ApiException	"Ya hay una exportación en curso o pendiente de descargar"
+//	@Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+//	@Router			/export [post]
+func (c *ExportController) requestExport(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("POST /export called")
+
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error(INVALID_AUTHENTIFICATION_MSG)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, INVALID_AUTHENTIFICATION_MSG))
+	}
+
+	job, err := c.exportService.Request(ctx.UserContext(), claims.Username)
+	if err != nil {
+		logger.Error("Error requesting export", log.F("username", claims.Username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(job)
+}
+
+//	@Summary		Consultar una exportación
+//	@Description	Devuelve el estado y el progreso de una exportación del usuario autenticado
+//	@Tags			export
+//	@Produce		json
+//	@Param			id	path	string	true	"Identificador de la exportación"
+//	@Security		CookieAuth
+//	@Success		200	{object}	exportDTO.ExportJobDTO	"Estado de la exportación"
+//	@Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+//	@Failure		404	{object}	exception.ApiException	"Exportación no encontrada"
+//	@Router			/export/{id} [get]
+func (c *ExportController) getExport(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	id := ctx.Params("id")
+	logger.Info("GET /export/:id called", log.F("export_id", id))
+
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error(INVALID_AUTHENTIFICATION_MSG)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, INVALID_AUTHENTIFICATION_MSG))
+	}
+
+	job, err := c.exportService.Find(id, claims.Username)
+	if err != nil {
+		logger.Warning("Export not found", log.F("export_id", id), log.F("username", claims.Username))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(job)
+}
+
+//	@Summary		Descargar una exportación
+//	@Description	Descarga el zip de una exportación mediante el enlace firmado enviado por correo. El enlace solo puede usarse una vez y el fichero se elimina tras la descarga.
+//	@Tags			export
+//	@Produce		application/zip
+//	@Param			token	query	string	true	"Token firmado del enlace de descarga"
+//	@Success		200	{file}		file					"Fichero zip con los datos de la cuenta"
+//	@Failure		400	{object}	exception.ApiException	"Falta el token de descarga"
+//	@Failure		401	{object}	exception.ApiException	"El enlace no es válido, ha caducado o ya se ha usado"
+//	@Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+//	@Router			/export/download [get]
+func (c *ExportController) download(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("GET /export/download called")
+
+	token := ctx.Query("token")
+	if token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, EXPORT_TOKEN_REQUIRED_MSG))
+	}
+
+	job, err := c.exportService.Download(ctx.UserContext(), token)
+	if err != nil {
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	file, errFile := os.Open(job.FilePath)
+	if errFile != nil {
+		logger.Error("Error opening export file", log.F("export_id", job.Id), log.F("error", errFile.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, EXPORT_FILE_ERROR_MSG))
+	}
+
+	// The link is single-use, so the file is removed while it is still open and streamed
+	if errRemove := os.Remove(job.FilePath); errRemove != nil {
+		logger.Error("Error removing export file", log.F("export_id", job.Id), log.F("error", errRemove.Error()))
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="go-gallery-%s.zip"`, job.Username))
+	return ctx.Status(fiber.StatusOK).SendStream(file)
+}
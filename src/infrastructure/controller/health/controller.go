@@ -0,0 +1,67 @@
+package healthController
+
+import (
+	healthDTO "go-gallery/src/infrastructure/dto/health"
+	log "go-gallery/src/infrastructure/logger"
+	healthService "go-gallery/src/service/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var logger log.Logger
+
+type HealthController struct {
+	healthService *healthService.HealthService
+}
+
+func NewHealthController(healthService *healthService.HealthService) *HealthController {
+	logger = log.Instance()
+	return &HealthController{healthService: healthService}
+}
+
+func (c *HealthController) SetUpRoutes(router fiber.Router) {
+	router.Get("/health", c.health)
+	router.Get("/ready", c.ready)
+	router.Get("/version", c.version)
+}
+
+//	@Summary		Comprobar que el servicio está vivo
+//	@Description	Responde siempre que el proceso atiende peticiones, sin comprobar las dependencias. Pensado para la sonda de liveness del orquestador.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	healthDTO.HealthDTO	"El servicio está vivo"
+//	@Router			/health [get]
+func (c *HealthController) health(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(healthDTO.HealthDTO{Status: healthDTO.HEALTH_STATUS_UP})
+}
+
+//	@Summary		Comprobar que el servicio puede atender peticiones
+//	@Description	Comprueba la conexión con cada repositorio configurado (MongoDB, PostgreSQL o Redis). Los repositorios en memoria no se comprueban. Pensado para la sonda de readiness del orquestador.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	healthDTO.ReadinessDTO	"Todas las dependencias están disponibles"
+//	@Failure		503	{object}	healthDTO.ReadinessDTO	"Alguna dependencia no está disponible"
+//	@Router			/ready [get]
+func (c *HealthController) ready(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Debug("GET /ready called")
+
+	readiness := c.healthService.Readiness(ctx.UserContext())
+	if readiness.Status != healthDTO.HEALTH_STATUS_UP {
+		logger.Warning("Service is not ready, some dependencies are unavailable")
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(readiness)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(readiness)
+}
+
+//	@Summary		Obtener la versión del servicio
+//	@Description	Devuelve la versión de la aplicación, el commit y la fecha de compilación del binario en ejecución
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	healthDTO.VersionDTO	"Versión del servicio"
+//	@Router			/version [get]
+func (c *HealthController) version(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(c.healthService.Version())
+}
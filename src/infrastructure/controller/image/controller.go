@@ -1,13 +1,14 @@
 package imageController
 
 import (
-	"fmt"
 	"go-gallery/src/commons/exception"
 	validators "go-gallery/src/commons/utils/validations"
+	auditService "go-gallery/src/service/audit"
 	imageService "go-gallery/src/service/image"
 	userService "go-gallery/src/service/user"
 	"strconv"
 
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
 	imageDTO "go-gallery/src/infrastructure/dto/image"
 	log "go-gallery/src/infrastructure/logger"
 
@@ -28,13 +29,15 @@ var logger log.Logger
 type ImageController struct {
 	imageService *imageService.ImageService
 	userService  *userService.UserService
+	auditService *auditService.AuditService
 }
 
-func NewImageController(imageService *imageService.ImageService, userService *userService.UserService) *ImageController {
+func NewImageController(imageService *imageService.ImageService, userService *userService.UserService, auditService *auditService.AuditService) *ImageController {
 	logger = log.Instance()
 	return &ImageController{
 		imageService: imageService,
 		userService:  userService,
+		auditService: auditService,
 	}
 }
 
@@ -63,12 +66,14 @@ func (c *ImageController) SetUpRoutes(router fiber.Router) {
 //	@Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
 //	@Router			/image/getImage/{id} [get]
 func (c *ImageController) getImage(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	id := ctx.Params("id")
 	if id == "" {
 		logger.Error(IMAGE_ID_REQUIRED_MSG)
 		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, IMAGE_ID_REQUIRED_MSG))
 	}
-	logger.Info("GET /getImage called with id: " + id)
+	logger.Info("GET /getImage called", log.F("id", id))
 
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
 	if !ok {
@@ -81,13 +86,13 @@ func (c *ImageController) getImage(ctx *fiber.Ctx) error {
 		Owner: claims.Username,
 	}
 
-	image, err := c.imageService.Find(dtoFindImage)
+	image, err := c.imageService.Find(ctx.UserContext(), dtoFindImage)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error finding image with id %s : %s", id, err.Message))
+		logger.Error("Error finding image", log.F("id", id), log.F("error", err.Message))
 		return ctx.Status(err.Status).JSON(err)
 	}
 
-	logger.Info("Image successfully retrieved with id: " + id)
+	logger.Info("Image successfully retrieved", log.F("id", id))
 	return ctx.Status(fiber.StatusOK).JSON(image)
 }
 
@@ -107,6 +112,8 @@ func (c *ImageController) getImage(ctx *fiber.Ctx) error {
 //	@Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
 //	@Router			/image/uploadImage [post]
 func (c *ImageController) uploadImage(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("POST /uploadImage called")
 
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
@@ -117,24 +124,25 @@ func (c *ImageController) uploadImage(ctx *fiber.Ctx) error {
 
 	fileInput, errForm := ctx.FormFile("file")
 	if errForm != nil {
-		logger.Error("Failed to get file from form data caused by:" + errForm.Error())
+		logger.Error("Failed to get file from form data", log.F("error", errForm.Error()))
 		return ctx.Status(fiber.StatusNotFound).JSON(exception.NewApiException(fiber.StatusNotFound, "Error getting image from form"))
 	}
 
-	logger.Info("Processing image upload for user: " + claims.Username)
-	dtoInsertImage, errFile := imageHandler.ProcessImageFile(fileInput, claims.Username)
+	logger.Info("Processing image upload", log.F("username", claims.Username))
+	dtoInsertImage, errFile := imageHandler.ProcessImageFile(ctx.UserContext(), fileInput, claims.Username)
 	if errFile != nil {
-		logger.Error("Error processing image file: " + errFile.Message)
+		logger.Error("Error processing image file", log.F("error", errFile.Message))
 		return ctx.Status(errFile.Status).JSON(errFile)
 	}
 
-	dto, errInsert := c.imageService.Insert(dtoInsertImage)
+	dto, errInsert := c.imageService.Insert(ctx.UserContext(), dtoInsertImage)
 	if errInsert != nil {
-		logger.Error("Error inserting image: " + errInsert.Message)
+		logger.Error("Error inserting image", log.F("error", errInsert.Message))
 		return ctx.Status(errInsert.Status).JSON(errInsert)
 	}
 
-	logger.Info("Image successfully uploaded by user: " + claims.Username)
+	c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_IMAGE_UPLOAD, dto.Id, true)
+	logger.Info("Image successfully uploaded", log.F("username", claims.Username))
 	return ctx.Status(fiber.StatusOK).JSON(dto)
 }
 
@@ -152,6 +160,8 @@ func (c *ImageController) uploadImage(ctx *fiber.Ctx) error {
 //	@Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
 //	@Router			/image/deleteImage [delete]
 func (c *ImageController) deleteImage(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
 	if !ok {
 		logger.Error(INVALID_AUTHENTIFICATION_MSG)
@@ -179,15 +189,17 @@ func (c *ImageController) deleteImage(ctx *fiber.Ctx) error {
 
 	request.Owner = claims.Username
 
-	logger.Info("DELETE /deleteImage called with id: " + request.Id)
+	logger.Info("DELETE /deleteImage called", log.F("id", request.Id))
 
-	response, errDelete := c.imageService.Delete(request)
+	response, errDelete := c.imageService.Delete(ctx.UserContext(), request)
 	if errDelete != nil {
-		logger.Error("Error deleting image with id " + request.Id + ": " + errDelete.Message)
+		logger.Error("Error deleting image", log.F("id", request.Id), log.F("error", errDelete.Message))
+		c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_IMAGE_DELETE, request.Id, false)
 		return ctx.Status(errDelete.Status).JSON(err)
 	}
 
-	logger.Info(fmt.Sprintf("Image and thumbnail successfully deleted with image id: %s and thumbnail id: %s", request.Id, request.ThumbnailID))
+	c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_IMAGE_DELETE, request.Id, true)
+	logger.Info("Image and thumbnail successfully deleted", log.F("id", request.Id), log.F("thumbnail_id", request.ThumbnailID))
 	return ctx.Status(fiber.StatusOK).JSON(response)
 }
 
@@ -206,6 +218,8 @@ func (c *ImageController) deleteImage(ctx *fiber.Ctx) error {
 //	@Failure		500	{object}	exception.ApiException			"Ha ocurrido un error inesperado"
 //	@Router			/image/updateImage [put]
 func (c *ImageController) updateImage(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("UPDATE /updateImage called")
 
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
@@ -239,13 +253,15 @@ func (c *ImageController) updateImage(ctx *fiber.Ctx) error {
 
 	request.Owner = claims.Username
 
-	result, errUpdate := c.imageService.Update(request)
+	result, errUpdate := c.imageService.Update(ctx.UserContext(), request)
 	if errUpdate != nil {
-		logger.Error("Error updating image with id " + request.Id + ": " + errUpdate.Message)
+		logger.Error("Error updating image", log.F("id", request.Id), log.F("error", errUpdate.Message))
+		c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_IMAGE_UPDATE, request.Id, false)
 		return ctx.Status(errUpdate.Status).JSON(errUpdate)
 	}
 
-	logger.Info(fmt.Sprintf("Image and thumbnail successfully updated with image id: %s and thumbnail id: %s", request.Id, request.ThumbnailID))
+	c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_IMAGE_UPDATE, request.Id, true)
+	logger.Info("Image and thumbnail successfully updated", log.F("id", request.Id), log.F("thumbnail_id", request.ThumbnailID))
 	return ctx.Status(fiber.StatusOK).JSON(result)
 }
 
@@ -264,10 +280,12 @@ func (c *ImageController) updateImage(ctx *fiber.Ctx) error {
 //	@Failure		500	{object}	exception.ApiException						"Error inesperado"
 //	@Router			/image/getThumbnailImages [get]
 func (c *ImageController) getThumbnailImages(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	lastID := ctx.Query("lastID")
 	pageSizeParam := ctx.Query("pageSize")
 
-	logger.Info("GET /getThumbnailImages called with lastID: " + lastID + ", pageSize: " + pageSizeParam)
+	logger.Info("GET /getThumbnailImages called", log.F("last_id", lastID), log.F("page_size", pageSizeParam))
 
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
 	if !ok {
@@ -283,12 +301,17 @@ func (c *ImageController) getThumbnailImages(ctx *fiber.Ctx) error {
 		}
 	}
 
-	thumbnails, errThumb := c.imageService.FindAllThumbnails(claims.Username, lastID, pageSize)
+	thumbnails, errThumb := c.imageService.FindAllThumbnails(ctx.UserContext(), claims.Username, lastID, pageSize)
 	if errThumb != nil {
-		logger.Error("Error retrieving thumbnails: " + errThumb.Message)
+		logger.Error("Error retrieving thumbnails", log.F("error", errThumb.Message))
 		return ctx.Status(errThumb.Status).JSON(errThumb)
 	}
 
-	logger.Info("Thumbnails successfully retrieved for user: " + claims.Username)
+	logger.Info("Thumbnails successfully retrieved", log.F("username", claims.Username))
 	return ctx.Status(fiber.StatusOK).JSON(thumbnails)
 }
+
+// Records an action on an image together with the origin of the request
+func (c *ImageController) audit(ctx *fiber.Ctx, actor, action, target string, success bool) {
+	c.auditService.Record(ctx.UserContext(), actor, action, target, ctx.IP(), ctx.Get(fiber.HeaderUserAgent), success)
+}
@@ -1,39 +1,40 @@
 package imageHandler
 
 import (
+	"context"
 	"go-gallery/src/commons/constants"
 	"go-gallery/src/commons/exception"
 	utilsImage "go-gallery/src/commons/utils/image"
 	imageDTO "go-gallery/src/infrastructure/dto/image"
-	"go-gallery/src/infrastructure/logger"
+	log "go-gallery/src/infrastructure/logger"
 	"io"
 	"mime/multipart"
 	"path/filepath"
 	"slices"
-	"strconv"
 	"strings"
 )
 
-func ProcessImageFile(fileInput *multipart.FileHeader, owner string) (*imageDTO.ImageUploadRequestDTO, *exception.ApiException) {
-	logger.Instance().Info("Starting image file processing: filename=" + fileInput.Filename + ", owner=" + owner)
+func ProcessImageFile(ctx context.Context, fileInput *multipart.FileHeader, owner string) (*imageDTO.ImageUploadRequestDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	logger.Debug("Starting image file processing", log.F("filename", fileInput.Filename), log.F("owner", owner))
 
 	fileExtension := filepath.Ext(fileInput.Filename)
 	fileName := strings.TrimSuffix(fileInput.Filename, fileExtension)
-	logger.Instance().Info("Extracted filename and extension: name=" + fileName + ", extension=" + fileExtension)
+	logger.Debug("Extracted filename and extension", log.F("name", fileName), log.F("extension", fileExtension))
 
-	if !isValidExtension(fileExtension) {
-		logger.Instance().Warning("Invalid file extension detected: extension=" + fileExtension)
+	if !isValidExtension(logger, fileExtension) {
+		logger.Warning("Invalid file extension detected", log.F("extension", fileExtension))
 		return nil, exception.NewApiException(400, "Unsupported file format. Only jpg, jpeg, png, and webp images are accepted.")
 	}
 
-	rawData, err := encodeToRawBytes(fileInput)
+	rawData, err := encodeToRawBytes(logger, fileInput)
 	if err != nil {
-		logger.Instance().Error("Failed to convert file to bytes: error=" + err.Message)
+		logger.Error("Failed to convert file to bytes", log.F("error", err.Message))
 		return nil, err
 	}
 
 	fileSizeHumanReadable := utilsImage.HumanizeBytes(uint64(fileInput.Size))
-	logger.Instance().Info("File processed successfully: name=" + fileName + ", extension=" + fileExtension + ", size=" + fileSizeHumanReadable)
+	logger.Debug("File processed successfully", log.F("name", fileName), log.F("extension", fileExtension), log.F("size", fileSizeHumanReadable))
 
 	return &imageDTO.ImageUploadRequestDTO{
 		Name:           fileName,
@@ -44,33 +45,33 @@ func ProcessImageFile(fileInput *multipart.FileHeader, owner string) (*imageDTO.
 	}, nil
 }
 
-func isValidExtension(extension string) bool {
+func isValidExtension(logger log.Logger, extension string) bool {
 	validExtensions := []string{constants.JPG_EXTENSION, constants.JPEG_EXTENSION, constants.PNG_EXTENSION, constants.WEBP_EXTENSION}
 	isValid := slices.Contains(validExtensions, extension)
-	logger.Instance().Info("Checking file extension: extension=" + extension + ", isValid=" + strconv.FormatBool(isValid))
+	logger.Debug("Checking file extension", log.F("extension", extension), log.F("valid", isValid))
 	return isValid
 }
 
-func encodeToRawBytes(fileInput *multipart.FileHeader) ([]byte, *exception.ApiException) {
-	logger.Instance().Info("Opening file for byte conversion: filename=" + fileInput.Filename)
+func encodeToRawBytes(logger log.Logger, fileInput *multipart.FileHeader) ([]byte, *exception.ApiException) {
+	logger.Debug("Opening file for byte conversion", log.F("filename", fileInput.Filename))
 
 	fileBytes, err := fileInput.Open()
 	if err != nil {
-		logger.Instance().Error("Failed to open file: filename=" + fileInput.Filename + ", error=" + err.Error())
+		logger.Error("Failed to open file", log.F("filename", fileInput.Filename), log.F("error", err.Error()))
 		return nil, exception.NewApiException(500, "Error opening the image file")
 	}
 	defer fileBytes.Close()
 
-	return readAllFile(fileBytes)
+	return readAllFile(logger, fileBytes)
 }
 
-func readAllFile(file multipart.File) ([]byte, *exception.ApiException) {
-	logger.Instance().Info("Reading entire file into memory")
+func readAllFile(logger log.Logger, file multipart.File) ([]byte, *exception.ApiException) {
+	logger.Debug("Reading entire file into memory")
 	fileData, err := io.ReadAll(file)
 	if err != nil {
-		logger.Instance().Error("Failed to read file content")
+		logger.Error("Failed to read file content")
 		return nil, exception.NewApiException(500, "Error reading the image file")
 	}
-	logger.Instance().Info("File read successfully: sizeBytes=" + strconv.Itoa(len(fileData)))
+	logger.Debug("File read successfully", log.F("size_bytes", len(fileData)))
 	return fileData, nil
 }
@@ -2,6 +2,7 @@ package imageHandler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"go-gallery/src/commons/constants"
@@ -72,7 +73,7 @@ func TestProcessImageFileFailToOpen(t *testing.T) {
 		Filename: "nonexistent.jpg",
 	}
 
-	result, apiErr := ProcessImageFile(fileHeader, "testOwner")
+	result, apiErr := ProcessImageFile(context.Background(), fileHeader, "testOwner")
 
 	if result != nil {
 		t.Errorf("Expected result to be nil, but got: %+v", result)
@@ -87,7 +88,7 @@ func TestEncodeToRawBytes(t *testing.T) {
 	fileHeader := &multipart.FileHeader{
 		Filename: "nonexistent.jpg",
 	}
-	_, apiErr := encodeToRawBytes(fileHeader)
+	_, apiErr := encodeToRawBytes(logger.Instance(), fileHeader)
 
 	if apiErr == nil || apiErr.Message != "Error opening the image file" {
 		t.Errorf("Expected error opening the file, but got: %v", apiErr)
@@ -98,7 +99,7 @@ func TestReadAllFile(t *testing.T) {
 	beforeAll()
 	reader := &failingFile{}
 
-	_, apiErr := readAllFile(reader)
+	_, apiErr := readAllFile(logger.Instance(), reader)
 	if apiErr == nil || apiErr.Message != "Error reading the image file" {
 		t.Errorf("Expected error reading the file, but got: %v", apiErr)
 	}
@@ -117,7 +118,7 @@ func loadFiberApp() *fiber.App {
 		}
 
 		// Process the image with the function we are testing
-		result, apiErr := ProcessImageFile(file, "testOwner")
+		result, apiErr := ProcessImageFile(c.UserContext(), file, "testOwner")
 		if apiErr != nil {
 			return c.Status(apiErr.Status).JSON(apiErr)
 		}
@@ -0,0 +1,55 @@
+package metricsController
+
+import (
+	"crypto/subtle"
+	"go-gallery/src/commons/exception"
+	log "go-gallery/src/infrastructure/logger"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+const INVALID_METRICS_TOKEN_MSG string = "Invalid or missing metrics token"
+
+var logger log.Logger
+
+type MetricsController struct {
+	handler fiber.Handler
+	token   string
+}
+
+// NewMetricsController publica el registro de métricas, un token no vacío se exige como token Bearer
+func NewMetricsController(handler http.Handler, token string) *MetricsController {
+	logger = log.Instance()
+	return &MetricsController{handler: adaptor.HTTPHandler(handler), token: token}
+}
+
+func (c *MetricsController) SetUpRoutes(router fiber.Router) {
+	router.Get("/", c.authorize, c.metrics)
+}
+
+func (c *MetricsController) authorize(ctx *fiber.Ctx) error {
+	if c.token == "" {
+		return ctx.Next()
+	}
+
+	token, found := strings.CutPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+		log.FromContext(ctx.UserContext()).Warning("Metrics requested without a valid token", log.F("ip", ctx.IP()))
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, INVALID_METRICS_TOKEN_MSG))
+	}
+	return ctx.Next()
+}
+
+// @Summary		Obtener las métricas del servicio
+// @Description	Devuelve en el formato de texto de Prometheus el número y la duración de las peticiones HTTP por ruta y código de estado, la duración y los errores de las operaciones de los repositorios de usuarios, imágenes y miniaturas, la duración y los bytes procesados al generar miniaturas, el resultado del envío de correos, el estado de la cola del logger y las métricas del runtime de Go y del proceso. Si se ha configurado METRICS_TOKEN hay que enviarlo como token Bearer.
+// @Tags			metrics
+// @Produce		plain
+// @Success		200	{string}	string					"Métricas en formato de texto de Prometheus"
+// @Failure		401	{object}	exception.ApiException	"Falta el token de las métricas o no es válido"
+// @Router			/metrics [get]
+func (c *MetricsController) metrics(ctx *fiber.Ctx) error {
+	return c.handler(ctx)
+}
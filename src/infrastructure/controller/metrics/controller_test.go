@@ -0,0 +1,49 @@
+package metricsController
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "go-gallery/src/infrastructure/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetricsApp(token string) *fiber.App {
+	log.Init(log.NewConsoleLogger())
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("go_gallery_test 1\n"))
+	})
+	app := fiber.New()
+	NewMetricsController(handler, token).SetUpRoutes(app.Group("/metrics"))
+	return app
+}
+
+func scrape(t *testing.T, app *fiber.App, authorization string) int {
+	request := httptest.NewRequest("GET", "/metrics", nil)
+	if authorization != "" {
+		request.Header.Set(fiber.HeaderAuthorization, authorization)
+	}
+	response, err := app.Test(request)
+	require.NoError(t, err)
+	return response.StatusCode
+}
+
+func TestMetricsRequireTheConfiguredToken(t *testing.T) {
+	app := newMetricsApp("scrape-token")
+
+	assert.Equal(t, fiber.StatusUnauthorized, scrape(t, app, ""))
+	assert.Equal(t, fiber.StatusUnauthorized, scrape(t, app, "Bearer wrong-token"))
+	assert.Equal(t, fiber.StatusUnauthorized, scrape(t, app, "scrape-token"))
+	assert.Equal(t, fiber.StatusOK, scrape(t, app, "Bearer scrape-token"))
+}
+
+func TestMetricsWithoutTokenAreOpen(t *testing.T) {
+	app := newMetricsApp("")
+
+	assert.Equal(t, fiber.StatusOK, scrape(t, app, ""))
+}
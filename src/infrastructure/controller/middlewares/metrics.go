@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"go-gallery/src/infrastructure/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// Metrics cuenta las peticiones y mide su duración por método, ruta y código de estado
+func Metrics() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+		err := ctx.Next()
+
+		// The route pattern is used instead of the path so identifiers in the URL do not create new series.
+		// The method is a zero-copy string of the request buffer, which is reused once the request ends, and the series keep their label values
+		method := utils.CopyString(ctx.Method())
+		route := ctx.Route().Path
+		status := strconv.Itoa(responseStatus(ctx, err))
+
+		metrics.HTTPRequests.WithLabelValues(method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
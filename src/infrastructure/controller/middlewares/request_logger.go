@@ -0,0 +1,95 @@
+package middlewares
+
+import (
+	utilsToken "go-gallery/src/commons/utils/token"
+	"time"
+
+	log "go-gallery/src/infrastructure/logger"
+	"go-gallery/src/infrastructure/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const (
+	REQUEST_ID_HEADER     string = "X-Request-ID"
+	REQUEST_ID_LOCAL      string = "request_id"
+	REQUEST_ID_BYTES      int    = 16
+	MAX_REQUEST_ID_LENGTH int    = 128
+)
+
+// RequestLogger asigna un identificador a cada petición y guarda en su contexto un logger con el
+// identificador, el método y la ruta, que se devuelve en la cabecera X-Request-ID
+func RequestLogger() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// A valid identifier sent by a proxy is kept so the logs of both can be correlated
+		// The values of the request are zero-copy strings of a buffer that Fiber reuses once the request ends,
+		// they are copied because the logger keeps them
+		requestID := utils.CopyString(ctx.Get(REQUEST_ID_HEADER))
+		if !isValidRequestID(requestID) {
+			generated, err := utilsToken.GenerateToken(REQUEST_ID_BYTES)
+			if err != nil {
+				log.Instance().Error("Error generating request id: " + err.Error())
+			}
+			requestID = generated
+		}
+
+		ctx.Set(REQUEST_ID_HEADER, requestID)
+		ctx.Locals(REQUEST_ID_LOCAL, requestID)
+
+		fields := []log.Field{
+			log.F("request_id", requestID),
+			log.F("method", utils.CopyString(ctx.Method())),
+			log.F("route", utils.CopyString(ctx.Path())),
+		}
+		// The trace opened by the tracing middleware links the logs with the spans of the request
+		if traceID := tracing.TraceID(ctx.UserContext()); traceID != "" {
+			fields = append(fields, log.F("trace_id", traceID))
+		}
+		requestLogger := log.Instance().With(fields...)
+		ctx.SetUserContext(log.WithContext(ctx.UserContext(), requestLogger))
+
+		start := time.Now()
+		err := ctx.Next()
+
+		// The logger is read again because later middlewares may have added the user
+		Logger(ctx).Info("Request completed",
+			log.F("status", responseStatus(ctx, err)),
+			log.F("duration_ms", time.Since(start).Milliseconds()),
+		)
+		return err
+	}
+}
+
+// Logger devuelve el logger de la petición con sus campos de correlación
+func Logger(ctx *fiber.Ctx) log.Logger {
+	return log.FromContext(ctx.UserContext())
+}
+
+// AddLogFields añade campos al logger del resto de la petición
+func AddLogFields(ctx *fiber.Ctx, fields ...log.Field) {
+	ctx.SetUserContext(log.WithContext(ctx.UserContext(), Logger(ctx).With(fields...)))
+}
+
+// Errors returned by the handlers are turned into a response after the middlewares
+func responseStatus(ctx *fiber.Ctx, err error) int {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return fiberErr.Code
+	} else if err != nil {
+		return fiber.StatusInternalServerError
+	}
+	return ctx.Response().StatusCode()
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > MAX_REQUEST_ID_LENGTH {
+		return false
+	}
+	for _, char := range requestID {
+		isAlphanumeric := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+		if !isAlphanumeric && char != '-' && char != '_' && char != '.' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidRequestID(t *testing.T) {
+	assert.True(t, isValidRequestID("9f2c1b7e-4a3d-4e8f-b1c2-3d4e5f6a7b8c"))
+	assert.True(t, isValidRequestID("req_01.A"))
+	assert.True(t, isValidRequestID(strings.Repeat("a", MAX_REQUEST_ID_LENGTH)))
+
+	assert.False(t, isValidRequestID(""))
+	assert.False(t, isValidRequestID(strings.Repeat("a", MAX_REQUEST_ID_LENGTH+1)))
+	assert.False(t, isValidRequestID("id with spaces"))
+	assert.False(t, isValidRequestID("id\nforged=line"))
+	assert.False(t, isValidRequestID("\"quoted\""))
+}
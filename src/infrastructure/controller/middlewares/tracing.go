@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"go-gallery/src/infrastructure/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing abre un span por petición que continúa la traza recibida en la cabecera traceparent
+func Tracing() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		parent := otel.GetTextMapPropagator().Extract(ctx.UserContext(), requestHeaderCarrier{ctx: ctx})
+
+		// The span outlives the request, so the zero-copy strings of the request buffer are copied
+		method := utils.CopyString(ctx.Method())
+		path := utils.CopyString(ctx.Path())
+
+		spanCtx, span := tracing.Tracer().Start(parent, method+" "+path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(path),
+			),
+		)
+		defer span.End()
+		ctx.SetUserContext(spanCtx)
+
+		err := ctx.Next()
+
+		// The route pattern is only known once the router has matched the request
+		route := ctx.Route().Path
+		status := responseStatus(ctx, err)
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+		return err
+	}
+}
+
+// requestHeaderCarrier adapta las cabeceras de la petición de Fiber al propagador de OpenTelemetry
+type requestHeaderCarrier struct {
+	ctx *fiber.Ctx
+}
+
+func (c requestHeaderCarrier) Get(key string) string {
+	return c.ctx.Get(key)
+}
+
+func (c requestHeaderCarrier) Set(key, value string) {
+	c.ctx.Request().Header.Set(key, value)
+}
+
+func (c requestHeaderCarrier) Keys() []string {
+	keys := make([]string, 0)
+	c.ctx.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
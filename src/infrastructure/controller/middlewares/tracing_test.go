@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"go-gallery/src/infrastructure/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracingApp(t *testing.T) (*fiber.App, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(tracing.TracingSettings{SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	app := fiber.New()
+	app.Use(Tracing())
+	app.Get("/api/image/:id", func(ctx *fiber.Ctx) error {
+		_, span := tracing.Start(ctx.UserContext(), "ImageService.Find")
+		span.End()
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/api/fail", func(ctx *fiber.Ctx) error {
+		return fiber.ErrServiceUnavailable
+	})
+	return app, exporter
+}
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	app, exporter := newTracingApp(t)
+
+	request := httptest.NewRequest("GET", "/api/image/65f1c2", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	response, err := app.Test(request)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, response.StatusCode)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+
+	assert.Equal(t, "GET /api/image/:id", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+
+	assert.Equal(t, "ImageService.Find", child.Name)
+	assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+}
+
+func TestTracingStartsNewTraceAndMarksServerErrors(t *testing.T) {
+	app, exporter := newTracingApp(t)
+
+	_, err := app.Test(httptest.NewRequest("GET", "/api/fail", nil))
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /api/fail", spans[0].Name)
+	assert.False(t, spans[0].Parent.IsValid())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
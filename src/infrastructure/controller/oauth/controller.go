@@ -0,0 +1,371 @@
+package oauthController
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
+	"go-gallery/src/commons/exception"
+	utilsToken "go-gallery/src/commons/utils/token"
+	"go-gallery/src/infrastructure/auth/oauth"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	userMiddleware "go-gallery/src/infrastructure/controller/user/middlewares"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	userService "go-gallery/src/service/user"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var logger log.Logger
+
+const (
+	STATE_COOKIE_NAME        string        = "oauth_state"
+	STATE_COOKIE_PATH        string        = "/api/auth/oauth"
+	STATE_EXPIRATION         time.Duration = 10 * time.Minute
+	STATE_BYTES              int           = 16
+	RANDOM_PASSWORD_BYTES    int           = 24
+	USERNAME_SUFFIX_BYTES    int           = 3
+	MAX_USERNAME_ATTEMPTS    int           = 5
+	PROVIDER_NOT_FOUND_MSG   string        = "OAuth provider not found"
+	INVALID_STATE_MSG        string        = "Invalid or expired login request"
+	UNVERIFIED_IDENTITY_MSG  string        = "The provider did not return a verified email address"
+	UNVERIFIED_ACCOUNT_MSG   string        = "An unverified account already uses this email, verify it or recover its password before logging in with an external provider"
+	PROVIDER_ERROR_MSG       string        = "Error authenticating with the provider"
+	DISABLED_ACCOUNT_MSG     string        = "This account has been disabled"
+	SCHEDULED_DELETION_MSG   string        = "This account is scheduled for deletion"
+	LOGIN_SUCCESSFUL_MESSAGE string        = "Login successful"
+)
+
+var invalidUsernameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+type OAuthController struct {
+	userService   *userService.UserService
+	jwtMiddleware *userMiddleware.JWTMiddleware
+	providers     map[string]oauth.Provider
+	stateManager  *oauth.StateManager
+
+	oauthConfiguration configuration.OAuthConfiguration
+}
+
+func NewOAuthController(userService *userService.UserService, jwtMiddleware *userMiddleware.JWTMiddleware, providers map[string]oauth.Provider,
+	stateManager *oauth.StateManager, oauthConfiguration configuration.OAuthConfiguration) *OAuthController {
+	logger = log.Instance()
+	return &OAuthController{
+		userService:        userService,
+		jwtMiddleware:      jwtMiddleware,
+		providers:          providers,
+		stateManager:       stateManager,
+		oauthConfiguration: oauthConfiguration,
+	}
+}
+
+func (c *OAuthController) SetUpRoutes(router fiber.Router) {
+	router.Get("/providers", c.listProviders)
+	router.Get("/:provider/login", c.login)
+	router.Get("/:provider/callback", c.callback)
+}
+
+// @Summary		Lista los proveedores externos
+// @Description	Devuelve los proveedores externos habilitados para iniciar sesión
+// @Tags			oauth
+// @Produce		json
+// @Success		200	{array}	userDTO.OAuthProviderDTO	"Proveedores habilitados"
+// @Router			/auth/oauth/providers [get]
+func (c *OAuthController) listProviders(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("GET /oauth/providers called")
+
+	providers := make([]userDTO.OAuthProviderDTO, 0, len(c.providers))
+	for name := range c.providers {
+		providers = append(providers, userDTO.OAuthProviderDTO{
+			Name:     name,
+			LoginURL: fmt.Sprintf("%s/%s/login", STATE_COOKIE_PATH, name),
+		})
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name < providers[j].Name })
+
+	return ctx.Status(fiber.StatusOK).JSON(providers)
+}
+
+// @Summary		Inicia sesión con un proveedor externo
+// @Description	Redirige al proveedor externo para autenticar al usuario mediante el flujo authorization code con PKCE
+// @Tags			oauth
+// @Param			provider	path	string	true	"Nombre del proveedor"
+// @Success		302			"Redirección al proveedor"
+// @Failure		404			{object}	exception.ApiException	"Proveedor no encontrado"
+// @Failure		500			{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/auth/oauth/{provider}/login [get]
+func (c *OAuthController) login(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	providerName := ctx.Params("provider")
+	logger.Info("GET /oauth/:provider/login called", log.F("provider", providerName))
+
+	provider, found := c.providers[providerName]
+	if !found {
+		return ctx.Status(fiber.StatusNotFound).JSON(exception.NewApiException(fiber.StatusNotFound, PROVIDER_NOT_FOUND_MSG))
+	}
+
+	state, err := c.newState(providerName)
+	if err != nil {
+		logger.Error("Error generating OAuth state", log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, "Error starting the login"))
+	}
+
+	authURL, err := provider.AuthCodeURL(state.State, oauth.CodeChallenge(state.CodeVerifier), state.Nonce)
+	if err != nil {
+		logger.Error("Error building the authorization URL", log.F("provider", providerName), log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, PROVIDER_ERROR_MSG))
+	}
+
+	cookieValue, err := c.stateManager.Encode(state)
+	if err != nil {
+		logger.Error("Error encoding OAuth state", log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, "Error starting the login"))
+	}
+
+	c.setStateCookie(ctx, cookieValue, time.Now().Add(STATE_EXPIRATION))
+
+	return ctx.Redirect(authURL, fiber.StatusFound)
+}
+
+// @Summary		Callback del proveedor externo
+// @Description	Completa el inicio de sesión con el proveedor externo, vinculando la identidad a un usuario verificado con el mismo correo o creando uno nuevo, y genera la cookie de sesión
+// @Tags			oauth
+// @Produce		json
+// @Param			provider	path		string						true	"Nombre del proveedor"
+// @Param			code		query		string						true	"Código de autorización"
+// @Param			state		query		string						true	"Estado de la petición"
+// @Success		200			{object}	userDTO.LoginResponseDTO	"Se ha iniciado sesion correctamente"
+// @Success		302			"Redirección a la aplicación tras iniciar sesión"
+// @Header			200			{string}	Set-Cookie					"Authorization=auth_token; HttpOnly; Secure"
+// @Failure		400			{object}	exception.ApiException		"Petición de inicio de sesión no válida o caducada"
+// @Failure		401			{object}	exception.ApiException		"Error al autenticar con el proveedor"
+// @Failure		403			{object}	exception.ApiException		"El proveedor no ha devuelto un correo verificado o la cuenta está deshabilitada"
+// @Failure		404			{object}	exception.ApiException		"Proveedor no encontrado"
+// @Failure		409			{object}	exception.ApiException		"Ya existe una cuenta sin verificar con el mismo correo"
+// @Failure		500			{object}	exception.ApiException		"Ha ocurrido un error inesperado"
+// @Router			/auth/oauth/{provider}/callback [get]
+func (c *OAuthController) callback(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	providerName := ctx.Params("provider")
+	logger.Info("GET /oauth/:provider/callback called", log.F("provider", providerName))
+
+	provider, found := c.providers[providerName]
+	if !found {
+		return ctx.Status(fiber.StatusNotFound).JSON(exception.NewApiException(fiber.StatusNotFound, PROVIDER_NOT_FOUND_MSG))
+	}
+
+	// The state is single use, the cookie is removed whatever the result
+	cookieValue := ctx.Cookies(STATE_COOKIE_NAME)
+	c.setStateCookie(ctx, "", time.Unix(0, 0))
+
+	if providerError := ctx.Query("error"); providerError != "" {
+		logger.Warning("Login rejected by the provider", log.F("provider", providerName), log.F("error", providerError))
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, PROVIDER_ERROR_MSG))
+	}
+
+	state, err := c.stateManager.Decode(cookieValue)
+	if err != nil || state.Provider != providerName ||
+		subtle.ConstantTimeCompare([]byte(state.State), []byte(ctx.Query("state"))) != 1 {
+		logger.Warning("Invalid OAuth state in callback", log.F("provider", providerName))
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_STATE_MSG))
+	}
+
+	code := ctx.Query("code")
+	if code == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_STATE_MSG))
+	}
+
+	identity, err := provider.Exchange(ctx.Context(), code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		logger.Error("Error exchanging the authorization code", log.F("provider", providerName), log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, PROVIDER_ERROR_MSG))
+	}
+
+	user, errUser := c.resolveUser(identity)
+	if errUser != nil {
+		return ctx.Status(errUser.Status).JSON(errUser)
+	}
+
+	if user.Disabled {
+		logger.Warning("Disabled user tried to log in", log.F("username", user.Username), log.F("provider", providerName))
+		return ctx.Status(fiber.StatusForbidden).JSON(exception.NewApiException(fiber.StatusForbidden, DISABLED_ACCOUNT_MSG))
+	}
+
+	if user.DeletionScheduledAt != nil {
+		logger.Warning("User scheduled for deletion tried to log in", log.F("username", user.Username), log.F("provider", providerName))
+		return ctx.Status(fiber.StatusForbidden).JSON(exception.NewApiException(fiber.StatusForbidden, SCHEDULED_DELETION_MSG))
+	}
+
+	errJWT := c.jwtMiddleware.CreateJWTToken(ctx, user.Username, user.Email, user.Role, user.SessionVersion)
+	if errJWT != nil {
+		logger.Error("Error creating JWT token", log.F("error", errJWT.Message))
+		return ctx.Status(errJWT.Status).JSON(errJWT)
+	}
+
+	logger.Info("User logged in successfully", log.F("username", user.Username), log.F("provider", providerName))
+
+	if c.oauthConfiguration.SuccessRedirectURL != "" {
+		return ctx.Redirect(c.oauthConfiguration.SuccessRedirectURL, fiber.StatusFound)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(userDTO.LoginResponseDTO{
+		Message:   LOGIN_SUCCESSFUL_MESSAGE,
+		Username:  user.Username,
+		Email:     user.Email,
+		Firstname: user.Firstname,
+		Lastname:  user.Lastname,
+	})
+}
+
+// Finds the user linked to the external identity, links it to the verified account with the same email
+// or creates a new verified account
+func (c *OAuthController) resolveUser(identity *oauth.ExternalIdentity) (*userDTO.UserDTO, *exception.ApiException) {
+	user, errFind := c.userService.FindByIdentity(identity.Provider, identity.Subject)
+	if errFind == nil {
+		return user, nil
+	}
+	if errFind.Status != fiber.StatusNotFound {
+		return nil, errFind
+	}
+
+	// Linking by email is only safe when the provider has verified the address
+	if identity.Email == "" || !identity.EmailVerified {
+		logger.Warning("The identity has no verified email", log.F("provider", identity.Provider))
+		return nil, exception.NewApiException(fiber.StatusForbidden, UNVERIFIED_IDENTITY_MSG)
+	}
+
+	user, errFind = c.userService.FindByEmail(identity.Email)
+	if errFind != nil && errFind.Status != fiber.StatusNotFound {
+		return nil, errFind
+	}
+
+	if user == nil {
+		user, errFind = c.createUser(identity)
+		if errFind != nil {
+			return nil, errFind
+		}
+	} else if !user.Verified {
+		// Anyone can register an unverified account with someone else's email and keep its password,
+		// so the identity is only linked once the owner of the email has proved it
+		logger.Warning("Refused to link the identity to an unverified user", log.F("provider", identity.Provider), log.F("username", user.Username))
+		return nil, exception.NewApiException(fiber.StatusConflict, UNVERIFIED_ACCOUNT_MSG)
+	}
+
+	_, errIdentity := c.userService.InsertIdentity(&userDTO.UserIdentityDTO{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Username: user.Username,
+	})
+	if errIdentity != nil {
+		return nil, errIdentity
+	}
+
+	return user, nil
+}
+
+func (c *OAuthController) createUser(identity *oauth.ExternalIdentity) (*userDTO.UserDTO, *exception.ApiException) {
+	// The account can only be accessed through the provider or after recovering the password
+	password, err := utilsToken.GenerateToken(RANDOM_PASSWORD_BYTES)
+	if err != nil {
+		logger.Error("Error generating password", log.F("error", err.Error()))
+		return nil, exception.NewApiException(fiber.StatusInternalServerError, "Error creating user")
+	}
+
+	baseUsername := usernameFromIdentity(identity)
+	firstname := identity.Firstname
+	if firstname == "" {
+		firstname = baseUsername
+	}
+
+	var errInsert *exception.ApiException
+	for attempt := range MAX_USERNAME_ATTEMPTS {
+		username := baseUsername
+		if attempt > 0 {
+			suffix, err := utilsToken.GenerateToken(USERNAME_SUFFIX_BYTES)
+			if err != nil {
+				return nil, exception.NewApiException(fiber.StatusInternalServerError, "Error creating user")
+			}
+			username = baseUsername + "-" + suffix
+		}
+
+		var user *userDTO.UserDTO
+		user, errInsert = c.userService.Insert(&userDTO.UserDTO{
+			Username:  username,
+			Password:  password,
+			Email:     identity.Email,
+			Firstname: firstname,
+			Lastname:  identity.Lastname,
+			Verified:  true,
+		})
+		if errInsert == nil {
+			logger.Info("User created from identity", log.F("username", username), log.F("provider", identity.Provider))
+			return user, nil
+		}
+
+		// Any error other than a taken username is not solved by retrying
+		if errInsert.Status != fiber.StatusBadRequest {
+			break
+		}
+		logger.Warning("Username is not available, retrying with a suffix", log.F("username", username))
+	}
+
+	logger.Error("Error creating user from identity", log.F("provider", identity.Provider), log.F("error", errInsert.Message))
+	return nil, errInsert
+}
+
+func (c *OAuthController) newState(providerName string) (*oauth.State, error) {
+	state, err := utilsToken.GenerateToken(STATE_BYTES)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utilsToken.GenerateToken(STATE_BYTES)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := oauth.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth.State{
+		Provider:     providerName,
+		State:        state,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		Expiration:   oauth.NowFunc().Add(STATE_EXPIRATION).Unix(),
+	}, nil
+}
+
+func (c *OAuthController) setStateCookie(ctx *fiber.Ctx, value string, expires time.Time) {
+	ctx.Cookie(&fiber.Cookie{
+		Name:     STATE_COOKIE_NAME,
+		Value:    value,
+		Path:     STATE_COOKIE_PATH,
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax",
+	})
+}
+
+func usernameFromIdentity(identity *oauth.ExternalIdentity) string {
+	username := identity.Username
+	if username == "" {
+		username, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	username = strings.ToLower(invalidUsernameCharacters.ReplaceAllString(username, ""))
+	if username == "" {
+		username = identity.Provider
+	}
+
+	return username
+}
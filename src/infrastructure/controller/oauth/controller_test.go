@@ -0,0 +1,151 @@
+package oauthController
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gallery/src/commons/configurator/configuration"
+	"go-gallery/src/infrastructure/auth"
+	"go-gallery/src/infrastructure/auth/oauth"
+	userMiddleware "go-gallery/src/infrastructure/controller/user/middlewares"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	"go-gallery/src/infrastructure/repository/user/userTest"
+	userService "go-gallery/src/service/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	TEST_SECRET   = "test-secret"
+	TEST_PROVIDER = "google"
+	TEST_EMAIL    = "victim@example.com"
+)
+
+type stubProvider struct {
+	identity *oauth.ExternalIdentity
+}
+
+func (p *stubProvider) Name() string { return TEST_PROVIDER }
+
+func (p *stubProvider) AuthCodeURL(state, codeChallenge, nonce string) (string, error) {
+	return "https://provider.example.com/authorize", nil
+}
+
+func (p *stubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oauth.ExternalIdentity, error) {
+	return p.identity, nil
+}
+
+func newOAuthApp(t *testing.T, repository *userTest.Repository) *fiber.App {
+	return newOAuthAppWithEmail(t, repository, TEST_EMAIL)
+}
+
+func newOAuthAppWithEmail(t *testing.T, repository *userTest.Repository, email string) *fiber.App {
+	log.Init(log.NewConsoleLogger())
+
+	service := userService.NewUserService(repository, 0)
+	jwtMiddleware := userMiddleware.NewJWTMiddleware(auth.NewJWTTokenManager(TEST_SECRET), service)
+	provider := &stubProvider{identity: &oauth.ExternalIdentity{
+		Provider:      TEST_PROVIDER,
+		Subject:       "subject-1",
+		Email:         email,
+		EmailVerified: true,
+		Firstname:     "Victim",
+	}}
+
+	controller := NewOAuthController(service, jwtMiddleware, map[string]oauth.Provider{TEST_PROVIDER: provider},
+		oauth.NewStateManager(TEST_SECRET), configuration.OAuthConfiguration{})
+
+	app := fiber.New()
+	controller.SetUpRoutes(app.Group(STATE_COOKIE_PATH))
+	return app
+}
+
+func sendCallback(t *testing.T, app *fiber.App) int {
+	state, err := (&OAuthController{}).newState(TEST_PROVIDER)
+	require.NoError(t, err)
+	cookieValue, err := oauth.NewStateManager(TEST_SECRET).Encode(state)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest("GET", STATE_COOKIE_PATH+"/"+TEST_PROVIDER+"/callback?code=code&state="+state.State, nil)
+	request.AddCookie(&http.Cookie{Name: STATE_COOKIE_NAME, Value: cookieValue})
+	response, err := app.Test(request)
+	require.NoError(t, err)
+	return response.StatusCode
+}
+
+func TestCallbackRefusesToLinkUnverifiedAccount(t *testing.T) {
+	repository := userTest.NewRepository(&userDTO.UserDTO{
+		Username: "attacker",
+		Password: "attacker-password",
+		Email:    TEST_EMAIL,
+		Verified: false,
+	})
+	app := newOAuthApp(t, repository)
+
+	assert.Equal(t, fiber.StatusConflict, sendCallback(t, app))
+
+	user, found := repository.User("attacker")
+	require.True(t, found)
+	assert.False(t, user.Verified)
+	assert.Empty(t, repository.Identities())
+}
+
+func TestCallbackLinksVerifiedAccount(t *testing.T) {
+	repository := userTest.NewRepository(&userDTO.UserDTO{
+		Username: "victim",
+		Password: "victim-password",
+		Email:    TEST_EMAIL,
+		Verified: true,
+	})
+	app := newOAuthApp(t, repository)
+
+	assert.Equal(t, fiber.StatusOK, sendCallback(t, app))
+
+	identities := repository.Identities()
+	require.Len(t, identities, 1)
+	assert.Equal(t, "victim", identities[0].Username)
+}
+
+func TestCallbackCreatesVerifiedAccount(t *testing.T) {
+	repository := userTest.NewRepository()
+	app := newOAuthApp(t, repository)
+
+	assert.Equal(t, fiber.StatusOK, sendCallback(t, app))
+
+	user, found := repository.User("victim")
+	require.True(t, found)
+	assert.True(t, user.Verified)
+	assert.Len(t, repository.Identities(), 1)
+}
+
+func TestCallbackLinksAccountWhoseEmailDiffersInCase(t *testing.T) {
+	repository := userTest.NewRepository(&userDTO.UserDTO{
+		Username: "victim",
+		Password: "victim-password",
+		Email:    TEST_EMAIL,
+		Verified: true,
+	})
+	app := newOAuthAppWithEmail(t, repository, "Victim@Example.COM")
+
+	assert.Equal(t, fiber.StatusOK, sendCallback(t, app))
+
+	identities := repository.Identities()
+	require.Len(t, identities, 1)
+	assert.Equal(t, "victim", identities[0].Username)
+}
+
+func TestCallbackCreatesAccountWithTheNormalisedEmail(t *testing.T) {
+	repository := userTest.NewRepository()
+	app := newOAuthAppWithEmail(t, repository, " Victim@Example.COM ")
+
+	assert.Equal(t, fiber.StatusOK, sendCallback(t, app))
+
+	user, found := repository.User("victim")
+	require.True(t, found)
+	assert.Equal(t, TEST_EMAIL, user.Email)
+}
@@ -2,21 +2,33 @@ package userController
 
 import (
 	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
 	"go-gallery/src/commons/exception"
+	utilsToken "go-gallery/src/commons/utils/token"
+	passwordValidator "go-gallery/src/commons/utils/validations/password"
+	userEntity "go-gallery/src/domain/entities/user"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
 
+	"go-gallery/src/infrastructure/auth"
 	userHandler "go-gallery/src/infrastructure/controller/user/handler"
 	userMiddleware "go-gallery/src/infrastructure/controller/user/middlewares"
 	"go-gallery/src/infrastructure/dto"
-	imageDTO "go-gallery/src/infrastructure/dto/image"
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
 	userDTO "go-gallery/src/infrastructure/dto/user"
 	log "go-gallery/src/infrastructure/logger"
 	emailTemplate "go-gallery/src/infrastructure/repository/emailSender/template"
+	accountDeletionService "go-gallery/src/service/accountDeletion"
+	attemptService "go-gallery/src/service/attempt"
+	auditService "go-gallery/src/service/audit"
 	codeGeneratorService "go-gallery/src/service/codeGenerator"
 	emailService "go-gallery/src/service/email"
-	imageService "go-gallery/src/service/image"
 	userService "go-gallery/src/service/user"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
 
 var logger log.Logger
@@ -27,26 +39,55 @@ const (
 	CLAIMS_NOT_FOUND_MSG          string = "Unauthorized: no user claims found"
 	PREFIX_DELETE_CODE_GENERATOR  string = "delete"
 	PREFIX_RECOVER_CODE_GENERATOR string = "recover"
+	PREFIX_VERIFY_CODE_GENERATOR  string = "verify"
+	PREFIX_EMAIL_CHANGE_GENERATOR string = "email-change"
+	UNVERIFIED_ACCOUNT_MSG        string = "The email address of this account has not been verified"
+	TOO_MANY_REQUESTS_MSG         string = "Too many requests, please try again later"
+	DISABLED_ACCOUNT_MSG          string = "This account has been disabled"
+	SCHEDULED_DELETION_MSG        string = "This account is scheduled for deletion"
+	INVALID_RESET_LINK_MSG        string = "The password reset link is invalid or has expired"
+	ATTEMPT_SCOPE_LOGIN           string = "login"
+	ATTEMPT_SCOPE_RECOVER         string = "recover"
+	ATTEMPT_SCOPE_DELETE          string = "delete"
+	ATTEMPT_SCOPE_VERIFY          string = "verify"
 )
 
 type AuthController struct {
 	userService          *userService.UserService
 	emailSenderService   *emailService.EmailSenderService
-	imageService         *imageService.ImageService
+	accountDeletion      *accountDeletionService.AccountDeletionService
 	codeGeneratorService *codeGeneratorService.CodeGeneratorService
+	attemptService       *attemptService.AttemptService
+	auditService         *auditService.AuditService
 	jwtMiddleware        *userMiddleware.JWTMiddleware
+	passwordValidator    *passwordValidator.Validator
+	passwordResetManager *auth.PasswordResetManager
+
+	verificationConfiguration configuration.VerificationConfiguration
+	emailChangeConfiguration  configuration.EmailChangeConfiguration
+	recoveryConfiguration     configuration.RecoveryConfiguration
 }
 
 func NewAuthController(userService *userService.UserService, emailSenderService *emailService.EmailSenderService,
-	imageService *imageService.ImageService, codeGeneratorService *codeGeneratorService.CodeGeneratorService,
-	jwtMiddleware *userMiddleware.JWTMiddleware) *AuthController {
+	accountDeletion *accountDeletionService.AccountDeletionService, codeGeneratorService *codeGeneratorService.CodeGeneratorService,
+	attemptService *attemptService.AttemptService, auditService *auditService.AuditService, jwtMiddleware *userMiddleware.JWTMiddleware,
+	passwordValidator *passwordValidator.Validator,
+	passwordResetManager *auth.PasswordResetManager, verificationConfiguration configuration.VerificationConfiguration,
+	emailChangeConfiguration configuration.EmailChangeConfiguration, recoveryConfiguration configuration.RecoveryConfiguration) *AuthController {
 	logger = log.Instance()
 	return &AuthController{
-		userService:          userService,
-		emailSenderService:   emailSenderService,
-		imageService:         imageService,
-		codeGeneratorService: codeGeneratorService,
-		jwtMiddleware:        jwtMiddleware,
+		userService:               userService,
+		emailSenderService:        emailSenderService,
+		accountDeletion:           accountDeletion,
+		codeGeneratorService:      codeGeneratorService,
+		attemptService:            attemptService,
+		auditService:              auditService,
+		jwtMiddleware:             jwtMiddleware,
+		passwordValidator:         passwordValidator,
+		passwordResetManager:      passwordResetManager,
+		verificationConfiguration: verificationConfiguration,
+		emailChangeConfiguration:  emailChangeConfiguration,
+		recoveryConfiguration:     recoveryConfiguration,
 	}
 }
 
@@ -57,8 +98,38 @@ func (c *AuthController) SetUpRoutes(router fiber.Router) {
 	router.Put("/update", c.jwtMiddleware.Handler(), c.update)
 	router.Post("/request-delete", c.jwtMiddleware.Handler(), c.requestDelete)
 	router.Delete("/delete", c.jwtMiddleware.Handler(), c.confirmDelete)
+	router.Get("/cancel-delete", c.validateCancelDelete)
+	router.Post("/cancel-delete", c.cancelDelete)
 	router.Post("/request-recover", c.requestRecover)
 	router.Post("/recover", c.recover)
+	router.Post("/request-recover-link", c.requestRecoverLink)
+	router.Get("/recover-link", c.validateRecoverLink)
+	router.Post("/recover-link", c.recoverWithLink)
+	router.Post("/verify", c.verify)
+	router.Post("/resend-verification", c.resendVerificationLimiter(), c.resendVerification)
+	router.Post("/confirm-email-change", c.jwtMiddleware.Handler(), c.confirmEmailChange)
+	router.Get("/revert-email-change", c.validateRevertEmailChange)
+	router.Post("/revert-email-change", c.revertEmailChange)
+	router.Get("/activity", c.jwtMiddleware.Handler(), c.activity)
+}
+
+// Limits the number of verification emails that can be requested for the same address
+func (c *AuthController) resendVerificationLimiter() fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        c.verificationConfiguration.ResendMax,
+		Expiration: c.verificationConfiguration.ResendWindow,
+		KeyGenerator: func(ctx *fiber.Ctx) string {
+			req := new(userDTO.UserVerificationResendDTO)
+			if err := ctx.BodyParser(req); err != nil || req.Email == "" {
+				return ctx.IP()
+			}
+			return userEntity.NormalizeEmail(req.Email)
+		},
+		LimitReached: func(ctx *fiber.Ctx) error {
+			logger.Warning("Verification email resend limit reached")
+			return ctx.Status(fiber.StatusTooManyRequests).JSON(exception.NewApiException(fiber.StatusTooManyRequests, TOO_MANY_REQUESTS_MSG))
+		},
+	})
 }
 
 // @Summary		Iniciar sesión
@@ -71,10 +142,14 @@ func (c *AuthController) SetUpRoutes(router fiber.Router) {
 // @Header			200		{string}	Set-Cookie					"Authorization=auth_token; HttpOnly; Secure"
 // @Failure		400		{object}	exception.ApiException		"Contraseña incorrecta"
 // @Failure		401		{object}	exception.ApiException		"No autorizado"
+// @Failure		403		{object}	exception.ApiException		"El correo electrónico de la cuenta no ha sido verificado, la cuenta está deshabilitada o pendiente de eliminación"
 // @Failure		404		{object}	exception.ApiException		"Usuario no encontrado"
+// @Failure		429		{object}	exception.ApiException		"Demasiados intentos fallidos"
 // @Failure		500		{object}	exception.ApiException		"Ha ocurrido un error inesperado"
 // @Router			/auth/login [post]
 func (c *AuthController) login(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("POST /login called")
 
 	loginRequestDTO := new(userDTO.LoginRequestDTO)
@@ -84,15 +159,44 @@ func (c *AuthController) login(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_LOGIN_REQUEST_MSG))
 	}
 
+	ipKey := attemptService.IPKey(ATTEMPT_SCOPE_LOGIN, ctx.IP())
+	accountKey := attemptService.AccountKey(ATTEMPT_SCOPE_LOGIN, loginRequestDTO.Username)
+	if errAttempts := c.checkAttempts(ctx, ipKey, accountKey); errAttempts != nil {
+		return ctx.Status(errAttempts.Status).JSON(errAttempts)
+	}
+
 	user, errFind := c.userService.Find(loginRequestDTO)
 	if errFind != nil {
-		logger.Error(fmt.Sprintf("Error finding user: %s", errFind.Message))
+		logger.Error("Error finding user", log.F("error", errFind.Message))
+		if errFind.Status < fiber.StatusInternalServerError {
+			c.registerFailedAttempt(ipKey, accountKey)
+			c.audit(ctx, loginRequestDTO.Username, auditDTO.AUDIT_ACTION_LOGIN, "", false)
+		}
 		return ctx.Status(errFind.Status).JSON(errFind)
 	}
+	c.resetAttempts(accountKey)
+
+	if user.Disabled {
+		logger.Warning("Disabled user tried to log in", log.F("username", user.Username))
+		c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_LOGIN, "", false)
+		return ctx.Status(fiber.StatusForbidden).JSON(exception.NewApiException(fiber.StatusForbidden, DISABLED_ACCOUNT_MSG))
+	}
+
+	if user.DeletionScheduledAt != nil {
+		logger.Warning("User scheduled for deletion tried to log in", log.F("username", user.Username))
+		c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_LOGIN, "", false)
+		return ctx.Status(fiber.StatusForbidden).JSON(exception.NewApiException(fiber.StatusForbidden, SCHEDULED_DELETION_MSG))
+	}
+
+	if !user.Verified && !c.verificationConfiguration.AllowUnverifiedLogin {
+		logger.Warning("User tried to log in without a verified email", log.F("username", user.Username))
+		c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_LOGIN, "", false)
+		return ctx.Status(fiber.StatusForbidden).JSON(exception.NewApiException(fiber.StatusForbidden, UNVERIFIED_ACCOUNT_MSG))
+	}
 
-	errJWT := c.jwtMiddleware.CreateJWTToken(ctx, user.Username, user.Email)
+	errJWT := c.jwtMiddleware.CreateJWTToken(ctx, user.Username, user.Email, user.Role, user.SessionVersion)
 	if errJWT != nil {
-		logger.Error(fmt.Sprintf("Error creating JWT token: %s", errJWT.Message))
+		logger.Error("Error creating JWT token", log.F("error", errJWT.Message))
 		return ctx.Status(errJWT.Status).JSON(errJWT.Status)
 	}
 
@@ -104,12 +208,13 @@ func (c *AuthController) login(ctx *fiber.Ctx) error {
 		Lastname:  user.Lastname,
 	}
 
-	logger.Info(fmt.Sprintf("User %s logged in successfully", user.Username))
+	c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_LOGIN, "", true)
+	logger.Info("User logged in successfully", log.F("username", user.Username))
 	return ctx.Status(fiber.StatusOK).JSON(responseDTO)
 }
 
 // @Summary		Registro de un nuevo usuario
-// @Description	Registra un nuevo usuario en el sistema
+// @Description	Registra un nuevo usuario sin verificar en el sistema y envía un código de verificación a su correo electrónico
 // @Tags			auth
 // @Accept			json
 // @Produce		json
@@ -119,6 +224,8 @@ func (c *AuthController) login(ctx *fiber.Ctx) error {
 // @Failure		500		{object}	exception.ApiException			"Ha ocurrido un error inesperado"
 // @Router			/auth/register [post]
 func (c *AuthController) register(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("POST /register called")
 
 	registerRequestDTO := new(userDTO.UserDTO)
@@ -128,25 +235,36 @@ func (c *AuthController) register(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_LOGIN_REQUEST_MSG))
 	}
 
-	errHandler := userHandler.ProcessUser(registerRequestDTO.Password, registerRequestDTO.Email)
+	errHandler := userHandler.ProcessUser(c.passwordValidator, registerRequestDTO.Username, registerRequestDTO.Password, registerRequestDTO.Email)
 	if errHandler != nil {
-		logger.Error(fmt.Sprintf("Error processing user data: %s", errHandler.Message))
+		logger.Error("Error processing user data", log.F("error", errHandler.Message))
 		return ctx.Status(errHandler.Status).JSON(errHandler)
 	}
 
+	// New accounts always start unverified until the email address is confirmed
+	registerRequestDTO.Verified = false
+
 	user, errInsert := c.userService.Insert(registerRequestDTO)
 	if errInsert != nil {
-		logger.Error(fmt.Sprintf("Error inserting new user: %s", errInsert.Message))
+		logger.Error("Error inserting new user", log.F("error", errInsert.Message))
 		return ctx.Status(errInsert.Status).JSON(errInsert)
 	}
 
+	message := fmt.Sprintf("User registered successfully. A verification code has been sent to the email address %s.", user.Email)
+	errVerification := c.sendVerificationCode(user.Username, user.Email)
+	if errVerification != nil {
+		logger.Error("Error sending verification email", log.F("username", user.Username), log.F("error", errVerification.Error()))
+		message = "User registered successfully, but the verification email could not be sent. Please request a new one."
+	}
+
 	dto := userDTO.UserRegisterResponseDTO{
 		Username:  user.Username,
 		Firstname: user.Firstname,
-		Message:   "User registered successfully",
+		Message:   message,
 	}
 
-	logger.Info(fmt.Sprintf("User %s registered successfully", user.Username))
+	c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_REGISTER, "", true)
+	logger.Info("User registered successfully", log.F("username", user.Username))
 	return ctx.Status(fiber.StatusCreated).JSON(dto)
 }
 
@@ -161,6 +279,8 @@ func (c *AuthController) register(ctx *fiber.Ctx) error {
 // @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
 // @Router			/auth/logout [post]
 func (c *AuthController) logout(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("POST /logout called")
 
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
@@ -170,7 +290,8 @@ func (c *AuthController) logout(ctx *fiber.Ctx) error {
 	}
 
 	c.jwtMiddleware.DeleteAuthCookie(ctx)
-	logger.Info(fmt.Sprintf("User %s logged out successfully", claims.Username))
+	c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_LOGOUT, "", true)
+	logger.Info("User logged out successfully", log.F("username", claims.Username))
 
 	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
 		Message: fmt.Sprintf("Se ha cerrado sesión correctamente, %s", claims.Username),
@@ -178,7 +299,7 @@ func (c *AuthController) logout(ctx *fiber.Ctx) error {
 }
 
 // @Summary		Actualizar usuario
-// @Description	Actualiza los datos de un usuario autenticado
+// @Description	Actualiza los datos de un usuario autenticado. Si se indica un nuevo correo electrónico no se modifica directamente, se envía un código de confirmación a la nueva dirección
 // @Tags			auth
 // @Security		CookieAuth
 // @Accept			json
@@ -192,6 +313,8 @@ func (c *AuthController) logout(ctx *fiber.Ctx) error {
 // @Failure		500		{object}	exception.ApiException			"Ha ocurrido un error inesperado"
 // @Router			/auth/update [put]
 func (c *AuthController) update(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("PUT /update called")
 
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
@@ -207,40 +330,53 @@ func (c *AuthController) update(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_LOGIN_REQUEST_MSG))
 	}
 
+	// The password is compared against the new email when it is being changed as well
+	user.Email = userEntity.NormalizeEmail(user.Email)
+	email := user.Email
+	if email == "" {
+		email = claims.Email
+	}
+
+	errUser := userHandler.ProcessUser(c.passwordValidator, claims.Username, user.Password, email)
+	if errUser != nil {
+		logger.Error("Error processing user data", log.F("error", errUser.Message))
+		return ctx.Status(errUser.Status).JSON(errUser)
+	}
+
+	// The email is never updated directly, it goes through the confirmation flow
 	dtoUser := &userDTO.UserDTO{
 		Username:  claims.Username,
-		Email:     user.Email,
 		Password:  user.Password,
 		Lastname:  user.Lastname,
 		Firstname: user.Firstname,
 	}
 
-	errUser := userHandler.ProcessUser(dtoUser.Password, dtoUser.Email)
-	if errUser != nil {
-		logger.Error(fmt.Sprintf("Error processing user data: %s", errUser.Message))
-		return ctx.Status(errUser.Status).JSON(errUser)
-	}
-
 	emailChanged := user.Email != "" && user.Email != claims.Email
+	otherFieldsChanged := dtoUser.Password != "" || dtoUser.Firstname != "" || dtoUser.Lastname != ""
 
-	_, errUpdate := c.userService.Update(dtoUser)
-	if errUpdate != nil {
-		logger.Error(fmt.Sprintf("Error updating user: %s", errUpdate.Message))
-		return ctx.Status(errUpdate.Status).JSON(errUpdate)
+	if otherFieldsChanged || !emailChanged {
+		_, errUpdate := c.userService.Update(dtoUser)
+		if errUpdate != nil {
+			logger.Error("Error updating user", log.F("error", errUpdate.Message))
+			return ctx.Status(errUpdate.Status).JSON(errUpdate)
+		}
+		c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_ACCOUNT_UPDATE, "", true)
+		logger.Info("User updated successfully", log.F("username", dtoUser.Username))
 	}
 
+	message := fmt.Sprintf("User %s updated successfully.", dtoUser.Username)
 	if emailChanged {
-		errJWT := c.jwtMiddleware.CreateJWTToken(ctx, dtoUser.Username, dtoUser.Email)
-		if errJWT != nil {
-			logger.Error(fmt.Sprintf("Error creating new JWT token: %s", errJWT.Message))
-			return ctx.Status(errJWT.Status).JSON(errJWT.Status)
+		errChange := c.requestEmailChange(claims.Username, claims.Email, user.Email)
+		if errChange != nil {
+			logger.Error("Error requesting email change", log.F("username", claims.Username), log.F("error", errChange.Message))
+			return ctx.Status(errChange.Status).JSON(errChange)
 		}
-		logger.Info(fmt.Sprintf("User %s email updated, new JWT token created", dtoUser.Username))
+		c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_EMAIL_CHANGE_REQUEST, user.Email, true)
+		message = fmt.Sprintf("User %s updated successfully. A confirmation code has been sent to %s to complete the email change.", dtoUser.Username, user.Email)
 	}
 
-	logger.Info(fmt.Sprintf("User %s updated successfully", dtoUser.Username))
 	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
-		Message: fmt.Sprintf("User %s updated successfully.", dtoUser.Username),
+		Message: message,
 	})
 }
 
@@ -255,6 +391,8 @@ func (c *AuthController) update(ctx *fiber.Ctx) error {
 // @Failure		500	{object}	exception.ApiException			"Ha ocurrido un error inesperado"
 // @Router			/auth/request-delete [post]
 func (c *AuthController) requestDelete(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("POST /request-delete called")
 
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
@@ -265,39 +403,43 @@ func (c *AuthController) requestDelete(ctx *fiber.Ctx) error {
 
 	code, err := c.codeGeneratorService.GenerateCode("delete", claims.Username)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error generating delete code: %v", err))
+		logger.Error("Error generating delete code", log.F("error", err.Error()))
 		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, "Error generating delete code"))
 	}
 	template := emailTemplate.DeleteAccountTemplate{}
 	errEmail := c.emailSenderService.SendEmail(code, claims.Email, template)
 	if errEmail != nil {
-		logger.Error(fmt.Sprintf("Failed to send delete code email to %s", claims.Email))
+		logger.Error("Failed to send delete code email", log.F("email", claims.Email))
 		return ctx.Status(fiber.StatusInternalServerError).JSON(&dto.MessageResponseDTO{
 			Message: "Failed to send confirmation code email due to an internal system error.",
 		})
 	}
 
-	logger.Info(fmt.Sprintf("Delete code sent successfully to email: %s", claims.Email))
+	c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_DELETION_REQUEST, "", true)
+	logger.Info("Delete code sent successfully", log.F("email", claims.Email))
 	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
 		Message: fmt.Sprintf("A confirmation code for account deletion has been sent to the email address %s.", claims.Email),
 	})
 }
 
 // @Summary		Confirmar eliminación de cuenta
-// @Description	Elimina la cuenta de usuario tras verificar el código enviado
+// @Description	Programa la eliminación de la cuenta tras verificar el código enviado y la contraseña. La cuenta y sus imágenes se eliminan al terminar el periodo de gracia, hasta entonces no se puede iniciar sesión y se puede cancelar desde el enlace enviado por correo
 // @Tags			auth
 // @Security		ApiKeyAuth
 // @Accept			json
 // @Produce		json
 // @Param			request	body		userDTO.UserDeleteDTO			true	"Datos para confirmar eliminación"
-// @Success		200		{object}	userDTO.UserRegisterResponseDTO	"Se han eliminado los datos del usuario correctamente"
+// @Success		200		{object}	userDTO.UserDeleteScheduledDTO	"Se ha programado la eliminación de la cuenta"
 // @Failure		400		{object}	exception.ApiException			"Solicitud incorrecta"
 // @Failure		401		{object}	exception.ApiException			"Usuario no autenticado"
 // @Failure		403		{object}	exception.ApiException			"Los datos proporcionados no coinciden con el usuario autenticado"
 // @Failure		404		{object}	exception.ApiException			"Usuario no encontrado"
+// @Failure		429		{object}	exception.ApiException			"Demasiados intentos fallidos"
 // @Failure		500		{object}	exception.ApiException			"Ha ocurrido un error inesperado"
 // @Router			/auth/delete [delete]
 func (c *AuthController) confirmDelete(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("DELETE /delete called")
 
 	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
@@ -313,40 +455,106 @@ func (c *AuthController) confirmDelete(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, INVALID_LOGIN_REQUEST_MSG))
 	}
 
+	ipKey := attemptService.IPKey(ATTEMPT_SCOPE_DELETE, ctx.IP())
+	accountKey := attemptService.AccountKey(ATTEMPT_SCOPE_DELETE, claims.Username)
+	if errAttempts := c.checkAttempts(ctx, ipKey, accountKey); errAttempts != nil {
+		return ctx.Status(errAttempts.Status).JSON(errAttempts)
+	}
+
 	ok = c.codeGeneratorService.VerifyCode("delete", claims.Username, dtoDeleteUser.Code)
 	if !ok {
 		logger.Error("Invalid verification code")
+		c.registerFailedAttempt(ipKey, accountKey)
+		c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_DELETION_CONFIRM, "", false)
 		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, "Invalid verification code"))
 	}
 
-	// Create request to delete all user images/thumbnails by owner
-	dtoDeleteImage := new(imageDTO.ImageDeleteRequestDTO)
-	dtoDeleteImage.Owner = claims.Username
+	// The password is checked before anything is changed in the account
+	user, errFind := c.userService.Find(&userDTO.LoginRequestDTO{Username: claims.Username, Password: dtoDeleteUser.Password})
+	if errFind != nil {
+		logger.Error("Error checking the password of user", log.F("username", claims.Username), log.F("error", errFind.Message))
+		if errFind.Status < fiber.StatusInternalServerError {
+			c.registerFailedAttempt(ipKey, accountKey)
+			c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_DELETION_CONFIRM, "", false)
+		}
+		return ctx.Status(errFind.Status).JSON(errFind)
+	}
+	c.resetAttempts(accountKey)
 
-	_, errImageResponse := c.imageService.DeleteAll(dtoDeleteImage)
-	if errImageResponse != nil {
-		logger.Error(fmt.Sprintf("Error deleting all images for user %s: %s", claims.Username, errImageResponse.Message))
+	deletionScheduledAt, errSchedule := c.accountDeletion.Schedule(ctx.UserContext(), user)
+	if errSchedule != nil {
+		return ctx.Status(errSchedule.Status).JSON(errSchedule)
 	}
 
-	logger.Info(fmt.Sprintf("All images/thumbnails for user %s deleted successfully", claims.Username))
+	c.jwtMiddleware.DeleteAuthCookie(ctx)
 
-	dtoUser := &userDTO.UserDTO{
-		Username: claims.Username,
-		Email:    claims.Email,
-		Password: dtoDeleteUser.Password,
+	c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_DELETION_CONFIRM, "", true)
+	logger.Info("User confirmed the deletion of the account", log.F("username", user.Username))
+	return ctx.Status(fiber.StatusOK).JSON(&userDTO.UserDeleteScheduledDTO{
+		Message:             fmt.Sprintf("The account of %s will be deleted on %s.", user.Username, deletionScheduledAt.UTC().Format(time.RFC3339)),
+		DeletionScheduledAt: deletionScheduledAt,
+	})
+}
+
+// @Summary		Comprueba un enlace de cancelación de la eliminación de la cuenta
+// @Description	Indica si el enlace enviado por correo al confirmar la eliminación sigue siendo válido, para pedir confirmación antes de cancelarla. No modifica la cuenta
+// @Tags			auth
+// @Produce		json
+// @Param			token	query		string							true	"Token incluido en el enlace de cancelación"
+// @Success		200		{object}	userDTO.UserDeleteCancelLinkDTO	"El enlace es válido"
+// @Failure		400		{object}	exception.ApiException			"Petición no válida"
+// @Failure		401		{object}	exception.ApiException			"El enlace no es válido, ha caducado o ya se ha usado"
+// @Router			/auth/cancel-delete [get]
+func (c *AuthController) validateCancelDelete(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("GET /cancel-delete called")
+
+	token := ctx.Query("token")
+	if token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
 	}
 
-	_, errDelete := c.userService.Delete(dtoUser)
-	if errDelete != nil {
-		logger.Error(fmt.Sprintf("Error deleting user: %s", errDelete.Message))
-		return ctx.Status(errDelete.Status).JSON(errDelete)
+	user, errCheck := c.accountDeletion.CheckCancel(ctx.UserContext(), token)
+	if errCheck != nil {
+		return ctx.Status(errCheck.Status).JSON(errCheck)
 	}
 
-	c.jwtMiddleware.DeleteAuthCookie(ctx)
+	return ctx.Status(fiber.StatusOK).JSON(&userDTO.UserDeleteCancelLinkDTO{
+		Username:            user.Username,
+		DeletionScheduledAt: *user.DeletionScheduledAt,
+	})
+}
+
+// @Summary		Cancela la eliminación de la cuenta
+// @Description	Restaura una cuenta pendiente de eliminación usando el token del enlace enviado por correo al confirmar la eliminación. El enlace sólo puede usarse una vez y caduca al eliminarse la cuenta
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		userDTO.UserDeleteCancelDTO	true	"Token incluido en el enlace de cancelación"
+// @Success		200		{object}	dto.MessageResponseDTO		"Se ha cancelado la eliminación de la cuenta"
+// @Failure		400		{object}	exception.ApiException		"Petición no válida"
+// @Failure		401		{object}	exception.ApiException		"El enlace no es válido, ha caducado o ya se ha usado"
+// @Failure		500		{object}	exception.ApiException		"Ha ocurrido un error inesperado"
+// @Router			/auth/cancel-delete [post]
+func (c *AuthController) cancelDelete(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("POST /cancel-delete called")
+
+	req := new(userDTO.UserDeleteCancelDTO)
+	if err := ctx.BodyParser(req); err != nil || req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
+	}
+
+	user, errCancel := c.accountDeletion.Cancel(ctx.UserContext(), req.Token)
+	if errCancel != nil {
+		return ctx.Status(errCancel.Status).JSON(errCancel)
+	}
+	c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_DELETION_CANCEL, "", true)
 
-	logger.Info(fmt.Sprintf("User %s deleted successfully", dtoUser.Username))
 	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
-		Message: fmt.Sprintf("User %s deleted successfully.", dtoUser.Username),
+		Message: fmt.Sprintf("The deletion of the account %s has been cancelled, you can log in again.", user.Username),
 	})
 }
 
@@ -361,6 +569,8 @@ func (c *AuthController) confirmDelete(ctx *fiber.Ctx) error {
 // @Failure		500		{object}	exception.ApiException	"Ha ocurrido un error inesperado"
 // @Router			/auth/request-recover [post]
 func (c *AuthController) requestRecover(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("POST /request-recover called")
 
 	req := new(userDTO.PasswordRecoveryRequestDTO)
@@ -398,9 +608,12 @@ func (c *AuthController) requestRecover(ctx *fiber.Ctx) error {
 // @Failure		400		{object}	exception.ApiException	"Petición no válida"
 // @Failure		401		{object}	exception.ApiException	"Usuario no autenticado"
 // @Failure		404		{object}	exception.ApiException	"No se ha encontrado el usuario"
+// @Failure		429		{object}	exception.ApiException	"Demasiados intentos fallidos"
 // @Failure		500		{object}	exception.ApiException	"Ha ocurrido un error inesperado"
 // @Router			/auth/recover [post]
 func (c *AuthController) recover(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
 	logger.Info("POST /recover called")
 
 	req := new(userDTO.PasswordRecoveryConfirmDTO)
@@ -408,24 +621,619 @@ func (c *AuthController) recover(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
 	}
 
+	ipKey := attemptService.IPKey(ATTEMPT_SCOPE_RECOVER, ctx.IP())
+	accountKey := attemptService.AccountKey(ATTEMPT_SCOPE_RECOVER, req.Email)
+	if errAttempts := c.checkAttempts(ctx, ipKey, accountKey); errAttempts != nil {
+		return ctx.Status(errAttempts.Status).JSON(errAttempts)
+	}
+
 	userDTO, errFind := c.userService.FindByEmail(req.Email)
 	if errFind != nil {
+		c.registerFailedAttempt(ipKey, accountKey)
 		return ctx.Status(fiber.StatusNotFound).JSON(errFind)
 	}
 
+	// Verify new password, before the code is consumed so a rejected password does not require a new code
+	if errPassword := c.passwordValidator.Validate(req.NewPassword, userDTO.Username, userDTO.Email); errPassword != nil {
+		logger.Warning("New password rejected", log.F("username", userDTO.Username), log.F("error", errPassword.Message))
+		return ctx.Status(errPassword.Status).JSON(errPassword)
+	}
+
 	if !c.codeGeneratorService.VerifyCode(PREFIX_RECOVER_CODE_GENERATOR, userDTO.Username, req.Code) {
+		c.registerFailedAttempt(ipKey, accountKey)
+		c.audit(ctx, userDTO.Username, auditDTO.AUDIT_ACTION_PASSWORD_RESET, "", false)
 		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, "Invalid code"))
 	}
-
-	// Verify new password
-	userHandler.ValidatePassword(req.NewPassword)
+	c.resetAttempts(accountKey)
 	userDTO.Password = req.NewPassword
 
 	if _, err := c.userService.Update(userDTO); err != nil {
 		return ctx.Status(err.Status).JSON(err)
 	}
+	c.revokeSessions(ctx, userDTO.Username)
+	c.audit(ctx, userDTO.Username, auditDTO.AUDIT_ACTION_PASSWORD_RESET, "", true)
+
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: "Password has been reset successfully.",
+	})
+}
+
+// @Summary		Solicita un enlace para recuperar la contraseña
+// @Description	Envía al correo electrónico un enlace firmado de un solo uso y con caducidad para restablecer la contraseña, como alternativa al código de recuperación
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		userDTO.PasswordRecoveryRequestDTO	true	"Correo para recuperar contraseña"
+// @Success		200		{object}	dto.MessageResponseDTO				"Se ha enviado un correo electrónico con el enlace de recuperación"
+// @Failure		400		{object}	exception.ApiException				"Petición no válida"
+// @Failure		404		{object}	exception.ApiException				"Usuario no encontrado"
+// @Failure		500		{object}	exception.ApiException				"Ha ocurrido un error inesperado"
+// @Router			/auth/request-recover-link [post]
+func (c *AuthController) requestRecoverLink(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("POST /request-recover-link called")
+
+	req := new(userDTO.PasswordRecoveryRequestDTO)
+	if err := ctx.BodyParser(req); err != nil || req.Email == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
+	}
+
+	user, errFind := c.userService.FindByEmail(req.Email)
+	if errFind != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(errFind)
+	}
+
+	token, _, err := c.passwordResetManager.Create(user.Username, user.Password)
+	if err != nil {
+		logger.Error("Error creating password reset link", log.F("username", user.Username), log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, "Error generating recovery link"))
+	}
+
+	link := c.recoveryConfiguration.ResetURL + "?token=" + url.QueryEscape(token)
+	template := emailTemplate.RecoveryTemplate{Link: true, LinkExpiresIn: c.recoveryConfiguration.LinkExpiration}
+	if err := c.emailSenderService.SendEmail(link, user.Email, template); err != nil {
+		logger.Error("Error sending recovery link", log.F("username", user.Username), log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, "Error sending recovery email"))
+	}
+
+	logger.Info("Password reset link sent", log.F("username", user.Username))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("A recovery link has been successfully sent to the email address: %s", user.Email),
+	})
+}
+
+// @Summary		Comprueba un enlace de recuperación de contraseña
+// @Description	Indica si el token de un enlace de recuperación sigue siendo válido, para mostrar el formulario de nueva contraseña sólo en ese caso
+// @Tags			auth
+// @Produce		json
+// @Param			token	query		string						true	"Token incluido en el enlace de recuperación"
+// @Success		200		{object}	userDTO.PasswordResetLinkDTO	"El enlace es válido"
+// @Failure		401		{object}	exception.ApiException			"El enlace no es válido o ha caducado"
+// @Router			/auth/recover-link [get]
+func (c *AuthController) validateRecoverLink(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("GET /recover-link called")
+
+	user, claims, errToken := c.checkResetToken(ctx.Query("token"))
+	if errToken != nil {
+		return ctx.Status(errToken.Status).JSON(errToken)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(&userDTO.PasswordResetLinkDTO{
+		Username:   user.Username,
+		Expiration: claims.Expiration,
+	})
+}
+
+// @Summary		Restablece la contraseña con un enlace de recuperación
+// @Description	Restablece la contraseña usando el token del enlace de recuperación, el enlace deja de ser válido y se cierran todas las sesiones abiertas de la cuenta
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		userDTO.PasswordResetLinkConfirmDTO	true	"Token del enlace y nueva contraseña"
+// @Success		200		{object}	dto.MessageResponseDTO				"Se ha restablecido la contraseña"
+// @Failure		400		{object}	exception.ApiException				"Petición no válida o la contraseña no cumple la política"
+// @Failure		401		{object}	exception.ApiException				"El enlace no es válido o ha caducado"
+// @Failure		500		{object}	exception.ApiException				"Ha ocurrido un error inesperado"
+// @Router			/auth/recover-link [post]
+func (c *AuthController) recoverWithLink(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("POST /recover-link called")
+
+	req := new(userDTO.PasswordResetLinkConfirmDTO)
+	if err := ctx.BodyParser(req); err != nil || req.Token == "" || req.NewPassword == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
+	}
+
+	user, _, errToken := c.checkResetToken(req.Token)
+	if errToken != nil {
+		return ctx.Status(errToken.Status).JSON(errToken)
+	}
+
+	if errPassword := c.passwordValidator.Validate(req.NewPassword, user.Username, user.Email); errPassword != nil {
+		logger.Warning("New password rejected", log.F("username", user.Username), log.F("error", errPassword.Message))
+		return ctx.Status(errPassword.Status).JSON(errPassword)
+	}
+
+	// Changing the password also changes its hash, so the link cannot be used again
+	if _, err := c.userService.Update(&userDTO.UserDTO{Username: user.Username, Password: req.NewPassword}); err != nil {
+		logger.Error("Error resetting password of user", log.F("username", user.Username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+	c.revokeSessions(ctx, user.Username)
 
+	c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_PASSWORD_RESET, "", true)
+	logger.Info("User reset the password with a recovery link", log.F("username", user.Username))
 	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
 		Message: "Password has been reset successfully.",
 	})
 }
+
+// Checks the signature of the token against the current password hash of its user
+func (c *AuthController) checkResetToken(token string) (*userDTO.UserDTO, *auth.PasswordResetClaims, *exception.ApiException) {
+	invalidLink := exception.NewApiException(fiber.StatusUnauthorized, INVALID_RESET_LINK_MSG)
+
+	username, err := c.passwordResetManager.Username(token)
+	if err != nil {
+		logger.Warning("Malformed password reset token", log.F("error", err.Error()))
+		return nil, nil, invalidLink
+	}
+
+	user, errFind := c.userService.FindByUsername(username)
+	if errFind != nil {
+		logger.Warning("Password reset token of unknown user", log.F("username", username), log.F("error", errFind.Message))
+		return nil, nil, invalidLink
+	}
+
+	claims, err := c.passwordResetManager.Verify(token, user.Password)
+	if err != nil {
+		logger.Warning("Password reset token rejected", log.F("username", username), log.F("error", err.Error()))
+		return nil, nil, invalidLink
+	}
+
+	return user, claims, nil
+}
+
+// Ends every session of the user after a password reset or an email revert, the cookie of this browser is removed as well
+func (c *AuthController) revokeSessions(ctx *fiber.Ctx, username string) {
+	logger := log.FromContext(ctx.UserContext())
+
+	if _, err := c.userService.RevokeSessions(username); err != nil {
+		logger.Error("Error revoking the sessions of user", log.F("username", username), log.F("error", err.Message))
+		return
+	}
+	c.jwtMiddleware.DeleteAuthCookie(ctx)
+}
+
+// @Summary		Verifica el correo electrónico de la cuenta
+// @Description	Confirma el código de verificación enviado al correo electrónico del usuario y marca la cuenta como verificada
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		userDTO.UserVerificationRequestDTO	true	"Datos para verificar el correo electrónico"
+// @Success		200		{object}	dto.MessageResponseDTO				"Se ha verificado el correo electrónico correctamente"
+// @Failure		400		{object}	exception.ApiException				"Petición no válida"
+// @Failure		401		{object}	exception.ApiException				"Código no válido"
+// @Failure		404		{object}	exception.ApiException				"No se ha encontrado el usuario"
+// @Failure		409		{object}	exception.ApiException				"La cuenta ya está verificada"
+// @Failure		429		{object}	exception.ApiException				"Demasiados intentos fallidos"
+// @Failure		500		{object}	exception.ApiException				"Ha ocurrido un error inesperado"
+// @Router			/auth/verify [post]
+func (c *AuthController) verify(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("POST /verify called")
+
+	req := new(userDTO.UserVerificationRequestDTO)
+	if err := ctx.BodyParser(req); err != nil || req.Email == "" || req.Code == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
+	}
+
+	ipKey := attemptService.IPKey(ATTEMPT_SCOPE_VERIFY, ctx.IP())
+	accountKey := attemptService.AccountKey(ATTEMPT_SCOPE_VERIFY, req.Email)
+	if errAttempts := c.checkAttempts(ctx, ipKey, accountKey); errAttempts != nil {
+		return ctx.Status(errAttempts.Status).JSON(errAttempts)
+	}
+
+	user, errFind := c.userService.FindByEmail(req.Email)
+	if errFind != nil {
+		c.registerFailedAttempt(ipKey, accountKey)
+		return ctx.Status(errFind.Status).JSON(errFind)
+	}
+
+	if user.Verified {
+		logger.Warning("User is already verified", log.F("username", user.Username))
+		return ctx.Status(fiber.StatusConflict).JSON(exception.NewApiException(fiber.StatusConflict, "The account is already verified"))
+	}
+
+	if !c.codeGeneratorService.VerifyCode(PREFIX_VERIFY_CODE_GENERATOR, user.Username, req.Code) {
+		logger.Error("Invalid verification code")
+		c.registerFailedAttempt(ipKey, accountKey)
+		c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_EMAIL_VERIFY, "", false)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, "Invalid code"))
+	}
+	c.resetAttempts(accountKey)
+
+	if _, err := c.userService.Verify(user.Username); err != nil {
+		logger.Error("Error verifying user", log.F("username", user.Username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	c.audit(ctx, user.Username, auditDTO.AUDIT_ACTION_EMAIL_VERIFY, "", true)
+	logger.Info("User verified the email address successfully", log.F("username", user.Username))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: "The email address has been verified successfully.",
+	})
+}
+
+// @Summary		Reenvía el código de verificación del correo electrónico
+// @Description	Envía un nuevo código de verificación al correo electrónico de una cuenta sin verificar. El número de envíos está limitado por dirección de correo
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		userDTO.UserVerificationResendDTO	true	"Correo electrónico de la cuenta"
+// @Success		200		{object}	dto.MessageResponseDTO				"Se ha enviado un nuevo código de verificación"
+// @Failure		400		{object}	exception.ApiException				"Petición no válida"
+// @Failure		404		{object}	exception.ApiException				"No se ha encontrado el usuario"
+// @Failure		409		{object}	exception.ApiException				"La cuenta ya está verificada"
+// @Failure		429		{object}	exception.ApiException				"Demasiadas peticiones"
+// @Failure		500		{object}	exception.ApiException				"Ha ocurrido un error inesperado"
+// @Router			/auth/resend-verification [post]
+func (c *AuthController) resendVerification(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("POST /resend-verification called")
+
+	req := new(userDTO.UserVerificationResendDTO)
+	if err := ctx.BodyParser(req); err != nil || req.Email == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
+	}
+
+	user, errFind := c.userService.FindByEmail(req.Email)
+	if errFind != nil {
+		return ctx.Status(errFind.Status).JSON(errFind)
+	}
+
+	if user.Verified {
+		logger.Warning("User is already verified", log.F("username", user.Username))
+		return ctx.Status(fiber.StatusConflict).JSON(exception.NewApiException(fiber.StatusConflict, "The account is already verified"))
+	}
+
+	if err := c.sendVerificationCode(user.Username, user.Email); err != nil {
+		logger.Error("Error sending verification email", log.F("username", user.Username), log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, "Error sending verification email"))
+	}
+
+	logger.Info("Verification code resent successfully", log.F("email", user.Email))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("A new verification code has been sent to the email address %s.", user.Email),
+	})
+}
+
+func (c *AuthController) sendVerificationCode(username, email string) error {
+	code, err := c.codeGeneratorService.GenerateCode(PREFIX_VERIFY_CODE_GENERATOR, username)
+	if err != nil {
+		return err
+	}
+
+	template := emailTemplate.VerificationTemplate{}
+	return c.emailSenderService.SendEmail(code, email, template)
+}
+
+// @Summary		Confirma el cambio de correo electrónico
+// @Description	Confirma el código enviado al nuevo correo electrónico, aplica el cambio, notifica al correo anterior con un enlace para revertirlo y renueva el token JWT
+// @Tags			auth
+// @Security		CookieAuth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		userDTO.EmailChangeConfirmDTO	true	"Código de confirmación"
+// @Success		200		{object}	dto.MessageResponseDTO			"Se ha cambiado el correo electrónico correctamente"
+// @Header			200		{string}	Set-Cookie						"Authorization=auth_token; HttpOnly; Secure"
+// @Failure		400		{object}	exception.ApiException			"Petición no válida"
+// @Failure		401		{object}	exception.ApiException			"Código no válido"
+// @Failure		404		{object}	exception.ApiException			"No existe un cambio de correo pendiente"
+// @Failure		500		{object}	exception.ApiException			"Ha ocurrido un error inesperado"
+// @Router			/auth/confirm-email-change [post]
+func (c *AuthController) confirmEmailChange(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("POST /confirm-email-change called")
+
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error(CLAIMS_NOT_FOUND_MSG)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, INVALID_AUTHENTIFICATION_MSG))
+	}
+
+	req := new(userDTO.EmailChangeConfirmDTO)
+	if err := ctx.BodyParser(req); err != nil || req.Code == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
+	}
+
+	emailChange, errFind := c.userService.FindPendingEmailChange(claims.Username)
+	if errFind != nil {
+		logger.Error("Error finding pending email change", log.F("username", claims.Username), log.F("error", errFind.Message))
+		return ctx.Status(errFind.Status).JSON(errFind)
+	}
+
+	if !c.codeGeneratorService.VerifyCode(PREFIX_EMAIL_CHANGE_GENERATOR, claims.Username, req.Code) {
+		logger.Error("Invalid email change code")
+		c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_EMAIL_CHANGE_CONFIRM, emailChange.NewEmail, false)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, "Invalid code"))
+	}
+
+	// The address could have been registered by another account since the change was requested
+	if errAvailable := c.checkEmailAvailable(emailChange.NewEmail); errAvailable != nil {
+		return ctx.Status(errAvailable.Status).JSON(errAvailable)
+	}
+
+	revertToken, err := utilsToken.GenerateToken(32)
+	if err != nil {
+		logger.Error("Error generating revert token", log.F("error", err.Error()))
+		return ctx.Status(fiber.StatusInternalServerError).JSON(exception.NewApiException(fiber.StatusInternalServerError, "Error generating revert token"))
+	}
+
+	_, errUpdate := c.userService.Update(&userDTO.UserDTO{Username: claims.Username, Email: emailChange.NewEmail})
+	if errUpdate != nil {
+		logger.Error("Error updating email of user", log.F("username", claims.Username), log.F("error", errUpdate.Message))
+		return ctx.Status(errUpdate.Status).JSON(errUpdate)
+	}
+
+	emailChange.RevertTokenHash = utilsToken.HashToken(revertToken)
+	emailChange.RevertExpiration = time.Now().Add(c.emailChangeConfiguration.RevertWindow)
+	if _, errConfirm := c.userService.ConfirmEmailChange(emailChange); errConfirm != nil {
+		logger.Error("Error confirming email change", log.F("username", claims.Username), log.F("error", errConfirm.Message))
+		return ctx.Status(errConfirm.Status).JSON(errConfirm)
+	}
+
+	revertLink := fmt.Sprintf("%s/api/auth/revert-email-change?token=%s", c.emailChangeConfiguration.PublicURL, revertToken)
+	template := emailTemplate.EmailChangeNotificationTemplate{NewEmail: emailChange.NewEmail}
+	if errEmail := c.emailSenderService.SendEmail(revertLink, emailChange.OldEmail, template); errEmail != nil {
+		logger.Error("Failed to send email change notification", log.F("email", emailChange.OldEmail), log.F("error", errEmail.Error()))
+	}
+
+	// Claims embed the email, so the session token must be re-issued
+	errJWT := c.jwtMiddleware.CreateJWTToken(ctx, claims.Username, emailChange.NewEmail, claims.Role, claims.SessionVersion)
+	if errJWT != nil {
+		logger.Error("Error creating new JWT token", log.F("error", errJWT.Message))
+		return ctx.Status(errJWT.Status).JSON(errJWT)
+	}
+
+	c.audit(ctx, claims.Username, auditDTO.AUDIT_ACTION_EMAIL_CHANGE_CONFIRM, emailChange.NewEmail, true)
+	logger.Info("User changed the email address successfully", log.F("username", claims.Username))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("The email address has been changed successfully to %s.", emailChange.NewEmail),
+	})
+}
+
+// @Summary		Comprueba un enlace para revertir un cambio de correo electrónico
+// @Description	Indica si el enlace enviado al correo anterior tras el cambio sigue siendo válido y qué cambio revertiría, para pedir confirmación antes de aplicarlo. No modifica la cuenta
+// @Tags			auth
+// @Produce		json
+// @Param			token	query		string							true	"Token para revertir el cambio"
+// @Success		200		{object}	userDTO.EmailChangeRevertLinkDTO	"El enlace es válido"
+// @Failure		400		{object}	exception.ApiException				"Petición no válida, enlace caducado o el correo anterior ya está registrado"
+// @Failure		404		{object}	exception.ApiException				"No se ha encontrado el cambio de correo"
+// @Failure		500		{object}	exception.ApiException				"Ha ocurrido un error inesperado"
+// @Router			/auth/revert-email-change [get]
+func (c *AuthController) validateRevertEmailChange(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("GET /revert-email-change called")
+
+	token := ctx.Query("token")
+	if token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
+	}
+
+	emailChange, errFind := c.findEmailChangeToRevert(ctx, token)
+	if errFind != nil {
+		return ctx.Status(errFind.Status).JSON(errFind)
+	}
+	if errExpired := c.checkRevertExpiration(ctx, emailChange); errExpired != nil {
+		return ctx.Status(errExpired.Status).JSON(errExpired)
+	}
+	if errAvailable := c.checkEmailAvailable(emailChange.OldEmail); errAvailable != nil {
+		return ctx.Status(errAvailable.Status).JSON(errAvailable)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(&userDTO.EmailChangeRevertLinkDTO{
+		Username:         emailChange.Username,
+		OldEmail:         emailChange.OldEmail,
+		NewEmail:         emailChange.NewEmail,
+		RevertExpiration: emailChange.RevertExpiration,
+	})
+}
+
+// @Summary		Revierte un cambio de correo electrónico
+// @Description	Restablece el correo electrónico anterior de la cuenta usando el token del enlace enviado a dicha dirección tras el cambio y cierra todas sus sesiones
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		userDTO.EmailChangeRevertDTO	true	"Token para revertir el cambio"
+// @Success		200		{object}	dto.MessageResponseDTO			"Se ha restablecido el correo electrónico anterior"
+// @Failure		400		{object}	exception.ApiException			"Petición no válida, enlace caducado o el correo anterior ya está registrado"
+// @Failure		404		{object}	exception.ApiException			"No se ha encontrado el cambio de correo"
+// @Failure		500		{object}	exception.ApiException			"Ha ocurrido un error inesperado"
+// @Router			/auth/revert-email-change [post]
+func (c *AuthController) revertEmailChange(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("POST /revert-email-change called")
+
+	req := new(userDTO.EmailChangeRevertDTO)
+	if err := ctx.BodyParser(req); err != nil || req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(exception.NewApiException(fiber.StatusBadRequest, "Invalid request"))
+	}
+
+	tokenHash := utilsToken.HashToken(req.Token)
+	emailChange, errFind := c.findEmailChangeToRevert(ctx, req.Token)
+	if errFind != nil {
+		return ctx.Status(errFind.Status).JSON(errFind)
+	}
+
+	if errExpired := c.checkRevertExpiration(ctx, emailChange); errExpired != nil {
+		c.userService.DeleteEmailChange(tokenHash)
+		return ctx.Status(errExpired.Status).JSON(errExpired)
+	}
+
+	// The old address could have been registered by another account since the change was confirmed
+	if errAvailable := c.checkEmailAvailable(emailChange.OldEmail); errAvailable != nil {
+		logger.Warning("Cannot revert email change, the old email is no longer available", log.F("username", emailChange.Username), log.F("email", emailChange.OldEmail))
+		c.audit(ctx, emailChange.Username, auditDTO.AUDIT_ACTION_EMAIL_CHANGE_REVERT, emailChange.OldEmail, false)
+		return ctx.Status(errAvailable.Status).JSON(errAvailable)
+	}
+
+	_, errUpdate := c.userService.Update(&userDTO.UserDTO{Username: emailChange.Username, Email: emailChange.OldEmail})
+	if errUpdate != nil {
+		logger.Error("Error reverting email of user", log.F("username", emailChange.Username), log.F("error", errUpdate.Message))
+		return ctx.Status(errUpdate.Status).JSON(errUpdate)
+	}
+
+	if _, errDelete := c.userService.DeleteEmailChange(tokenHash); errDelete != nil {
+		logger.Error("Error deleting reverted email change", log.F("username", emailChange.Username), log.F("error", errDelete.Message))
+	}
+
+	// Whoever changed the email may still hold a session, so every session of the account is ended
+	c.revokeSessions(ctx, emailChange.Username)
+
+	c.audit(ctx, emailChange.Username, auditDTO.AUDIT_ACTION_EMAIL_CHANGE_REVERT, emailChange.OldEmail, true)
+	logger.Info("Email change reverted", log.F("username", emailChange.Username), log.F("email", emailChange.OldEmail))
+	return ctx.Status(fiber.StatusOK).JSON(&dto.MessageResponseDTO{
+		Message: fmt.Sprintf("The email address has been restored to %s.", emailChange.OldEmail),
+	})
+}
+
+func (c *AuthController) findEmailChangeToRevert(ctx *fiber.Ctx, token string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx.UserContext())
+
+	emailChange, errFind := c.userService.FindEmailChangeByRevertToken(utilsToken.HashToken(token))
+	if errFind != nil {
+		logger.Error("Error finding email change to revert", log.F("error", errFind.Message))
+		return nil, errFind
+	}
+	return emailChange, nil
+}
+
+func (c *AuthController) checkRevertExpiration(ctx *fiber.Ctx, emailChange *userDTO.EmailChangeDTO) *exception.ApiException {
+	if time.Now().After(emailChange.RevertExpiration) {
+		log.FromContext(ctx.UserContext()).Warning(fmt.Sprintf("Expired revert link used for user %s", emailChange.Username))
+		return exception.NewApiException(fiber.StatusBadRequest, "The revert link has expired")
+	}
+	return nil
+}
+
+// @Summary		Actividad reciente
+// @Description	Devuelve las acciones relevantes para la seguridad de la cuenta autenticada, como inicios de sesión, cambios de correo o imágenes eliminadas, de la más reciente a la más antigua. Incluye los intentos fallidos de inicio de sesión con su nombre de usuario
+// @Tags			auth
+// @Produce		json
+// @Param			lastId		query	string	false	"Identificador del último evento recibido para la paginación"
+// @Param			pageSize	query	int		false	"Cantidad de eventos a devolver (por defecto 20, máximo 100)"
+// @Security		CookieAuth
+// @Success		200	{object}	auditDTO.AuditCursorDTO	"Eventos de la cuenta"
+// @Failure		401	{object}	exception.ApiException	"Usuario no autenticado"
+// @Failure		500	{object}	exception.ApiException	"Ha ocurrido un error inesperado"
+// @Router			/auth/activity [get]
+func (c *AuthController) activity(ctx *fiber.Ctx) error {
+	logger := log.FromContext(ctx.UserContext())
+
+	logger.Info("GET /activity called")
+
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error(CLAIMS_NOT_FOUND_MSG)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, INVALID_AUTHENTIFICATION_MSG))
+	}
+
+	// An invalid page size falls back to the default one
+	pageSize, _ := strconv.ParseInt(ctx.Query("pageSize"), 10, 64)
+	activity, err := c.auditService.RecentActivity(claims.Username, ctx.Query("lastId"), pageSize)
+	if err != nil {
+		logger.Error("Error listing the activity of user", log.F("username", claims.Username), log.F("error", err.Message))
+		return ctx.Status(err.Status).JSON(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(activity)
+}
+
+// Records a security relevant action together with the origin of the request
+func (c *AuthController) audit(ctx *fiber.Ctx, actor, action, target string, success bool) {
+	c.auditService.Record(ctx.UserContext(), actor, action, target, ctx.IP(), ctx.Get(fiber.HeaderUserAgent), success)
+}
+
+func (c *AuthController) requestEmailChange(username, oldEmail, newEmail string) *exception.ApiException {
+	if errAvailable := c.checkEmailAvailable(newEmail); errAvailable != nil {
+		return errAvailable
+	}
+
+	code, err := c.codeGeneratorService.GenerateCode(PREFIX_EMAIL_CHANGE_GENERATOR, username)
+	if err != nil {
+		logger.Error("Error generating email change code", log.F("error", err.Error()))
+		return exception.NewApiException(fiber.StatusInternalServerError, "Error generating email change code")
+	}
+
+	emailChange := &userDTO.EmailChangeDTO{
+		Username: username,
+		OldEmail: oldEmail,
+		NewEmail: newEmail,
+	}
+	if _, errInsert := c.userService.InsertEmailChange(emailChange); errInsert != nil {
+		return errInsert
+	}
+
+	template := emailTemplate.EmailChangeTemplate{}
+	if errEmail := c.emailSenderService.SendEmail(code, newEmail, template); errEmail != nil {
+		logger.Error("Failed to send email change code", log.F("email", newEmail), log.F("error", errEmail.Error()))
+		return exception.NewApiException(fiber.StatusInternalServerError, "Error sending email change confirmation")
+	}
+
+	logger.Info("Email change code sent successfully", log.F("email", newEmail))
+	return nil
+}
+
+func (c *AuthController) checkEmailAvailable(email string) *exception.ApiException {
+	_, errFind := c.userService.FindByEmail(email)
+	if errFind == nil {
+		return exception.NewApiException(fiber.StatusBadRequest, "Email is already registered")
+	}
+	if errFind.Status != fiber.StatusNotFound {
+		return errFind
+	}
+	return nil
+}
+
+// Returns a 429 exception and sets the Retry-After header when any of the keys is blocked
+func (c *AuthController) checkAttempts(ctx *fiber.Ctx, keys ...string) *exception.ApiException {
+	logger := log.FromContext(ctx.UserContext())
+
+	retryAfter, err := c.attemptService.Check(keys...)
+	if err != nil {
+		logger.Error("Error checking failed attempts", log.F("error", err.Error()))
+		return nil
+	}
+
+	if retryAfter <= 0 {
+		return nil
+	}
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	logger.Warning("Request blocked by too many failed attempts", log.F("retry_after_seconds", seconds))
+
+	return exception.NewApiException(fiber.StatusTooManyRequests, fmt.Sprintf("Too many failed attempts, please try again in %d seconds", seconds))
+}
+
+func (c *AuthController) registerFailedAttempt(keys ...string) {
+	if _, err := c.attemptService.RegisterFailure(keys...); err != nil {
+		logger.Error("Error registering failed attempt", log.F("error", err.Error()))
+	}
+}
+
+func (c *AuthController) resetAttempts(keys ...string) {
+	if err := c.attemptService.Reset(keys...); err != nil {
+		logger.Error("Error resetting failed attempts", log.F("error", err.Error()))
+	}
+}
@@ -0,0 +1,102 @@
+package userController
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gallery/src/commons/configurator/configuration"
+	utilsToken "go-gallery/src/commons/utils/token"
+	"go-gallery/src/infrastructure/auth"
+	userMiddleware "go-gallery/src/infrastructure/controller/user/middlewares"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	auditRepository "go-gallery/src/infrastructure/repository/audit"
+	"go-gallery/src/infrastructure/repository/user/userTest"
+	auditService "go-gallery/src/service/audit"
+	userService "go-gallery/src/service/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	TEST_SECRET       = "test-secret"
+	TEST_REVERT_TOKEN = "revert-token"
+	TEST_OLD_EMAIL    = "alice@example.com"
+	TEST_NEW_EMAIL    = "attacker@example.com"
+)
+
+func newRevertApp(t *testing.T, revertExpiration time.Time) (*fiber.App, *userTest.Repository) {
+	log.Init(log.NewConsoleLogger())
+
+	repository := userTest.NewRepository(&userDTO.UserDTO{
+		Username: "alice",
+		Password: "alice-password",
+		Email:    TEST_NEW_EMAIL,
+		Verified: true,
+	})
+	_, err := repository.InsertEmailChange(&userDTO.EmailChangeDTO{Username: "alice", OldEmail: TEST_OLD_EMAIL, NewEmail: TEST_NEW_EMAIL})
+	require.Nil(t, err)
+	_, err = repository.ConfirmEmailChange(&userDTO.EmailChangeDTO{
+		Username:         "alice",
+		RevertTokenHash:  utilsToken.HashToken(TEST_REVERT_TOKEN),
+		RevertExpiration: revertExpiration,
+	})
+	require.Nil(t, err)
+
+	service := userService.NewUserService(repository, 0)
+	jwtMiddleware := userMiddleware.NewJWTMiddleware(auth.NewJWTTokenManager(TEST_SECRET), service)
+	audit := auditService.NewAuditService(auditRepository.NewAuditMemoryRepository(map[string]string{}))
+	controller := NewAuthController(service, nil, nil, nil, nil, audit, jwtMiddleware, nil, nil,
+		configuration.VerificationConfiguration{}, configuration.EmailChangeConfiguration{}, configuration.RecoveryConfiguration{})
+
+	app := fiber.New()
+	controller.SetUpRoutes(app.Group("/api/auth"))
+	return app, repository
+}
+
+func currentEmail(t *testing.T, repository *userTest.Repository) string {
+	user, found := repository.User("alice")
+	require.True(t, found)
+	return user.Email
+}
+
+func TestRevertEmailChangeLinkOnlyDescribesTheChange(t *testing.T) {
+	app, repository := newRevertApp(t, time.Now().Add(time.Hour))
+
+	response, err := app.Test(httptest.NewRequest("GET", "/api/auth/revert-email-change?token="+TEST_REVERT_TOKEN, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, response.StatusCode)
+	assert.Equal(t, TEST_NEW_EMAIL, currentEmail(t, repository))
+}
+
+func TestRevertEmailChangeAppliesTheChangeOnPost(t *testing.T) {
+	app, repository := newRevertApp(t, time.Now().Add(time.Hour))
+
+	request := httptest.NewRequest("POST", "/api/auth/revert-email-change", strings.NewReader(`{"token":"`+TEST_REVERT_TOKEN+`"}`))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	response, err := app.Test(request)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, response.StatusCode)
+	assert.Equal(t, TEST_OLD_EMAIL, currentEmail(t, repository))
+}
+
+func TestRevertEmailChangeRejectsExpiredLink(t *testing.T) {
+	app, repository := newRevertApp(t, time.Now().Add(-time.Hour))
+
+	response, err := app.Test(httptest.NewRequest("GET", "/api/auth/revert-email-change?token="+TEST_REVERT_TOKEN, nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, response.StatusCode)
+
+	request := httptest.NewRequest("POST", "/api/auth/revert-email-change", strings.NewReader(`{"token":"`+TEST_REVERT_TOKEN+`"}`))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	response, err = app.Test(request)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, TEST_NEW_EMAIL, currentEmail(t, repository))
+}
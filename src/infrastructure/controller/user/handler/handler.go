@@ -2,13 +2,15 @@ package userHandler
 
 import (
 	"go-gallery/src/commons/exception"
+	passwordValidator "go-gallery/src/commons/utils/validations/password"
 	"net/http"
 	"regexp"
 )
 
-func ProcessUser(password, email string) *exception.ApiException {
+// ProcessUser valida la contraseña contra la política configurada y el formato del email, los campos vacíos no se validan
+func ProcessUser(validator *passwordValidator.Validator, username, password, email string) *exception.ApiException {
 	if password != "" {
-		err := ValidatePassword(password)
+		err := validator.Validate(password, username, email)
 		if err != nil {
 			return err
 		}
@@ -24,24 +26,6 @@ func ProcessUser(password, email string) *exception.ApiException {
 	return nil
 }
 
-func ValidatePassword(password string) *exception.ApiException {
-	if len(password) < 8 {
-		return exception.NewApiException(http.StatusBadRequest, "The password must be at least 8 characters long")
-	}
-
-	hasUppercase := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	if !hasUppercase {
-		return exception.NewApiException(http.StatusBadRequest, "The password must contain at least one uppercase letter")
-	}
-
-	hasSpecialChar := regexp.MustCompile(`[!@#$%^&*(),.?":{}|<>]`).MatchString(password)
-	if !hasSpecialChar {
-		return exception.NewApiException(http.StatusBadRequest, "The password must contain at least one special character")
-	}
-
-	return nil
-}
-
 func ValidateEmail(email string) *exception.ApiException {
 	const emailPattern = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
 	re := regexp.MustCompile(emailPattern)
@@ -3,6 +3,8 @@ package userHandler
 import (
 	"testing"
 
+	"go-gallery/src/commons/configurator/configuration"
+	passwordValidator "go-gallery/src/commons/utils/validations/password"
 	userDTO "go-gallery/src/infrastructure/dto/user"
 
 	"github.com/stretchr/testify/assert"
@@ -16,10 +18,17 @@ type testCaseUserHandler struct {
 
 func TestProcessUser(t *testing.T) {
 	cases := loadTestCasesUserHandler()
+	validator := passwordValidator.NewValidator(configuration.PasswordPolicyConfiguration{
+		MinLength:        8,
+		MaxLength:        72,
+		RequireUppercase: true,
+		RequireSpecial:   true,
+		ForbidUserData:   true,
+	}, nil)
 
 	for _, testCase := range cases {
 		t.Run(testCase.desc, func(t *testing.T) {
-			err := ProcessUser(testCase.dto.Password, testCase.dto.Email)
+			err := ProcessUser(validator, testCase.dto.Username, testCase.dto.Password, testCase.dto.Email)
 
 			if testCase.expects == "" {
 				assert.Nil(t, err, testCase.desc)
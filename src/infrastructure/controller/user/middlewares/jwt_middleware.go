@@ -3,9 +3,11 @@ package userMiddleware
 import (
 	"go-gallery/src/commons/exception"
 	"go-gallery/src/infrastructure/auth"
+	"go-gallery/src/infrastructure/controller/middlewares"
 	userDTO "go-gallery/src/infrastructure/dto/user"
 	log "go-gallery/src/infrastructure/logger"
 	userService "go-gallery/src/service/user"
+	"slices"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -31,6 +33,8 @@ func NewJWTMiddleware(tokenManager auth.TokenManager, userService *userService.U
 // Middleware to validate the JWT cookie
 func (auth *JWTMiddleware) Handler() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
+		logger := middlewares.Logger(ctx)
+
 		// Get the JWT token from the cookie
 		cookie := ctx.Cookies(COOKIE_NAME)
 		if cookie == "" {
@@ -41,33 +45,87 @@ func (auth *JWTMiddleware) Handler() fiber.Handler {
 		// Get claims from the token
 		claims, err := auth.tokenManager.ValidateToken(cookie)
 		if err != nil {
-			logger.Error("Failed to parse JWT claims: " + err.Message)
+			logger.Error("Failed to parse JWT claims", log.F("error", err.Message))
+			return ctx.Status(err.Status).JSON(err)
+		}
+		// Validate that claims are a correct in user database and the account has not been disabled
+		if _, err := auth.validateUserClaims(logger, claims); err != nil {
 			return ctx.Status(err.Status).JSON(err)
 		}
-		// Validate that claims are a correct in user database
-		auth.validateUserClaims(claims)
 
 		// Check expiration and renew the token if there are less than 10 minutes remaining
 		if claims.Expiration-time.Now().Unix() < 600 {
-			newToken, err := auth.tokenManager.CreateToken(claims.Username, claims.Email)
+			newToken, err := auth.tokenManager.CreateToken(claims.Username, claims.Email, claims.Role, claims.SessionVersion)
 			if err != nil {
-				logger.Error("Failed to create a new JWT token: " + err.Message)
+				logger.Error("Failed to create a new JWT token", log.F("error", err.Message))
 				return ctx.Status(fiber.StatusInternalServerError).JSON(err)
 			}
 			auth.createCookie(ctx, newToken)
 		}
 
-		// Save the user in the context
+		// Save the user in the context and tag the rest of the request logs with it
 		ctx.Locals("user", claims)
+		middlewares.AddLogFields(ctx, log.F("username", claims.Username))
+
+		return ctx.Next()
+	}
+}
+
+// Middleware to reject users whose email has not been verified, it must run after Handler
+func (auth *JWTMiddleware) VerifiedHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		logger := middlewares.Logger(ctx)
+
+		claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+		if !ok {
+			logger.Error("No user claims found")
+			return ctx.Status(fiber.StatusUnauthorized).JSON(exception.NewApiException(fiber.StatusUnauthorized, "User not authenticated"))
+		}
+
+		user, err := auth.findActiveUser(logger, claims)
+		if err != nil {
+			return ctx.Status(err.Status).JSON(err)
+		}
+
+		if !user.Verified {
+			logger.Warning("Access denied to unverified user", log.F("username", claims.Username))
+			return ctx.Status(fiber.StatusForbidden).JSON(exception.NewApiException(fiber.StatusForbidden, "The email address of this account has not been verified"))
+		}
+
+		return ctx.Next()
+	}
+}
 
+// Middleware to restrict the routes to users with one of the given roles, it must run after Handler.
+// The role is read from the stored user so a role change applies without waiting for the token to expire
+func (auth *JWTMiddleware) RoleHandler(roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if err := auth.checkRole(ctx, roles); err != nil {
+			return ctx.Status(err.Status).JSON(err)
+		}
 		return ctx.Next()
 	}
 }
 
-func (auth *JWTMiddleware) CreateJWTToken(ctx *fiber.Ctx, username, email string) *exception.ApiException {
-	t, err := auth.tokenManager.CreateToken(username, email)
+// Same as RoleHandler but only applied to the requests that modify data, read requests are always allowed
+func (auth *JWTMiddleware) WriteRoleHandler(roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		switch ctx.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return ctx.Next()
+		}
+
+		if err := auth.checkRole(ctx, roles); err != nil {
+			return ctx.Status(err.Status).JSON(err)
+		}
+		return ctx.Next()
+	}
+}
+
+func (auth *JWTMiddleware) CreateJWTToken(ctx *fiber.Ctx, username, email, role string, sessionVersion int64) *exception.ApiException {
+	t, err := auth.tokenManager.CreateToken(username, email, role, sessionVersion)
 	if err != nil {
-		logger.Error("Error creating JWT token: " + err.Message)
+		middlewares.Logger(ctx).Error("Error creating JWT token: " + err.Message)
 		return err
 	}
 	auth.createCookie(ctx, t)
@@ -86,13 +144,11 @@ func (auth *JWTMiddleware) DeleteAuthCookie(ctx *fiber.Ctx) {
 		SameSite: "Lax",
 	})
 
-	logger.Info("Auth cookie deleted successfully")
+	middlewares.Logger(ctx).Info("Auth cookie deleted successfully")
 }
 
-func (auth *JWTMiddleware) validateUserClaims(claims *userDTO.JwtClaimsDTO) (*userDTO.JwtClaimsDTO, *exception.ApiException) {
-	_, errUser := auth.userService.FindAndCheckJWT(claims)
-	if errUser != nil {
-		logger.Error("User validation failed: " + errUser.Message)
+func (auth *JWTMiddleware) validateUserClaims(logger log.Logger, claims *userDTO.JwtClaimsDTO) (*userDTO.JwtClaimsDTO, *exception.ApiException) {
+	if _, errUser := auth.findActiveUser(logger, claims); errUser != nil {
 		return nil, errUser
 	}
 
@@ -100,6 +156,53 @@ func (auth *JWTMiddleware) validateUserClaims(claims *userDTO.JwtClaimsDTO) (*us
 	return claims, nil
 }
 
+func (auth *JWTMiddleware) checkRole(ctx *fiber.Ctx, roles []string) *exception.ApiException {
+	logger := middlewares.Logger(ctx)
+
+	claims, ok := ctx.Locals("user").(*userDTO.JwtClaimsDTO)
+	if !ok {
+		logger.Error("No user claims found")
+		return exception.NewApiException(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	user, err := auth.findActiveUser(logger, claims)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(roles, user.Role) {
+		logger.Warning("Access denied by role", log.F("username", claims.Username), log.F("role", user.Role), log.F("method", ctx.Method()), log.F("route", ctx.Path()))
+		return exception.NewApiException(fiber.StatusForbidden, "You do not have permission to perform this action")
+	}
+
+	return nil
+}
+
+// Finds the user of the claims and rejects the sessions of deleted users, changed emails, accounts disabled by an administrator and accounts scheduled for deletion
+func (auth *JWTMiddleware) findActiveUser(logger log.Logger, claims *userDTO.JwtClaimsDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	user, err := auth.userService.FindAndCheckJWT(claims)
+	if err != nil {
+		logger.Error("User validation failed", log.F("error", err.Message))
+		// A deleted user or a changed email means the token no longer belongs to a valid session
+		if err.Status != fiber.StatusInternalServerError {
+			return nil, exception.NewApiException(fiber.StatusUnauthorized, "The session is no longer valid")
+		}
+		return nil, err
+	}
+
+	if user.Disabled {
+		logger.Warning("Access denied to disabled user", log.F("username", claims.Username))
+		return nil, exception.NewApiException(fiber.StatusForbidden, "This account has been disabled")
+	}
+
+	if user.DeletionScheduledAt != nil {
+		logger.Warning("Access denied to user scheduled for deletion", log.F("username", claims.Username))
+		return nil, exception.NewApiException(fiber.StatusForbidden, "This account is scheduled for deletion")
+	}
+
+	return user, nil
+}
+
 func (auth *JWTMiddleware) createCookie(ctx *fiber.Ctx, token string) {
 	ctx.Cookie(&fiber.Cookie{
 		Name:     COOKIE_NAME,
@@ -110,5 +213,5 @@ func (auth *JWTMiddleware) createCookie(ctx *fiber.Ctx, token string) {
 		SameSite: "Lax",
 	})
 
-	logger.Info("Auth cookie created successfully")
+	middlewares.Logger(ctx).Info("Auth cookie created successfully")
 }
@@ -1,143 +1,196 @@
 package userMiddleware
 
-// TODO Cambiar en el futuro por un mock (mockery) ahora fallan por que el userService se tiene que mockear para que no de un
-// nil pointer exception
-
-// import (
-// 	"go-gallery/src/infrastructure/auth"
-// 	log "go-gallery/src/infrastructure/logger"
-// 	"net/http"
-// 	"net/http/httptest"
-// 	"testing"
-// 	"time"
-
-// 	"github.com/gofiber/fiber/v2"
-// 	jtoken "github.com/golang-jwt/jwt/v5"
-// 	"github.com/stretchr/testify/assert"
-// )
-
-// const (
-// 	EMAIL_EXAMPLE    = "test@example.com"
-// 	USERNAME_EXAMPLE = "testuser"
-// 	SECRET           = "testsecret"
-// 	TEST_ROUTE       = "/test"
-// )
-
-// func beforeAll() {
-// 	logger = log.Init(log.NewConsoleLogger())
-// }
-
-// func setupApp(secret string, handler fiber.Handler) (*fiber.App, *JWTMiddleware, *auth.JWTTokenManager) {
-// 	tokenManager := auth.NewJWTTokenManager(secret)
-//
-// 	middleware := NewJWTMiddleware(tokenManager, nil)
-// 	app := fiber.New()
-// 	app.Post(TEST_ROUTE, middleware.Handler(), handler)
-// 	return app, middleware, tokenManager
-// }
-
-// func sendTestRequest(app *fiber.App, token string) (*http.Response, error) {
-// 	req := httptest.NewRequest("POST", TEST_ROUTE, nil)
-// 	if token != "" {
-// 		req.Header.Set("Cookie", COOKIE_NAME+"="+token)
-// 	}
-// 	return app.Test(req)
-// }
-
-// func TestHandlerWithValidToken(t *testing.T) {
-// 	beforeAll()
-// 	app, middleware, _ := setupApp(SECRET, func(c *fiber.Ctx) error {
-// 		return c.SendString("Authenticated")
-// 	})
-
-// 	validToken, apiErr := middleware.tokenManager.CreateToken(USERNAME_EXAMPLE, EMAIL_EXAMPLE)
-// 	assert.Nil(t, apiErr)
-
-// 	resp, err := sendTestRequest(app, validToken)
-// 	assert.NoError(t, err)
-// 	assert.Equal(t, http.StatusOK, resp.StatusCode)
-// }
-
-// func TestHandlerWithNoToken(t *testing.T) {
-// 	beforeAll()
-// 	app, _, _ := setupApp(SECRET, func(c *fiber.Ctx) error {
-// 		return c.SendString("Authenticated")
-// 	})
-
-// 	resp, err := sendTestRequest(app, "")
-// 	assert.NoError(t, err)
-// 	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
-// }
-
-// func TestHandlerWithInvalidToken(t *testing.T) {
-// 	beforeAll()
-// 	app, _, _ := setupApp(SECRET, func(c *fiber.Ctx) error {
-// 		return c.SendString("Authenticated")
-// 	})
-
-// 	resp, err := sendTestRequest(app, "invalidtokenformat")
-// 	assert.NoError(t, err)
-// 	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
-// }
-
-// func TestHandlerWithExpiringTokenAndShouldRenew(t *testing.T) {
-// 	beforeAll()
-// 	app, _, _ := setupApp(SECRET, func(c *fiber.Ctx) error {
-// 		newCookie := c.Cookies(COOKIE_NAME)
-// 		assert.NotEmpty(t, newCookie, "Expected cookie to be renewed, but got empty")
-// 		return c.SendString("Authenticated")
-// 	})
-
-// 	// Creamos token que expira en 5 minutos para simular que está a punto de expirar
-// 	claims := jtoken.MapClaims{
-// 		"username": USERNAME_EXAMPLE,
-// 		"email":    EMAIL_EXAMPLE,
-// 		"exp":      time.Now().Add(5 * time.Minute).Unix(),
-// 		"iat":      time.Now().Unix(),
-// 	}
-// 	token := jtoken.NewWithClaims(jtoken.SigningMethodHS256, claims)
-// 	expiringToken, err := token.SignedString([]byte(SECRET))
-// 	assert.NoError(t, err)
-
-// 	resp, err := sendTestRequest(app, expiringToken)
-// 	assert.NoError(t, err)
-// 	assert.Equal(t, http.StatusOK, resp.StatusCode)
-// }
-
-// func TestDeleteAuthCookie(t *testing.T) {
-// 	beforeAll()
-// 	app, middleware, tokenManager := setupApp(SECRET, func(c *fiber.Ctx) error {
-// 		return c.SendString("Authenticated")
-// 	})
-
-// 	validToken, apiErr := middleware.tokenManager.CreateToken(USERNAME_EXAMPLE, EMAIL_EXAMPLE)
-// 	assert.Nil(t, apiErr)
-
-// 	_, err := sendTestRequest(app, validToken)
-// 	assert.NoError(t, err)
-
-// 	tokenManagerMiddleware := NewJWTMiddleware(tokenManager, nil)
-// 	app.Post("/delete", func(c *fiber.Ctx) error {
-// 		tokenManagerMiddleware.DeleteAuthCookie(c)
-// 		return c.SendString("Cookie deleted")
-// 	})
-
-// 	req := httptest.NewRequest("POST", "/delete", nil)
-// 	req.Header.Set("Cookie", COOKIE_NAME+"="+validToken)
-
-// 	respDelete, err := app.Test(req)
-// 	assert.NoError(t, err)
-// 	assert.Equal(t, http.StatusOK, respDelete.StatusCode)
-
-// 	cookies := respDelete.Cookies()
-// 	var cookie *http.Cookie
-// 	for _, c := range cookies {
-// 		if c.Name == COOKIE_NAME {
-// 			cookie = c
-// 			break
-// 		}
-// 	}
-
-// 	assert.NotNil(t, cookie, "Expected cookie to be found in response")
-// 	assert.Equal(t, "", cookie.Value, "Expected cookie to be deleted (empty value)")
-// }
+import (
+	"go-gallery/src/infrastructure/auth"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	"go-gallery/src/infrastructure/repository/user/userTest"
+	userService "go-gallery/src/service/user"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	jtoken "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	EMAIL_EXAMPLE    = "test@example.com"
+	USERNAME_EXAMPLE = "testuser"
+	SECRET           = "testsecret"
+	TEST_ROUTE       = "/test"
+)
+
+func setupApp(t *testing.T, handler fiber.Handler) (*fiber.App, *JWTMiddleware, *userService.UserService, *userTest.Repository) {
+	log.Init(log.NewConsoleLogger())
+
+	repository := userTest.NewRepository(&userDTO.UserDTO{
+		Username: USERNAME_EXAMPLE,
+		Email:    EMAIL_EXAMPLE,
+		Role:     "user",
+		Verified: true,
+	})
+	service := userService.NewUserService(repository, time.Minute)
+	middleware := NewJWTMiddleware(auth.NewJWTTokenManager(SECRET), service)
+
+	app := fiber.New()
+	app.Post(TEST_ROUTE, middleware.Handler(), handler)
+	return app, middleware, service, repository
+}
+
+func authenticated(c *fiber.Ctx) error {
+	return c.SendString("Authenticated")
+}
+
+func sendTestRequest(t *testing.T, app *fiber.App, token string) *http.Response {
+	req := httptest.NewRequest("POST", TEST_ROUTE, nil)
+	if token != "" {
+		req.Header.Set("Cookie", COOKIE_NAME+"="+token)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func createToken(t *testing.T, middleware *JWTMiddleware, email string, sessionVersion int64) string {
+	token, err := middleware.tokenManager.CreateToken(USERNAME_EXAMPLE, email, "user", sessionVersion)
+	require.Nil(t, err)
+	return token
+}
+
+func TestHandlerWithValidToken(t *testing.T) {
+	app, middleware, _, _ := setupApp(t, authenticated)
+
+	resp := sendTestRequest(t, app, createToken(t, middleware, EMAIL_EXAMPLE, 0))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHandlerWithNoToken(t *testing.T) {
+	app, _, _, _ := setupApp(t, authenticated)
+
+	resp := sendTestRequest(t, app, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestHandlerWithInvalidToken(t *testing.T) {
+	app, _, _, _ := setupApp(t, authenticated)
+
+	resp := sendTestRequest(t, app, "invalidtokenformat")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestHandlerWithExpiringTokenAndShouldRenew(t *testing.T) {
+	app, _, _, _ := setupApp(t, authenticated)
+
+	// Token que expira en 5 minutos para simular que está a punto de expirar
+	claims := jtoken.MapClaims{
+		"username": USERNAME_EXAMPLE,
+		"email":    EMAIL_EXAMPLE,
+		"role":     "user",
+		"sv":       0,
+		"exp":      time.Now().Add(5 * time.Minute).Unix(),
+		"iat":      time.Now().Unix(),
+	}
+	expiringToken, err := jtoken.NewWithClaims(jtoken.SigningMethodHS256, claims).SignedString([]byte(SECRET))
+	require.NoError(t, err)
+
+	resp := sendTestRequest(t, app, expiringToken)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var renewed *http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == COOKIE_NAME {
+			renewed = cookie
+		}
+	}
+	require.NotNil(t, renewed, "Expected cookie to be renewed")
+	assert.NotEqual(t, expiringToken, renewed.Value)
+}
+
+func TestHandlerRejectsDeletedUser(t *testing.T) {
+	app, middleware, service, _ := setupApp(t, authenticated)
+	token := createToken(t, middleware, EMAIL_EXAMPLE, 0)
+	require.Equal(t, http.StatusOK, sendTestRequest(t, app, token).StatusCode)
+
+	_, err := service.DeleteByUsername(USERNAME_EXAMPLE)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, sendTestRequest(t, app, token).StatusCode)
+}
+
+func TestHandlerRejectsChangedEmail(t *testing.T) {
+	app, middleware, service, _ := setupApp(t, authenticated)
+	token := createToken(t, middleware, EMAIL_EXAMPLE, 0)
+	require.Equal(t, http.StatusOK, sendTestRequest(t, app, token).StatusCode)
+
+	_, err := service.Update(&userDTO.UserDTO{Username: USERNAME_EXAMPLE, Email: "new@example.com"})
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, sendTestRequest(t, app, token).StatusCode)
+	assert.Equal(t, http.StatusOK, sendTestRequest(t, app, createToken(t, middleware, "new@example.com", 0)).StatusCode)
+}
+
+func TestHandlerRejectsRevokedSession(t *testing.T) {
+	app, middleware, service, _ := setupApp(t, authenticated)
+	token := createToken(t, middleware, EMAIL_EXAMPLE, 0)
+	require.Equal(t, http.StatusOK, sendTestRequest(t, app, token).StatusCode)
+
+	_, err := service.RevokeSessions(USERNAME_EXAMPLE)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, sendTestRequest(t, app, token).StatusCode)
+	assert.Equal(t, http.StatusOK, sendTestRequest(t, app, createToken(t, middleware, EMAIL_EXAMPLE, 1)).StatusCode)
+}
+
+func TestHandlerRejectsDisabledUser(t *testing.T) {
+	app, middleware, service, _ := setupApp(t, authenticated)
+	token := createToken(t, middleware, EMAIL_EXAMPLE, 0)
+	require.Equal(t, http.StatusOK, sendTestRequest(t, app, token).StatusCode)
+
+	_, err := service.SetDisabled(USERNAME_EXAMPLE, true)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusForbidden, sendTestRequest(t, app, token).StatusCode)
+}
+
+func TestHandlerCachesValidationUntilUserChanges(t *testing.T) {
+	app, middleware, service, repository := setupApp(t, authenticated)
+	token := createToken(t, middleware, EMAIL_EXAMPLE, 0)
+
+	require.Equal(t, http.StatusOK, sendTestRequest(t, app, token).StatusCode)
+	require.Equal(t, http.StatusOK, sendTestRequest(t, app, token).StatusCode)
+	assert.Equal(t, 1, repository.JWTChecks(), "The second request should reuse the cached validation")
+
+	// Cualquier cambio del usuario invalida la caché y obliga a consultar el repositorio
+	_, err := service.RevokeSessions(USERNAME_EXAMPLE)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, sendTestRequest(t, app, token).StatusCode)
+	assert.Equal(t, 2, repository.JWTChecks())
+}
+
+func TestDeleteAuthCookie(t *testing.T) {
+	app, middleware, _, _ := setupApp(t, authenticated)
+	app.Post("/delete", func(c *fiber.Ctx) error {
+		middleware.DeleteAuthCookie(c)
+		return c.SendString("Cookie deleted")
+	})
+
+	req := httptest.NewRequest("POST", "/delete", nil)
+	req.Header.Set("Cookie", COOKIE_NAME+"="+createToken(t, middleware, EMAIL_EXAMPLE, 0))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == COOKIE_NAME {
+			cookie = c
+		}
+	}
+	require.NotNil(t, cookie, "Expected cookie to be found in response")
+	assert.Equal(t, "", cookie.Value, "Expected cookie to be deleted (empty value)")
+}
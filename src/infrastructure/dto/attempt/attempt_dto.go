@@ -0,0 +1,15 @@
+package attemptDTO
+
+import "time"
+
+// AttemptDTO representa el estado de los intentos fallidos asociados a una clave (cuenta o IP)
+type AttemptDTO struct {
+	// Clave a la que se asocian los intentos
+	Key string `json:"key" bson:"key"`
+
+	// Número de intentos fallidos consecutivos
+	Failures int `json:"failures" bson:"failures"`
+
+	// Fecha hasta la que la clave permanece bloqueada
+	BlockedUntil time.Time `json:"blocked_until" bson:"blocked_until"`
+}
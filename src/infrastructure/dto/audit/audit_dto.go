@@ -0,0 +1,80 @@
+package auditDTO
+
+import "time"
+
+const (
+	AUDIT_OUTCOME_SUCCESS string = "success"
+	AUDIT_OUTCOME_FAILURE string = "failure"
+)
+
+const (
+	AUDIT_ACTION_LOGIN                string = "login"
+	AUDIT_ACTION_LOGOUT               string = "logout"
+	AUDIT_ACTION_REGISTER             string = "register"
+	AUDIT_ACTION_ACCOUNT_UPDATE       string = "account_update"
+	AUDIT_ACTION_PASSWORD_RESET       string = "password_reset"
+	AUDIT_ACTION_EMAIL_VERIFY         string = "email_verify"
+	AUDIT_ACTION_EMAIL_CHANGE_REQUEST string = "email_change_request"
+	AUDIT_ACTION_EMAIL_CHANGE_CONFIRM string = "email_change_confirm"
+	AUDIT_ACTION_EMAIL_CHANGE_REVERT  string = "email_change_revert"
+	AUDIT_ACTION_DELETION_REQUEST     string = "deletion_request"
+	AUDIT_ACTION_DELETION_CONFIRM     string = "deletion_confirm"
+	AUDIT_ACTION_DELETION_CANCEL      string = "deletion_cancel"
+	AUDIT_ACTION_IMAGE_UPLOAD         string = "image_upload"
+	AUDIT_ACTION_IMAGE_UPDATE         string = "image_update"
+	AUDIT_ACTION_IMAGE_DELETE         string = "image_delete"
+	AUDIT_ACTION_ADMIN_USER_DELETE    string = "admin_user_delete"
+	AUDIT_ACTION_ADMIN_ROLE_CHANGE    string = "admin_role_change"
+	AUDIT_ACTION_ADMIN_USER_DISABLE   string = "admin_user_disable"
+	AUDIT_ACTION_ADMIN_USER_ENABLE    string = "admin_user_enable"
+	AUDIT_ACTION_ADMIN_PASSWORD_RESET string = "admin_password_reset"
+)
+
+// AuditEventDTO representa una acción relevante para la seguridad realizada sobre una cuenta
+// @Description Acción registrada con quién la hizo, sobre qué, desde dónde y su resultado
+type AuditEventDTO struct {
+	// Identificador del evento, ordenado por fecha
+	Id string `json:"id" bson:"_id" example:"0001760781234567890-9f2c1b7e"`
+
+	// Usuario que realiza la acción, o el indicado en un inicio de sesión fallido
+	Actor string `json:"actor" bson:"actor" example:"usuario123"`
+
+	// Acción realizada
+	Action string `json:"action" bson:"action" example:"login"`
+
+	// Elemento sobre el que se realiza la acción, como el identificador de una imagen
+	Target string `json:"target,omitempty" bson:"target,omitempty" example:"64a1f8b8e4b0c10d3c5b2e75"`
+
+	// Dirección IP de la petición
+	IP string `json:"ip" bson:"ip" example:"203.0.113.7"`
+
+	// Agente de usuario de la petición
+	UserAgent string `json:"userAgent" bson:"user_agent" example:"Mozilla/5.0"`
+
+	// Resultado de la acción (success o failure)
+	Outcome string `json:"outcome" bson:"outcome" example:"success"`
+
+	// Fecha del evento
+	Timestamp time.Time `json:"timestamp" bson:"timestamp" example:"2025-01-01T12:00:00Z"`
+}
+
+// AuditFilterDTO agrupa los filtros de una búsqueda de eventos, los campos vacíos no filtran
+type AuditFilterDTO struct {
+	Actor    string
+	Action   string
+	Outcome  string
+	From     *time.Time
+	To       *time.Time
+	LastId   string
+	PageSize int64
+}
+
+// AuditCursorDTO representa una página de eventos ordenados del más reciente al más antiguo
+// @Description Lista de eventos y el identificador del último para pedir la siguiente página
+type AuditCursorDTO struct {
+	// Eventos de la página
+	Events []AuditEventDTO `json:"events"`
+
+	// Identificador del último evento devuelto, se envía como lastId para obtener la siguiente página
+	LastId string `json:"lastId,omitempty" example:"0001760781234567890-9f2c1b7e"`
+}
@@ -0,0 +1,56 @@
+package avatarDTO
+
+import (
+	"strconv"
+	"time"
+)
+
+// AvatarDTO representa el avatar de un usuario ya renderizado en cada uno de sus tamaños
+type AvatarDTO struct {
+	// Usuario al que pertenece el avatar
+	Username string `json:"username" bson:"_id"`
+
+	// Imágenes WebP del avatar indexadas por su tamaño en píxeles
+	Images map[string][]byte `json:"-" bson:"images"`
+
+	// Fecha de la última actualización, se usa para invalidar las cachés
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Image devuelve el avatar en el tamaño indicado
+func (a *AvatarDTO) Image(size int) ([]byte, bool) {
+	image, found := a.Images[strconv.Itoa(size)]
+	return image, found
+}
+
+// AvatarCropDTO es el recorte cuadrado de la imagen original, sin tamaño se usa el mayor cuadrado centrado
+// @Description Recorte cuadrado en píxeles de la imagen original usado para el avatar
+type AvatarCropDTO struct {
+	// Coordenada horizontal de la esquina superior izquierda
+	X int `json:"x" form:"x" example:"120"`
+
+	// Coordenada vertical de la esquina superior izquierda
+	Y int `json:"y" form:"y" example:"40"`
+
+	// Lado del cuadrado recortado
+	Size int `json:"size" form:"size" example:"480"`
+}
+
+// AvatarFromImageRequestDTO representa la petición para usar una imagen de la galería como avatar
+// @Description Imagen de la galería del usuario y recorte usados para generar el avatar
+type AvatarFromImageRequestDTO struct {
+	AvatarCropDTO
+
+	// ID de la imagen de la galería
+	ImageID string `json:"image_id" example:"64a1f8b8e4b0c10d3c5b2e75"`
+}
+
+// AvatarResponseDTO contiene las direcciones públicas del avatar en cada tamaño
+// @Description Direcciones públicas del avatar indexadas por su tamaño en píxeles
+type AvatarResponseDTO struct {
+	// Direcciones del avatar indexadas por tamaño
+	URLs map[string]string `json:"urls"`
+
+	// Fecha de la última actualización
+	UpdatedAt time.Time `json:"updated_at" example:"2025-01-01T10:00:00Z"`
+}
@@ -0,0 +1,54 @@
+package exportDTO
+
+import "time"
+
+const (
+	EXPORT_STATUS_PENDING    = "pending"
+	EXPORT_STATUS_RUNNING    = "running"
+	EXPORT_STATUS_READY      = "ready"
+	EXPORT_STATUS_FAILED     = "failed"
+	EXPORT_STATUS_DOWNLOADED = "downloaded"
+	EXPORT_STATUS_EXPIRED    = "expired"
+)
+
+// ExportJobDTO representa una exportación de los datos de un usuario
+// @Description Estado de la generación del fichero zip con los datos de la cuenta y su progreso
+type ExportJobDTO struct {
+	// Identificador de la exportación
+	Id string `json:"id" example:"3f9a1c2b7d4e5f60"`
+
+	// Usuario propietario de los datos
+	Username string `json:"username" example:"usuario123"`
+
+	// Estado de la exportación: pending, running, ready, failed, downloaded o expired
+	Status string `json:"status" example:"running"`
+
+	// Imágenes procesadas
+	Processed int64 `json:"processed" example:"12"`
+
+	// Total de imágenes a exportar
+	Total int64 `json:"total" example:"40"`
+
+	// Porcentaje completado
+	Progress int `json:"progress" example:"30"`
+
+	// Motivo del fallo si el estado es failed
+	Error string `json:"error,omitempty" example:"Error reading the images"`
+
+	// Fecha de creación
+	CreatedAt time.Time `json:"created_at" example:"2025-01-01T10:00:00Z"`
+
+	// Fecha en la que el fichero quedó disponible
+	CompletedAt *time.Time `json:"completed_at,omitempty" example:"2025-01-01T10:05:00Z"`
+
+	// Fecha a partir de la cual el enlace de descarga deja de ser válido
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-01-02T10:05:00Z"`
+
+	// Ruta del fichero generado, nunca se expone
+	FilePath string `json:"-"`
+}
+
+// Active indica si la exportación todavía se está generando o puede descargarse
+func (dto *ExportJobDTO) Active() bool {
+	return dto.Status == EXPORT_STATUS_PENDING || dto.Status == EXPORT_STATUS_RUNNING || dto.Status == EXPORT_STATUS_READY
+}
@@ -0,0 +1,49 @@
+package healthDTO
+
+const (
+	HEALTH_STATUS_UP   string = "UP"
+	HEALTH_STATUS_DOWN string = "DOWN"
+)
+
+// HealthDTO representa el estado general del servicio
+// @Description Estado del servicio, UP si responde o todas sus dependencias están disponibles y DOWN en otro caso
+type HealthDTO struct {
+	// Estado del servicio
+	Status string `json:"status" example:"UP"`
+}
+
+// DependencyHealthDTO representa el resultado de comprobar una dependencia
+// @Description Estado de una dependencia, el tiempo que tardó en responder y el motivo si no está disponible
+type DependencyHealthDTO struct {
+	// Estado de la dependencia
+	Status string `json:"status" example:"UP"`
+
+	// Duración de la comprobación en milisegundos
+	DurationMs int64 `json:"durationMs" example:"3"`
+
+	// Motivo genérico del fallo, el error de la dependencia solo se registra en los logs
+	Error string `json:"error,omitempty" example:"Dependency unavailable"`
+}
+
+// ReadinessDTO representa el estado del servicio y el de cada una de sus dependencias
+// @Description Estado general y resultado de la comprobación de cada repositorio configurado
+type ReadinessDTO struct {
+	// Estado del servicio
+	Status string `json:"status" example:"UP"`
+
+	// Estado de cada dependencia por nombre
+	Dependencies map[string]DependencyHealthDTO `json:"dependencies"`
+}
+
+// VersionDTO representa la versión del servicio en ejecución
+// @Description Versión, commit y fecha de compilación del servicio
+type VersionDTO struct {
+	// Versión de la aplicación
+	AppVersion string `json:"appVersion" example:"1.0.0"`
+
+	// Commit a partir del que se compiló
+	CommitHash string `json:"commitHash" example:"f613588c811d765e151c80bd7cbc162ef2e9f2d7"`
+
+	// Fecha de compilación
+	BuildDate string `json:"buildDate" example:"10/04/2025 17:47:03"`
+}
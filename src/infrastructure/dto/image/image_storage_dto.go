@@ -0,0 +1,33 @@
+package imageDTO
+
+// StorageUsageDTO representa el almacenamiento ocupado por las imágenes de un usuario
+// @Description Número de imágenes y bytes almacenados por un usuario
+type StorageUsageDTO struct {
+	// Usuario propietario de las imágenes
+	Owner string `json:"owner" bson:"_id" example:"usuario123"`
+
+	// Número de imágenes almacenadas
+	Images int64 `json:"images" bson:"images" example:"12"`
+
+	// Bytes ocupados por las imágenes originales
+	ImagesBytes int64 `json:"images_bytes" bson:"images_bytes" example:"2048000"`
+
+	// Bytes ocupados por las miniaturas
+	ThumbnailsBytes int64 `json:"thumbnails_bytes" bson:"thumbnails_bytes" example:"102400"`
+
+	// Bytes totales ocupados
+	TotalBytes int64 `json:"total_bytes" bson:"-" example:"2150400"`
+}
+
+// StorageUsageReportDTO representa el almacenamiento ocupado en el sistema
+// @Description Almacenamiento total y desglosado por usuario
+type StorageUsageReportDTO struct {
+	// Almacenamiento por usuario, ordenado de mayor a menor
+	Owners []StorageUsageDTO `json:"owners"`
+
+	// Número total de imágenes
+	TotalImages int64 `json:"total_images" example:"120"`
+
+	// Bytes totales ocupados
+	TotalBytes int64 `json:"total_bytes" example:"21504000"`
+}
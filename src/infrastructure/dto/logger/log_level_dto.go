@@ -0,0 +1,60 @@
+package loggerDTO
+
+import (
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	log "go-gallery/src/infrastructure/logger"
+)
+
+// LogLevelsDTO representa el nivel mínimo de cada destino de los logs
+// @Description Nivel mínimo que escribe cada destino de los logs (console, file)
+type LogLevelsDTO struct {
+	// Nivel mínimo por destino
+	Levels map[string]string `json:"levels" example:"console:INFO,file:DEBUG"`
+}
+
+// LogLevelUpdateDTO representa la petición para cambiar el nivel de un destino de los logs
+// @Description Destino de los logs y su nuevo nivel mínimo (TRACE, DEBUG, INFO, WARNING, ERROR o PANIC)
+type LogLevelUpdateDTO struct {
+	// Destino de los logs
+	Sink string `json:"sink" example:"console"`
+
+	// Nuevo nivel mínimo
+	Level string `json:"level" example:"DEBUG"`
+}
+
+func ToLogLevelsDTO(levels map[string]loggerEntity.LogLevel) *LogLevelsDTO {
+	names := make(map[string]string, len(levels))
+	for sink, level := range levels {
+		names[sink] = level.String()
+	}
+	return &LogLevelsDTO{Levels: names}
+}
+
+// LogQueueStatsDTO representa el estado de la cola de mensajes de log
+// @Description Política de desbordamiento, ocupación de la cola y mensajes descartados desde el arranque
+type LogQueueStatsDTO struct {
+	// Política aplicada cuando la cola está llena (block, drop-oldest o drop-new)
+	OverflowPolicy string `json:"overflowPolicy" example:"drop-oldest"`
+
+	// Capacidad de la cola
+	BufferSize int `json:"bufferSize" example:"1000"`
+
+	// Mensajes pendientes de escribir
+	Queued int `json:"queued" example:"12"`
+
+	// Mensajes antiguos descartados para hacer sitio a otros nuevos
+	DroppedOldest uint64 `json:"droppedOldest" example:"0"`
+
+	// Mensajes nuevos descartados por encontrar la cola llena
+	DroppedNew uint64 `json:"droppedNew" example:"0"`
+}
+
+func ToLogQueueStatsDTO(stats log.AsyncStats) *LogQueueStatsDTO {
+	return &LogQueueStatsDTO{
+		OverflowPolicy: stats.OverflowPolicy,
+		BufferSize:     stats.BufferSize,
+		Queued:         stats.Queued,
+		DroppedOldest:  stats.DroppedOldest,
+		DroppedNew:     stats.DroppedNew,
+	}
+}
@@ -1,8 +1,10 @@
 package userDTO
 
 type JwtClaimsDTO struct {
-	Username   string `json:"username"`
-	Email      string `json:"email"`
-	IssuedAt   int64  `json:"firstname"`
-	Expiration int64  `json:"expiration"`
+	Username       string `json:"username"`
+	Email          string `json:"email"`
+	Role           string `json:"role"`
+	IssuedAt       int64  `json:"firstname"`
+	Expiration     int64  `json:"expiration"`
+	SessionVersion int64  `json:"sessionVersion"`
 }
@@ -0,0 +1,75 @@
+package userDTO
+
+import "time"
+
+// UserSummaryDTO representa los datos de un usuario visibles para un administrador
+// @Description Datos de un usuario sin información sensible
+type UserSummaryDTO struct {
+	// Nombre de usuario
+	Username string `json:"username" example:"usuario123"`
+
+	// Correo electrónico
+	Email string `json:"email" example:"usuario@example.com"`
+
+	// Nombre
+	Firstname string `json:"firstname" example:"Juan"`
+
+	// Apellido
+	Lastname string `json:"lastname" example:"Pérez"`
+
+	// Rol del usuario
+	Role string `json:"role" example:"user"`
+
+	// Indica si el correo electrónico ha sido verificado
+	Verified bool `json:"verified" example:"true"`
+
+	// Indica si la cuenta está deshabilitada
+	Disabled bool `json:"disabled" example:"false"`
+
+	// Fecha en la que se eliminará la cuenta, solo si el usuario ha solicitado su borrado
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" example:"2025-01-08T10:00:00Z"`
+}
+
+// UserCursorDTO representa un cursor de paginación para los usuarios
+// @Description Contiene una lista de usuarios y el nombre del último usuario para la paginación.
+type UserCursorDTO struct {
+	// Lista de usuarios
+	Users []UserSummaryDTO `json:"users"`
+
+	// Nombre del último usuario para la paginación
+	LastUsername string `json:"lastUsername,omitempty" example:"usuario123"`
+}
+
+// UserRoleUpdateDTO representa la petición para cambiar el rol de un usuario
+// @Description Nuevo rol del usuario (user, admin o read-only)
+type UserRoleUpdateDTO struct {
+	// Nuevo rol
+	Role string `json:"role" example:"read-only"`
+}
+
+func ToUserSummary(dto *UserDTO) UserSummaryDTO {
+	return UserSummaryDTO{
+		Username:  dto.Username,
+		Email:     dto.Email,
+		Firstname: dto.Firstname,
+		Lastname:  dto.Lastname,
+		Role:      dto.Role,
+		Verified:  dto.Verified,
+		Disabled:  dto.Disabled,
+
+		DeletionScheduledAt: dto.DeletionScheduledAt,
+	}
+}
+
+// UserDetailDTO representa los datos de un usuario junto con su almacenamiento ocupado
+// @Description Datos de un usuario con el número de imágenes y los bytes que ocupan
+type UserDetailDTO struct {
+	// Datos del usuario
+	User UserSummaryDTO `json:"user"`
+
+	// Número de imágenes del usuario
+	Images int64 `json:"images" example:"42"`
+
+	// Bytes ocupados por las imágenes y sus miniaturas
+	TotalBytes int64 `json:"total_bytes" example:"10485760"`
+}
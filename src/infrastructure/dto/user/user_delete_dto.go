@@ -1,5 +1,7 @@
 package userDTO
 
+import "time"
+
 // UserDeleteDTO representa los datos requeridos para eliminar un usuario
 // @Description Datos necesarios para proceder con la eliminación del usuario
 type UserDeleteDTO struct {
@@ -9,3 +11,30 @@ type UserDeleteDTO struct {
 	// Código de verificación para la eliminación
 	Code string `json:"code" example:"123456"`
 }
+
+// UserDeleteScheduledDTO representa la respuesta al confirmar la eliminación de la cuenta
+// @Description La cuenta se eliminará al terminar el periodo de gracia salvo que se cancele desde el enlace enviado por correo
+type UserDeleteScheduledDTO struct {
+	// Mensaje de confirmación
+	Message string `json:"message" example:"The account of usuario123 will be deleted on 2025-01-08T10:00:00Z."`
+
+	// Fecha en la que se eliminarán definitivamente la cuenta y sus imágenes
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at" example:"2025-01-08T10:00:00Z"`
+}
+
+// UserDeleteCancelDTO representa la petición para cancelar la eliminación de la cuenta
+// @Description Token recibido en el enlace de cancelación
+type UserDeleteCancelDTO struct {
+	// Token incluido en el enlace de cancelación
+	Token string `json:"token" example:"eyJ1c2VybmFtZSI6InVzdWFyaW8xMjMifQ.c2lnbmF0dXJl"`
+}
+
+// UserDeleteCancelLinkDTO representa un enlace de cancelación válido
+// @Description Cuenta cuya eliminación se cancelará al confirmar y fecha en la que se eliminaría
+type UserDeleteCancelLinkDTO struct {
+	// Nombre de usuario
+	Username string `json:"username" example:"usuario123"`
+
+	// Fecha en la que se eliminarán definitivamente la cuenta y sus imágenes si no se cancela
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at" example:"2025-01-08T10:00:00Z"`
+}
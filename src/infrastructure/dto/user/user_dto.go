@@ -2,6 +2,7 @@ package userDTO
 
 import (
 	userEntity "go-gallery/src/domain/entities/user"
+	"time"
 )
 
 // UserDTO Representa los datos del usuario
@@ -26,6 +27,21 @@ type UserDTO struct {
 	// Nombre
 	// example "Juan"
 	Firstname string `json:"firstname" bson:"firstname" example:"Juan"`
+
+	// Indica si el correo electrónico ha sido verificado (gestionado por el servidor)
+	Verified bool `json:"-" bson:"verified"`
+
+	// Rol del usuario (gestionado por el servidor)
+	Role string `json:"-" bson:"role"`
+
+	// Indica si la cuenta ha sido deshabilitada por un administrador (gestionado por el servidor)
+	Disabled bool `json:"-" bson:"disabled"`
+
+	// Versión de las sesiones, al incrementarse se invalidan todos los JWT emitidos (gestionado por el servidor)
+	SessionVersion int64 `json:"-" bson:"session_version"`
+
+	// Fecha a partir de la cual se eliminará la cuenta si el usuario no cancela el borrado (gestionado por el servidor)
+	DeletionScheduledAt *time.Time `json:"-" bson:"deletion_scheduled_at,omitempty"`
 }
 
 func FromUser(user *userEntity.User) *UserDTO {
@@ -35,5 +51,11 @@ func FromUser(user *userEntity.User) *UserDTO {
 		Email:     user.GetEmail(),
 		Lastname:  user.GetLastname(),
 		Firstname: user.GetFirstname(),
+		Verified:  user.IsVerified(),
+		Role:      user.GetRole(),
+		Disabled:  user.IsDisabled(),
+
+		SessionVersion:      user.GetSessionVersion(),
+		DeletionScheduledAt: user.GetDeletionScheduledAt(),
 	}
 }
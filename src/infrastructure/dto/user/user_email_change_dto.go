@@ -0,0 +1,51 @@
+package userDTO
+
+import "time"
+
+// EmailChangeDTO representa una solicitud de cambio de correo electrónico pendiente o confirmada
+type EmailChangeDTO struct {
+	// Nombre de usuario que solicita el cambio
+	Username string `json:"username" bson:"username"`
+
+	// Correo electrónico anterior al cambio
+	OldEmail string `json:"old_email" bson:"old_email"`
+
+	// Nuevo correo electrónico solicitado
+	NewEmail string `json:"new_email" bson:"new_email"`
+
+	// Indica si el nuevo correo electrónico ha sido confirmado
+	Confirmed bool `json:"confirmed" bson:"confirmed"`
+
+	// Hash del token que permite revertir el cambio desde el correo anterior
+	RevertTokenHash string `json:"-" bson:"revert_token_hash"`
+
+	// Fecha límite para revertir el cambio
+	RevertExpiration time.Time `json:"revert_expiration" bson:"revert_expiration"`
+}
+
+// EmailChangeConfirmDTO representa la estructura para confirmar el cambio de correo electrónico
+// @Description Datos requeridos para confirmar el cambio de correo electrónico
+type EmailChangeConfirmDTO struct {
+	// Código de verificación enviado al nuevo correo electrónico
+	Code string `json:"code" example:"123456"`
+}
+
+// EmailChangeRevertDTO representa la petición para revertir un cambio de correo electrónico
+// @Description Token recibido en el enlace enviado al correo anterior
+type EmailChangeRevertDTO struct {
+	// Token incluido en el enlace para revertir el cambio
+	Token string `json:"token" example:"b3f1c2d4e5a6978812345678abcdef00"`
+}
+
+// EmailChangeRevertLinkDTO representa un enlace válido para revertir un cambio de correo electrónico
+// @Description Cambio que se revertirá al confirmar y fecha límite para hacerlo
+type EmailChangeRevertLinkDTO struct {
+	// Nombre de usuario de la cuenta
+	Username string `json:"username" example:"usuario123"`
+	// Correo electrónico que se restablecerá
+	OldEmail string `json:"old_email" example:"usuario@example.com"`
+	// Correo electrónico que se sustituirá
+	NewEmail string `json:"new_email" example:"nuevo@example.com"`
+	// Fecha límite para revertir el cambio
+	RevertExpiration time.Time `json:"revert_expiration" example:"2025-01-01T00:00:00Z"`
+}
@@ -0,0 +1,23 @@
+package userDTO
+
+// UserIdentityDTO representa la vinculación de un usuario con una identidad de un proveedor externo
+type UserIdentityDTO struct {
+	// Nombre del proveedor externo (google, github...)
+	Provider string `json:"provider" bson:"provider"`
+
+	// Identificador del usuario en el proveedor externo
+	Subject string `json:"subject" bson:"subject"`
+
+	// Nombre de usuario local vinculado
+	Username string `json:"username" bson:"username"`
+}
+
+// OAuthProviderDTO representa un proveedor externo disponible para iniciar sesión
+// @Description Proveedor externo habilitado para iniciar sesión
+type OAuthProviderDTO struct {
+	// Nombre del proveedor
+	Name string `json:"name" example:"google"`
+
+	// URL para iniciar sesión con el proveedor
+	LoginURL string `json:"login_url" example:"/api/auth/oauth/google/login"`
+}
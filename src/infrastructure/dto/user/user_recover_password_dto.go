@@ -18,3 +18,21 @@ type PasswordRecoveryConfirmDTO struct {
 	// Nueva contraseña del usuario
 	NewPassword string `json:"newPassword" example:"NuevaContraseñaSegura."`
 }
+
+// PasswordResetLinkConfirmDTO representa la petición para restablecer la contraseña con un enlace de recuperación
+// @Description Token recibido en el enlace de recuperación y nueva contraseña
+type PasswordResetLinkConfirmDTO struct {
+	// Token incluido en el enlace de recuperación
+	Token string `json:"token" example:"eyJ1c2VybmFtZSI6InVzdWFyaW8xMjMifQ.c2lnbmF0dXJl"`
+	// Nueva contraseña del usuario
+	NewPassword string `json:"newPassword" example:"NuevaContraseñaSegura."`
+}
+
+// PasswordResetLinkDTO representa un enlace de recuperación válido
+// @Description Usuario al que pertenece el enlace de recuperación y fecha de caducidad en segundos Unix
+type PasswordResetLinkDTO struct {
+	// Nombre de usuario
+	Username string `json:"username" example:"usuario123"`
+	// Fecha de caducidad del enlace
+	Expiration int64 `json:"expiration" example:"1735689600"`
+}
@@ -0,0 +1,17 @@
+package userDTO
+
+// UserVerificationRequestDTO representa la estructura para verificar el correo electrónico de una cuenta
+// @Description Datos requeridos para confirmar el correo electrónico del usuario
+type UserVerificationRequestDTO struct {
+	// Correo electrónico del usuario
+	Email string `json:"email" example:"usuario@example.com"`
+	// Código de verificación enviado al correo
+	Code string `json:"code" example:"123456"`
+}
+
+// UserVerificationResendDTO representa la estructura para solicitar un nuevo código de verificación
+// @Description Datos requeridos para reenviar el código de verificación del correo electrónico
+type UserVerificationResendDTO struct {
+	// Correo electrónico del usuario
+	Email string `json:"email" example:"usuario@example.com"`
+}
@@ -1,5 +1,11 @@
 package logger
 
+import (
+	"errors"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"io"
+)
+
 type CompositeLogger struct {
 	loggers []Logger
 }
@@ -10,26 +16,85 @@ func NewCompositeLogger(loggers ...Logger) *CompositeLogger {
 	}
 }
 
-func (cl *CompositeLogger) Info(msg string) {
+func (cl *CompositeLogger) Trace(msg string, fields ...Field) {
+	for _, logger := range cl.loggers {
+		logger.Trace(msg, fields...)
+	}
+}
+
+func (cl *CompositeLogger) Debug(msg string, fields ...Field) {
+	for _, logger := range cl.loggers {
+		logger.Debug(msg, fields...)
+	}
+}
+
+func (cl *CompositeLogger) Info(msg string, fields ...Field) {
+	for _, logger := range cl.loggers {
+		logger.Info(msg, fields...)
+	}
+}
+
+func (cl *CompositeLogger) Error(msg string, fields ...Field) {
+	for _, logger := range cl.loggers {
+		logger.Error(msg, fields...)
+	}
+}
+
+func (cl *CompositeLogger) Warning(msg string, fields ...Field) {
+	for _, logger := range cl.loggers {
+		logger.Warning(msg, fields...)
+	}
+}
+
+func (cl *CompositeLogger) Panic(msg string, fields ...Field) {
+	for _, logger := range cl.loggers {
+		logger.Panic(msg, fields...)
+	}
+}
+
+func (cl *CompositeLogger) With(fields ...Field) Logger {
+	return newChildLogger(cl, fields)
+}
+
+// Levels devuelve el nivel mínimo de cada sink que permite cambiarlo
+func (cl *CompositeLogger) Levels() map[string]loggerEntity.LogLevel {
+	levels := map[string]loggerEntity.LogLevel{}
 	for _, logger := range cl.loggers {
-		logger.Info(msg)
+		if leveled, ok := logger.(LeveledLogger); ok {
+			levels[leveled.Name()] = leveled.Level()
+		}
 	}
+	return levels
 }
 
-func (cl *CompositeLogger) Error(msg string) {
+func (cl *CompositeLogger) SetLevel(sink string, level loggerEntity.LogLevel) error {
 	for _, logger := range cl.loggers {
-		logger.Error(msg)
+		if leveled, ok := logger.(LeveledLogger); ok && leveled.Name() == sink {
+			leveled.SetLevel(level)
+			return nil
+		}
 	}
+	return ErrUnknownSink
 }
 
-func (cl *CompositeLogger) Warning(msg string) {
+// Enabled indica si algún sink escribiría el nivel, los sinks sin nivel propio lo escriben todo
+func (cl *CompositeLogger) Enabled(level loggerEntity.LogLevel) bool {
 	for _, logger := range cl.loggers {
-		logger.Warning(msg)
+		leveled, ok := logger.(LeveledLogger)
+		if !ok || leveled.Enabled(level) {
+			return true
+		}
 	}
+	return false
 }
 
-func (cl *CompositeLogger) Panic(msg string) {
+// Close cierra los sinks que mantienen recursos abiertos, como el fichero de log
+func (cl *CompositeLogger) Close() error {
+	var errs []error
 	for _, logger := range cl.loggers {
-		logger.Panic(msg)
+		if closer, ok := logger.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
 	}
+	return errors.Join(errs...)
 }
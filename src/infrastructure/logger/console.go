@@ -4,53 +4,69 @@ import (
 	"fmt"
 	loggerEntity "go-gallery/src/domain/entities/logger"
 	"log"
+	"os"
 	"time"
-
-	"github.com/fatih/color"
 )
 
-type ConsoleLogger struct{}
+const ConsoleLoggerName string = "console"
+
+type ConsoleLogger struct {
+	LevelFilter
+	encoder Encoder
+}
 
 func NewConsoleLogger() *ConsoleLogger {
-	return &ConsoleLogger{}
+	return &ConsoleLogger{encoder: TextEncoder{Colored: true}}
+}
+
+// NewConsoleLoggerWithFormat permite escribir en JSON para que otra herramienta recoja la salida
+func NewConsoleLoggerWithFormat(format string) *ConsoleLogger {
+	return &ConsoleLogger{encoder: NewEncoder(format, true)}
+}
+
+func (l *ConsoleLogger) Trace(msg string, fields ...Field) {
+	l.printLog(loggerEntity.TRACE, msg, fields)
 }
 
-func (l *ConsoleLogger) Info(msg string) {
-	l.printLog(loggerEntity.INFO, msg)
+func (l *ConsoleLogger) Debug(msg string, fields ...Field) {
+	l.printLog(loggerEntity.DEBUG, msg, fields)
 }
 
-func (l *ConsoleLogger) Error(msg string) {
-	l.printLog(loggerEntity.ERROR, msg)
+func (l *ConsoleLogger) Info(msg string, fields ...Field) {
+	l.printLog(loggerEntity.INFO, msg, fields)
 }
 
-func (l *ConsoleLogger) Warning(msg string) {
-	l.printLog(loggerEntity.WARNING, msg)
+func (l *ConsoleLogger) Error(msg string, fields ...Field) {
+	l.printLog(loggerEntity.ERROR, msg, fields)
 }
 
-func (l *ConsoleLogger) Panic(msg string) {
-	l.printLog(loggerEntity.PANIC, msg)
+func (l *ConsoleLogger) Warning(msg string, fields ...Field) {
+	l.printLog(loggerEntity.WARNING, msg, fields)
 }
 
-func (l *ConsoleLogger) printLog(level loggerEntity.LogLevel, msg string) {
-	currentTime := time.Now().Format("02/01/06 15:04:05")
-	var logColor func(format string, a ...any) string
-	var prefix string
+func (l *ConsoleLogger) Panic(msg string, fields ...Field) {
+	l.printLog(loggerEntity.PANIC, msg, fields)
+}
+
+func (l *ConsoleLogger) With(fields ...Field) Logger {
+	return newChildLogger(l, fields)
+}
 
-	switch level {
-	case loggerEntity.INFO:
-		prefix = loggerEntity.INFO.String()
-		logColor = color.New(color.FgGreen).SprintfFunc()
-	case loggerEntity.ERROR:
-		prefix = loggerEntity.ERROR.String()
-		logColor = color.New(color.FgRed).SprintfFunc()
-	case loggerEntity.WARNING:
-		prefix = loggerEntity.WARNING.String()
-		logColor = color.New(color.FgYellow).SprintfFunc()
-	case loggerEntity.PANIC:
-		prefix = loggerEntity.PANIC.String()
-		logColor = color.New(color.FgHiMagenta).SprintfFunc()
+func (l *ConsoleLogger) Name() string {
+	return ConsoleLoggerName
+}
+
+func (l *ConsoleLogger) printLog(level loggerEntity.LogLevel, msg string, fields []Field) {
+	if !l.Enabled(level) {
+		return
 	}
 
-	logMessage := fmt.Sprintf("%s: %s %s", prefix, currentTime, msg)
-	log.Println(logColor("%s", logMessage))
+	line := l.encoder.Encode(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields})
+
+	// JSON lines are written without the prefix of the standard logger so every line stays valid JSON
+	if _, isJSON := l.encoder.(JSONEncoder); isJSON {
+		fmt.Fprintln(os.Stderr, string(line))
+		return
+	}
+	log.Println(string(line))
 }
@@ -0,0 +1,20 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// WithContext guarda el logger de la petición en el contexto
+func WithContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext devuelve el logger de la petición, o el global si el contexto no tiene ninguno
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(Logger); ok {
+			return logger
+		}
+	}
+	return Instance()
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	LOG_FORMAT_TEXT  string = "text"
+	LOG_FORMAT_JSON  string = "json"
+	TEXT_TIME_FORMAT string = "02/01/06 15:04:05"
+)
+
+// Encoder convierte una línea de log en los bytes que escribe cada destino, sin salto de línea final
+type Encoder interface {
+	Encode(entry Entry) []byte
+}
+
+// NewEncoder devuelve el codificador JSON o el de texto según el formato configurado
+func NewEncoder(format string, colored bool) Encoder {
+	if strings.EqualFold(strings.TrimSpace(format), LOG_FORMAT_JSON) {
+		return JSONEncoder{}
+	}
+	return TextEncoder{Colored: colored}
+}
+
+// TextEncoder escribe el nivel, la hora, el mensaje y los campos como clave=valor
+type TextEncoder struct {
+	Colored bool
+}
+
+func (e TextEncoder) Encode(entry Entry) []byte {
+	var buf strings.Builder
+	buf.WriteString(entry.Level.String())
+	buf.WriteString(": ")
+	buf.WriteString(entry.Time.Format(TEXT_TIME_FORMAT))
+	buf.WriteString(" ")
+	buf.WriteString(entry.Message)
+	for _, field := range entry.Fields {
+		buf.WriteString(" ")
+		buf.WriteString(field.Key)
+		buf.WriteString("=")
+		buf.WriteString(textValue(field.Value))
+	}
+
+	if !e.Colored {
+		return []byte(buf.String())
+	}
+	return []byte(levelColor(entry.Level)("%s", buf.String()))
+}
+
+func textValue(value any) string {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case error:
+		text = v.Error()
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+		return strconv.Quote(text)
+	}
+	return text
+}
+
+func levelColor(level loggerEntity.LogLevel) func(format string, a ...any) string {
+	switch level {
+	case loggerEntity.ERROR:
+		return color.New(color.FgRed).SprintfFunc()
+	case loggerEntity.WARNING:
+		return color.New(color.FgYellow).SprintfFunc()
+	case loggerEntity.PANIC:
+		return color.New(color.FgHiMagenta).SprintfFunc()
+	case loggerEntity.DEBUG:
+		return color.New(color.FgCyan).SprintfFunc()
+	case loggerEntity.TRACE:
+		return color.New(color.FgHiBlack).SprintfFunc()
+	default:
+		return color.New(color.FgGreen).SprintfFunc()
+	}
+}
+
+// JSONEncoder escribe un objeto JSON por línea con time, level, msg y los campos en el orden en que se añadieron
+type JSONEncoder struct{}
+
+func (e JSONEncoder) Encode(entry Entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJSONValue(&buf, entry.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSONValue(&buf, entry.Level.String())
+	buf.WriteString(`,"msg":`)
+	writeJSONValue(&buf, entry.Message)
+	for _, field := range entry.Fields {
+		buf.WriteString(",")
+		writeJSONValue(&buf, field.Key)
+		buf.WriteString(":")
+		writeJSONValue(&buf, field.Value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes()
+}
+
+func writeJSONValue(buf *bytes.Buffer, value any) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		encoded, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(encoded)
+}
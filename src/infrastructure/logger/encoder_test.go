@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntry = Entry{
+	Time:    time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+	Level:   loggerEntity.WARNING,
+	Message: "Login failed",
+	Fields:  []Field{F("username", "alice"), F("attempts", 3), F("error", errors.New("wrong password"))},
+}
+
+func TestTextEncoder(t *testing.T) {
+	line := TextEncoder{}.Encode(testEntry)
+	assert.Equal(t, `WARNING: 02/01/24 15:04:05 Login failed username=alice attempts=3 error="wrong password"`, string(line))
+}
+
+func TestJSONEncoder(t *testing.T) {
+	line := JSONEncoder{}.Encode(testEntry)
+	assert.Equal(t, `{"time":"2024-01-02T15:04:05Z","level":"WARNING","msg":"Login failed","username":"alice","attempts":3,"error":"wrong password"}`, string(line))
+
+	// Values that cannot be marshalled are written as text
+	line = JSONEncoder{}.Encode(Entry{Level: loggerEntity.INFO, Fields: []Field{F("channel", make(chan int))}})
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(line, &decoded))
+	assert.IsType(t, "", decoded["channel"])
+}
+
+func TestNewEncoder(t *testing.T) {
+	assert.IsType(t, JSONEncoder{}, NewEncoder(" JSON ", true))
+	assert.Equal(t, TextEncoder{Colored: true}, NewEncoder("", true))
+}
+
+type recordingLogger struct {
+	messages []string
+	fields   [][]Field
+}
+
+func (r *recordingLogger) record(msg string, fields []Field) {
+	r.messages = append(r.messages, msg)
+	r.fields = append(r.fields, fields)
+}
+
+func (r *recordingLogger) Trace(msg string, fields ...Field)   { r.record(msg, fields) }
+func (r *recordingLogger) Debug(msg string, fields ...Field)   { r.record(msg, fields) }
+func (r *recordingLogger) Info(msg string, fields ...Field)    { r.record(msg, fields) }
+func (r *recordingLogger) Error(msg string, fields ...Field)   { r.record(msg, fields) }
+func (r *recordingLogger) Warning(msg string, fields ...Field) { r.record(msg, fields) }
+func (r *recordingLogger) Panic(msg string, fields ...Field)   { r.record(msg, fields) }
+func (r *recordingLogger) With(fields ...Field) Logger         { return newChildLogger(r, fields) }
+
+func TestChildLoggersAddTheirFields(t *testing.T) {
+	recorder := &recordingLogger{}
+	request := recorder.With(F("request_id", "abc"))
+	user := request.With(F("username", "alice"))
+
+	user.Info("Image uploaded", F("image", "cat.png"))
+	request.Error("Request failed")
+
+	assert.Equal(t, []Field{F("request_id", "abc"), F("username", "alice"), F("image", "cat.png")}, recorder.fields[0])
+	// The child does not leak its fields into the parent
+	assert.Equal(t, []Field{F("request_id", "abc")}, recorder.fields[1])
+}
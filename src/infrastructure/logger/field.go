@@ -0,0 +1,87 @@
+package logger
+
+import (
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Field es un par clave/valor que acompaña a una línea de log
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F crea un campo. Los textos se copian porque la línea se escribe en otro goroutine y los de Fiber
+// apuntan a buffers que se reutilizan al terminar la petición
+func F(key string, value any) Field {
+	if text, ok := value.(string); ok {
+		value = strings.Clone(text)
+	}
+	return Field{Key: key, Value: value}
+}
+
+// Entry es una línea de log antes de codificarla
+type Entry struct {
+	Time    time.Time
+	Level   loggerEntity.LogLevel
+	Message string
+	Fields  []Field
+}
+
+// mergeFields devuelve una copia con los campos de ambos, así los hijos no comparten el slice del padre.
+// Si una clave se repite se queda el valor de extra, así una línea nunca lleva la misma clave dos veces
+func mergeFields(fields []Field, extra []Field) []Field {
+	if len(extra) == 0 {
+		return fields
+	}
+	merged := make([]Field, 0, len(fields)+len(extra))
+	merged = append(merged, fields...)
+	for _, field := range extra {
+		if i := slices.IndexFunc(merged, func(existing Field) bool { return existing.Key == field.Key }); i >= 0 {
+			merged[i] = field
+			continue
+		}
+		merged = append(merged, field)
+	}
+	return merged
+}
+
+// childLogger añade sus campos a cada línea antes de delegar en el logger padre
+type childLogger struct {
+	parent Logger
+	fields []Field
+}
+
+func newChildLogger(parent Logger, fields []Field) Logger {
+	return &childLogger{parent: parent, fields: fields}
+}
+
+func (c *childLogger) Trace(msg string, fields ...Field) {
+	c.parent.Trace(msg, mergeFields(c.fields, fields)...)
+}
+
+func (c *childLogger) Debug(msg string, fields ...Field) {
+	c.parent.Debug(msg, mergeFields(c.fields, fields)...)
+}
+
+func (c *childLogger) Info(msg string, fields ...Field) {
+	c.parent.Info(msg, mergeFields(c.fields, fields)...)
+}
+
+func (c *childLogger) Error(msg string, fields ...Field) {
+	c.parent.Error(msg, mergeFields(c.fields, fields)...)
+}
+
+func (c *childLogger) Warning(msg string, fields ...Field) {
+	c.parent.Warning(msg, mergeFields(c.fields, fields)...)
+}
+
+func (c *childLogger) Panic(msg string, fields ...Field) {
+	c.parent.Panic(msg, mergeFields(c.fields, fields)...)
+}
+
+func (c *childLogger) With(fields ...Field) Logger {
+	return newChildLogger(c.parent, mergeFields(c.fields, fields))
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFCopiesTheText(t *testing.T) {
+	buffer := []byte("alice")
+	field := F("username", unsafe.String(&buffer[0], len(buffer)))
+
+	// Fiber reuses the buffer for the next request while the line may still be queued
+	copy(buffer, "mallo")
+
+	assert.Equal(t, "alice", field.Value)
+	assert.Equal(t, 42, F("count", 42).Value)
+}
+
+func TestMergeFieldsKeepsEveryKeyOnce(t *testing.T) {
+	merged := mergeFields(
+		[]Field{F("request_id", "1"), F("username", "alice")},
+		[]Field{F("username", "bob"), F("status", 200)},
+	)
+
+	assert.Equal(t, []Field{F("request_id", "1"), F("username", "bob"), F("status", 200)}, merged)
+}
@@ -0,0 +1,399 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	FileLoggerKey  string = "FileLogger"
+	FileLoggerName string = "file"
+)
+
+const (
+	DEFAULT_LOGGER_FILE_PATH              string = "logs/go-gallery.log"
+	DEFAULT_LOGGER_FILE_MAX_SIZE          int    = 10
+	DEFAULT_LOGGER_FILE_ROTATION_INTERVAL int    = 24
+	DEFAULT_LOGGER_FILE_MAX_FILES         int    = 7
+	ROTATED_FILE_TIME_FORMAT              string = "20060102-150405.000"
+	COMPRESSED_FILE_EXTENSION             string = ".gz"
+)
+
+// NowFunc allows tests to control the current time
+var NowFunc = time.Now
+
+// FileLoggerSettings agrupa los parámetros de rotación del fichero de log
+type FileLoggerSettings struct {
+	Path             string
+	MaxSize          int64
+	RotationInterval time.Duration
+	MaxFiles         int
+	Compress         bool
+	Format           string
+	Level            loggerEntity.LogLevel
+}
+
+func ParseFileLoggerSettings(args map[string]string) FileLoggerSettings {
+	path := strings.TrimSpace(args["LOGGER_FILE_PATH"])
+	if path == "" {
+		path = DEFAULT_LOGGER_FILE_PATH
+	}
+
+	// Size in megabytes that triggers a rotation, 0 disables it
+	maxSize, err := strconv.Atoi(args["LOGGER_FILE_MAX_SIZE"])
+	if err != nil || maxSize < 0 {
+		maxSize = DEFAULT_LOGGER_FILE_MAX_SIZE
+	}
+
+	// Hours after which the file is rotated even if it is small, 0 disables it
+	rotationInterval, err := strconv.Atoi(args["LOGGER_FILE_ROTATION_INTERVAL"])
+	if err != nil || rotationInterval < 0 {
+		rotationInterval = DEFAULT_LOGGER_FILE_ROTATION_INTERVAL
+	}
+
+	// Number of rotated files kept, 0 keeps all of them
+	maxFiles, err := strconv.Atoi(args["LOGGER_FILE_MAX_FILES"])
+	if err != nil || maxFiles < 0 {
+		maxFiles = DEFAULT_LOGGER_FILE_MAX_FILES
+	}
+
+	compress, err := strconv.ParseBool(args["LOGGER_FILE_COMPRESS"])
+	if err != nil {
+		compress = true
+	}
+
+	// Files are written as JSON unless the plain text format is requested
+	format := strings.TrimSpace(args["LOGGER_FILE_FORMAT"])
+	if format == "" {
+		format = LOG_FORMAT_JSON
+	}
+
+	// Minimum level written to the file, independent from the console one
+	level := ParseLevelOrDefault(args["LOGGER_FILE_LEVEL"])
+
+	return FileLoggerSettings{
+		Path:             path,
+		MaxSize:          int64(maxSize) * 1024 * 1024,
+		RotationInterval: time.Duration(rotationInterval) * time.Hour,
+		MaxFiles:         maxFiles,
+		Compress:         compress,
+		Format:           format,
+		Level:            level,
+	}
+}
+
+// FileLogger escribe los logs en un fichero que rota por tamaño y por tiempo. Los ficheros
+// rotados se comprimen con gzip y solo se conservan los más recientes
+type FileLogger struct {
+	LevelFilter
+	mutex    sync.Mutex
+	settings FileLoggerSettings
+	encoder  Encoder
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	// Rotated files are compressed and pruned in the background, one rotation at a time
+	housekeeping      sync.WaitGroup
+	housekeepingMutex sync.Mutex
+	signals           chan os.Signal
+	closed            bool
+}
+
+func NewFileLogger(args map[string]string) *FileLogger {
+	fileLogger, err := NewFileLoggerWithSettings(ParseFileLoggerSettings(args))
+	if err != nil {
+		panicMessage := fmt.Sprintf("Could not open the log file: %s", err.Error())
+		log.Println(panicMessage)
+		panic(panicMessage)
+	}
+
+	fileLogger.ReopenOnSignal()
+	return fileLogger
+}
+
+func NewFileLoggerWithSettings(settings FileLoggerSettings) (*FileLogger, error) {
+	fileLogger := &FileLogger{settings: settings, encoder: NewEncoder(settings.Format, false)}
+	fileLogger.SetLevel(settings.Level)
+	if err := os.MkdirAll(filepath.Dir(settings.Path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := fileLogger.open(); err != nil {
+		return nil, err
+	}
+	return fileLogger, nil
+}
+
+func (l *FileLogger) Trace(msg string, fields ...Field) {
+	l.write(loggerEntity.TRACE, msg, fields)
+}
+
+func (l *FileLogger) Debug(msg string, fields ...Field) {
+	l.write(loggerEntity.DEBUG, msg, fields)
+}
+
+func (l *FileLogger) Info(msg string, fields ...Field) {
+	l.write(loggerEntity.INFO, msg, fields)
+}
+
+func (l *FileLogger) Error(msg string, fields ...Field) {
+	l.write(loggerEntity.ERROR, msg, fields)
+}
+
+func (l *FileLogger) Warning(msg string, fields ...Field) {
+	l.write(loggerEntity.WARNING, msg, fields)
+}
+
+// Panic se escribe en disco antes de volver, el proceso puede terminar justo después
+func (l *FileLogger) Panic(msg string, fields ...Field) {
+	l.write(loggerEntity.PANIC, msg, fields)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.file != nil {
+		l.file.Sync()
+	}
+}
+
+func (l *FileLogger) With(fields ...Field) Logger {
+	return newChildLogger(l, fields)
+}
+
+func (l *FileLogger) Name() string {
+	return FileLoggerName
+}
+
+// Reopen cierra y vuelve a abrir el fichero, así una rotación externa como logrotate no pierde líneas
+func (l *FileLogger) Reopen() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		return os.ErrClosed
+	}
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	return l.open()
+}
+
+// ReopenOnSignal reabre el fichero cada vez que el proceso recibe SIGHUP
+func (l *FileLogger) ReopenOnSignal() {
+	l.signals = make(chan os.Signal, 1)
+	signal.Notify(l.signals, syscall.SIGHUP)
+
+	go func() {
+		for range l.signals {
+			if err := l.Reopen(); err != nil {
+				log.Println(fmt.Sprintf("Could not reopen the log file %s: %s", l.settings.Path, err.Error()))
+			}
+		}
+	}()
+}
+
+// Close cierra el fichero y espera a que terminen las compresiones pendientes
+func (l *FileLogger) Close() error {
+	if l.signals != nil {
+		signal.Stop(l.signals)
+		close(l.signals)
+		l.signals = nil
+	}
+
+	l.mutex.Lock()
+	l.closed = true
+	var err error
+	if l.file != nil {
+		err = l.file.Close()
+		l.file = nil
+	}
+	l.mutex.Unlock()
+
+	l.housekeeping.Wait()
+	return err
+}
+
+func (l *FileLogger) write(level loggerEntity.LogLevel, msg string, fields []Field) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	now := NowFunc()
+	line := string(l.encoder.Encode(Entry{Time: now, Level: level, Message: msg, Fields: fields})) + "\n"
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Once closed the file is not opened again by late messages
+	if l.closed {
+		return
+	}
+
+	if l.shouldRotate(now, int64(len(line))) {
+		if err := l.rotate(now); err != nil {
+			log.Println(fmt.Sprintf("Could not rotate the log file %s: %s", l.settings.Path, err.Error()))
+		}
+	}
+
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			log.Println(fmt.Sprintf("Could not open the log file %s: %s", l.settings.Path, err.Error()))
+			return
+		}
+	}
+
+	written, err := io.WriteString(l.file, line)
+	l.size += int64(written)
+	if err != nil {
+		log.Println(fmt.Sprintf("Could not write to the log file %s: %s", l.settings.Path, err.Error()))
+	}
+}
+
+func (l *FileLogger) shouldRotate(now time.Time, lineSize int64) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.settings.MaxSize > 0 && l.size+lineSize > l.settings.MaxSize {
+		return true
+	}
+	return l.settings.RotationInterval > 0 && now.Sub(l.openedAt) >= l.settings.RotationInterval
+}
+
+func (l *FileLogger) open() error {
+	file, err := os.OpenFile(l.settings.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	l.file = file
+	l.size = info.Size()
+	l.openedAt = NowFunc()
+	return nil
+}
+
+func (l *FileLogger) rotate(now time.Time) error {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+
+	rotatedPath := l.rotatedPath(now)
+	if err := os.Rename(l.settings.Path, rotatedPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := l.open(); err != nil {
+		return err
+	}
+
+	l.housekeeping.Add(1)
+	go func() {
+		defer l.housekeeping.Done()
+		l.housekeepingMutex.Lock()
+		defer l.housekeepingMutex.Unlock()
+
+		if l.settings.Compress {
+			if err := compressFile(rotatedPath); err != nil {
+				log.Println(fmt.Sprintf("Could not compress the rotated log file %s: %s", rotatedPath, err.Error()))
+			}
+		}
+		l.prune()
+	}()
+
+	return nil
+}
+
+// The timestamp keeps the rotated files sorted by name, a suffix avoids overwriting one from the same millisecond
+func (l *FileLogger) rotatedPath(now time.Time) string {
+	base := l.settings.Path + "." + now.Format(ROTATED_FILE_TIME_FORMAT)
+	path := base
+	for i := 1; fileExists(path) || fileExists(path+COMPRESSED_FILE_EXTENSION); i++ {
+		path = base + "-" + strconv.Itoa(i)
+	}
+	return path
+}
+
+// RotatedFiles devuelve los ficheros rotados del log ordenados del más antiguo al más reciente
+func (l *FileLogger) RotatedFiles() ([]string, error) {
+	matches, err := filepath.Glob(l.settings.Path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(matches)
+	return matches, nil
+}
+
+func (l *FileLogger) prune() {
+	if l.settings.MaxFiles <= 0 {
+		return
+	}
+
+	rotated, err := l.RotatedFiles()
+	if err != nil {
+		log.Println(fmt.Sprintf("Could not list the rotated log files of %s: %s", l.settings.Path, err.Error()))
+		return
+	}
+
+	for len(rotated) > l.settings.MaxFiles {
+		if err := os.Remove(rotated[0]); err != nil && !os.IsNotExist(err) {
+			log.Println(fmt.Sprintf("Could not remove the rotated log file %s: %s", rotated[0], err.Error()))
+		}
+		rotated = rotated[1:]
+	}
+}
+
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(path+COMPRESSED_FILE_EXTENSION, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+	_, errCopy := io.Copy(writer, source)
+	errWriter := writer.Close()
+	errTarget := target.Close()
+	if err := firstError(errCopy, errWriter, errTarget); err != nil {
+		os.Remove(path + COMPRESSED_FILE_EXTENSION)
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"compress/gzip"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFileLogger(t *testing.T, settings FileLoggerSettings) *FileLogger {
+	settings.Path = filepath.Join(t.TempDir(), "logs", "go-gallery.log")
+	fileLogger, err := NewFileLoggerWithSettings(settings)
+	require.NoError(t, err)
+	t.Cleanup(func() { fileLogger.Close() })
+	return fileLogger
+}
+
+func readGzip(t *testing.T, path string) string {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestParseFileLoggerSettings(t *testing.T) {
+	settings := ParseFileLoggerSettings(map[string]string{
+		"LOGGER_FILE_PATH":              "/var/log/gallery.log",
+		"LOGGER_FILE_MAX_SIZE":          "5",
+		"LOGGER_FILE_ROTATION_INTERVAL": "0",
+		"LOGGER_FILE_MAX_FILES":         "3",
+		"LOGGER_FILE_COMPRESS":          "false",
+		"LOGGER_FILE_LEVEL":             "debug",
+	})
+
+	assert.Equal(t, "/var/log/gallery.log", settings.Path)
+	assert.Equal(t, int64(5*1024*1024), settings.MaxSize)
+	assert.Equal(t, time.Duration(0), settings.RotationInterval)
+	assert.Equal(t, 3, settings.MaxFiles)
+	assert.False(t, settings.Compress)
+	assert.Equal(t, loggerEntity.DEBUG, settings.Level)
+
+	defaults := ParseFileLoggerSettings(map[string]string{"LOGGER_FILE_MAX_FILES": "-1"})
+	assert.Equal(t, DEFAULT_LOGGER_FILE_PATH, defaults.Path)
+	assert.Equal(t, 24*time.Hour, defaults.RotationInterval)
+	assert.Equal(t, DEFAULT_LOGGER_FILE_MAX_FILES, defaults.MaxFiles)
+	assert.True(t, defaults.Compress)
+	assert.Equal(t, LOG_FORMAT_JSON, defaults.Format)
+	assert.Equal(t, loggerEntity.INFO, defaults.Level)
+}
+
+func TestFileLoggerRotatesBySizeAndCompresses(t *testing.T) {
+	fileLogger := newTestFileLogger(t, FileLoggerSettings{MaxSize: 100, Compress: true})
+
+	fileLogger.Info(strings.Repeat("a", 60))
+	fileLogger.Error(strings.Repeat("b", 60))
+	fileLogger.Close()
+
+	rotated, err := fileLogger.RotatedFiles()
+	require.NoError(t, err)
+	require.Len(t, rotated, 1)
+	assert.True(t, strings.HasSuffix(rotated[0], COMPRESSED_FILE_EXTENSION))
+	assert.Contains(t, readGzip(t, rotated[0]), "INFO: ")
+
+	current, err := os.ReadFile(fileLogger.settings.Path)
+	require.NoError(t, err)
+	assert.Contains(t, string(current), "ERROR: ")
+	assert.NotContains(t, string(current), "INFO: ")
+}
+
+func TestFileLoggerRotatesByTimeAndKeepsMaxFiles(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	NowFunc = func() time.Time { return now }
+	defer func() { NowFunc = time.Now }()
+
+	fileLogger := newTestFileLogger(t, FileLoggerSettings{RotationInterval: time.Hour, MaxFiles: 2})
+	for i := 0; i < 4; i++ {
+		fileLogger.Warning("message")
+		now = now.Add(time.Hour)
+	}
+	fileLogger.Info("last message")
+	fileLogger.Close()
+
+	// The file rotated every hour, only the two most recent rotations are kept
+	rotated, err := fileLogger.RotatedFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		fileLogger.settings.Path + ".20240101-030000.000",
+		fileLogger.settings.Path + ".20240101-040000.000",
+	}, rotated)
+}
+
+func TestFileLoggerReopen(t *testing.T) {
+	fileLogger := newTestFileLogger(t, FileLoggerSettings{})
+	fileLogger.Info("before")
+
+	// An external tool moves the file away and asks the logger to reopen it
+	moved := fileLogger.settings.Path + ".moved"
+	require.NoError(t, os.Rename(fileLogger.settings.Path, moved))
+	require.NoError(t, fileLogger.Reopen())
+	fileLogger.Panic("after")
+
+	before, _ := os.ReadFile(moved)
+	after, _ := os.ReadFile(fileLogger.settings.Path)
+	assert.Contains(t, string(before), "before")
+	assert.NotContains(t, string(before), "after")
+	assert.Contains(t, string(after), "PANIC: ")
+}
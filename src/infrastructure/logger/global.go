@@ -1,22 +1,90 @@
 package logger
 
-import "sync"
+import (
+	"fmt"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	OVERFLOW_POLICY_BLOCK       string = "block"
+	OVERFLOW_POLICY_DROP_OLDEST string = "drop-oldest"
+	OVERFLOW_POLICY_DROP_NEW    string = "drop-new"
+	DEFAULT_LOGGER_BUFFER_SIZE  int    = 1000
+)
 
 var (
 	logger Logger
 	once   sync.Once
 )
 
+// AsyncSettings indica el tamaño de la cola de mensajes y qué hacer cuando se llena
+type AsyncSettings struct {
+	BufferSize     int
+	OverflowPolicy string
+}
+
+func ParseAsyncSettings(args map[string]string) AsyncSettings {
+	bufferSize, err := strconv.Atoi(args["LOGGER_BUFFER_SIZE"])
+	if err != nil || bufferSize <= 0 {
+		bufferSize = DEFAULT_LOGGER_BUFFER_SIZE
+	}
+
+	// Blocking keeps every message, the drop policies never make a request wait for a slow sink
+	policy := strings.ToLower(strings.TrimSpace(args["LOGGER_OVERFLOW_POLICY"]))
+	switch policy {
+	case OVERFLOW_POLICY_DROP_OLDEST, OVERFLOW_POLICY_DROP_NEW:
+	default:
+		policy = OVERFLOW_POLICY_BLOCK
+	}
+
+	return AsyncSettings{BufferSize: bufferSize, OverflowPolicy: policy}
+}
+
+// AsyncStats resume el estado de la cola y los mensajes descartados desde el arranque
+type AsyncStats struct {
+	OverflowPolicy string
+	BufferSize     int
+	Queued         int
+	DroppedOldest  uint64
+	DroppedNew     uint64
+}
+
+type queuedEntry struct {
+	level  loggerEntity.LogLevel
+	msg    string
+	fields []Field
+}
+
+// AsyncGlobalLogger escribe los mensajes en otro goroutine para no retrasar las peticiones.
+// Los mensajes de Panic se escriben de forma síncrona después de vaciar la cola
 type AsyncGlobalLogger struct {
-	inner Logger
-	queue chan func()
+	inner    Logger
+	settings AsyncSettings
+	queue    chan queuedEntry
+	flushes  chan chan struct{}
+	stopped  chan struct{}
+
+	// Senders hold the read lock so Close never closes the queue under them
+	mutex  sync.RWMutex
+	closed bool
+
+	droppedOldest atomic.Uint64
+	droppedNew    atomic.Uint64
+	reported      uint64
 }
 
 func Init(inner Logger) Logger {
+	return InitWithSettings(inner, AsyncSettings{})
+}
+
+func InitWithSettings(inner Logger, settings AsyncSettings) Logger {
 	once.Do(func() {
-		agl := &AsyncGlobalLogger{inner: inner, queue: make(chan func(), 1000)}
-		go agl.startWorker()
-		logger = agl
+		logger = newAsyncGlobalLogger(inner, settings)
 	})
 	return logger
 }
@@ -28,32 +96,245 @@ func Instance() Logger {
 	return logger
 }
 
+// Flush espera a que se escriban los mensajes encolados en el logger global
+func Flush() {
+	if async, ok := Instance().(*AsyncGlobalLogger); ok {
+		async.Flush()
+	}
+}
+
+// Close vacía la cola del logger global y cierra sus sinks, los mensajes posteriores se escriben de forma síncrona
+func Close() error {
+	if async, ok := Instance().(*AsyncGlobalLogger); ok {
+		return async.Close()
+	}
+	return nil
+}
+
+// QueueStats devuelve el estado de la cola del logger global
+func QueueStats() (AsyncStats, bool) {
+	if async, ok := Instance().(*AsyncGlobalLogger); ok {
+		return async.Stats(), true
+	}
+	return AsyncStats{}, false
+}
+
+func newAsyncGlobalLogger(inner Logger, settings AsyncSettings) *AsyncGlobalLogger {
+	if settings.BufferSize <= 0 {
+		settings.BufferSize = DEFAULT_LOGGER_BUFFER_SIZE
+	}
+	if settings.OverflowPolicy == "" {
+		settings.OverflowPolicy = OVERFLOW_POLICY_BLOCK
+	}
+
+	agl := &AsyncGlobalLogger{
+		inner:    inner,
+		settings: settings,
+		queue:    make(chan queuedEntry, settings.BufferSize),
+		flushes:  make(chan chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go agl.startWorker()
+	return agl
+}
+
 func (a *AsyncGlobalLogger) startWorker() {
-	for fn := range a.queue {
-		fn()
+	defer close(a.stopped)
+	for {
+		select {
+		case entry, ok := <-a.queue:
+			if !ok {
+				a.reportDropped()
+				return
+			}
+			a.write(entry)
+		case done := <-a.flushes:
+			a.drain()
+			close(done)
+		}
+	}
+}
+
+// drain writes the entries queued so far, new ones may keep arriving so it stops once the queue looks empty
+func (a *AsyncGlobalLogger) drain() {
+	for pending := len(a.queue); pending > 0; pending-- {
+		select {
+		case entry, ok := <-a.queue:
+			if !ok {
+				return
+			}
+			a.write(entry)
+		default:
+			return
+		}
+	}
+}
+
+func (a *AsyncGlobalLogger) write(entry queuedEntry) {
+	writeEntry(a.inner, entry)
+
+	// Drops are reported once the queue has room again, so the warning does not add to the pressure
+	if len(a.queue) == 0 {
+		a.reportDropped()
+	}
+}
+
+func (a *AsyncGlobalLogger) reportDropped() {
+	dropped := a.droppedOldest.Load() + a.droppedNew.Load()
+	if dropped > a.reported {
+		a.inner.Warning(fmt.Sprintf("Dropped %d log messages because the queue was full", dropped-a.reported),
+			F("overflow_policy", a.settings.OverflowPolicy), F("dropped_total", dropped))
+		a.reported = dropped
+	}
+}
+
+func (a *AsyncGlobalLogger) enqueue(entry queuedEntry) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if a.closed {
+		writeEntry(a.inner, entry)
+		return
+	}
+
+	switch a.settings.OverflowPolicy {
+	case OVERFLOW_POLICY_DROP_NEW:
+		select {
+		case a.queue <- entry:
+		default:
+			a.droppedNew.Add(1)
+		}
+	case OVERFLOW_POLICY_DROP_OLDEST:
+		for {
+			select {
+			case a.queue <- entry:
+				return
+			default:
+			}
+			// Make room by discarding the oldest entry, the worker may have taken it first
+			select {
+			case <-a.queue:
+				a.droppedOldest.Add(1)
+			default:
+			}
+		}
+	default:
+		a.queue <- entry
+	}
+}
+
+func (a *AsyncGlobalLogger) Trace(msg string, fields ...Field) {
+	if !a.Enabled(loggerEntity.TRACE) {
+		return
+	}
+	a.enqueue(queuedEntry{level: loggerEntity.TRACE, msg: msg, fields: fields})
+}
+
+func (a *AsyncGlobalLogger) Debug(msg string, fields ...Field) {
+	if !a.Enabled(loggerEntity.DEBUG) {
+		return
+	}
+	a.enqueue(queuedEntry{level: loggerEntity.DEBUG, msg: msg, fields: fields})
+}
+
+func (a *AsyncGlobalLogger) Info(msg string, fields ...Field) {
+	a.enqueue(queuedEntry{level: loggerEntity.INFO, msg: msg, fields: fields})
+}
+
+func (a *AsyncGlobalLogger) Warning(msg string, fields ...Field) {
+	a.enqueue(queuedEntry{level: loggerEntity.WARNING, msg: msg, fields: fields})
+}
+
+func (a *AsyncGlobalLogger) Error(msg string, fields ...Field) {
+	a.enqueue(queuedEntry{level: loggerEntity.ERROR, msg: msg, fields: fields})
+}
+
+// Panic vacía la cola y escribe el mensaje antes de volver, el proceso puede terminar justo después
+func (a *AsyncGlobalLogger) Panic(msg string, fields ...Field) {
+	a.Flush()
+	a.inner.Panic(msg, fields...)
+}
+
+func (a *AsyncGlobalLogger) With(fields ...Field) Logger {
+	return newChildLogger(a, fields)
+}
+
+// Flush espera a que el worker escriba los mensajes encolados hasta ahora
+func (a *AsyncGlobalLogger) Flush() {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.closed {
+		return
+	}
+
+	done := make(chan struct{})
+	a.flushes <- done
+	<-done
+}
+
+// Close escribe los mensajes pendientes, para el worker y cierra los sinks que lo necesitan
+func (a *AsyncGlobalLogger) Close() error {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.queue)
+	a.mutex.Unlock()
+
+	<-a.stopped
+	if closer, ok := a.inner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (a *AsyncGlobalLogger) Stats() AsyncStats {
+	return AsyncStats{
+		OverflowPolicy: a.settings.OverflowPolicy,
+		BufferSize:     a.settings.BufferSize,
+		Queued:         len(a.queue),
+		DroppedOldest:  a.droppedOldest.Load(),
+		DroppedNew:     a.droppedNew.Load(),
 	}
 }
 
-func (a *AsyncGlobalLogger) Info(msg string) {
-	a.queue <- func() {
-		a.inner.Info(msg)
+func (a *AsyncGlobalLogger) Levels() map[string]loggerEntity.LogLevel {
+	if controller, ok := a.inner.(LevelController); ok {
+		return controller.Levels()
 	}
+	return map[string]loggerEntity.LogLevel{}
 }
 
-func (a *AsyncGlobalLogger) Warning(msg string) {
-	a.queue <- func() {
-		a.inner.Warning(msg)
+func (a *AsyncGlobalLogger) SetLevel(sink string, level loggerEntity.LogLevel) error {
+	if controller, ok := a.inner.(LevelController); ok {
+		return controller.SetLevel(sink, level)
 	}
+	return ErrUnknownSink
 }
 
-func (a *AsyncGlobalLogger) Error(msg string) {
-	a.queue <- func() {
-		a.inner.Error(msg)
+// Enabled evita encolar los mensajes que ningún sink va a escribir
+func (a *AsyncGlobalLogger) Enabled(level loggerEntity.LogLevel) bool {
+	if controller, ok := a.inner.(LevelController); ok {
+		return controller.Enabled(level)
 	}
+	return true
 }
 
-func (a *AsyncGlobalLogger) Panic(msg string) {
-	a.queue <- func() {
-		a.inner.Panic(msg)
+func writeEntry(logger Logger, entry queuedEntry) {
+	switch entry.level {
+	case loggerEntity.TRACE:
+		logger.Trace(entry.msg, entry.fields...)
+	case loggerEntity.DEBUG:
+		logger.Debug(entry.msg, entry.fields...)
+	case loggerEntity.WARNING:
+		logger.Warning(entry.msg, entry.fields...)
+	case loggerEntity.ERROR:
+		logger.Error(entry.msg, entry.fields...)
+	case loggerEntity.PANIC:
+		logger.Panic(entry.msg, entry.fields...)
+	default:
+		logger.Info(entry.msg, entry.fields...)
 	}
 }
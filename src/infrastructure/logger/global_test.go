@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedLogger records the messages and blocks every write until the gate is opened
+type gatedLogger struct {
+	recordingLogger
+	mutex  sync.Mutex
+	gate   chan struct{}
+	closed bool
+}
+
+func newGatedLogger() *gatedLogger {
+	return &gatedLogger{gate: make(chan struct{})}
+}
+
+func (g *gatedLogger) record(msg string, fields []Field) {
+	<-g.gate
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.recordingLogger.record(msg, fields)
+}
+
+func (g *gatedLogger) Info(msg string, fields ...Field)    { g.record(msg, fields) }
+func (g *gatedLogger) Warning(msg string, fields ...Field) { g.record(msg, fields) }
+func (g *gatedLogger) Panic(msg string, fields ...Field)   { g.record(msg, fields) }
+
+func (g *gatedLogger) Close() error {
+	g.closed = true
+	return nil
+}
+
+func (g *gatedLogger) recorded() []string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return append([]string(nil), g.messages...)
+}
+
+// fillQueue blocks the worker on a first message and fills the whole buffer behind it
+func fillQueue(t *testing.T, async *AsyncGlobalLogger, messages ...string) {
+	async.Info("in progress")
+	assert.Eventually(t, func() bool { return len(async.queue) == 0 }, time.Second, time.Millisecond)
+	for _, msg := range messages {
+		async.Info(msg)
+	}
+}
+
+func TestParseAsyncSettings(t *testing.T) {
+	settings := ParseAsyncSettings(map[string]string{"LOGGER_BUFFER_SIZE": "50", "LOGGER_OVERFLOW_POLICY": "Drop-Oldest"})
+	assert.Equal(t, AsyncSettings{BufferSize: 50, OverflowPolicy: OVERFLOW_POLICY_DROP_OLDEST}, settings)
+
+	defaults := ParseAsyncSettings(map[string]string{"LOGGER_BUFFER_SIZE": "0", "LOGGER_OVERFLOW_POLICY": "discard"})
+	assert.Equal(t, AsyncSettings{BufferSize: DEFAULT_LOGGER_BUFFER_SIZE, OverflowPolicy: OVERFLOW_POLICY_BLOCK}, defaults)
+}
+
+func TestAsyncLoggerDropNew(t *testing.T) {
+	sink := newGatedLogger()
+	async := newAsyncGlobalLogger(sink, AsyncSettings{BufferSize: 2, OverflowPolicy: OVERFLOW_POLICY_DROP_NEW})
+
+	fillQueue(t, async, "first", "second", "third", "fourth")
+	assert.Equal(t, uint64(2), async.Stats().DroppedNew)
+
+	close(sink.gate)
+	async.Flush()
+	recorded := sink.recorded()
+	assert.Equal(t, []string{"in progress", "first", "second"}, recorded[:3])
+	assert.Contains(t, recorded[3], "Dropped 2 log messages")
+}
+
+func TestAsyncLoggerDropOldest(t *testing.T) {
+	sink := newGatedLogger()
+	async := newAsyncGlobalLogger(sink, AsyncSettings{BufferSize: 2, OverflowPolicy: OVERFLOW_POLICY_DROP_OLDEST})
+
+	fillQueue(t, async, "first", "second", "third", "fourth")
+	assert.Equal(t, uint64(2), async.Stats().DroppedOldest)
+	assert.Equal(t, 2, async.Stats().Queued)
+
+	close(sink.gate)
+	async.Flush()
+	assert.Equal(t, []string{"in progress", "third", "fourth"}, sink.recorded()[:3])
+}
+
+func TestAsyncLoggerPanicIsWrittenAfterTheQueue(t *testing.T) {
+	sink := newGatedLogger()
+	close(sink.gate)
+	async := newAsyncGlobalLogger(sink, AsyncSettings{})
+
+	async.Info("first")
+	async.Warning("second")
+	async.Panic("panic")
+
+	// Panic returns once everything has been written
+	assert.Equal(t, []string{"first", "second", "panic"}, sink.recorded())
+}
+
+func TestAsyncLoggerClose(t *testing.T) {
+	sink := newGatedLogger()
+	close(sink.gate)
+	async := newAsyncGlobalLogger(sink, AsyncSettings{})
+
+	async.Info("queued")
+	assert.NoError(t, async.Close())
+	assert.True(t, sink.closed)
+	assert.Equal(t, []string{"queued"}, sink.recorded())
+
+	// Late messages are written directly and a second Close does nothing
+	async.Info("after close")
+	async.Flush()
+	assert.NoError(t, async.Close())
+	assert.Equal(t, []string{"queued", "after close"}, sink.recorded())
+}
@@ -1,8 +1,12 @@
 package logger
 
 type Logger interface {
-	Info(msg string)
-	Error(msg string)
-	Warning(msg string)
-	Panic(msg string)
+	Trace(msg string, fields ...Field)
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+	Warning(msg string, fields ...Field)
+	Panic(msg string, fields ...Field)
+	// With devuelve un logger hijo que añade los campos a todas sus líneas
+	With(fields ...Field) Logger
 }
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"errors"
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"strings"
+	"sync/atomic"
+)
+
+const DEFAULT_LOG_LEVEL loggerEntity.LogLevel = loggerEntity.INFO
+
+var ErrUnknownSink = errors.New("unknown logger sink")
+
+// LevelFilter guarda el nivel mínimo de un sink, se puede cambiar mientras otros goroutines escriben
+type LevelFilter struct {
+	minLevel atomic.Uint32
+}
+
+func (f *LevelFilter) Level() loggerEntity.LogLevel {
+	level := loggerEntity.LogLevel(f.minLevel.Load())
+	if level == 0 {
+		return DEFAULT_LOG_LEVEL
+	}
+	return level
+}
+
+func (f *LevelFilter) SetLevel(level loggerEntity.LogLevel) {
+	f.minLevel.Store(uint32(level))
+}
+
+func (f *LevelFilter) Enabled(level loggerEntity.LogLevel) bool {
+	return level >= f.Level()
+}
+
+// LeveledLogger es un sink con nombre cuyo nivel mínimo se puede consultar y cambiar
+type LeveledLogger interface {
+	Logger
+	Name() string
+	Level() loggerEntity.LogLevel
+	SetLevel(level loggerEntity.LogLevel)
+	Enabled(level loggerEntity.LogLevel) bool
+}
+
+// LevelController lo implementan los loggers que agrupan sinks con nivel propio
+type LevelController interface {
+	Levels() map[string]loggerEntity.LogLevel
+	SetLevel(sink string, level loggerEntity.LogLevel) error
+	Enabled(level loggerEntity.LogLevel) bool
+}
+
+// ParseLevelOrDefault devuelve el nivel por defecto si el nombre está vacío o no es válido
+func ParseLevelOrDefault(name string) loggerEntity.LogLevel {
+	if strings.TrimSpace(name) == "" {
+		return DEFAULT_LOG_LEVEL
+	}
+	level, ok := loggerEntity.ParseLevel(name)
+	if !ok {
+		return DEFAULT_LOG_LEVEL
+	}
+	return level
+}
+
+// Levels devuelve el nivel mínimo de cada sink del logger global
+func Levels() map[string]loggerEntity.LogLevel {
+	if controller, ok := Instance().(LevelController); ok {
+		return controller.Levels()
+	}
+	return map[string]loggerEntity.LogLevel{}
+}
+
+// SetLevel cambia en caliente el nivel mínimo de un sink del logger global
+func SetLevel(sink string, level loggerEntity.LogLevel) error {
+	if controller, ok := Instance().(LevelController); ok {
+		return controller.SetLevel(sink, level)
+	}
+	return ErrUnknownSink
+}
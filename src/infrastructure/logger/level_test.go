@@ -0,0 +1,64 @@
+package logger
+
+import (
+	loggerEntity "go-gallery/src/domain/entities/logger"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelFilter(t *testing.T) {
+	var filter LevelFilter
+	assert.Equal(t, DEFAULT_LOG_LEVEL, filter.Level())
+	assert.False(t, filter.Enabled(loggerEntity.DEBUG))
+	assert.True(t, filter.Enabled(loggerEntity.INFO))
+
+	filter.SetLevel(loggerEntity.TRACE)
+	assert.True(t, filter.Enabled(loggerEntity.TRACE))
+
+	filter.SetLevel(loggerEntity.ERROR)
+	assert.False(t, filter.Enabled(loggerEntity.WARNING))
+	assert.True(t, filter.Enabled(loggerEntity.PANIC))
+}
+
+func TestParseLevelOrDefault(t *testing.T) {
+	assert.Equal(t, loggerEntity.TRACE, ParseLevelOrDefault("trace"))
+	assert.Equal(t, DEFAULT_LOG_LEVEL, ParseLevelOrDefault(""))
+	assert.Equal(t, DEFAULT_LOG_LEVEL, ParseLevelOrDefault("verbose"))
+}
+
+func TestCompositeLoggerLevels(t *testing.T) {
+	console := NewConsoleLogger()
+	file := newTestFileLogger(t, FileLoggerSettings{Level: loggerEntity.DEBUG})
+	composite := NewCompositeLogger(console, file, &recordingLogger{})
+
+	assert.Equal(t, map[string]loggerEntity.LogLevel{
+		ConsoleLoggerName: loggerEntity.INFO,
+		FileLoggerName:    loggerEntity.DEBUG,
+	}, composite.Levels())
+
+	require.NoError(t, composite.SetLevel(ConsoleLoggerName, loggerEntity.WARNING))
+	assert.Equal(t, loggerEntity.WARNING, console.Level())
+	assert.ErrorIs(t, composite.SetLevel("syslog", loggerEntity.INFO), ErrUnknownSink)
+
+	// A sink without its own level writes everything
+	assert.True(t, composite.Enabled(loggerEntity.TRACE))
+	assert.False(t, NewCompositeLogger(console, file).Enabled(loggerEntity.TRACE))
+}
+
+func TestFileLoggerSkipsLevelsBelowTheMinimum(t *testing.T) {
+	fileLogger := newTestFileLogger(t, FileLoggerSettings{Format: LOG_FORMAT_TEXT})
+	fileLogger.Debug("hidden debug")
+	fileLogger.Info("visible info")
+
+	fileLogger.SetLevel(loggerEntity.TRACE)
+	fileLogger.Trace("visible trace")
+
+	content, err := os.ReadFile(fileLogger.settings.Path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "hidden debug")
+	assert.Contains(t, string(content), "visible info")
+	assert.Contains(t, string(content), "visible trace")
+}
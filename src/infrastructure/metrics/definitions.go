@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const NAMESPACE string = "go_gallery"
+
+const (
+	EMAIL_OUTCOME_SUCCESS string = "success"
+	EMAIL_OUTCOME_FAILURE string = "failure"
+
+	THUMBNAIL_BYTES_INPUT  string = "input"
+	THUMBNAIL_BYTES_OUTPUT string = "output"
+)
+
+// Buckets in seconds for the thumbnail generation, decoding and encoding large images takes longer than a query
+var THUMBNAIL_DURATION_BUCKETS = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time spent handling HTTP requests, by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	RepositoryOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "repository_operation_duration_seconds",
+		Help:      "Time spent in repository operations, by repository and operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"repository", "operation"})
+
+	RepositoryOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "repository_operation_errors_total",
+		Help:      "Number of repository operations that returned an error, by repository, operation and status code.",
+	}, []string{"repository", "operation", "status"})
+
+	ThumbnailGenerationDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "thumbnail_generation_duration_seconds",
+		Help:      "Time spent resizing uploaded images into thumbnails.",
+		Buckets:   THUMBNAIL_DURATION_BUCKETS,
+	})
+
+	ThumbnailGenerationErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "thumbnail_generation_errors_total",
+		Help:      "Number of uploaded images that could not be resized into a thumbnail.",
+	})
+
+	ThumbnailBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "thumbnail_processed_bytes_total",
+		Help:      "Bytes read from the uploaded images and written to the thumbnails.",
+	}, []string{"direction"})
+
+	EmailsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "emails_sent_total",
+		Help:      "Number of emails sent, by template and outcome.",
+	}, []string{"template", "outcome"})
+)
+
+// ObserveRepositoryOperation registra la duración de la operación y, si ha fallado, el código de estado del error
+func ObserveRepositoryOperation(repository, operation string, start time.Time, errorStatus int) {
+	RepositoryOperationDuration.WithLabelValues(repository, operation).Observe(time.Since(start).Seconds())
+	if errorStatus != 0 {
+		RepositoryOperationErrors.WithLabelValues(repository, operation, strconv.Itoa(errorStatus)).Inc()
+	}
+}
+
+// ObserveThumbnailGeneration registra la duración y los bytes leídos y escritos al generar una miniatura
+func ObserveThumbnailGeneration(start time.Time, inputBytes, outputBytes int, err error) {
+	ThumbnailGenerationDuration.Observe(time.Since(start).Seconds())
+	ThumbnailBytes.WithLabelValues(THUMBNAIL_BYTES_INPUT).Add(float64(inputBytes))
+	if err != nil {
+		ThumbnailGenerationErrors.Inc()
+		return
+	}
+	ThumbnailBytes.WithLabelValues(THUMBNAIL_BYTES_OUTPUT).Add(float64(outputBytes))
+}
+
+// ObserveEmail registra el resultado del envío de un correo con la plantilla indicada
+func ObserveEmail(template string, err error) {
+	outcome := EMAIL_OUTCOME_SUCCESS
+	if err != nil {
+		outcome = EMAIL_OUTCOME_FAILURE
+	}
+	EmailsSent.WithLabelValues(template, outcome).Inc()
+}
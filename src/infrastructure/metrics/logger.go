@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	log "go-gallery/src/infrastructure/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// loggerQueueCollector lee los contadores que ya lleva la cola del logger cada vez que se consultan las métricas.
+// No publica nada si el logger global no es asíncrono
+type loggerQueueCollector struct {
+	depth    *prometheus.Desc
+	capacity *prometheus.Desc
+	dropped  *prometheus.Desc
+	stats    func() (log.AsyncStats, bool)
+}
+
+func newLoggerQueueCollector() *loggerQueueCollector {
+	return &loggerQueueCollector{
+		depth: prometheus.NewDesc(prometheus.BuildFQName(NAMESPACE, "", "logger_queue_depth"),
+			"Number of log messages waiting to be written.", nil, nil),
+		capacity: prometheus.NewDesc(prometheus.BuildFQName(NAMESPACE, "", "logger_queue_capacity"),
+			"Maximum number of log messages the queue can hold.", nil, nil),
+		dropped: prometheus.NewDesc(prometheus.BuildFQName(NAMESPACE, "", "logger_dropped_messages_total"),
+			"Number of log messages dropped because the queue was full, by the message that was dropped.", []string{"dropped"}, nil),
+		stats: log.QueueStats,
+	}
+}
+
+func (c *loggerQueueCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.depth
+	descs <- c.capacity
+	descs <- c.dropped
+}
+
+func (c *loggerQueueCollector) Collect(metrics chan<- prometheus.Metric) {
+	stats, ok := c.stats()
+	if !ok {
+		return
+	}
+	metrics <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(stats.Queued))
+	metrics <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(stats.BufferSize))
+	metrics <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(stats.DroppedOldest), "oldest")
+	metrics <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(stats.DroppedNew), "new")
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry agrupa las métricas que se publican en el endpoint /metrics. Es propio de la aplicación en lugar del
+// registro global de Prometheus, así ninguna dependencia publica métricas sin que se den de alta aquí
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		RepositoryOperationDuration,
+		RepositoryOperationErrors,
+		ThumbnailGenerationDuration,
+		ThumbnailGenerationErrors,
+		ThumbnailBytes,
+		EmailsSent,
+		newLoggerQueueCollector(),
+	)
+}
+
+// Handler publica las métricas del registro en el formato de texto de Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
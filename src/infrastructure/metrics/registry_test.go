@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	log "go-gallery/src/infrastructure/logger"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveRepositoryOperation(t *testing.T) {
+	ObserveRepositoryOperation("test_repository", "find", time.Now(), 0)
+	ObserveRepositoryOperation("test_repository", "find", time.Now(), 404)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(RepositoryOperationDuration))
+	assert.Equal(t, float64(1), testutil.ToFloat64(RepositoryOperationErrors.WithLabelValues("test_repository", "find", "404")))
+}
+
+func TestObserveEmail(t *testing.T) {
+	ObserveEmail("test_template", nil)
+	ObserveEmail("test_template", errors.New("smtp error"))
+	ObserveEmail("test_template", nil)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(EmailsSent.WithLabelValues("test_template", EMAIL_OUTCOME_SUCCESS)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(EmailsSent.WithLabelValues("test_template", EMAIL_OUTCOME_FAILURE)))
+}
+
+func TestLoggerQueueCollector(t *testing.T) {
+	collector := newLoggerQueueCollector()
+	collector.stats = func() (log.AsyncStats, bool) {
+		return log.AsyncStats{BufferSize: 100, Queued: 7, DroppedOldest: 2, DroppedNew: 1}, true
+	}
+
+	expected := `
+# HELP go_gallery_logger_dropped_messages_total Number of log messages dropped because the queue was full, by the message that was dropped.
+# TYPE go_gallery_logger_dropped_messages_total counter
+go_gallery_logger_dropped_messages_total{dropped="new"} 1
+go_gallery_logger_dropped_messages_total{dropped="oldest"} 2
+# HELP go_gallery_logger_queue_capacity Maximum number of log messages the queue can hold.
+# TYPE go_gallery_logger_queue_capacity gauge
+go_gallery_logger_queue_capacity 100
+# HELP go_gallery_logger_queue_depth Number of log messages waiting to be written.
+# TYPE go_gallery_logger_queue_depth gauge
+go_gallery_logger_queue_depth 7
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	collector.stats = func() (log.AsyncStats, bool) { return log.AsyncStats{}, false }
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
+
+func TestHandlerPublishesTheRegistry(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	HTTPRequests.WithLabelValues("GET", "/test", "200").Inc()
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, string(body), `go_gallery_http_requests_total{method="GET",route="/test",status="200"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
@@ -0,0 +1,138 @@
+package mongoClient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "go-gallery/src/infrastructure/logger"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// sleep allows tests to skip the wait between connection attempts
+var sleep = time.Sleep
+
+var logger log.Logger
+
+// Provider comparte un único cliente de MongoDB, y por tanto un único pool de conexiones, entre
+// todos los repositorios. La conexión se abre la primera vez que un repositorio la necesita, así
+// no se conecta a MongoDB si ningún repositorio configurado lo usa
+type Provider struct {
+	settings Settings
+
+	mutex    sync.Mutex
+	client   *mongo.Client
+	database *mongo.Database
+}
+
+func NewProvider(settings Settings) *Provider {
+	logger = log.Instance()
+	return &Provider{settings: settings}
+}
+
+// Database devuelve la base de datos configurada, conectando si aún no se ha hecho.
+// Si MongoDB no responde tras todos los reintentos no se puede arrancar la aplicación
+func (p *Provider) Database() *mongo.Database {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.database != nil {
+		return p.database
+	}
+
+	client, err := connect(p.settings)
+	if err != nil {
+		panicMessage := fmt.Sprintf("Could not connect to MongoDB: %s", err.Error())
+		logger.Panic(panicMessage)
+		panic(panicMessage)
+	}
+
+	p.client = client
+	p.database = client.Database(p.settings.Database)
+	return p.database
+}
+
+func (p *Provider) Collection(name string) *mongo.Collection {
+	return p.Database().Collection(name)
+}
+
+func (p *Provider) DatabaseName() string {
+	return p.settings.Database
+}
+
+// WithTimeout limita la duración de una operación con MONGODB_OPERATION_TIMEOUT. Si el contexto
+// recibido vence antes se mantiene su plazo
+func (p *Provider) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.settings.OperationTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.settings.OperationTimeout)
+}
+
+// Connected indica si algún repositorio ha abierto ya la conexión
+func (p *Provider) Connected() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.client != nil
+}
+
+// HealthCheck comprueba que el nodo primario responde
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	return p.Database().Client().Ping(ctx, readpref.Primary())
+}
+
+// Close cierra las conexiones del pool. Si nunca se llegó a conectar no hace nada
+func (p *Provider) Close(ctx context.Context) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.client == nil {
+		return nil
+	}
+
+	err := p.client.Disconnect(ctx)
+	p.client = nil
+	p.database = nil
+	return err
+}
+
+// connect abre el cliente y espera a que el servidor responda, reintentando con una espera
+// que se duplica en cada intento
+func connect(settings Settings) (*mongo.Client, error) {
+	opts, err := clientOptions(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := mongo.Connect(context.Background(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	retries := max(settings.ConnectRetries, 1)
+	backoff := settings.ConnectRetryBackoff
+	for i := range retries {
+		ctx, cancel := context.WithTimeout(context.Background(), settings.ServerSelectionTimeout)
+		err = client.Ping(ctx, readpref.Primary())
+		cancel()
+		if err == nil {
+			logger.Info("Successfully connected to MongoDB", log.F("database", settings.Database), log.F("min_pool_size", settings.MinPoolSize), log.F("max_pool_size", settings.MaxPoolSize))
+			return client, nil
+		}
+
+		if i == retries-1 {
+			logger.Error("Could not connect to MongoDB after several attempts.")
+			break
+		}
+
+		logger.Warning("Attempt to connect to MongoDB failed, retrying", log.F("attempt", i+1), log.F("retries", retries), log.F("retry_in", backoff.String()), log.F("error", err.Error()))
+		sleep(backoff)
+		backoff = min(backoff*2, MAX_CONNECT_RETRY_BACKOFF)
+	}
+
+	client.Disconnect(context.Background())
+	return nil, err
+}
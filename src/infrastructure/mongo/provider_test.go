@@ -0,0 +1,74 @@
+package mongoClient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "go-gallery/src/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unreachableSettings(retries uint) Settings {
+	settings := ParseSettings(map[string]string{"MONGODB_URL_CONNECTION": "mongodb://127.0.0.1:1/?connect=direct"})
+	settings.ServerSelectionTimeout = 50 * time.Millisecond
+	settings.ConnectRetries = retries
+	return settings
+}
+
+func TestConnectRetriesWithBackoff(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	logger = log.Instance()
+
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	settings := unreachableSettings(4)
+	settings.ConnectRetryBackoff = 20 * time.Second
+
+	client, err := connect(settings)
+
+	assert.Nil(t, client)
+	assert.Error(t, err)
+	// The wait doubles after every failed attempt up to the maximum
+	assert.Equal(t, []time.Duration{20 * time.Second, MAX_CONNECT_RETRY_BACKOFF, MAX_CONNECT_RETRY_BACKOFF}, waits)
+}
+
+func TestDatabasePanicsWhenMongoIsUnreachable(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	provider := NewProvider(unreachableSettings(1))
+
+	assert.Panics(t, func() { provider.Database() })
+}
+
+func TestCloseWithoutConnecting(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	provider := NewProvider(unreachableSettings(1))
+
+	assert.NoError(t, provider.Close(context.Background()))
+}
+
+func TestWithTimeout(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	provider := NewProvider(Settings{OperationTimeout: time.Minute})
+
+	ctx, cancel := provider.WithTimeout(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	// A shorter deadline of the caller is kept
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Second)
+	defer cancelParent()
+	ctx, cancel = provider.WithTimeout(parent)
+	defer cancel()
+	deadline, _ = ctx.Deadline()
+	parentDeadline, _ := parent.Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+}
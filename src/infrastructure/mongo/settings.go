@@ -0,0 +1,153 @@
+package mongoClient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+const (
+	DEFAULT_MAX_POOL_SIZE            uint64 = 100
+	DEFAULT_MIN_POOL_SIZE            uint64 = 0
+	DEFAULT_CONNECT_TIMEOUT                 = 10 * time.Second
+	DEFAULT_SERVER_SELECTION_TIMEOUT        = 10 * time.Second
+	DEFAULT_OPERATION_TIMEOUT               = 10 * time.Second
+	DEFAULT_CONNECT_RETRIES          uint   = 5
+	DEFAULT_CONNECT_RETRY_BACKOFF           = 1 * time.Second
+	MAX_CONNECT_RETRY_BACKOFF               = 30 * time.Second
+)
+
+// Settings agrupa las opciones del cliente de MongoDB compartido por todos los repositorios
+type Settings struct {
+	URI                    string
+	Database               string
+	MaxPoolSize            uint64
+	MinPoolSize            uint64
+	MaxConnIdleTime        time.Duration
+	ConnectTimeout         time.Duration
+	ServerSelectionTimeout time.Duration
+	OperationTimeout       time.Duration
+	ReadConcern            string
+	WriteConcern           string
+	RetryWrites            bool
+	RetryReads             bool
+	ConnectRetries         uint
+	ConnectRetryBackoff    time.Duration
+}
+
+func ParseSettings(args map[string]string) Settings {
+	maxPoolSize, err := strconv.ParseUint(args["MONGODB_MAX_POOL_SIZE"], 10, 64)
+	if err != nil || maxPoolSize == 0 {
+		maxPoolSize = DEFAULT_MAX_POOL_SIZE
+	}
+
+	minPoolSize, err := strconv.ParseUint(args["MONGODB_MIN_POOL_SIZE"], 10, 64)
+	if err != nil || minPoolSize > maxPoolSize {
+		minPoolSize = DEFAULT_MIN_POOL_SIZE
+	}
+
+	connectRetries, err := strconv.ParseUint(args["MONGODB_CONNECT_RETRIES"], 10, 32)
+	if err != nil || connectRetries == 0 {
+		connectRetries = uint64(DEFAULT_CONNECT_RETRIES)
+	}
+
+	return Settings{
+		URI:                    args["MONGODB_URL_CONNECTION"],
+		Database:               args["MONGODB_DATABASE"],
+		MaxPoolSize:            maxPoolSize,
+		MinPoolSize:            minPoolSize,
+		MaxConnIdleTime:        parseSeconds(args["MONGODB_MAX_CONN_IDLE_TIME"], 0),
+		ConnectTimeout:         parseSeconds(args["MONGODB_CONNECT_TIMEOUT"], DEFAULT_CONNECT_TIMEOUT),
+		ServerSelectionTimeout: parseSeconds(args["MONGODB_SERVER_SELECTION_TIMEOUT"], DEFAULT_SERVER_SELECTION_TIMEOUT),
+		OperationTimeout:       parseSeconds(args["MONGODB_OPERATION_TIMEOUT"], DEFAULT_OPERATION_TIMEOUT),
+		ReadConcern:            strings.ToLower(strings.TrimSpace(args["MONGODB_READ_CONCERN"])),
+		WriteConcern:           strings.ToLower(strings.TrimSpace(args["MONGODB_WRITE_CONCERN"])),
+		RetryWrites:            parseBool(args["MONGODB_RETRY_WRITES"], true),
+		RetryReads:             parseBool(args["MONGODB_RETRY_READS"], true),
+		ConnectRetries:         uint(connectRetries),
+		ConnectRetryBackoff:    parseSeconds(args["MONGODB_CONNECT_RETRY_BACKOFF"], DEFAULT_CONNECT_RETRY_BACKOFF),
+	}
+}
+
+// clientOptions traduce la configuración a las opciones del driver. Sin read o write concern
+// se mantienen los valores por defecto del servidor
+func clientOptions(settings Settings) (*options.ClientOptions, error) {
+	opts := options.Client().
+		ApplyURI(settings.URI).
+		SetMaxPoolSize(settings.MaxPoolSize).
+		SetMinPoolSize(settings.MinPoolSize).
+		SetMaxConnIdleTime(settings.MaxConnIdleTime).
+		SetConnectTimeout(settings.ConnectTimeout).
+		SetServerSelectionTimeout(settings.ServerSelectionTimeout).
+		SetRetryWrites(settings.RetryWrites).
+		SetRetryReads(settings.RetryReads)
+
+	if settings.ReadConcern != "" {
+		readConcern, err := parseReadConcern(settings.ReadConcern)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetReadConcern(readConcern)
+	}
+
+	if settings.WriteConcern != "" {
+		writeConcern, err := parseWriteConcern(settings.WriteConcern)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetWriteConcern(writeConcern)
+	}
+
+	return opts, opts.Validate()
+}
+
+func parseReadConcern(level string) (*readconcern.ReadConcern, error) {
+	switch level {
+	case "local":
+		return readconcern.Local(), nil
+	case "majority":
+		return readconcern.Majority(), nil
+	case "available":
+		return readconcern.Available(), nil
+	case "linearizable":
+		return readconcern.Linearizable(), nil
+	case "snapshot":
+		return readconcern.Snapshot(), nil
+	default:
+		return nil, fmt.Errorf("unknown MongoDB read concern %q", level)
+	}
+}
+
+// parseWriteConcern acepta majority o el número de nodos que deben confirmar la escritura
+func parseWriteConcern(value string) (*writeconcern.WriteConcern, error) {
+	if value == "majority" {
+		return writeconcern.Majority(), nil
+	}
+
+	nodes, err := strconv.Atoi(value)
+	if err != nil || nodes < 0 {
+		return nil, fmt.Errorf("unknown MongoDB write concern %q", value)
+	}
+	return &writeconcern.WriteConcern{W: nodes}, nil
+}
+
+func parseSeconds(value string, defaultValue time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func parseBool(value string, defaultValue bool) bool {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
@@ -0,0 +1,101 @@
+package mongoClient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+func TestParseSettings(t *testing.T) {
+	settings := ParseSettings(map[string]string{
+		"MONGODB_URL_CONNECTION":           "mongodb://localhost:27017",
+		"MONGODB_DATABASE":                 "gallery",
+		"MONGODB_MAX_POOL_SIZE":            "50",
+		"MONGODB_MIN_POOL_SIZE":            "5",
+		"MONGODB_MAX_CONN_IDLE_TIME":       "60",
+		"MONGODB_CONNECT_TIMEOUT":          "3",
+		"MONGODB_SERVER_SELECTION_TIMEOUT": "4",
+		"MONGODB_OPERATION_TIMEOUT":        "2",
+		"MONGODB_READ_CONCERN":             " Majority ",
+		"MONGODB_WRITE_CONCERN":            "majority",
+		"MONGODB_RETRY_WRITES":             "false",
+		"MONGODB_RETRY_READS":              "false",
+		"MONGODB_CONNECT_RETRIES":          "3",
+		"MONGODB_CONNECT_RETRY_BACKOFF":    "2",
+	})
+
+	assert.Equal(t, "mongodb://localhost:27017", settings.URI)
+	assert.Equal(t, "gallery", settings.Database)
+	assert.Equal(t, uint64(50), settings.MaxPoolSize)
+	assert.Equal(t, uint64(5), settings.MinPoolSize)
+	assert.Equal(t, time.Minute, settings.MaxConnIdleTime)
+	assert.Equal(t, 3*time.Second, settings.ConnectTimeout)
+	assert.Equal(t, 4*time.Second, settings.ServerSelectionTimeout)
+	assert.Equal(t, 2*time.Second, settings.OperationTimeout)
+	assert.Equal(t, "majority", settings.ReadConcern)
+	assert.Equal(t, "majority", settings.WriteConcern)
+	assert.False(t, settings.RetryWrites)
+	assert.False(t, settings.RetryReads)
+	assert.Equal(t, uint(3), settings.ConnectRetries)
+	assert.Equal(t, 2*time.Second, settings.ConnectRetryBackoff)
+}
+
+func TestParseSettingsDefaults(t *testing.T) {
+	settings := ParseSettings(map[string]string{
+		"MONGODB_MAX_POOL_SIZE":     "0",
+		"MONGODB_MIN_POOL_SIZE":     "500",
+		"MONGODB_OPERATION_TIMEOUT": "-1",
+		"MONGODB_RETRY_WRITES":      "maybe",
+		"MONGODB_CONNECT_RETRIES":   "0",
+	})
+
+	assert.Equal(t, DEFAULT_MAX_POOL_SIZE, settings.MaxPoolSize)
+	assert.Equal(t, DEFAULT_MIN_POOL_SIZE, settings.MinPoolSize)
+	assert.Equal(t, time.Duration(0), settings.MaxConnIdleTime)
+	assert.Equal(t, DEFAULT_CONNECT_TIMEOUT, settings.ConnectTimeout)
+	assert.Equal(t, DEFAULT_SERVER_SELECTION_TIMEOUT, settings.ServerSelectionTimeout)
+	assert.Equal(t, DEFAULT_OPERATION_TIMEOUT, settings.OperationTimeout)
+	assert.Empty(t, settings.ReadConcern)
+	assert.Empty(t, settings.WriteConcern)
+	assert.True(t, settings.RetryWrites)
+	assert.True(t, settings.RetryReads)
+	assert.Equal(t, DEFAULT_CONNECT_RETRIES, settings.ConnectRetries)
+	assert.Equal(t, DEFAULT_CONNECT_RETRY_BACKOFF, settings.ConnectRetryBackoff)
+}
+
+func TestClientOptions(t *testing.T) {
+	settings := ParseSettings(map[string]string{
+		"MONGODB_URL_CONNECTION": "mongodb://localhost:27017",
+		"MONGODB_MAX_POOL_SIZE":  "20",
+		"MONGODB_READ_CONCERN":   "local",
+		"MONGODB_WRITE_CONCERN":  "2",
+	})
+
+	opts, err := clientOptions(settings)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20), *opts.MaxPoolSize)
+	assert.Equal(t, DEFAULT_SERVER_SELECTION_TIMEOUT, *opts.ServerSelectionTimeout)
+	assert.True(t, *opts.RetryWrites)
+	assert.Equal(t, readconcern.Local().Level, opts.ReadConcern.Level)
+	assert.Equal(t, &writeconcern.WriteConcern{W: 2}, opts.WriteConcern)
+}
+
+func TestClientOptionsWithoutConcerns(t *testing.T) {
+	opts, err := clientOptions(ParseSettings(map[string]string{"MONGODB_URL_CONNECTION": "mongodb://localhost:27017"}))
+
+	assert.NoError(t, err)
+	assert.Nil(t, opts.ReadConcern)
+	assert.Nil(t, opts.WriteConcern)
+}
+
+func TestClientOptionsInvalidConcerns(t *testing.T) {
+	_, err := clientOptions(ParseSettings(map[string]string{"MONGODB_READ_CONCERN": "strong"}))
+	assert.ErrorContains(t, err, "read concern")
+
+	_, err = clientOptions(ParseSettings(map[string]string{"MONGODB_WRITE_CONCERN": "all"}))
+	assert.ErrorContains(t, err, "write concern")
+}
@@ -0,0 +1,133 @@
+package postgresClient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "go-gallery/src/infrastructure/logger"
+
+	_ "github.com/lib/pq"
+)
+
+// sleep allows tests to skip the wait between connection attempts
+var sleep = time.Sleep
+
+var logger log.Logger
+
+// Provider comparte un único pool de conexiones con PostgreSQL entre todos los repositorios. La conexión
+// se abre la primera vez que un repositorio la necesita, así no se conecta a PostgreSQL si ningún
+// repositorio configurado lo usa
+type Provider struct {
+	settings Settings
+
+	mutex sync.Mutex
+	db    *sql.DB
+}
+
+func NewProvider(settings Settings) *Provider {
+	logger = log.Instance()
+	return &Provider{settings: settings}
+}
+
+// DB devuelve el pool de conexiones, conectando si aún no se ha hecho.
+// Si PostgreSQL no responde tras todos los reintentos no se puede arrancar la aplicación
+func (p *Provider) DB() *sql.DB {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.db != nil {
+		return p.db
+	}
+
+	db, err := connect(p.settings)
+	if err != nil {
+		panicMessage := fmt.Sprintf("Could not connect to PostgreSQL: %s", err.Error())
+		logger.Panic(panicMessage)
+		panic(panicMessage)
+	}
+
+	p.db = db
+	return p.db
+}
+
+// ExecFile ejecuta un fichero SQL, los repositorios lo usan para crear sus tablas al arrancar
+func (p *Provider) ExecFile(path string) {
+	ddl, err := os.ReadFile(path)
+	if err != nil {
+		panicMessage := fmt.Sprintf("Could not read the DDL file %s: %s", path, err.Error())
+		logger.Panic(panicMessage)
+		panic(panicMessage)
+	}
+
+	if _, err := p.DB().Exec(string(ddl)); err != nil {
+		panicMessage := fmt.Sprintf("Error executing the DDL file %s: %s", path, err.Error())
+		logger.Panic(panicMessage)
+		panic(panicMessage)
+	}
+}
+
+// Connected indica si algún repositorio ha abierto ya la conexión
+func (p *Provider) Connected() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.db != nil
+}
+
+// HealthCheck comprueba que PostgreSQL responde
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	return p.DB().PingContext(ctx)
+}
+
+// Close cierra las conexiones del pool. Si nunca se llegó a conectar no hace nada
+func (p *Provider) Close(ctx context.Context) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.db == nil {
+		return nil
+	}
+
+	err := p.db.Close()
+	p.db = nil
+	return err
+}
+
+// connect abre el pool y espera a que el servidor responda, reintentando con una espera
+// que se duplica en cada intento
+func connect(settings Settings) (*sql.DB, error) {
+	db, err := sql.Open("postgres", settings.connectionURL())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(settings.MaxOpenConns)
+	db.SetMaxIdleConns(settings.MaxIdleConns)
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
+
+	retries := max(settings.ConnectRetries, 1)
+	backoff := settings.ConnectRetryBackoff
+	for i := range retries {
+		err = db.Ping()
+		if err == nil {
+			logger.Info("Successfully connected to PostgreSQL", log.F("database", settings.Database), log.F("max_open_conns", settings.MaxOpenConns))
+			return db, nil
+		}
+
+		if i == retries-1 {
+			logger.Error("Could not connect to PostgreSQL after several attempts.")
+			break
+		}
+
+		logger.Warning("Attempt to connect to PostgreSQL failed, retrying", log.F("attempt", i+1), log.F("retries", retries), log.F("retry_in", backoff.String()), log.F("error", err.Error()))
+		sleep(backoff)
+		backoff = min(backoff*2, MAX_CONNECT_RETRY_BACKOFF)
+	}
+
+	db.Close()
+	return nil, err
+}
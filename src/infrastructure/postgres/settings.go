@@ -0,0 +1,82 @@
+package postgresClient
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_MAX_OPEN_CONNS        int  = 25
+	DEFAULT_MAX_IDLE_CONNS        int  = 5
+	DEFAULT_CONNECT_RETRIES       uint = 5
+	DEFAULT_CONNECT_RETRY_BACKOFF      = 1 * time.Second
+	MAX_CONNECT_RETRY_BACKOFF          = 30 * time.Second
+)
+
+// Settings agrupa las opciones del pool de PostgreSQL compartido por todos los repositorios
+type Settings struct {
+	User                string
+	Password            string
+	Database            string
+	Host                string
+	Port                string
+	MaxOpenConns        int
+	MaxIdleConns        int
+	ConnMaxLifetime     time.Duration
+	ConnMaxIdleTime     time.Duration
+	ConnectRetries      uint
+	ConnectRetryBackoff time.Duration
+}
+
+func ParseSettings(args map[string]string) Settings {
+	maxOpenConns, err := strconv.Atoi(args["POSTGRESQL_MAX_OPEN_CONNS"])
+	if err != nil || maxOpenConns <= 0 {
+		maxOpenConns = DEFAULT_MAX_OPEN_CONNS
+	}
+
+	maxIdleConns, err := strconv.Atoi(args["POSTGRESQL_MAX_IDLE_CONNS"])
+	if err != nil || maxIdleConns < 0 || maxIdleConns > maxOpenConns {
+		maxIdleConns = min(DEFAULT_MAX_IDLE_CONNS, maxOpenConns)
+	}
+
+	connectRetries, err := strconv.ParseUint(args["POSTGRESQL_CONNECT_RETRIES"], 10, 32)
+	if err != nil || connectRetries == 0 {
+		connectRetries = uint64(DEFAULT_CONNECT_RETRIES)
+	}
+
+	return Settings{
+		User:                args["POSTGRESQL_USER"],
+		Password:            args["POSTGRESQL_PASSWORD"],
+		Database:            args["POSTGRESQL_DB"],
+		Host:                args["POSTGRESQL_HOST"],
+		Port:                args["POSTGRESQL_PORT"],
+		MaxOpenConns:        maxOpenConns,
+		MaxIdleConns:        maxIdleConns,
+		ConnMaxLifetime:     parseSeconds(args["POSTGRESQL_CONN_MAX_LIFETIME"], 0),
+		ConnMaxIdleTime:     parseSeconds(args["POSTGRESQL_CONN_MAX_IDLE_TIME"], 0),
+		ConnectRetries:      uint(connectRetries),
+		ConnectRetryBackoff: parseSeconds(args["POSTGRESQL_CONNECT_RETRY_BACKOFF"], DEFAULT_CONNECT_RETRY_BACKOFF),
+	}
+}
+
+// connectionURL escapa el usuario y la contraseña, que pueden contener caracteres reservados
+func (s Settings) connectionURL() string {
+	connection := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(s.User, s.Password),
+		Host:     net.JoinHostPort(s.Host, s.Port),
+		Path:     "/" + s.Database,
+		RawQuery: "sslmode=disable",
+	}
+	return connection.String()
+}
+
+func parseSeconds(value string, defaultValue time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,316 @@
+package redisTest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server es un servidor en proceso compatible con el subconjunto del protocolo de Redis
+// que usa la aplicación, para poder probar el cliente go-redis sin un Redis real
+type Server struct {
+	password string
+	listener net.Listener
+	mu       sync.Mutex
+	values   map[string]entry
+	wg       sync.WaitGroup
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+type entry struct {
+	value      string
+	expiration time.Time
+}
+
+// NewServer arranca el servidor en un puerto libre de localhost
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener: listener,
+		values:   make(map[string]entry),
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// RequirePassword exige AUTH con la contraseña indicada a las nuevas conexiones
+func (s *Server) RequirePassword(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.password = password
+}
+
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close detiene el servidor y cierra las conexiones abiertas
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+// Keys devuelve las claves vigentes, útil para inspeccionar lo que se ha persistido
+func (s *Server) Keys() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make(map[string]string)
+	for key, e := range s.values {
+		if !s.expired(e) {
+			keys[key] = e.value
+		}
+	}
+	return keys
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			c.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.Close()
+	}()
+
+	reader := bufio.NewReader(c)
+	writer := bufio.NewWriter(c)
+	s.mu.Lock()
+	password := s.password
+	s.mu.Unlock()
+	authenticated := password == ""
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				writeError(writer, "ERR Protocol error")
+				writer.Flush()
+			}
+			return
+		}
+
+		if len(args) == 0 {
+			writeError(writer, "ERR Protocol error")
+		} else if strings.ToUpper(args[0]) == "AUTH" {
+			authenticated = len(args) == 2 && args[1] == password
+			if authenticated {
+				writeSimple(writer, "OK")
+			} else {
+				writeError(writer, "WRONGPASS invalid password")
+			}
+		} else if !authenticated {
+			writeError(writer, "NOAUTH Authentication required.")
+		} else {
+			s.execute(writer, args)
+		}
+
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) execute(w *bufio.Writer, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	command := strings.ToUpper(args[0])
+	switch {
+	case command == "PING":
+		writeSimple(w, "PONG")
+	case command == "SELECT" && len(args) == 2:
+		writeSimple(w, "OK")
+	case command == "GET" && len(args) == 2:
+		e, ok := s.lookup(args[1])
+		if !ok {
+			writeNil(w)
+			return
+		}
+		writeBulk(w, e.value)
+	case command == "GETDEL" && len(args) == 2:
+		e, ok := s.lookup(args[1])
+		if !ok {
+			writeNil(w)
+			return
+		}
+		delete(s.values, args[1])
+		writeBulk(w, e.value)
+	case command == "SET" && len(args) == 3:
+		s.values[args[1]] = entry{value: args[2]}
+		writeSimple(w, "OK")
+	case command == "SETEX" && len(args) == 4:
+		seconds, err := strconv.Atoi(args[2])
+		if err != nil || seconds <= 0 {
+			writeError(w, "ERR invalid expire time in 'setex' command")
+			return
+		}
+		s.values[args[1]] = entry{value: args[3], expiration: time.Now().Add(time.Duration(seconds) * time.Second)}
+		writeSimple(w, "OK")
+	case command == "INCR" && len(args) == 2:
+		e, _ := s.lookup(args[1])
+		current := int64(0)
+		if e.value != "" {
+			parsed, err := strconv.ParseInt(e.value, 10, 64)
+			if err != nil {
+				writeError(w, "ERR value is not an integer or out of range")
+				return
+			}
+			current = parsed
+		}
+		current++
+		// Como en Redis, INCR conserva la expiración de la clave
+		s.values[args[1]] = entry{value: strconv.FormatInt(current, 10), expiration: e.expiration}
+		writeInt(w, current)
+	case command == "DEL" && len(args) >= 2:
+		deleted := int64(0)
+		for _, key := range args[1:] {
+			if _, ok := s.lookup(key); ok {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		writeInt(w, deleted)
+	case command == "TTL" && len(args) == 2:
+		e, ok := s.lookup(args[1])
+		switch {
+		case !ok:
+			writeInt(w, -2)
+		case e.expiration.IsZero():
+			writeInt(w, -1)
+		default:
+			writeInt(w, int64(time.Until(e.expiration).Round(time.Second)/time.Second))
+		}
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command or wrong number of arguments for '%s'", args[0]))
+	}
+}
+
+// lookup devuelve la entrada si existe y elimina las que han expirado
+func (s *Server) lookup(key string) (entry, bool) {
+	e, ok := s.values[key]
+	if !ok {
+		return entry{}, false
+	}
+	if s.expired(e) {
+		delete(s.values, key)
+		return entry{}, false
+	}
+	return e, true
+}
+
+func (s *Server) expired(e entry) bool {
+	return !e.expiration.IsZero() && !time.Now().Before(e.expiration)
+}
+
+// readCommand lee un comando, que los clientes envían como un array de bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("redis: expected an array: %q", line)
+	}
+	size, err := strconv.Atoi(line[1:])
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("redis: invalid array length: %q", line)
+	}
+
+	args := make([]string, size)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("redis: expected a bulk string: %q", line)
+		}
+		length, err := strconv.Atoi(line[1:])
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("redis: invalid bulk length: %q", line)
+		}
+		buf := make([]byte, length+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:length])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed line: %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+func writeSimple(w *bufio.Writer, value string) {
+	fmt.Fprintf(w, "+%s\r\n", value)
+}
+
+func writeError(w *bufio.Writer, message string) {
+	fmt.Fprintf(w, "-%s\r\n", message)
+}
+
+func writeInt(w *bufio.Writer, value int64) {
+	fmt.Fprintf(w, ":%d\r\n", value)
+}
+
+func writeBulk(w *bufio.Writer, value string) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(value), value)
+}
+
+func writeNil(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
@@ -0,0 +1,13 @@
+package attemptRepository
+
+import (
+	attemptDTO "go-gallery/src/infrastructure/dto/attempt"
+	"time"
+)
+
+type AttemptRepository interface {
+	Find(key string) (*attemptDTO.AttemptDTO, error)
+	Increment(key string, expiration time.Duration) (int, error)
+	Block(key string, until time.Time) error
+	Reset(key string) error
+}
@@ -0,0 +1,153 @@
+package attemptRepository
+
+import (
+	"context"
+	attemptDTO "go-gallery/src/infrastructure/dto/attempt"
+	"sync"
+	"time"
+)
+
+const (
+	AttemptMemoryRepositoryKey string = "AttemptMemoryRepository"
+	DEFAULT_CLEANUP_INTERVAL   int    = 1
+)
+
+type AttemptMemoryRepository struct {
+	mutex           sync.Mutex
+	attempts        map[string]*attemptEntry
+	cleanupInterval time.Duration
+	stop            chan struct{}
+	stopOnce        sync.Once
+}
+
+type attemptEntry struct {
+	failures     int
+	blockedUntil time.Time
+	expiration   time.Time
+}
+
+var NowFunc = time.Now
+
+func NewAttemptMemoryRepository(args map[string]string) *AttemptMemoryRepository {
+	repository := &AttemptMemoryRepository{
+		attempts:        make(map[string]*attemptEntry),
+		cleanupInterval: time.Duration(DEFAULT_CLEANUP_INTERVAL) * time.Minute,
+	}
+
+	repository.StartAutoCleanup()
+
+	return repository
+}
+
+func (r *AttemptMemoryRepository) Find(key string) (*attemptDTO.AttemptDTO, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	dto := &attemptDTO.AttemptDTO{Key: key}
+
+	entry := r.activeEntry(key)
+	if entry != nil {
+		dto.Failures = entry.failures
+		dto.BlockedUntil = entry.blockedUntil
+	}
+
+	return dto, nil
+}
+
+func (r *AttemptMemoryRepository) Increment(key string, expiration time.Duration) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry := r.activeEntry(key)
+	if entry == nil {
+		entry = &attemptEntry{}
+		r.attempts[key] = entry
+	}
+
+	entry.failures++
+	entry.expiration = latest(entry.expiration, NowFunc().Add(expiration))
+
+	return entry.failures, nil
+}
+
+func (r *AttemptMemoryRepository) Block(key string, until time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry := r.activeEntry(key)
+	if entry == nil {
+		entry = &attemptEntry{}
+		r.attempts[key] = entry
+	}
+
+	entry.blockedUntil = until
+	entry.expiration = latest(entry.expiration, until)
+
+	return nil
+}
+
+func (r *AttemptMemoryRepository) Reset(key string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *AttemptMemoryRepository) StartAutoCleanup() {
+	r.stop = make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-time.After(r.cleanupInterval):
+				r.cleanupExpiredAttempts()
+			}
+		}
+	}()
+}
+
+// Close detiene la limpieza periódica de los intentos expirados
+func (r *AttemptMemoryRepository) Close(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		if r.stop != nil {
+			close(r.stop)
+		}
+	})
+	return nil
+}
+
+func (r *AttemptMemoryRepository) cleanupExpiredAttempts() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := NowFunc()
+	for key, entry := range r.attempts {
+		if now.After(entry.expiration) {
+			delete(r.attempts, key)
+		}
+	}
+}
+
+// Devuelve la entrada de la clave si existe y no ha expirado, debe llamarse con el mutex bloqueado
+func (r *AttemptMemoryRepository) activeEntry(key string) *attemptEntry {
+	entry, exists := r.attempts[key]
+	if !exists {
+		return nil
+	}
+
+	if NowFunc().After(entry.expiration) {
+		delete(r.attempts, key)
+		return nil
+	}
+
+	return entry
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+package attemptRepository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const KEY_EXAMPLE = "login:account:sanbricio"
+
+func newRepository() *AttemptMemoryRepository {
+	return &AttemptMemoryRepository{
+		attempts:        make(map[string]*attemptEntry),
+		cleanupInterval: time.Minute,
+	}
+}
+
+func TestIncrement(t *testing.T) {
+	repository := newRepository()
+
+	failures, err := repository.Increment(KEY_EXAMPLE, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	failures, _ = repository.Increment(KEY_EXAMPLE, time.Minute)
+	assert.Equal(t, 2, failures)
+
+	dto, err := repository.Find(KEY_EXAMPLE)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dto.Failures)
+}
+
+func TestFindUnknownKey(t *testing.T) {
+	repository := newRepository()
+
+	dto, err := repository.Find("unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dto.Failures)
+	assert.True(t, dto.BlockedUntil.IsZero())
+}
+
+func TestBlockAndReset(t *testing.T) {
+	repository := newRepository()
+	until := time.Now().Add(time.Minute)
+
+	repository.Increment(KEY_EXAMPLE, time.Minute)
+	repository.Block(KEY_EXAMPLE, until)
+
+	dto, _ := repository.Find(KEY_EXAMPLE)
+	assert.Equal(t, until, dto.BlockedUntil)
+
+	repository.Reset(KEY_EXAMPLE)
+
+	dto, _ = repository.Find(KEY_EXAMPLE)
+	assert.Equal(t, 0, dto.Failures)
+	assert.True(t, dto.BlockedUntil.IsZero())
+}
+
+func TestAttemptsExpire(t *testing.T) {
+	repository := newRepository()
+	repository.Increment(KEY_EXAMPLE, time.Minute)
+
+	NowFunc = func() time.Time {
+		return time.Now().Add(2 * time.Minute)
+	}
+	defer func() { NowFunc = time.Now }()
+
+	dto, _ := repository.Find(KEY_EXAMPLE)
+	assert.Equal(t, 0, dto.Failures, "expected attempts to expire after the window")
+
+	repository.Increment(KEY_EXAMPLE, time.Minute)
+	repository.cleanupExpiredAttempts()
+	assert.Len(t, repository.attempts, 1)
+}
@@ -0,0 +1,12 @@
+package auditRepository
+
+import (
+	"go-gallery/src/commons/exception"
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+)
+
+type AuditRepository interface {
+	Insert(event *auditDTO.AuditEventDTO) *exception.ApiException
+	// Find returns the events matching the filter from the newest to the oldest, starting after filter.LastId
+	Find(filter *auditDTO.AuditFilterDTO) (*auditDTO.AuditCursorDTO, *exception.ApiException)
+}
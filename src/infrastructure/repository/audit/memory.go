@@ -0,0 +1,69 @@
+package auditRepository
+
+import (
+	"go-gallery/src/commons/exception"
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const AuditMemoryRepositoryKey string = "AuditMemoryRepository"
+
+// AuditMemoryRepository guarda los eventos en memoria, se pierden al reiniciar
+type AuditMemoryRepository struct {
+	mutex  sync.RWMutex
+	events []auditDTO.AuditEventDTO
+}
+
+func NewAuditMemoryRepository(args map[string]string) *AuditMemoryRepository {
+	return &AuditMemoryRepository{}
+}
+
+func (r *AuditMemoryRepository) Insert(event *auditDTO.AuditEventDTO) *exception.ApiException {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Kept sorted by id so the pages match the database implementations
+	index, _ := slices.BinarySearchFunc(r.events, event.Id, func(stored auditDTO.AuditEventDTO, id string) int {
+		return strings.Compare(stored.Id, id)
+	})
+	r.events = slices.Insert(r.events, index, *event)
+	return nil
+}
+
+func (r *AuditMemoryRepository) Find(filter *auditDTO.AuditFilterDTO) (*auditDTO.AuditCursorDTO, *exception.ApiException) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := &auditDTO.AuditCursorDTO{Events: []auditDTO.AuditEventDTO{}}
+	for i := len(r.events) - 1; i >= 0 && int64(len(result.Events)) < filter.PageSize; i-- {
+		if matches(&r.events[i], filter) {
+			result.Events = append(result.Events, r.events[i])
+		}
+	}
+
+	if len(result.Events) > 0 {
+		result.LastId = result.Events[len(result.Events)-1].Id
+	}
+
+	return result, nil
+}
+
+func matches(event *auditDTO.AuditEventDTO, filter *auditDTO.AuditFilterDTO) bool {
+	switch {
+	case filter.LastId != "" && event.Id >= filter.LastId:
+		return false
+	case filter.Actor != "" && event.Actor != filter.Actor:
+		return false
+	case filter.Action != "" && event.Action != filter.Action:
+		return false
+	case filter.Outcome != "" && event.Outcome != filter.Outcome:
+		return false
+	case filter.From != nil && event.Timestamp.Before(*filter.From):
+		return false
+	case filter.To != nil && !event.Timestamp.Before(*filter.To):
+		return false
+	}
+	return true
+}
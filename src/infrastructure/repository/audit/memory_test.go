@@ -0,0 +1,71 @@
+package auditRepository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func insertEvents(repo *AuditMemoryRepository, start time.Time) {
+	actions := []string{auditDTO.AUDIT_ACTION_LOGIN, auditDTO.AUDIT_ACTION_IMAGE_UPLOAD, auditDTO.AUDIT_ACTION_LOGIN, auditDTO.AUDIT_ACTION_LOGOUT}
+	// Inserted from the newest to the oldest on purpose
+	for index := len(actions) - 1; index >= 0; index-- {
+		outcome := auditDTO.AUDIT_OUTCOME_SUCCESS
+		if index == 2 {
+			outcome = auditDTO.AUDIT_OUTCOME_FAILURE
+		}
+		repo.Insert(&auditDTO.AuditEventDTO{
+			Id:        fmt.Sprintf("%03d", index),
+			Actor:     "alice",
+			Action:    actions[index],
+			Outcome:   outcome,
+			Timestamp: start.Add(time.Duration(index) * time.Minute),
+		})
+	}
+	repo.Insert(&auditDTO.AuditEventDTO{Id: "010", Actor: "bob", Action: auditDTO.AUDIT_ACTION_LOGIN, Timestamp: start.Add(time.Hour)})
+}
+
+func ids(cursor *auditDTO.AuditCursorDTO) []string {
+	result := []string{}
+	for _, event := range cursor.Events {
+		result = append(result, event.Id)
+	}
+	return result
+}
+
+func TestAuditMemoryRepositoryPagination(t *testing.T) {
+	repo := NewAuditMemoryRepository(nil)
+	insertEvents(repo, time.Now())
+
+	page, _ := repo.Find(&auditDTO.AuditFilterDTO{Actor: "alice", PageSize: 3})
+	assert.Equal(t, []string{"003", "002", "001"}, ids(page))
+	assert.Equal(t, "001", page.LastId)
+
+	next, _ := repo.Find(&auditDTO.AuditFilterDTO{Actor: "alice", LastId: page.LastId, PageSize: 3})
+	assert.Equal(t, []string{"000"}, ids(next))
+
+	last, _ := repo.Find(&auditDTO.AuditFilterDTO{Actor: "alice", LastId: next.LastId, PageSize: 3})
+	assert.Empty(t, last.Events)
+	assert.Empty(t, last.LastId)
+}
+
+func TestAuditMemoryRepositoryFilters(t *testing.T) {
+	repo := NewAuditMemoryRepository(nil)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	insertEvents(repo, start)
+
+	logins, _ := repo.Find(&auditDTO.AuditFilterDTO{Action: auditDTO.AUDIT_ACTION_LOGIN, PageSize: 10})
+	assert.Equal(t, []string{"010", "002", "000"}, ids(logins))
+
+	failures, _ := repo.Find(&auditDTO.AuditFilterDTO{Outcome: auditDTO.AUDIT_OUTCOME_FAILURE, PageSize: 10})
+	assert.Len(t, failures.Events, 1)
+
+	from := start.Add(time.Minute)
+	to := start.Add(3 * time.Minute)
+	window, _ := repo.Find(&auditDTO.AuditFilterDTO{From: &from, To: &to, PageSize: 10})
+	assert.Equal(t, []string{"002", "001"}, ids(window))
+}
@@ -0,0 +1,119 @@
+package auditRepository
+
+import (
+	"context"
+	"fmt"
+	"go-gallery/src/commons/exception"
+
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+	log "go-gallery/src/infrastructure/logger"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AuditMongoDBRepositoryKey = "AuditMongoDBRepository"
+
+const (
+	AUDIT_COLLECTION string = "AuditEvent"
+	ID               string = "_id"
+	ACTOR            string = "actor"
+	ACTION           string = "action"
+	OUTCOME          string = "outcome"
+	TIMESTAMP        string = "timestamp"
+)
+
+var logger log.Logger
+
+type AuditMongoDBRepository struct {
+	provider   *mongoClient.Provider
+	mongoAudit *mongo.Collection
+}
+
+func NewAuditMongoDBRepository(provider *mongoClient.Provider) AuditRepository {
+	logger = log.Instance()
+
+	collection := provider.Collection(AUDIT_COLLECTION)
+
+	// The activity of a user and the events of an action are read newest first
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: ACTOR, Value: 1}, {Key: ID, Value: -1}}},
+		{Keys: bson.D{{Key: ACTION, Value: 1}, {Key: ID, Value: -1}}},
+	}
+	ctx, cancel := provider.WithTimeout(context.Background())
+	defer cancel()
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		panicMessage := fmt.Sprintf("Unable to create the indexes of the audit events: %s", err.Error())
+		logger.Panic(panicMessage)
+		panic(panicMessage)
+	}
+
+	logger.Info("Audit repository initialized", log.F("database", provider.DatabaseName()), log.F("collection", AUDIT_COLLECTION))
+	return &AuditMongoDBRepository{provider: provider, mongoAudit: collection}
+}
+
+func (r *AuditMongoDBRepository) Insert(event *auditDTO.AuditEventDTO) *exception.ApiException {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	if _, err := r.mongoAudit.InsertOne(ctx, event); err != nil {
+		logger.Error("Error storing audit event", log.F("action", event.Action), log.F("actor", event.Actor), log.F("error", err.Error()))
+		return exception.NewApiException(500, "Error storing audit event")
+	}
+	return nil
+}
+
+func (r *AuditMongoDBRepository) Find(filter *auditDTO.AuditFilterDTO) (*auditDTO.AuditCursorDTO, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	query := bson.M{}
+	if filter.LastId != "" {
+		query[ID] = bson.M{"$lt": filter.LastId}
+	}
+	if filter.Actor != "" {
+		query[ACTOR] = filter.Actor
+	}
+	if filter.Action != "" {
+		query[ACTION] = filter.Action
+	}
+	if filter.Outcome != "" {
+		query[OUTCOME] = filter.Outcome
+	}
+
+	timestamp := bson.M{}
+	if filter.From != nil {
+		timestamp["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		timestamp["$lt"] = *filter.To
+	}
+	if len(timestamp) > 0 {
+		query[TIMESTAMP] = timestamp
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: ID, Value: -1}}).
+		SetLimit(filter.PageSize)
+
+	cursor, err := r.mongoAudit.Find(ctx, query, findOptions)
+	if err != nil {
+		logger.Error("Error listing audit events", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error listing audit events")
+	}
+	defer cursor.Close(ctx)
+
+	result := &auditDTO.AuditCursorDTO{Events: []auditDTO.AuditEventDTO{}}
+	if err := cursor.All(ctx, &result.Events); err != nil {
+		logger.Error("Error decoding audit events", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error decoding audit events")
+	}
+
+	if len(result.Events) > 0 {
+		result.LastId = result.Events[len(result.Events)-1].Id
+	}
+
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package auditRepository
+
+import (
+	"database/sql"
+	"go-gallery/src/commons/exception"
+	"time"
+
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+	log "go-gallery/src/infrastructure/logger"
+	postgresClient "go-gallery/src/infrastructure/postgres"
+)
+
+const AuditPostgreSQLRepositoryKey = "AuditPostgreSQLRepository"
+
+type AuditPostgreSQLRepository struct {
+	db *sql.DB
+}
+
+func NewAuditPostgreSQLRepository(provider *postgresClient.Provider) AuditRepository {
+	logger = log.Instance()
+
+	// Ejecutar el DDL para crear la tabla si no existe
+	provider.ExecFile("sql/auditTableDDL.sql")
+	logger.Info("Audit repository initialized with the shared PostgreSQL connection pool")
+
+	return &AuditPostgreSQLRepository{db: provider.DB()}
+}
+
+func (r *AuditPostgreSQLRepository) Insert(event *auditDTO.AuditEventDTO) *exception.ApiException {
+	query := `INSERT INTO audit_events (id, actor, action, target, ip, user_agent, outcome, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8)`
+	_, err := r.db.Exec(query, event.Id, event.Actor, event.Action, event.Target, event.IP, event.UserAgent, event.Outcome, event.Timestamp)
+	if err != nil {
+		logger.Error("Error storing audit event", log.F("action", event.Action), log.F("actor", event.Actor), log.F("error", err.Error()))
+		return exception.NewApiException(500, "Error storing audit event")
+	}
+	return nil
+}
+
+func (r *AuditPostgreSQLRepository) Find(filter *auditDTO.AuditFilterDTO) (*auditDTO.AuditCursorDTO, *exception.ApiException) {
+	query := `SELECT id, actor, action, target, ip, user_agent, outcome, created_at FROM audit_events
+		WHERE ($1 = '' OR id < $1) AND ($2 = '' OR actor = $2) AND ($3 = '' OR action = $3) AND ($4 = '' OR outcome = $4)
+		AND ($5::timestamptz IS NULL OR created_at >= $5) AND ($6::timestamptz IS NULL OR created_at < $6)
+		ORDER BY id DESC LIMIT $7`
+	rows, err := r.db.Query(query, filter.LastId, filter.Actor, filter.Action, filter.Outcome,
+		nullTime(filter.From), nullTime(filter.To), filter.PageSize)
+	if err != nil {
+		logger.Error("Error listing audit events", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error listing audit events")
+	}
+	defer rows.Close()
+
+	result := &auditDTO.AuditCursorDTO{Events: []auditDTO.AuditEventDTO{}}
+	for rows.Next() {
+		var event auditDTO.AuditEventDTO
+		var target, ip, userAgent sql.NullString
+		if err := rows.Scan(&event.Id, &event.Actor, &event.Action, &target, &ip, &userAgent, &event.Outcome, &event.Timestamp); err != nil {
+			logger.Error("Error decoding audit event", log.F("error", err.Error()))
+			return nil, exception.NewApiException(500, "Error decoding audit events")
+		}
+		event.Target = target.String
+		event.IP = ip.String
+		event.UserAgent = userAgent.String
+		result.Events = append(result.Events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error("Error iterating audit events", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error listing audit events")
+	}
+
+	if len(result.Events) > 0 {
+		result.LastId = result.Events[len(result.Events)-1].Id
+	}
+
+	return result, nil
+}
+
+func nullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *value, Valid: true}
+}
@@ -0,0 +1,13 @@
+package avatarRepository
+
+import (
+	"go-gallery/src/commons/exception"
+	avatarDTO "go-gallery/src/infrastructure/dto/avatar"
+)
+
+type AvatarRepository interface {
+	// Upsert replaces the avatar of the user, every size is stored together
+	Upsert(dto *avatarDTO.AvatarDTO) *exception.ApiException
+	Find(username string) (*avatarDTO.AvatarDTO, *exception.ApiException)
+	Delete(username string) (int64, *exception.ApiException)
+}
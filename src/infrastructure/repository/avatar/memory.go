@@ -0,0 +1,53 @@
+package avatarRepository
+
+import (
+	"go-gallery/src/commons/exception"
+	avatarDTO "go-gallery/src/infrastructure/dto/avatar"
+	"maps"
+	"sync"
+)
+
+const AvatarMemoryRepositoryKey string = "AvatarMemoryRepository"
+
+type AvatarMemoryRepository struct {
+	mutex   sync.RWMutex
+	avatars map[string]avatarDTO.AvatarDTO
+}
+
+func NewAvatarMemoryRepository(args map[string]string) *AvatarMemoryRepository {
+	return &AvatarMemoryRepository{
+		avatars: make(map[string]avatarDTO.AvatarDTO),
+	}
+}
+
+func (r *AvatarMemoryRepository) Upsert(dto *avatarDTO.AvatarDTO) *exception.ApiException {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	avatar := *dto
+	avatar.Images = maps.Clone(dto.Images)
+	r.avatars[dto.Username] = avatar
+	return nil
+}
+
+func (r *AvatarMemoryRepository) Find(username string) (*avatarDTO.AvatarDTO, *exception.ApiException) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	avatar, found := r.avatars[username]
+	if !found {
+		return nil, exception.NewApiException(404, "Avatar not found")
+	}
+	return &avatar, nil
+}
+
+func (r *AvatarMemoryRepository) Delete(username string) (int64, *exception.ApiException) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, found := r.avatars[username]; !found {
+		return 0, nil
+	}
+	delete(r.avatars, username)
+	return 1, nil
+}
@@ -0,0 +1,44 @@
+package avatarRepository
+
+import (
+	avatarDTO "go-gallery/src/infrastructure/dto/avatar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpsertReplacesTheAvatar(t *testing.T) {
+	repo := NewAvatarMemoryRepository(nil)
+	images := map[string][]byte{"64": []byte("first")}
+	repo.Upsert(&avatarDTO.AvatarDTO{Username: "alice", Images: images, UpdatedAt: time.Now()})
+
+	// The stored avatar does not share the map of the caller
+	images["64"] = []byte("changed")
+	avatar, err := repo.Find("alice")
+	assert.Nil(t, err)
+	image, found := avatar.Image(64)
+	assert.True(t, found)
+	assert.Equal(t, []byte("first"), image)
+
+	repo.Upsert(&avatarDTO.AvatarDTO{Username: "alice", Images: map[string][]byte{"128": []byte("second")}})
+	avatar, _ = repo.Find("alice")
+	_, found = avatar.Image(64)
+	assert.False(t, found)
+}
+
+func TestDeleteAvatar(t *testing.T) {
+	repo := NewAvatarMemoryRepository(nil)
+	repo.Upsert(&avatarDTO.AvatarDTO{Username: "alice"})
+
+	deleted, err := repo.Delete("alice")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	_, err = repo.Find("alice")
+	assert.Equal(t, 404, err.Status)
+
+	deleted, err = repo.Delete("alice")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
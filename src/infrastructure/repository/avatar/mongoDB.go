@@ -0,0 +1,91 @@
+package avatarRepository
+
+import (
+	"context"
+	"errors"
+	"go-gallery/src/commons/exception"
+
+	avatarDTO "go-gallery/src/infrastructure/dto/avatar"
+	log "go-gallery/src/infrastructure/logger"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AvatarMongoDBRepositoryKey = "AvatarMongoDBRepository"
+
+const (
+	AVATAR_COLLECTION string = "Avatar"
+	ID                string = "_id"
+)
+
+var logger log.Logger
+
+type AvatarMongoDBRepository struct {
+	provider    *mongoClient.Provider
+	mongoAvatar *mongo.Collection
+}
+
+func NewAvatarMongoDBRepository(provider *mongoClient.Provider) AvatarRepository {
+	logger = log.Instance()
+
+	repo := &AvatarMongoDBRepository{
+		provider:    provider,
+		mongoAvatar: provider.Collection(AVATAR_COLLECTION),
+	}
+
+	logger.Info("Avatar repository initialized", log.F("database", provider.DatabaseName()), log.F("collection", AVATAR_COLLECTION))
+	return repo
+}
+
+func (r *AvatarMongoDBRepository) Upsert(dto *avatarDTO.AvatarDTO) *exception.ApiException {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info("Storing avatar", log.F("username", dto.Username))
+
+	filter := bson.M{ID: dto.Username}
+	_, err := r.mongoAvatar.ReplaceOne(ctx, filter, dto, options.Replace().SetUpsert(true))
+	if err != nil {
+		logger.Error("Error storing avatar", log.F("username", dto.Username), log.F("error", err.Error()))
+		return exception.NewApiException(500, "Error storing the avatar")
+	}
+
+	logger.Info("Avatar stored successfully", log.F("username", dto.Username))
+	return nil
+}
+
+func (r *AvatarMongoDBRepository) Find(username string) (*avatarDTO.AvatarDTO, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	avatar := new(avatarDTO.AvatarDTO)
+	err := r.mongoAvatar.FindOne(ctx, bson.M{ID: username}).Decode(avatar)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, exception.NewApiException(404, "Avatar not found")
+	}
+	if err != nil {
+		logger.Error("Error searching avatar", log.F("username", username), log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error searching the avatar")
+	}
+
+	return avatar, nil
+}
+
+func (r *AvatarMongoDBRepository) Delete(username string) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info("Attempting to delete avatar", log.F("username", username))
+
+	result, err := r.mongoAvatar.DeleteOne(ctx, bson.M{ID: username})
+	if err != nil {
+		logger.Error("Error deleting avatar", log.F("username", username), log.F("error", err.Error()))
+		return 0, exception.NewApiException(500, "Error deleting the avatar")
+	}
+
+	logger.Info("Avatars deleted", log.F("username", username), log.F("count", result.DeletedCount))
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,68 @@
+package codeGeneratorRepository
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// CODE_HASH_PURPOSE separa la clave de los códigos de la de otros usos del mismo secreto
+const CODE_HASH_PURPOSE string = "verification-code"
+
+// Parámetros comunes a todas las implementaciones del generador de códigos
+type codeGeneratorSettings struct {
+	expirationCode  time.Duration
+	cleanupInterval time.Duration
+	maxAttempts     int
+	hasher          codeHasher
+}
+
+func parseCodeGeneratorSettings(args map[string]string) codeGeneratorSettings {
+	expirationCode, err := strconv.Atoi(args["CODE_GENERATOR_EXPIRATION_CODE"])
+	if err != nil {
+		expirationCode = DEFAULT_EXPIRATION_CODE
+	}
+
+	cleanupInterval, err := strconv.Atoi(args["CODE_GENERATOR_CLEANUP_INTERVAL"])
+	if err != nil {
+		cleanupInterval = DEFAULT_CLEANUP_INTERVAL
+	}
+
+	maxAttempts, err := strconv.Atoi(args["CODE_GENERATOR_MAX_ATTEMPTS"])
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = DEFAULT_MAX_ATTEMPTS
+	}
+
+	return codeGeneratorSettings{
+		expirationCode:  time.Duration(expirationCode) * time.Minute,
+		cleanupInterval: time.Duration(cleanupInterval) * time.Minute,
+		maxAttempts:     maxAttempts,
+		hasher:          newCodeHasher(args["JWT_SECRET"]),
+	}
+}
+
+// codeHasher calcula el HMAC-SHA256 que se persiste en lugar del código. Solo hay 10^6 códigos posibles,
+// así que sin una clave del servidor cualquiera con acceso a la base de datos los recuperaría al instante
+type codeHasher struct {
+	key []byte
+}
+
+// newCodeHasher deriva la clave del secreto del servidor con su propio propósito
+func newCodeHasher(secret string) codeHasher {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(CODE_HASH_PURPOSE))
+	return codeHasher{key: mac.Sum(nil)}
+}
+
+// hash está ligado a la clave para que el mismo código de dos usuarios no produzca el mismo hash
+func (h codeHasher) hash(key, code string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(key + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h codeHasher) matches(key, code, codeHash string) bool {
+	return hmac.Equal([]byte(h.hash(key, code)), []byte(codeHash))
+}
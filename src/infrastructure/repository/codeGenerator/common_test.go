@@ -0,0 +1,47 @@
+package codeGeneratorRepository
+
+import (
+	utilsToken "go-gallery/src/commons/utils/token"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeGeneratorSettingsDefaults(t *testing.T) {
+	settings := parseCodeGeneratorSettings(map[string]string{})
+
+	assert.Equal(t, time.Duration(DEFAULT_EXPIRATION_CODE)*time.Minute, settings.expirationCode)
+	assert.Equal(t, time.Duration(DEFAULT_CLEANUP_INTERVAL)*time.Minute, settings.cleanupInterval)
+	assert.Equal(t, DEFAULT_MAX_ATTEMPTS, settings.maxAttempts)
+}
+
+func TestParseCodeGeneratorSettings(t *testing.T) {
+	settings := parseCodeGeneratorSettings(map[string]string{
+		"CODE_GENERATOR_EXPIRATION_CODE":  "10",
+		"CODE_GENERATOR_CLEANUP_INTERVAL": "2",
+		"CODE_GENERATOR_MAX_ATTEMPTS":     "0",
+	})
+
+	assert.Equal(t, 10*time.Minute, settings.expirationCode)
+	assert.Equal(t, 2*time.Minute, settings.cleanupInterval)
+	assert.Equal(t, DEFAULT_MAX_ATTEMPTS, settings.maxAttempts, "expected invalid max attempts to fall back to the default")
+}
+
+func TestHashCode(t *testing.T) {
+	hasher := newCodeHasher("server-secret")
+	hash := hasher.hash("user", "123456")
+
+	assert.NotContains(t, hash, "123456", "expected the code not to be stored in clear")
+	assert.NotEqual(t, hash, hasher.hash("other", "123456"), "expected the hash to depend on the key")
+	assert.True(t, hasher.matches("user", "123456", hash))
+	assert.False(t, hasher.matches("user", "654321", hash))
+}
+
+func TestHashCodeDependsOnTheServerSecret(t *testing.T) {
+	hash := newCodeHasher("server-secret").hash("user", "123456")
+
+	// Without the secret the hash cannot be recomputed to find the code
+	assert.NotEqual(t, utilsToken.HashToken("user:123456"), hash)
+	assert.False(t, newCodeHasher("other-secret").matches("user", "123456", hash))
+}
@@ -0,0 +1,177 @@
+package codeGeneratorRepository
+
+import (
+	"context"
+	"fmt"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+	postgresClient "go-gallery/src/infrastructure/postgres"
+	redisTest "go-gallery/src/infrastructure/redis/redisTest"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Batería común que deben cumplir todas las implementaciones de CodeGeneratorRepository
+func runCodeGeneratorConformance(t *testing.T, repo CodeGeneratorRepository, maxAttempts int) {
+	// Cada ejecución usa claves propias para no chocar con datos de otras pruebas
+	newKey := func(t *testing.T) string {
+		return fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano())
+	}
+
+	t.Run("GeneratesSixDigitCode", func(t *testing.T) {
+		code, err := repo.GenerateCode(newKey(t))
+
+		assert.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^[0-9]{6}$`), code)
+	})
+
+	t.Run("VerifiesGeneratedCode", func(t *testing.T) {
+		key := newKey(t)
+		code, _ := repo.GenerateCode(key)
+
+		assert.True(t, repo.VerifyCode(key, code), "expected code to be valid")
+		assert.False(t, repo.VerifyCode(key, code), "expected code to be consumed by a successful verification")
+	})
+
+	t.Run("RejectsWrongEmptyAndUnknown", func(t *testing.T) {
+		key := newKey(t)
+		code, _ := repo.GenerateCode(key)
+
+		assert.False(t, repo.VerifyCode(key, "invalidCode"))
+		assert.False(t, repo.VerifyCode(key, ""))
+		assert.False(t, repo.VerifyCode("", code))
+		assert.False(t, repo.VerifyCode(newKey(t)+"-unknown", code))
+	})
+
+	t.Run("CodesAreBoundToTheirKey", func(t *testing.T) {
+		key := newKey(t)
+		otherKey := key + "-other"
+		code, _ := repo.GenerateCode(key)
+		otherCode, _ := repo.GenerateCode(otherKey)
+		for otherCode == code {
+			otherCode, _ = repo.GenerateCode(otherKey)
+		}
+
+		assert.False(t, repo.VerifyCode(otherKey, code), "expected a code not to be valid for another key")
+		assert.True(t, repo.VerifyCode(key, code))
+		assert.True(t, repo.VerifyCode(otherKey, otherCode))
+	})
+
+	t.Run("NewCodeReplacesPrevious", func(t *testing.T) {
+		key := newKey(t)
+		first, _ := repo.GenerateCode(key)
+		second, _ := repo.GenerateCode(key)
+		for second == first {
+			second, _ = repo.GenerateCode(key)
+		}
+
+		assert.False(t, repo.VerifyCode(key, first), "expected previous code to be replaced")
+		assert.True(t, repo.VerifyCode(key, second))
+	})
+
+	t.Run("RejectsExpiredCode", func(t *testing.T) {
+		key := newKey(t)
+		code, _ := repo.GenerateCode(key)
+
+		NowFunc = func() time.Time {
+			return time.Now().Add(24 * time.Hour)
+		}
+		defer func() { NowFunc = time.Now }()
+
+		assert.False(t, repo.VerifyCode(key, code), "expected code to be expired")
+	})
+
+	t.Run("InvalidatedAfterMaxAttempts", func(t *testing.T) {
+		key := newKey(t)
+		code, _ := repo.GenerateCode(key)
+
+		for range maxAttempts {
+			assert.False(t, repo.VerifyCode(key, "wrongCode"))
+		}
+
+		assert.False(t, repo.VerifyCode(key, code), "expected code to be invalidated after too many wrong attempts")
+	})
+
+	t.Run("ValidBelowMaxAttempts", func(t *testing.T) {
+		key := newKey(t)
+		code, _ := repo.GenerateCode(key)
+
+		for range maxAttempts - 1 {
+			repo.VerifyCode(key, "wrongCode")
+		}
+
+		assert.True(t, repo.VerifyCode(key, code), "expected code to remain valid below the attempt limit")
+	})
+
+	t.Run("NewCodeResetsAttempts", func(t *testing.T) {
+		key := newKey(t)
+		repo.GenerateCode(key)
+		for range maxAttempts - 1 {
+			repo.VerifyCode(key, "wrongCode")
+		}
+
+		code, _ := repo.GenerateCode(key)
+		repo.VerifyCode(key, "wrongCode")
+
+		assert.True(t, repo.VerifyCode(key, code), "expected attempts to be reset with a new code")
+	})
+}
+
+func TestCodeGeneratorMemoryConformance(t *testing.T) {
+	runCodeGeneratorConformance(t, codeGen, codeGen.maxAttempts)
+}
+
+// Las implementaciones persistentes solo se prueban si hay una base de datos configurada
+func TestCodeGeneratorMongoDBConformance(t *testing.T) {
+	url := os.Getenv("MONGODB_URL_CONNECTION")
+	if url == "" {
+		t.Skip("MONGODB_URL_CONNECTION not set, skipping MongoDB code generator tests")
+	}
+
+	provider := mongoClient.NewProvider(mongoClient.ParseSettings(map[string]string{
+		"MONGODB_URL_CONNECTION": url,
+		"MONGODB_DATABASE":       os.Getenv("MONGODB_DATABASE"),
+	}))
+	defer provider.Close(context.Background())
+	repo := NewCodeGeneratorMongoDBRepository(map[string]string{}, provider)
+
+	runCodeGeneratorConformance(t, repo, repo.maxAttempts)
+}
+
+func TestCodeGeneratorPostgreSQLConformance(t *testing.T) {
+	if os.Getenv("POSTGRESQL_HOST") == "" {
+		t.Skip("POSTGRESQL_HOST not set, skipping PostgreSQL code generator tests")
+	}
+
+	// El DDL se lee con una ruta relativa a la raíz del proyecto
+	t.Chdir("../../../..")
+
+	provider := postgresClient.NewProvider(postgresClient.ParseSettings(map[string]string{
+		"POSTGRESQL_USER":     os.Getenv("POSTGRESQL_USER"),
+		"POSTGRESQL_PASSWORD": os.Getenv("POSTGRESQL_PASSWORD"),
+		"POSTGRESQL_DB":       os.Getenv("POSTGRESQL_DB"),
+		"POSTGRESQL_HOST":     os.Getenv("POSTGRESQL_HOST"),
+		"POSTGRESQL_PORT":     os.Getenv("POSTGRESQL_PORT"),
+	}))
+	defer provider.Close(context.Background())
+	repo := NewCodeGeneratorPostgreSQLRepository(map[string]string{}, provider)
+	defer repo.Close(context.Background())
+
+	runCodeGeneratorConformance(t, repo, repo.maxAttempts)
+}
+
+// Redis se prueba siempre contra un servidor compatible en proceso
+func TestCodeGeneratorRedisConformance(t *testing.T) {
+	server, err := redisTest.NewServer()
+	if err != nil {
+		t.Fatalf("could not start the Redis stand-in server: %s", err)
+	}
+	defer server.Close()
+
+	repo := NewCodeGeneratorRedisRepository(map[string]string{"REDIS_ADDRESS": server.Addr()})
+
+	runCodeGeneratorConformance(t, repo, repo.maxAttempts)
+}
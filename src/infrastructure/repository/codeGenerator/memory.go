@@ -1,9 +1,9 @@
 package codeGeneratorRepository
 
 import (
+	"context"
 	"crypto/rand"
 	"math/big"
-	"strconv"
 	"sync"
 	"time"
 )
@@ -12,27 +12,32 @@ const (
 	CodeGeneratorMemoryRepositoryKey string = "CodeGeneratorMemoryRepository"
 	DEFAULT_EXPIRATION_CODE          int    = 5
 	DEFAULT_CLEANUP_INTERVAL         int    = 1
+	DEFAULT_MAX_ATTEMPTS             int    = 3
 )
 
 type CodeGeneratorMemoryRepository struct {
 	expirationCode  time.Duration
 	cleanupInterval time.Duration
+	maxAttempts     int
+	hasher          codeHasher
+	stop            chan struct{}
+	stopOnce        sync.Once
 }
 
-func NewCodeGeneratorMemory(args map[string]string) *CodeGeneratorMemoryRepository {
-	expirationCode, err := strconv.Atoi(args["CODE_GENERATOR_EXPIRATION_CODE"])
-	if err != nil {
-		expirationCode = DEFAULT_EXPIRATION_CODE
-	}
+type codeEntry struct {
+	CodeHash   string
+	Expiration time.Time
+	Attempts   int
+}
 
-	cleanupInterval, err := strconv.Atoi(args["CODE_GENERATOR_CLEANUP_INTERVAL"])
-	if err != nil {
-		cleanupInterval = DEFAULT_CLEANUP_INTERVAL
-	}
+func NewCodeGeneratorMemory(args map[string]string) *CodeGeneratorMemoryRepository {
+	settings := parseCodeGeneratorSettings(args)
 
 	codeGenerator := &CodeGeneratorMemoryRepository{
-		expirationCode:  time.Duration(expirationCode) * time.Minute,
-		cleanupInterval: time.Duration(cleanupInterval) * time.Minute,
+		expirationCode:  settings.expirationCode,
+		cleanupInterval: settings.cleanupInterval,
+		maxAttempts:     settings.maxAttempts,
+		hasher:          settings.hasher,
 	}
 
 	codeGenerator.StartAutoCleanup()
@@ -42,10 +47,7 @@ func NewCodeGeneratorMemory(args map[string]string) *CodeGeneratorMemoryReposito
 
 var (
 	mutex sync.RWMutex
-	codes = make(map[string]struct {
-		Code       string
-		Expiration time.Time
-	})
+	codes = make(map[string]codeEntry)
 
 	NowFunc  = time.Now
 	RandFunc = rand.Int
@@ -60,11 +62,8 @@ func (c *CodeGeneratorMemoryRepository) GenerateCode(key string) (string, error)
 	if err != nil {
 		return "", err
 	}
-	codes[key] = struct {
-		Code       string
-		Expiration time.Time
-	}{
-		Code:       code,
+	codes[key] = codeEntry{
+		CodeHash:   c.hasher.hash(key, code),
 		Expiration: NowFunc().Add(c.expirationCode),
 	}
 
@@ -76,20 +75,34 @@ func (c *CodeGeneratorMemoryRepository) VerifyCode(key, code string) bool {
 		return false
 	}
 
-	mutex.RLock()
-	entry, exists := codes[key]
-	mutex.RUnlock()
+	mutex.Lock()
+	defer mutex.Unlock()
 
+	entry, exists := codes[key]
 	if !exists {
 		return false
 	}
 	// Si ha expirado el código lo eliminamos y devolvemos false
 	if NowFunc().After(entry.Expiration) {
-		c.removeCode(key)
+		delete(codes, key)
+		return false
+	}
+
+	// Un acierto consume el código para que no pueda reutilizarse
+	if c.hasher.matches(key, code, entry.CodeHash) {
+		delete(codes, key)
+		return true
+	}
+
+	// Tras superar el número de intentos fallidos el código queda invalidado
+	entry.Attempts++
+	if entry.Attempts >= c.maxAttempts {
+		delete(codes, key)
 		return false
 	}
+	codes[key] = entry
 
-	return entry.Code == code
+	return false
 }
 
 func (c *CodeGeneratorMemoryRepository) removeCode(key string) {
@@ -99,14 +112,29 @@ func (c *CodeGeneratorMemoryRepository) removeCode(key string) {
 }
 
 func (c *CodeGeneratorMemoryRepository) StartAutoCleanup() {
+	c.stop = make(chan struct{})
 	go func() {
 		for {
-			time.Sleep(c.cleanupInterval)
-			cleanupExpiredCodes()
+			select {
+			case <-c.stop:
+				return
+			case <-time.After(c.cleanupInterval):
+				cleanupExpiredCodes()
+			}
 		}
 	}()
 }
 
+// Close detiene la limpieza periódica de los códigos expirados
+func (c *CodeGeneratorMemoryRepository) Close(ctx context.Context) error {
+	c.stopOnce.Do(func() {
+		if c.stop != nil {
+			close(c.stop)
+		}
+	})
+	return nil
+}
+
 func cleanupExpiredCodes() {
 	mutex.Lock()
 	defer mutex.Unlock()
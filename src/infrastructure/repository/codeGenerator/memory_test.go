@@ -1,7 +1,9 @@
 package codeGeneratorRepository
 
 import (
+	"context"
 	"errors"
+	log "go-gallery/src/infrastructure/logger"
 	"io"
 	"math/big"
 	"os"
@@ -18,6 +20,8 @@ const (
 var codeGen *CodeGeneratorMemoryRepository
 
 func TestMain(m *testing.M) {
+	log.Init(log.NewConsoleLogger())
+
 	// Init global instance (como un BeforeAll)
 	codeGen = NewCodeGeneratorMemory(make(map[string]string))
 
@@ -99,6 +103,36 @@ func TestVerifyCodeExpired(t *testing.T) {
 	NowFunc = time.Now
 }
 
+func TestVerifyCodeInvalidatedAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	user := USER_EXAMPLE
+	code, _ := codeGen.GenerateCode(user)
+
+	// Act: Exhaust the allowed attempts with wrong codes
+	for range codeGen.maxAttempts {
+		assert.False(t, codeGen.VerifyCode(user, "wrongCode"), "expected wrong code to be invalid")
+	}
+
+	// Assert: The right code is no longer accepted
+	isValid := codeGen.VerifyCode(user, code)
+	assert.False(t, isValid, "expected code to be invalidated after too many wrong attempts, but it was valid")
+}
+
+func TestVerifyCodeBelowMaxAttempts(t *testing.T) {
+	// Arrange
+	user := USER_EXAMPLE
+	code, _ := codeGen.GenerateCode(user)
+
+	// Act: Fail one attempt less than the limit
+	for range codeGen.maxAttempts - 1 {
+		codeGen.VerifyCode(user, "wrongCode")
+	}
+
+	// Assert
+	isValid := codeGen.VerifyCode(user, code)
+	assert.True(t, isValid, "expected code to remain valid below the attempt limit, but it was invalid")
+}
+
 func TestRemoveCode(t *testing.T) {
 	// Arrange
 	user := USER_EXAMPLE
@@ -122,6 +156,7 @@ func TestAutoCleanup(t *testing.T) {
 
 	codeGen.GenerateCode(user)
 	codeGen.StartAutoCleanup()
+	defer codeGen.Close(context.Background())
 
 	time.Sleep(300 * time.Millisecond)
 
@@ -131,4 +166,27 @@ func TestAutoCleanup(t *testing.T) {
 	mutex.RUnlock()
 
 	assert.False(t, exists, "expected auto cleanup to remove expired code, but it still exists")
-}
\ No newline at end of file
+}
+func TestCloseStopsAutoCleanup(t *testing.T) {
+	// Arrange
+	user := USER_EXAMPLE
+	codeGen := &CodeGeneratorMemoryRepository{
+		expirationCode:  10 * time.Millisecond,
+		cleanupInterval: 50 * time.Millisecond,
+	}
+	codeGen.StartAutoCleanup()
+
+	// Act
+	assert.NoError(t, codeGen.Close(context.Background()))
+	assert.NoError(t, codeGen.Close(context.Background()))
+	codeGen.GenerateCode(user)
+	time.Sleep(150 * time.Millisecond)
+
+	// Assert
+	mutex.RLock()
+	_, exists := codes[user]
+	mutex.RUnlock()
+	codeGen.removeCode(user)
+
+	assert.True(t, exists, "expected the expired code to be kept once the cleanup has been stopped")
+}
@@ -0,0 +1,162 @@
+package codeGeneratorRepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	log "go-gallery/src/infrastructure/logger"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CodeGeneratorMongoDBRepositoryKey = "CodeGeneratorMongoDBRepository"
+
+const (
+	CODE_COLLECTION = "VerificationCode"
+	CODE_KEY        = "_id"
+	CODE_HASH       = "code_hash"
+	CODE_EXPIRATION = "expiration"
+	CODE_ATTEMPTS   = "attempts"
+)
+
+var logger log.Logger
+
+// CodeGeneratorMongoDBRepository persiste los códigos para que sobrevivan a reinicios y se
+// compartan entre réplicas. Los documentos caducados los elimina el índice TTL de MongoDB
+type CodeGeneratorMongoDBRepository struct {
+	provider       *mongoClient.Provider
+	mongo          *mongo.Collection
+	expirationCode time.Duration
+	maxAttempts    int
+	hasher         codeHasher
+}
+
+type codeDocument struct {
+	Key        string    `bson:"_id"`
+	CodeHash   string    `bson:"code_hash"`
+	Expiration time.Time `bson:"expiration"`
+	Attempts   int       `bson:"attempts"`
+}
+
+func NewCodeGeneratorMongoDBRepository(args map[string]string, provider *mongoClient.Provider) *CodeGeneratorMongoDBRepository {
+	logger = log.Instance()
+
+	settings := parseCodeGeneratorSettings(args)
+	collection := provider.Collection(CODE_COLLECTION)
+
+	// El índice TTL borra los códigos en cuanto pasa su fecha de expiración
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: CODE_EXPIRATION, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	ctx, cancel := provider.WithTimeout(context.Background())
+	defer cancel()
+	if _, err := collection.Indexes().CreateOne(ctx, index); err != nil {
+		panicMessage := fmt.Sprintf("Unable to create the TTL index of the verification codes: %s", err.Error())
+		logger.Panic(panicMessage)
+		panic(panicMessage)
+	}
+
+	return &CodeGeneratorMongoDBRepository{
+		provider:       provider,
+		mongo:          collection,
+		expirationCode: settings.expirationCode,
+		maxAttempts:    settings.maxAttempts,
+		hasher:         settings.hasher,
+	}
+}
+
+func (r *CodeGeneratorMongoDBRepository) GenerateCode(key string) (string, error) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	code, err := generateRandomCode(6)
+	if err != nil {
+		return "", err
+	}
+
+	document := codeDocument{
+		Key:        key,
+		CodeHash:   r.hasher.hash(key, code),
+		Expiration: NowFunc().Add(r.expirationCode),
+	}
+
+	// Un nuevo código sustituye al anterior y reinicia los intentos
+	opts := options.Replace().SetUpsert(true)
+	if _, err := r.mongo.ReplaceOne(ctx, bson.M{CODE_KEY: key}, document, opts); err != nil {
+		logger.Error("Error storing verification code", log.F("key", key), log.F("error", err.Error()))
+		return "", err
+	}
+
+	return code, nil
+}
+
+func (r *CodeGeneratorMongoDBRepository) VerifyCode(key, code string) bool {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	if key == "" || code == "" {
+		return false
+	}
+
+	var document codeDocument
+	err := r.mongo.FindOne(ctx, bson.M{CODE_KEY: key}).Decode(&document)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Error("Error searching verification code", log.F("key", key), log.F("error", err.Error()))
+		}
+		return false
+	}
+
+	// El índice TTL no es inmediato, así que comprobamos también la expiración y los intentos
+	if NowFunc().After(document.Expiration) || document.Attempts >= r.maxAttempts {
+		r.removeCode(key)
+		return false
+	}
+
+	// Solo se modifica el código comprobado, no uno generado entretanto
+	filter := bson.M{CODE_KEY: key, CODE_HASH: document.CodeHash}
+
+	// Un acierto consume el código, solo una de las peticiones concurrentes consigue borrarlo
+	if r.hasher.matches(key, code, document.CodeHash) {
+		consumeFilter := bson.M{CODE_KEY: key, CODE_HASH: document.CodeHash, CODE_ATTEMPTS: bson.M{"$lt": r.maxAttempts}}
+		result, err := r.mongo.DeleteOne(ctx, consumeFilter)
+		if err != nil {
+			logger.Error("Error consuming verification code", log.F("key", key), log.F("error", err.Error()))
+			return false
+		}
+		return result.DeletedCount == 1
+	}
+
+	update := bson.M{"$inc": bson.M{CODE_ATTEMPTS: 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = r.mongo.FindOneAndUpdate(ctx, filter, update, opts).Decode(&document)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Error("Error updating verification code attempts", log.F("key", key), log.F("error", err.Error()))
+		}
+		return false
+	}
+
+	// Tras superar el número de intentos fallidos el código queda invalidado
+	if document.Attempts >= r.maxAttempts {
+		if _, err := r.mongo.DeleteOne(ctx, filter); err != nil {
+			logger.Error("Error invalidating verification code", log.F("key", key), log.F("error", err.Error()))
+		}
+	}
+
+	return false
+}
+
+func (r *CodeGeneratorMongoDBRepository) removeCode(key string) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	if _, err := r.mongo.DeleteOne(ctx, bson.M{CODE_KEY: key}); err != nil {
+		logger.Error("Error removing verification code", log.F("key", key), log.F("error", err.Error()))
+	}
+}
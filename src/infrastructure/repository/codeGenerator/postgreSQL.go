@@ -0,0 +1,158 @@
+package codeGeneratorRepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	log "go-gallery/src/infrastructure/logger"
+	postgresClient "go-gallery/src/infrastructure/postgres"
+	"sync"
+	"time"
+)
+
+const CodeGeneratorPostgreSQLRepositoryKey = "CodeGeneratorPostgreSQLRepository"
+
+// CodeGeneratorPostgreSQLRepository persiste los códigos para que sobrevivan a reinicios y se
+// compartan entre réplicas. Los códigos caducados se eliminan periódicamente
+type CodeGeneratorPostgreSQLRepository struct {
+	db              *sql.DB
+	expirationCode  time.Duration
+	cleanupInterval time.Duration
+	maxAttempts     int
+	hasher          codeHasher
+	stop            chan struct{}
+	stopOnce        sync.Once
+}
+
+func NewCodeGeneratorPostgreSQLRepository(args map[string]string, provider *postgresClient.Provider) *CodeGeneratorPostgreSQLRepository {
+	logger = log.Instance()
+
+	// Ejecutar el DDL para crear la tabla si no existe
+	provider.ExecFile("sql/codeGeneratorTableDDL.sql")
+	logger.Info("Code generator repository initialized with the shared PostgreSQL connection pool")
+
+	settings := parseCodeGeneratorSettings(args)
+
+	repo := &CodeGeneratorPostgreSQLRepository{
+		db:              provider.DB(),
+		expirationCode:  settings.expirationCode,
+		cleanupInterval: settings.cleanupInterval,
+		maxAttempts:     settings.maxAttempts,
+		hasher:          settings.hasher,
+	}
+
+	repo.StartAutoCleanup()
+
+	return repo
+}
+
+func (r *CodeGeneratorPostgreSQLRepository) GenerateCode(key string) (string, error) {
+	code, err := generateRandomCode(6)
+	if err != nil {
+		return "", err
+	}
+
+	// Un nuevo código sustituye al anterior y reinicia los intentos
+	query := `INSERT INTO verification_codes (code_key, code_hash, expiration, attempts)
+		VALUES ($1, $2, $3, 0)
+		ON CONFLICT (code_key) DO UPDATE SET code_hash = EXCLUDED.code_hash, expiration = EXCLUDED.expiration, attempts = 0`
+	_, err = r.db.Exec(query, key, r.hasher.hash(key, code), NowFunc().Add(r.expirationCode))
+	if err != nil {
+		logger.Error("Error storing verification code", log.F("key", key), log.F("error", err.Error()))
+		return "", err
+	}
+
+	return code, nil
+}
+
+func (r *CodeGeneratorPostgreSQLRepository) VerifyCode(key, code string) bool {
+	if key == "" || code == "" {
+		return false
+	}
+
+	var codeHash string
+	var expiration time.Time
+	var attempts int
+	query := "SELECT code_hash, expiration, attempts FROM verification_codes WHERE code_key = $1"
+	err := r.db.QueryRow(query, key).Scan(&codeHash, &expiration, &attempts)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error("Error searching verification code", log.F("key", key), log.F("error", err.Error()))
+		}
+		return false
+	}
+
+	// Si ha expirado el código o se han agotado los intentos lo eliminamos y devolvemos false
+	if NowFunc().After(expiration) || attempts >= r.maxAttempts {
+		r.removeCode(key)
+		return false
+	}
+
+	// Un acierto consume el código, solo una de las peticiones concurrentes consigue borrarlo
+	if r.hasher.matches(key, code, codeHash) {
+		query = "DELETE FROM verification_codes WHERE code_key = $1 AND code_hash = $2 AND attempts < $3"
+		result, err := r.db.Exec(query, key, codeHash, r.maxAttempts)
+		if err != nil {
+			logger.Error("Error consuming verification code", log.F("key", key), log.F("error", err.Error()))
+			return false
+		}
+		deleted, err := result.RowsAffected()
+		return err == nil && deleted == 1
+	}
+
+	// Solo se incrementan los intentos del código comprobado, no de uno generado entretanto
+	query = "UPDATE verification_codes SET attempts = attempts + 1 WHERE code_key = $1 AND code_hash = $2 RETURNING attempts"
+	err = r.db.QueryRow(query, key, codeHash).Scan(&attempts)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error("Error updating verification code attempts", log.F("key", key), log.F("error", err.Error()))
+		}
+		return false
+	}
+
+	// Tras superar el número de intentos fallidos el código queda invalidado
+	if attempts >= r.maxAttempts {
+		_, err = r.db.Exec("DELETE FROM verification_codes WHERE code_key = $1 AND code_hash = $2", key, codeHash)
+		if err != nil {
+			logger.Error("Error invalidating verification code", log.F("key", key), log.F("error", err.Error()))
+		}
+	}
+
+	return false
+}
+
+func (r *CodeGeneratorPostgreSQLRepository) removeCode(key string) {
+	if _, err := r.db.Exec("DELETE FROM verification_codes WHERE code_key = $1", key); err != nil {
+		logger.Error("Error removing verification code", log.F("key", key), log.F("error", err.Error()))
+	}
+}
+
+func (r *CodeGeneratorPostgreSQLRepository) StartAutoCleanup() {
+	r.stop = make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-time.After(r.cleanupInterval):
+				r.cleanupExpiredCodes()
+			}
+		}
+	}()
+}
+
+func (r *CodeGeneratorPostgreSQLRepository) cleanupExpiredCodes() {
+	if _, err := r.db.Exec("DELETE FROM verification_codes WHERE expiration < $1", NowFunc()); err != nil {
+		logger.Error("Error cleaning up expired verification codes", log.F("error", err.Error()))
+	}
+}
+
+// Close detiene la limpieza periódica, el pool de conexiones lo cierra el proveedor compartido
+func (r *CodeGeneratorPostgreSQLRepository) Close(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		if r.stop != nil {
+			close(r.stop)
+		}
+	})
+	return nil
+}
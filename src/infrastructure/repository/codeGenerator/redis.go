@@ -0,0 +1,204 @@
+package codeGeneratorRepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	log "go-gallery/src/infrastructure/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const CodeGeneratorRedisRepositoryKey = "CodeGeneratorRedisRepository"
+
+const (
+	REDIS_CODE_PREFIX                = "code:"
+	REDIS_ATTEMPTS_PREFIX            = "code-attempts:"
+	REDIS_TIMEOUT                    = 5 * time.Second
+	REDIS_CONNECT_RETRIES       uint = 5
+	REDIS_CONNECT_RETRY_BACKOFF      = 1 * time.Second
+	MAX_REDIS_CONNECT_BACKOFF        = 30 * time.Second
+)
+
+// CodeGeneratorRedisRepository guarda el hash del código con SETEX, de modo que Redis lo
+// expira sin necesidad de limpieza, y lleva los intentos fallidos en un contador con INCR
+type CodeGeneratorRedisRepository struct {
+	client         *redis.Client
+	expirationCode time.Duration
+	maxAttempts    int
+	hasher         codeHasher
+}
+
+func NewCodeGeneratorRedisRepository(args map[string]string) *CodeGeneratorRedisRepository {
+	db, err := strconv.Atoi(args["REDIS_DB"])
+	if err != nil {
+		db = 0
+	}
+
+	logger = log.Instance()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         args["REDIS_ADDRESS"],
+		Password:     args["REDIS_PASSWORD"],
+		DB:           db,
+		DialTimeout:  REDIS_TIMEOUT,
+		ReadTimeout:  REDIS_TIMEOUT,
+		WriteTimeout: REDIS_TIMEOUT,
+		// Only RESP2 commands are used, the client does not need to announce itself
+		Protocol:        2,
+		DisableIdentity: true,
+	})
+
+	// Comprobamos si Redis realmente está disponible
+	backoff := REDIS_CONNECT_RETRY_BACKOFF
+	for i := range REDIS_CONNECT_RETRIES {
+		err = client.Ping(context.Background()).Err()
+		if err == nil {
+			logger.Info("Successfully connected to Redis code generator database")
+			break
+		}
+
+		// Si hemos llegado al último intento, mostramos un mensaje de error
+		if i == REDIS_CONNECT_RETRIES-1 {
+			logger.Error("Could not connect to Redis after several attempts.")
+			panicMessage := fmt.Sprintf("Error trying to connect to Redis: %s", err.Error())
+			logger.Panic(panicMessage)
+			panic(panicMessage)
+		}
+
+		logger.Warning("Attempt to connect to Redis failed, retrying", log.F("attempt", i+1), log.F("retries", REDIS_CONNECT_RETRIES), log.F("retry_in", backoff.String()), log.F("error", err.Error()))
+		time.Sleep(backoff)
+		backoff = min(backoff*2, MAX_REDIS_CONNECT_BACKOFF)
+	}
+
+	settings := parseCodeGeneratorSettings(args)
+
+	return &CodeGeneratorRedisRepository{
+		client:         client,
+		expirationCode: settings.expirationCode,
+		maxAttempts:    settings.maxAttempts,
+		hasher:         settings.hasher,
+	}
+}
+
+func (r *CodeGeneratorRedisRepository) GenerateCode(key string) (string, error) {
+	code, err := generateRandomCode(6)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+
+	// Un nuevo código sustituye al anterior y reinicia los intentos
+	if err := r.client.SetEx(ctx, REDIS_ATTEMPTS_PREFIX+key, "0", r.expirationCode).Err(); err != nil {
+		logger.Error("Error resetting verification code attempts", log.F("key", key), log.F("error", err.Error()))
+		return "", err
+	}
+
+	value := encodeRedisCode(r.hasher.hash(key, code), NowFunc().Add(r.expirationCode))
+	if err := r.client.SetEx(ctx, REDIS_CODE_PREFIX+key, value, r.expirationCode).Err(); err != nil {
+		logger.Error("Error storing verification code", log.F("key", key), log.F("error", err.Error()))
+		return "", err
+	}
+
+	return code, nil
+}
+
+func (r *CodeGeneratorRedisRepository) VerifyCode(key, code string) bool {
+	if key == "" || code == "" {
+		return false
+	}
+
+	ctx := context.Background()
+
+	value, err := r.client.Get(ctx, REDIS_CODE_PREFIX+key).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Error("Error searching verification code", log.F("key", key), log.F("error", err.Error()))
+		}
+		return false
+	}
+
+	codeHash, expiration, ok := decodeRedisCode(value)
+	// La expiración también se guarda en el valor para no depender solo del reloj de Redis
+	if !ok || NowFunc().After(expiration) {
+		r.removeCode(ctx, key)
+		return false
+	}
+
+	if r.hasher.matches(key, code, codeHash) {
+		attempts, err := r.client.Get(ctx, REDIS_ATTEMPTS_PREFIX+key).Int64()
+		if (err != nil && !errors.Is(err, redis.Nil)) || attempts >= int64(r.maxAttempts) {
+			return false
+		}
+		return r.consumeCode(ctx, key, value)
+	}
+
+	attempts, err := r.client.Incr(ctx, REDIS_ATTEMPTS_PREFIX+key).Result()
+	if err != nil {
+		logger.Error("Error updating verification code attempts", log.F("key", key), log.F("error", err.Error()))
+		return false
+	}
+
+	// Tras superar el número de intentos fallidos el código queda invalidado
+	if attempts >= int64(r.maxAttempts) {
+		r.removeCode(ctx, key)
+	}
+
+	return false
+}
+
+// consumeCode borra el código con GETDEL, solo una de las peticiones concurrentes obtiene el valor comprobado
+func (r *CodeGeneratorRedisRepository) consumeCode(ctx context.Context, key, value string) bool {
+	consumed, err := r.client.GetDel(ctx, REDIS_CODE_PREFIX+key).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Error("Error consuming verification code", log.F("key", key), log.F("error", err.Error()))
+		}
+		return false
+	}
+	if consumed != value {
+		return false
+	}
+
+	if err := r.client.Del(ctx, REDIS_ATTEMPTS_PREFIX+key).Err(); err != nil {
+		logger.Error("Error removing verification code attempts", log.F("key", key), log.F("error", err.Error()))
+	}
+	return true
+}
+
+func (r *CodeGeneratorRedisRepository) removeCode(ctx context.Context, key string) {
+	if err := r.client.Del(ctx, REDIS_CODE_PREFIX+key, REDIS_ATTEMPTS_PREFIX+key).Err(); err != nil {
+		logger.Error("Error removing verification code", log.F("key", key), log.F("error", err.Error()))
+	}
+}
+
+// El valor almacenado es "<hash>:<expiración en nanosegundos unix>"
+func encodeRedisCode(codeHash string, expiration time.Time) string {
+	return codeHash + ":" + strconv.FormatInt(expiration.UnixNano(), 10)
+}
+
+func decodeRedisCode(value string) (string, time.Time, bool) {
+	codeHash, rawExpiration, found := strings.Cut(value, ":")
+	if !found {
+		return "", time.Time{}, false
+	}
+	expiration, err := strconv.ParseInt(rawExpiration, 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return codeHash, time.Unix(0, expiration), true
+}
+
+// HealthCheck envía un PING a Redis que se cancela cuando vence el contexto
+func (r *CodeGeneratorRedisRepository) HealthCheck(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// Close cierra las conexiones abiertas con Redis
+func (r *CodeGeneratorRedisRepository) Close(ctx context.Context) error {
+	return r.client.Close()
+}
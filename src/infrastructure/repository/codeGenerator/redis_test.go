@@ -0,0 +1,94 @@
+package codeGeneratorRepository
+
+import (
+	"context"
+	redisTest "go-gallery/src/infrastructure/redis/redisTest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisRepository(t *testing.T, args map[string]string) (*CodeGeneratorRedisRepository, *redisTest.Server) {
+	server, err := redisTest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	args["REDIS_ADDRESS"] = server.Addr()
+	return NewCodeGeneratorRedisRepository(args), server
+}
+
+func TestRedisStoresOnlyHashedCodes(t *testing.T) {
+	repo, server := newRedisRepository(t, map[string]string{})
+
+	code, err := repo.GenerateCode(USER_EXAMPLE)
+	require.NoError(t, err)
+
+	stored := server.Keys()
+	assert.Contains(t, stored, REDIS_CODE_PREFIX+USER_EXAMPLE)
+	assert.Equal(t, "0", stored[REDIS_ATTEMPTS_PREFIX+USER_EXAMPLE])
+	for key, value := range stored {
+		assert.NotContains(t, value, code, "expected code not to be stored in clear under %s", key)
+	}
+	assert.True(t, strings.HasPrefix(stored[REDIS_CODE_PREFIX+USER_EXAMPLE], repo.hasher.hash(USER_EXAMPLE, code)))
+}
+
+func TestRedisRemovesKeysAfterMaxAttempts(t *testing.T) {
+	repo, server := newRedisRepository(t, map[string]string{"CODE_GENERATOR_MAX_ATTEMPTS": "2"})
+
+	repo.GenerateCode(USER_EXAMPLE)
+	repo.VerifyCode(USER_EXAMPLE, "wrongCode")
+	repo.VerifyCode(USER_EXAMPLE, "wrongCode")
+
+	assert.Empty(t, server.Keys(), "expected code and attempts to be removed after too many wrong attempts")
+}
+
+func TestRedisCodeExpiresInServer(t *testing.T) {
+	repo, server := newRedisRepository(t, map[string]string{})
+	repo.expirationCode = time.Second
+
+	code, _ := repo.GenerateCode(USER_EXAMPLE)
+	time.Sleep(1100 * time.Millisecond)
+
+	assert.Empty(t, server.Keys(), "expected Redis to expire the code")
+	assert.False(t, repo.VerifyCode(USER_EXAMPLE, code))
+}
+
+func TestDecodeRedisCode(t *testing.T) {
+	expiration := time.Unix(0, 1700000000000000000)
+
+	codeHash, decoded, ok := decodeRedisCode(encodeRedisCode("hash", expiration))
+	assert.True(t, ok)
+	assert.Equal(t, "hash", codeHash)
+	assert.True(t, expiration.Equal(decoded))
+
+	_, _, ok = decodeRedisCode("invalid")
+	assert.False(t, ok)
+	_, _, ok = decodeRedisCode("hash:notANumber")
+	assert.False(t, ok)
+}
+
+func TestRedisAuthenticatesWithThePassword(t *testing.T) {
+	server, err := redisTest.NewServer()
+	require.NoError(t, err)
+	server.RequirePassword("secret")
+	t.Cleanup(func() { server.Close() })
+
+	repo := NewCodeGeneratorRedisRepository(map[string]string{"REDIS_ADDRESS": server.Addr(), "REDIS_PASSWORD": "secret"})
+	t.Cleanup(func() { repo.Close(context.Background()) })
+
+	code, err := repo.GenerateCode(USER_EXAMPLE)
+	require.NoError(t, err)
+	assert.True(t, repo.VerifyCode(USER_EXAMPLE, code))
+}
+
+func TestRedisHealthCheckHonoursTheContext(t *testing.T) {
+	repo, _ := newRedisRepository(t, map[string]string{})
+	assert.NoError(t, repo.HealthCheck(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, repo.HealthCheck(ctx), context.Canceled)
+}
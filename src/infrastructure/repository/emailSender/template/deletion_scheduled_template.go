@@ -0,0 +1,62 @@
+package emailTemplate
+
+import (
+	"fmt"
+	"time"
+)
+
+// DeletionScheduledTemplate avisa de que la cuenta se borrará al terminar el periodo de gracia, recibe el enlace para cancelarlo
+type DeletionScheduledTemplate struct {
+	DeletionScheduledAt time.Time
+}
+
+func (t DeletionScheduledTemplate) Subject() string {
+	return "🗑️ Your go-gallery account is scheduled for deletion"
+}
+
+func (t DeletionScheduledTemplate) Body(link string, email string) string {
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<meta charset="UTF-8">
+			<title>Account Deletion Scheduled</title>
+		</head>
+		<body style="font-family: Arial, sans-serif; background-color: #f4f4f4; padding: 20px;">
+			<table align="center" width="100%%" bgcolor="#ffffff" style="max-width: 600px; padding: 20px; border-radius: 8px; box-shadow: 0px 0px 10px #cccccc;">
+				<tr>
+					<td align="center" style="padding-bottom: 20px;">
+						<h2 style="color: #333;">🗑️ Your account will be deleted</h2>
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 16px; color: #555;">
+						We received the confirmation to delete your Go Gallery account. Your account and all your images will be permanently deleted on <strong>%s</strong>.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 16px; color: #555; padding-top: 10px;">
+						Until then you will not be able to log in. If you changed your mind, you can cancel the deletion:
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="padding: 20px;">
+						<a href="%s" style="display: inline-block; background-color: #3498db; padding: 15px 30px; border-radius: 5px; font-size: 16px; font-weight: bold; color: #ffffff; text-decoration: none;">Keep my account</a>
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 14px; color: #777; padding-top: 20px;">
+						⚠️ If you didn't request this deletion, cancel it and change your password as soon as possible.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="padding-top: 30px; font-size: 12px; color: #aaa;">
+						Best regards, <br>
+						<strong>Go Gallery Support Team</strong><br>
+						<a href="mailto:gogalleryteam@gmail.com" style="color: #3498db; text-decoration: none;">gogalleryteam@gmail.com</a>
+					</td>
+				</tr>
+			</table>
+		</body>
+		</html>`, t.DeletionScheduledAt.UTC().Format("January 2, 2006 15:04 MST"), link)
+}
@@ -0,0 +1,56 @@
+package emailTemplate
+
+import "fmt"
+
+// EmailChangeNotificationTemplate se envía al correo anterior, recibe el enlace para revertir el cambio en lugar de un código
+type EmailChangeNotificationTemplate struct {
+	NewEmail string
+}
+
+func (t EmailChangeNotificationTemplate) Subject() string {
+	return "⚠️ The email address of your go-gallery account has been changed"
+}
+
+func (t EmailChangeNotificationTemplate) Body(revertLink string, email string) string {
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<meta charset="UTF-8">
+			<title>Email Changed</title>
+		</head>
+		<body style="font-family: Arial, sans-serif; background-color: #f4f4f4; padding: 20px;">
+			<table align="center" width="100%%" bgcolor="#ffffff" style="max-width: 600px; padding: 20px; border-radius: 8px; box-shadow: 0px 0px 10px #cccccc;">
+				<tr>
+					<td align="center" style="padding-bottom: 20px;">
+						<h2 style="color: #333;">⚠️ Email address changed</h2>
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 16px; color: #555;">
+						The email address of your Go Gallery account has been changed from <strong>%s</strong> to <strong>%s</strong>.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="padding: 20px;">
+						<a href="%s" style="display: inline-block; background-color: #e74c3c; padding: 15px 30px; border-radius: 5px; font-size: 16px; font-weight: bold; color: #ffffff; text-decoration: none;">
+							This wasn't me, revert the change
+						</a>
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 14px; color: #777; padding-top: 10px;">
+						If you made this change you can safely ignore this message.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="padding-top: 30px; font-size: 12px; color: #aaa;">
+						Best regards, <br>
+						<strong>Go Gallery Support Team</strong><br>
+						<a href="mailto:gogalleryteam@gmail.com" style="color: #3498db; text-decoration: none;">gogalleryteam@gmail.com</a>
+					</td>
+				</tr>
+			</table>
+		</body>
+		</html>`, email, t.NewEmail, revertLink)
+}
@@ -0,0 +1,62 @@
+package emailTemplate
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExportReadyTemplate avisa de que la exportación de datos está lista, recibe el enlace de descarga en lugar de un código
+type ExportReadyTemplate struct {
+	ExpiresIn time.Duration
+}
+
+func (t ExportReadyTemplate) Subject() string {
+	return "📦 Your go-gallery data export is ready"
+}
+
+func (t ExportReadyTemplate) Body(link string, email string) string {
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<meta charset="UTF-8">
+			<title>Data Export Ready</title>
+		</head>
+		<body style="font-family: Arial, sans-serif; background-color: #f4f4f4; padding: 20px;">
+			<table align="center" width="100%%" bgcolor="#ffffff" style="max-width: 600px; padding: 20px; border-radius: 8px; box-shadow: 0px 0px 10px #cccccc;">
+				<tr>
+					<td align="center" style="padding-bottom: 20px;">
+						<h2 style="color: #333;">📦 Your data export is ready</h2>
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 16px; color: #555;">
+						The copy of the data of your Go Gallery account has been generated. It contains your profile, your original images, their thumbnails and their metadata.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="padding: 20px;">
+						<a href="%s" style="display: inline-block; background-color: #3498db; padding: 15px 30px; border-radius: 5px; font-size: 16px; font-weight: bold; color: #ffffff; text-decoration: none;">Download my data</a>
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 14px; color: #777; padding-top: 20px;">
+						⚠️ This link is valid for <strong>%d hours</strong> and can only be used once. Do not share it with anyone.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 14px; color: #777; padding-top: 10px;">
+						If you didn't request this export, please change your password as soon as possible.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="padding-top: 30px; font-size: 12px; color: #aaa;">
+						Best regards, <br>
+						<strong>Go Gallery Support Team</strong><br>
+						<a href="mailto:gogalleryteam@gmail.com" style="color: #3498db; text-decoration: none;">gogalleryteam@gmail.com</a>
+					</td>
+				</tr>
+			</table>
+		</body>
+		</html>`, link, int(t.ExpiresIn.Hours()))
+}
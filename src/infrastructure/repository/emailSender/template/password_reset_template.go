@@ -0,0 +1,47 @@
+package emailTemplate
+
+import "fmt"
+
+// PasswordResetTemplate se envía cuando un administrador restablece la contraseña, recibe el nombre de usuario en lugar de un código
+type PasswordResetTemplate struct{}
+
+func (t PasswordResetTemplate) Subject() string {
+	return "🔑 The password of your go-gallery account has been reset"
+}
+
+func (t PasswordResetTemplate) Body(username string, email string) string {
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<meta charset="UTF-8">
+			<title>Password Reset</title>
+		</head>
+		<body style="font-family: Arial, sans-serif; background-color: #f4f4f4; padding: 20px;">
+			<table align="center" width="100%%" bgcolor="#ffffff" style="max-width: 600px; padding: 20px; border-radius: 8px; box-shadow: 0px 0px 10px #cccccc;">
+				<tr>
+					<td align="center" style="padding-bottom: 20px;">
+						<h2 style="color: #333;">🔑 Password reset required</h2>
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 16px; color: #555;">
+						An administrator has reset the password of your Go Gallery account <strong>%s</strong>. Your previous password no longer works.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="font-size: 14px; color: #777; padding-top: 20px;">
+						To access your account again, use the password recovery option on the login page with the address <strong>%s</strong> to choose a new password.
+					</td>
+				</tr>
+				<tr>
+					<td align="center" style="padding-top: 30px; font-size: 12px; color: #aaa;">
+						Best regards, <br>
+						<strong>Go Gallery Support Team</strong><br>
+						<a href="mailto:gogalleryteam@gmail.com" style="color: #3498db; text-decoration: none;">gogalleryteam@gmail.com</a>
+					</td>
+				</tr>
+			</table>
+		</body>
+		</html>`, username, email)
+}
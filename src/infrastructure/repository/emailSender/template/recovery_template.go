@@ -1,14 +1,34 @@
 package emailTemplate
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
-type RecoveryTemplate struct{}
+// RecoveryTemplate envía un código de recuperación o, si Link es true, recibe el enlace firmado en lugar del código
+type RecoveryTemplate struct {
+	Link          bool
+	LinkExpiresIn time.Duration
+}
 
 func (t RecoveryTemplate) Subject() string {
+	if t.Link {
+		return "🔑 Link to reset your go-gallery password"
+	}
 	return "🔑 Recovery code to reset your go-gallery password"
 }
 
 func (t RecoveryTemplate) Body(code string, email string) string {
+	instructions := "Use the following code to proceed:"
+	action := fmt.Sprintf(`<input type="text" value="%s" id="recoveryCode" readonly style="text-align: center; width: 250px; font-size: 24px; padding: 12px; border: 1px solid #ddd; border-radius: 5px; background-color: #f8f8f8; color: #333;" />`, code)
+	validity := "⚠️ This code is valid for <strong>5 minutes</strong>. Do not share it with anyone."
+
+	if t.Link {
+		instructions = "Use the following link to choose a new password:"
+		action = fmt.Sprintf(`<a href="%s" style="display: inline-block; background-color: #3498db; padding: 15px 30px; border-radius: 5px; font-size: 16px; font-weight: bold; color: #ffffff; text-decoration: none;">Reset my password</a>`, code)
+		validity = fmt.Sprintf("⚠️ This link is valid for <strong>%d minutes</strong> and can only be used once. Do not share it with anyone.", int(t.LinkExpiresIn.Minutes()))
+	}
+
 	return fmt.Sprintf(`
 		<!DOCTYPE html>
 		<html>
@@ -25,17 +45,17 @@ func (t RecoveryTemplate) Body(code string, email string) string {
 				</tr>
 				<tr>
 					<td align="center" style="font-size: 16px; color: #555;">
-						You have requested to reset your Go Gallery account password. Use the following code to proceed:
+						You have requested to reset your Go Gallery account password. %s
 					</td>
 				</tr>
 				<tr>
 					<td align="center" style="padding: 20px;">
-						<input type="text" value="%s" id="recoveryCode" readonly style="text-align: center; width: 250px; font-size: 24px; padding: 12px; border: 1px solid #ddd; border-radius: 5px; background-color: #f8f8f8; color: #333;" />
+						%s
 					</td>
 				</tr>
 				<tr>
 					<td align="center" style="font-size: 14px; color: #777; padding-top: 20px;">
-						⚠️ This code is valid for <strong>5 minutes</strong>. Do not share it with anyone.
+						%s
 					</td>
 				</tr>
 				<tr>
@@ -52,5 +72,5 @@ func (t RecoveryTemplate) Body(code string, email string) string {
 				</tr>
 			</table>
 		</body>
-		</html>`, code)
+		</html>`, instructions, action, validity)
 }
@@ -0,0 +1,17 @@
+package exportRepository
+
+import (
+	exportDTO "go-gallery/src/infrastructure/dto/export"
+	"time"
+)
+
+type ExportRepository interface {
+	// Insert stores the export unless the user already has an active one, ErrExportInProgress is returned then
+	Insert(dto *exportDTO.ExportJobDTO) error
+	Find(id string) (*exportDTO.ExportJobDTO, error)
+	FindLatest(username string) (*exportDTO.ExportJobDTO, error)
+	Update(dto *exportDTO.ExportJobDTO) error
+	// ClaimDownload marks a ready export as downloaded and returns it, only the first call for each export succeeds
+	ClaimDownload(id string) (*exportDTO.ExportJobDTO, error)
+	FindExpired(now time.Time) ([]exportDTO.ExportJobDTO, error)
+}
@@ -0,0 +1,115 @@
+package exportRepository
+
+import (
+	"errors"
+	exportDTO "go-gallery/src/infrastructure/dto/export"
+	"sync"
+	"time"
+)
+
+const ExportMemoryRepositoryKey string = "ExportMemoryRepository"
+
+var (
+	ErrExportNotFound     = errors.New("export not found")
+	ErrExportNotAvailable = errors.New("export is not available for download")
+	ErrExportInProgress   = errors.New("there is already an active export")
+)
+
+type ExportMemoryRepository struct {
+	mutex   sync.Mutex
+	exports map[string]exportDTO.ExportJobDTO
+}
+
+func NewExportMemoryRepository(args map[string]string) *ExportMemoryRepository {
+	return &ExportMemoryRepository{
+		exports: make(map[string]exportDTO.ExportJobDTO),
+	}
+}
+
+func (r *ExportMemoryRepository) Insert(dto *exportDTO.ExportJobDTO) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Checked under the same lock as the write, so two concurrent requests cannot both start an export
+	for _, export := range r.exports {
+		if export.Username == dto.Username && export.Active() {
+			return ErrExportInProgress
+		}
+	}
+
+	r.exports[dto.Id] = *dto
+	return nil
+}
+
+func (r *ExportMemoryRepository) Find(id string) (*exportDTO.ExportJobDTO, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	export, found := r.exports[id]
+	if !found {
+		return nil, ErrExportNotFound
+	}
+	return &export, nil
+}
+
+func (r *ExportMemoryRepository) FindLatest(username string) (*exportDTO.ExportJobDTO, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var latest *exportDTO.ExportJobDTO
+	for _, export := range r.exports {
+		if export.Username != username {
+			continue
+		}
+		if latest == nil || export.CreatedAt.After(latest.CreatedAt) {
+			copy := export
+			latest = &copy
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrExportNotFound
+	}
+	return latest, nil
+}
+
+func (r *ExportMemoryRepository) Update(dto *exportDTO.ExportJobDTO) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, found := r.exports[dto.Id]; !found {
+		return ErrExportNotFound
+	}
+	r.exports[dto.Id] = *dto
+	return nil
+}
+
+func (r *ExportMemoryRepository) ClaimDownload(id string) (*exportDTO.ExportJobDTO, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	export, found := r.exports[id]
+	if !found {
+		return nil, ErrExportNotFound
+	}
+	if export.Status != exportDTO.EXPORT_STATUS_READY {
+		return nil, ErrExportNotAvailable
+	}
+
+	export.Status = exportDTO.EXPORT_STATUS_DOWNLOADED
+	r.exports[id] = export
+	return &export, nil
+}
+
+func (r *ExportMemoryRepository) FindExpired(now time.Time) ([]exportDTO.ExportJobDTO, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	expired := make([]exportDTO.ExportJobDTO, 0)
+	for _, export := range r.exports {
+		if export.Status == exportDTO.EXPORT_STATUS_READY && export.ExpiresAt != nil && now.After(*export.ExpiresAt) {
+			expired = append(expired, export)
+		}
+	}
+	return expired, nil
+}
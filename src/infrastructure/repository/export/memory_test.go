@@ -0,0 +1,82 @@
+package exportRepository
+
+import (
+	exportDTO "go-gallery/src/infrastructure/dto/export"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLatest(t *testing.T) {
+	repo := NewExportMemoryRepository(nil)
+	now := time.Now()
+	repo.Insert(&exportDTO.ExportJobDTO{Id: "old", Username: "alice", CreatedAt: now.Add(-time.Hour)})
+	repo.Insert(&exportDTO.ExportJobDTO{Id: "new", Username: "alice", CreatedAt: now})
+	repo.Insert(&exportDTO.ExportJobDTO{Id: "other", Username: "bob", CreatedAt: now.Add(time.Hour)})
+
+	latest, err := repo.FindLatest("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "new", latest.Id)
+
+	_, err = repo.FindLatest("carol")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+}
+
+func TestClaimDownloadOnlyOnce(t *testing.T) {
+	repo := NewExportMemoryRepository(nil)
+	repo.Insert(&exportDTO.ExportJobDTO{Id: "export", Username: "alice", Status: exportDTO.EXPORT_STATUS_READY})
+
+	claimed, err := repo.ClaimDownload("export")
+	assert.NoError(t, err)
+	assert.Equal(t, exportDTO.EXPORT_STATUS_DOWNLOADED, claimed.Status)
+
+	_, err = repo.ClaimDownload("export")
+	assert.ErrorIs(t, err, ErrExportNotAvailable)
+
+	_, err = repo.ClaimDownload("missing")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+}
+
+func TestClaimDownloadNotReady(t *testing.T) {
+	repo := NewExportMemoryRepository(nil)
+	repo.Insert(&exportDTO.ExportJobDTO{Id: "export", Username: "alice", Status: exportDTO.EXPORT_STATUS_RUNNING})
+
+	_, err := repo.ClaimDownload("export")
+	assert.ErrorIs(t, err, ErrExportNotAvailable)
+}
+
+func TestFindExpired(t *testing.T) {
+	repo := NewExportMemoryRepository(nil)
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+	repo.Insert(&exportDTO.ExportJobDTO{Id: "expired", Username: "alice", Status: exportDTO.EXPORT_STATUS_READY, ExpiresAt: &past})
+	repo.Insert(&exportDTO.ExportJobDTO{Id: "valid", Username: "bob", Status: exportDTO.EXPORT_STATUS_READY, ExpiresAt: &future})
+	repo.Insert(&exportDTO.ExportJobDTO{Id: "downloaded", Username: "carol", Status: exportDTO.EXPORT_STATUS_DOWNLOADED, ExpiresAt: &past})
+
+	expired, err := repo.FindExpired(now)
+	assert.NoError(t, err)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, "expired", expired[0].Id)
+}
+
+func TestUpdateMissingExport(t *testing.T) {
+	repo := NewExportMemoryRepository(nil)
+
+	assert.ErrorIs(t, repo.Update(&exportDTO.ExportJobDTO{Id: "missing"}), ErrExportNotFound)
+}
+
+func TestInsertRejectsASecondActiveExport(t *testing.T) {
+	repo := NewExportMemoryRepository(nil)
+	assert.NoError(t, repo.Insert(&exportDTO.ExportJobDTO{Id: "first", Username: "alice", Status: exportDTO.EXPORT_STATUS_PENDING}))
+
+	err := repo.Insert(&exportDTO.ExportJobDTO{Id: "second", Username: "alice", Status: exportDTO.EXPORT_STATUS_PENDING})
+	assert.ErrorIs(t, err, ErrExportInProgress)
+	assert.NoError(t, repo.Insert(&exportDTO.ExportJobDTO{Id: "other", Username: "bob", Status: exportDTO.EXPORT_STATUS_PENDING}))
+
+	first, _ := repo.Find("first")
+	first.Status = exportDTO.EXPORT_STATUS_DOWNLOADED
+	assert.NoError(t, repo.Update(first))
+	assert.NoError(t, repo.Insert(&exportDTO.ExportJobDTO{Id: "second", Username: "alice", Status: exportDTO.EXPORT_STATUS_PENDING}))
+}
@@ -1,14 +1,16 @@
 package imageRepository
 
 import (
+	"context"
 	"go-gallery/src/commons/exception"
 	imageDTO "go-gallery/src/infrastructure/dto/image"
 )
 
 type ImageRepository interface {
-	Find(dto *imageDTO.ImageDTO) (*imageDTO.ImageDTO, *exception.ApiException)
-	Insert(dto *imageDTO.ImageUploadRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException)
-	Update(dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException)
-	Delete(dto *imageDTO.ImageDeleteRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException)
-	DeleteAll(dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException)
+	Find(ctx context.Context, dto *imageDTO.ImageDTO) (*imageDTO.ImageDTO, *exception.ApiException)
+	Insert(ctx context.Context, dto *imageDTO.ImageUploadRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException)
+	Update(ctx context.Context, dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException)
+	Delete(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException)
+	DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException)
+	StorageUsage(ctx context.Context, owner string) ([]imageDTO.StorageUsageDTO, *exception.ApiException)
 }
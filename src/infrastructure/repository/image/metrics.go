@@ -0,0 +1,75 @@
+package imageRepository
+
+import (
+	"context"
+	"go-gallery/src/commons/exception"
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	"go-gallery/src/infrastructure/metrics"
+	"time"
+)
+
+const IMAGE_METRICS_LABEL string = "image"
+
+// ImageMetricsRepository envuelve otro ImageRepository y registra la duración y los errores de cada operación
+type ImageMetricsRepository struct {
+	repository ImageRepository
+}
+
+func NewImageMetricsRepository(repository ImageRepository) ImageRepository {
+	return &ImageMetricsRepository{repository: repository}
+}
+
+// Unwrap devuelve el repositorio decorado
+func (r *ImageMetricsRepository) Unwrap() any {
+	return r.repository
+}
+
+func (r *ImageMetricsRepository) Find(ctx context.Context, dto *imageDTO.ImageDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Find(ctx, dto)
+	observeImageOperation("find", start, err)
+	return result, err
+}
+
+func (r *ImageMetricsRepository) Insert(ctx context.Context, dto *imageDTO.ImageUploadRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Insert(ctx, dto)
+	observeImageOperation("insert", start, err)
+	return result, err
+}
+
+func (r *ImageMetricsRepository) Update(ctx context.Context, dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Update(ctx, dto)
+	observeImageOperation("update", start, err)
+	return result, err
+}
+
+func (r *ImageMetricsRepository) Delete(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Delete(ctx, dto)
+	observeImageOperation("delete", start, err)
+	return result, err
+}
+
+func (r *ImageMetricsRepository) DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.DeleteAll(ctx, dto)
+	observeImageOperation("delete_all", start, err)
+	return result, err
+}
+
+func (r *ImageMetricsRepository) StorageUsage(ctx context.Context, owner string) ([]imageDTO.StorageUsageDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.StorageUsage(ctx, owner)
+	observeImageOperation("storage_usage", start, err)
+	return result, err
+}
+
+func observeImageOperation(operation string, start time.Time, err *exception.ApiException) {
+	errorStatus := 0
+	if err != nil {
+		errorStatus = err.Status
+	}
+	metrics.ObserveRepositoryOperation(IMAGE_METRICS_LABEL, operation, start, errorStatus)
+}
@@ -9,12 +9,14 @@ import (
 
 	imageDTO "go-gallery/src/infrastructure/dto/image"
 	log "go-gallery/src/infrastructure/logger"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+	"go-gallery/src/infrastructure/tracing"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.mongodb.org/mongo-driver/mongo/readpref"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const ImageMongoDBRepositoryKey = "ImageMongoDBRepository"
@@ -30,46 +32,30 @@ const (
 var logger log.Logger
 
 type ImageMongoDBRepository struct {
+	provider   *mongoClient.Provider
 	mongoImage *mongo.Collection
 }
 
-func NewImageMongoDBRepository(args map[string]string) ImageRepository {
-	urlConnection := args["MONGODB_URL_CONNECTION"]
-	databaseName := args["MONGODB_DATABASE"]
-
+func NewImageMongoDBRepository(provider *mongoClient.Provider) ImageRepository {
 	logger = log.Instance()
 
-	db := connect(urlConnection, databaseName)
-
 	repo := &ImageMongoDBRepository{
-		mongoImage: db.Collection(IMAGE_COLLECTION),
+		provider:   provider,
+		mongoImage: provider.Collection(IMAGE_COLLECTION),
 	}
 
-	logger.Info(fmt.Sprintf("Image repository initialized with connection to database '%s' and collection '%s'", databaseName, IMAGE_COLLECTION))
+	logger.Info("Image repository initialized", log.F("database", provider.DatabaseName()), log.F("collection", IMAGE_COLLECTION))
 	return repo
 }
 
-func connect(urlConnection string, databaseName string) *mongo.Database {
-	database, err := mongo.Connect(context.Background(), options.Client().ApplyURI(urlConnection))
-	if err != nil {
-		panicMessage := fmt.Sprintf("Could not connect to MongoDB: %s", err.Error())
-		logger.Panic(panicMessage)
-		panic(panicMessage)
-	}
-
-	err = database.Ping(context.Background(), readpref.Primary())
-	if err != nil {
-		panicMessage := fmt.Sprintf("Could not ping MongoDB: %s", err.Error())
-		logger.Panic(panicMessage)
-		panic(panicMessage)
-	}
+func (r *ImageMongoDBRepository) Find(ctx context.Context, dtoFind *imageDTO.ImageDTO) (image *imageDTO.ImageDTO, errFind *exception.ApiException) {
+	ctx, span := startSpan(ctx, "Find")
+	defer func() { tracing.End(span, errFind) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
 
-	logger.Info(fmt.Sprintf("Successfully connected to MongoDB with database '%s'", databaseName))
-	return database.Database(databaseName)
-}
-
-func (r *ImageMongoDBRepository) Find(dtoFind *imageDTO.ImageDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
-	objectID, errObjectID := getObjectID(dtoFind.Id)
+	objectID, errObjectID := getObjectID(ctx, dtoFind.Id)
 	if errObjectID != nil {
 		return nil, errObjectID
 	}
@@ -79,95 +65,107 @@ func (r *ImageMongoDBRepository) Find(dtoFind *imageDTO.ImageDTO) (*imageDTO.Ima
 		OWNER: dtoFind.Owner,
 	}
 
-	logger.Info(fmt.Sprintf("Searching for image with filter: %+v", filter))
+	logger.Info("Searching for image", log.F("id", *dtoFind.Id), log.F("owner", dtoFind.Owner))
 
-	result, err := r.find(filter)
+	result, err := r.find(ctx, filter)
 	if err != nil {
-		logger.Warning(fmt.Sprintf("Image not found with Id '%v' and Owner '%v'", *dtoFind.Id, dtoFind.Owner))
+		logger.Warning("Image not found", log.F("id", *dtoFind.Id), log.F("owner", dtoFind.Owner))
 		return nil, err
 	}
 
-	logger.Info(fmt.Sprintf("Image found: %v", *result[0].Id))
+	logger.Info("Image found", log.F("id", *result[0].Id))
 	return &result[0], nil
 }
 
-func (r *ImageMongoDBRepository) find(filter bson.M) ([]imageDTO.ImageDTO, *exception.ApiException) {
-	cursor, err := r.mongoImage.Find(context.Background(), filter)
+func (r *ImageMongoDBRepository) find(ctx context.Context, filter bson.M) ([]imageDTO.ImageDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	cursor, err := r.mongoImage.Find(ctx, filter)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error searching for images with filter: %+v - %s", filter, err.Error()))
+		logger.Error("Error searching for images", log.F("filter", filter), log.F("error", err.Error()))
 		return nil, exception.NewApiException(500, "Error searching for images")
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var results []imageDTO.ImageDTO
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var image imageDTO.ImageDTO
 		if err := cursor.Decode(&image); err != nil {
-			logger.Error(fmt.Sprintf("Error decoding image: %s", err.Error()))
+			logger.Error("Error decoding image", log.F("error", err.Error()))
 			return nil, exception.NewApiException(500, "Error decoding images")
 		}
 		results = append(results, image)
 	}
 
 	if len(results) == 0 {
-		logger.Warning(fmt.Sprintf("No results found with filter: %+v", filter))
+		logger.Warning("No images found", log.F("filter", filter))
 		return nil, exception.NewApiException(404, "Image not found")
 	}
 
-	logger.Info(fmt.Sprintf("Found %d images", len(results)))
+	logger.Info("Images found", log.F("count", len(results)))
 	return results, nil
 }
 
-func (r *ImageMongoDBRepository) Insert(dtoInsertImage *imageDTO.ImageUploadRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
+func (r *ImageMongoDBRepository) Insert(ctx context.Context, dtoInsertImage *imageDTO.ImageUploadRequestDTO) (inserted *imageDTO.ImageDTO, errInsertImage *exception.ApiException) {
+	ctx, span := startSpan(ctx, "Insert")
+	defer func() { tracing.End(span, errInsertImage) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
 	filter := bson.M{
 		NAME:      dtoInsertImage.Name,
 		OWNER:     dtoInsertImage.Owner,
 		EXTENSION: dtoInsertImage.Extension,
 	}
 
-	logger.Info(fmt.Sprintf("Checking if the image already exists with filter: %+v", filter))
+	logger.Info("Checking if the image already exists", log.F("owner", dtoInsertImage.Owner), log.F("name", dtoInsertImage.Name))
 
-	results, err := r.find(filter)
+	results, err := r.find(ctx, filter)
 	if err != nil && err.Status != 404 {
-		logger.Error(fmt.Sprintf("Error checking if the image already exists: %s", err.Message))
+		logger.Error("Error checking if the image already exists", log.F("error", err.Message))
 		return nil, err
 	}
 
 	if err == nil && len(results) > 0 {
-		logger.Warning(fmt.Sprintf("Image already exists for Owner '%s' with name '%s'", dtoInsertImage.Owner, dtoInsertImage.Name))
+		logger.Warning("Image already exists", log.F("owner", dtoInsertImage.Owner), log.F("name", dtoInsertImage.Name))
 		return nil, exception.NewApiException(409, "Image already exists")
 	}
 
-	logger.Info(fmt.Sprintf("Building image entity for owner '%s' with name '%s'", dtoInsertImage.Owner, dtoInsertImage.Name))
+	logger.Info("Building image entity", log.F("owner", dtoInsertImage.Owner), log.F("name", dtoInsertImage.Name))
 
 	image, errBuilder := imageBuilder.NewImageBuilder().
 		FromImageUploadRequestDTO(dtoInsertImage).
 		BuildNew()
 
 	if errBuilder != nil {
-		errorMessage := fmt.Sprintf("Error building image: %s", errBuilder.Error())
-		logger.Error(errorMessage)
-		return nil, exception.NewApiException(500, errorMessage)
+		logger.Error("Error building image", log.F("error", errBuilder.Error()))
+		return nil, exception.NewApiException(500, fmt.Sprintf("Error building image: %s", errBuilder.Error()))
 	}
 
 	dto := imageDTO.FromImage(image)
 
-	logger.Info(fmt.Sprintf("Inserting new image into the database for owner '%s' with name '%s':", dto.Owner, dto.Name))
+	logger.Info("Inserting new image into the database", log.F("owner", dto.Owner), log.F("name", dto.Name))
 
-	result, errInsert := r.mongoImage.InsertOne(context.Background(), dto)
+	result, errInsert := r.mongoImage.InsertOne(ctx, dto)
 	if errInsert != nil {
-		logger.Error(fmt.Sprintf("Error inserting image: %s", errInsert.Error()))
+		logger.Error("Error inserting image", log.F("error", errInsert.Error()))
 		return nil, exception.NewApiException(500, "Error inserting the document")
 	}
 	imageID := result.InsertedID.(primitive.ObjectID).Hex()
-	logger.Info(fmt.Sprintf("Image successfully inserted with ID: %s", imageID))
+	logger.Info("Image successfully inserted", log.F("id", imageID))
 	dto.Id = &imageID
 
 	return dto, nil
 }
 
-func (r *ImageMongoDBRepository) Delete(dto *imageDTO.ImageDeleteRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
-	objectID, errObjectID := getObjectID(&dto.Id)
+func (r *ImageMongoDBRepository) Delete(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (deleted *imageDTO.ImageDTO, errDeleteImage *exception.ApiException) {
+	ctx, span := startSpan(ctx, "Delete")
+	defer func() { tracing.End(span, errDeleteImage) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
+	objectID, errObjectID := getObjectID(ctx, &dto.Id)
 	if errObjectID != nil {
 		return nil, errObjectID
 	}
@@ -177,43 +175,55 @@ func (r *ImageMongoDBRepository) Delete(dto *imageDTO.ImageDeleteRequestDTO) (*i
 		OWNER: dto.Owner,
 	}
 
-	logger.Info(fmt.Sprintf("Attempting to delete image with filter: %+v", filter))
+	logger.Info("Attempting to delete image", log.F("id", dto.Id), log.F("owner", dto.Owner))
 
-	foundImages, err := r.find(filter)
+	foundImages, err := r.find(ctx, filter)
 	if err != nil {
-		logger.Warning(fmt.Sprintf("Image not found for deletion with Id '%v' and Owner '%v'", dto.Id, dto.Owner))
+		logger.Warning("Image not found for deletion", log.F("id", dto.Id), log.F("owner", dto.Owner))
 		return nil, err
 	}
 
-	_, errDelete := r.mongoImage.DeleteOne(context.Background(), filter)
+	_, errDelete := r.mongoImage.DeleteOne(ctx, filter)
 	if errDelete != nil {
-		logger.Error(fmt.Sprintf("Error deleting image: %s", errDelete.Error()))
+		logger.Error("Error deleting image", log.F("id", dto.Id), log.F("error", errDelete.Error()))
 		return nil, exception.NewApiException(500, "Error deleting the image")
 	}
 
-	logger.Info(fmt.Sprintf("Image successfully deleted: %+v", *foundImages[0].Id))
+	logger.Info("Image successfully deleted", log.F("id", *foundImages[0].Id))
 	return &foundImages[0], nil
 }
 
-func (r *ImageMongoDBRepository) DeleteAll(dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
+func (r *ImageMongoDBRepository) DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (deletedCount int64, errDeleteAll *exception.ApiException) {
+	ctx, span := startSpan(ctx, "DeleteAll")
+	defer func() { tracing.End(span, errDeleteAll) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
 	filter := bson.M{
 		OWNER: dto.Owner,
 	}
 
-	logger.Info(fmt.Sprintf("Attempting to delete all images with owner: '%s'", dto.Owner))
+	logger.Info("Attempting to delete all images", log.F("owner", dto.Owner))
 
-	result, err := r.mongoImage.DeleteMany(context.Background(), filter)
+	result, err := r.mongoImage.DeleteMany(ctx, filter)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error deleting images for owner '%s': %s", dto.Owner, err.Error()))
+		logger.Error("Error deleting images", log.F("owner", dto.Owner), log.F("error", err.Error()))
 		return 0, exception.NewApiException(500, "Error deleting images by owner")
 	}
 
-	logger.Info(fmt.Sprintf("Successfully deleted %d images for owner '%s'", result.DeletedCount, dto.Owner))
+	logger.Info("Images successfully deleted", log.F("owner", dto.Owner), log.F("count", result.DeletedCount))
 	return result.DeletedCount, nil
 }
 
-func (r *ImageMongoDBRepository) Update(dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException) {
-	objectID, errObjectID := getObjectID(&dto.Id)
+func (r *ImageMongoDBRepository) Update(ctx context.Context, dto *imageDTO.ImageUpdateRequestDTO) (updated *imageDTO.ImageUpdateResponseDTO, errUpdateImage *exception.ApiException) {
+	ctx, span := startSpan(ctx, "Update")
+	defer func() { tracing.End(span, errUpdateImage) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
+	objectID, errObjectID := getObjectID(ctx, &dto.Id)
 	if errObjectID != nil {
 		return nil, errObjectID
 	}
@@ -228,7 +238,7 @@ func (r *ImageMongoDBRepository) Update(dto *imageDTO.ImageUpdateRequestDTO) (*i
 	}
 
 	if len(updateFields) == 0 {
-		logger.Warning(fmt.Sprintf("No fields to update for image with Id '%s' and Owner '%s'", dto.Id, dto.Owner))
+		logger.Warning("No fields to update for image", log.F("id", dto.Id), log.F("owner", dto.Owner))
 		return nil, exception.NewApiException(400, "No fields to update")
 	}
 
@@ -236,20 +246,20 @@ func (r *ImageMongoDBRepository) Update(dto *imageDTO.ImageUpdateRequestDTO) (*i
 		"$set": updateFields,
 	}
 
-	logger.Info(fmt.Sprintf("Updating image with filter: %+v and update: %+v", filter, update))
+	logger.Info("Updating image", log.F("id", dto.Id), log.F("owner", dto.Owner), log.F("update", update))
 
-	result, errUpdate := r.mongoImage.UpdateOne(context.Background(), filter, update)
+	result, errUpdate := r.mongoImage.UpdateOne(ctx, filter, update)
 	if errUpdate != nil {
-		logger.Error(fmt.Sprintf("Error updating image: %s", errUpdate.Error()))
+		logger.Error("Error updating image", log.F("id", dto.Id), log.F("error", errUpdate.Error()))
 		return nil, exception.NewApiException(500, "Error updating the image")
 	}
 
 	if result.MatchedCount == 0 {
-		logger.Warning(fmt.Sprintf("No image found to update with Id '%s' and Owner '%s'", dto.Id, dto.Owner))
+		logger.Warning("No image found to update", log.F("id", dto.Id), log.F("owner", dto.Owner))
 		return nil, exception.NewApiException(404, "Image not found")
 	}
 
-	logger.Info(fmt.Sprintf("Image successfully updated: %s", dto.Id))
+	logger.Info("Image successfully updated", log.F("id", dto.Id))
 
 	return &imageDTO.ImageUpdateResponseDTO{
 		Id:            dto.Id,
@@ -258,10 +268,59 @@ func (r *ImageMongoDBRepository) Update(dto *imageDTO.ImageUpdateRequestDTO) (*i
 	}, nil
 }
 
-func getObjectID(id *string) (primitive.ObjectID, *exception.ApiException) {
+// Groups the images by owner, an empty owner returns the usage of every user
+func (r *ImageMongoDBRepository) StorageUsage(ctx context.Context, owner string) (usage []imageDTO.StorageUsageDTO, errUsage *exception.ApiException) {
+	ctx, span := startSpan(ctx, "StorageUsage")
+	defer func() { tracing.End(span, errUsage) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
+	logger.Info("Calculating image storage usage", log.F("owner", owner))
+
+	match := bson.M{}
+	if owner != "" {
+		match[OWNER] = owner
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			ID:             "$" + OWNER,
+			"images":       bson.M{"$sum": 1},
+			"images_bytes": bson.M{"$sum": bson.M{"$bsonSize": "$$ROOT"}},
+		}}},
+	}
+
+	cursor, err := r.mongoImage.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error("Error calculating image storage usage", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error calculating storage usage")
+	}
+	defer cursor.Close(ctx)
+
+	var results []imageDTO.StorageUsageDTO
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.Error("Error decoding image storage usage", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error calculating storage usage")
+	}
+
+	return results, nil
+}
+
+// Each operation opens a span named after the repository with the attributes of the MongoDB semantic conventions
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "ImageMongoDBRepository."+operation,
+		semconv.DBSystemNameMongoDB,
+		semconv.DBCollectionName(IMAGE_COLLECTION),
+		semconv.DBOperationName(operation),
+	)
+}
+
+func getObjectID(ctx context.Context, id *string) (primitive.ObjectID, *exception.ApiException) {
 	objectID, errObjectID := primitive.ObjectIDFromHex(*id)
 	if errObjectID != nil {
-		logger.Error(fmt.Sprintf("Invalid ObjectID: %v", *id))
+		log.FromContext(ctx).Error("Invalid ObjectID", log.F("id", *id))
 		return primitive.NilObjectID, exception.NewApiException(400, "Invalid image ID format")
 	}
 	return objectID, nil
@@ -1,6 +1,7 @@
 package thumbnailImageRepository
 
 import (
+	"context"
 	"go-gallery/src/commons/exception"
 	"go-gallery/src/infrastructure/dto"
 	imageDTO "go-gallery/src/infrastructure/dto/image"
@@ -8,9 +9,10 @@ import (
 )
 
 type ThumbnailImageRepository interface {
-	Insert(dto *imageDTO.ImageDTO, rawContentFile []byte) (*imageDTO.ImageUploadResponseDTO, *exception.ApiException)
-	Update(dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException)
-	Delete(dto *imageDTO.ImageDeleteRequestDTO) (*dto.MessageResponseDTO, *exception.ApiException)
-	DeleteAll(dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException)
-	FindAll(owner, lastIDHex string, pageSize int64) (*thumbnailImageDTO.ThumbnailImageCursorDTO, *exception.ApiException)
+	Insert(ctx context.Context, dto *imageDTO.ImageDTO, rawContentFile []byte) (*imageDTO.ImageUploadResponseDTO, *exception.ApiException)
+	Update(ctx context.Context, dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException)
+	Delete(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (*dto.MessageResponseDTO, *exception.ApiException)
+	DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException)
+	FindAll(ctx context.Context, owner, lastIDHex string, pageSize int64) (*thumbnailImageDTO.ThumbnailImageCursorDTO, *exception.ApiException)
+	StorageUsage(ctx context.Context, owner string) ([]imageDTO.StorageUsageDTO, *exception.ApiException)
 }
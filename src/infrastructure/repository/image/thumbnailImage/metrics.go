@@ -0,0 +1,77 @@
+package thumbnailImageRepository
+
+import (
+	"context"
+	"go-gallery/src/commons/exception"
+	"go-gallery/src/infrastructure/dto"
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	thumbnailImageDTO "go-gallery/src/infrastructure/dto/image/thumbnailImage"
+	"go-gallery/src/infrastructure/metrics"
+	"time"
+)
+
+const THUMBNAIL_IMAGE_METRICS_LABEL string = "thumbnail_image"
+
+// ThumbnailImageMetricsRepository envuelve otro ThumbnailImageRepository y registra la duración y los errores de cada operación
+type ThumbnailImageMetricsRepository struct {
+	repository ThumbnailImageRepository
+}
+
+func NewThumbnailImageMetricsRepository(repository ThumbnailImageRepository) ThumbnailImageRepository {
+	return &ThumbnailImageMetricsRepository{repository: repository}
+}
+
+// Unwrap devuelve el repositorio decorado
+func (r *ThumbnailImageMetricsRepository) Unwrap() any {
+	return r.repository
+}
+
+func (r *ThumbnailImageMetricsRepository) Insert(ctx context.Context, dto *imageDTO.ImageDTO, rawContentFile []byte) (*imageDTO.ImageUploadResponseDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Insert(ctx, dto, rawContentFile)
+	observeThumbnailImageOperation("insert", start, err)
+	return result, err
+}
+
+func (r *ThumbnailImageMetricsRepository) Update(ctx context.Context, dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Update(ctx, dto)
+	observeThumbnailImageOperation("update", start, err)
+	return result, err
+}
+
+func (r *ThumbnailImageMetricsRepository) Delete(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (*dto.MessageResponseDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Delete(ctx, dto)
+	observeThumbnailImageOperation("delete", start, err)
+	return result, err
+}
+
+func (r *ThumbnailImageMetricsRepository) DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.DeleteAll(ctx, dto)
+	observeThumbnailImageOperation("delete_all", start, err)
+	return result, err
+}
+
+func (r *ThumbnailImageMetricsRepository) FindAll(ctx context.Context, owner, lastIDHex string, pageSize int64) (*thumbnailImageDTO.ThumbnailImageCursorDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindAll(ctx, owner, lastIDHex, pageSize)
+	observeThumbnailImageOperation("find_all", start, err)
+	return result, err
+}
+
+func (r *ThumbnailImageMetricsRepository) StorageUsage(ctx context.Context, owner string) ([]imageDTO.StorageUsageDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.StorageUsage(ctx, owner)
+	observeThumbnailImageOperation("storage_usage", start, err)
+	return result, err
+}
+
+func observeThumbnailImageOperation(operation string, start time.Time, err *exception.ApiException) {
+	errorStatus := 0
+	if err != nil {
+		errorStatus = err.Status
+	}
+	metrics.ObserveRepositoryOperation(THUMBNAIL_IMAGE_METRICS_LABEL, operation, start, errorStatus)
+}
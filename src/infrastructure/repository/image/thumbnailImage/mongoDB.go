@@ -8,17 +8,23 @@ import (
 	utilsImage "go-gallery/src/commons/utils/image"
 	thumbnailImageBuilder "go-gallery/src/domain/entities/builder/image/thumbnailImage"
 	"strings"
+	"time"
 
 	"go-gallery/src/infrastructure/dto"
 	imageDTO "go-gallery/src/infrastructure/dto/image"
 	thumbnailImageDTO "go-gallery/src/infrastructure/dto/image/thumbnailImage"
 	log "go-gallery/src/infrastructure/logger"
+	"go-gallery/src/infrastructure/metrics"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+	"go-gallery/src/infrastructure/tracing"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const ThumnbailImageMongoDBRepositoryKey = "ThumnbailImageMongoDBRepository"
@@ -35,20 +41,17 @@ const (
 var logger log.Logger
 
 type ThumbnailImageMongoDBRepository struct {
+	provider            *mongoClient.Provider
 	mongoThumbnailImage *mongo.Collection
 }
 
-func NewThumbnailImageMongoDBRepository(args map[string]string) ThumbnailImageRepository {
-	urlConnection := args["MONGODB_URL_CONNECTION"]
-	databaseName := args["MONGODB_DATABASE"]
-
+func NewThumbnailImageMongoDBRepository(provider *mongoClient.Provider) ThumbnailImageRepository {
 	logger = log.Instance()
-	logger.Info("Initializing ThumbnailImageMongoDBRepository with MongoDB URL: " + urlConnection)
-
-	db := connect(urlConnection, databaseName)
+	logger.Info("Initializing ThumbnailImageMongoDBRepository", log.F("database", provider.DatabaseName()))
 
 	repo := &ThumbnailImageMongoDBRepository{
-		mongoThumbnailImage: db.Collection(THUMBNAIL_IMAGE_COLLECTION),
+		provider:            provider,
+		mongoThumbnailImage: provider.Collection(THUMBNAIL_IMAGE_COLLECTION),
 	}
 
 	logger.Info("ThumbnailImageMongoDBRepository successfully initialized")
@@ -56,37 +59,23 @@ func NewThumbnailImageMongoDBRepository(args map[string]string) ThumbnailImageRe
 	return repo
 }
 
-func connect(urlConnection string, databaseName string) *mongo.Database {
-	database, err := mongo.Connect(context.Background(), options.Client().ApplyURI(urlConnection))
-	if err != nil {
-		panicMessage := fmt.Sprintf("Could not connect to MongoDB: %s", err.Error())
-		logger.Panic(panicMessage)
-		panic(panicMessage)
-	}
-
-	err = database.Ping(context.Background(), readpref.Primary())
-	if err != nil {
-		panicMessage := fmt.Sprintf("Could not ping MongoDB: %s", err.Error())
-		logger.Panic(panicMessage)
-		panic(panicMessage)
-	}
-
-	logger.Info("Successfully connected to MongoDB")
+func (r *ThumbnailImageMongoDBRepository) FindAll(ctx context.Context, owner, lastID string, pageSize int64) (page *thumbnailImageDTO.ThumbnailImageCursorDTO, errFindAll *exception.ApiException) {
+	ctx, span := startSpan(ctx, "FindAll")
+	defer func() { tracing.End(span, errFindAll) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
 
-	return database.Database(databaseName)
-}
-
-func (r *ThumbnailImageMongoDBRepository) FindAll(owner, lastID string, pageSize int64) (*thumbnailImageDTO.ThumbnailImageCursorDTO, *exception.ApiException) {
 	filter := bson.M{
 		OWNER: strings.TrimSpace(owner),
 	}
 
-	logger.Info(fmt.Sprintf("Cursor-based search: filter=%+v, lastID=%s, pageSize=%d", filter, lastID, pageSize))
+	logger.Info("Cursor-based search of thumbnails", log.F("owner", filter[OWNER]), log.F("last_id", lastID), log.F("page_size", pageSize))
 
 	if lastID != "" {
 		lastObjectID, err := primitive.ObjectIDFromHex(lastID)
 		if err != nil {
-			logger.Error(fmt.Sprintf("Invalid lastID: %s", lastID))
+			logger.Error("Invalid last ID", log.F("last_id", lastID))
 			return nil, exception.NewApiException(400, "Invalid last ID")
 		}
 		filter[ID] = bson.M{"$lt": lastObjectID}
@@ -96,7 +85,7 @@ func (r *ThumbnailImageMongoDBRepository) FindAll(owner, lastID string, pageSize
 	findOptions.SetLimit(pageSize)
 	findOptions.SetSort(bson.D{{Key: ID, Value: SORT}})
 
-	dto, err := r.find(filter, findOptions)
+	dto, err := r.find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -107,20 +96,21 @@ func (r *ThumbnailImageMongoDBRepository) FindAll(owner, lastID string, pageSize
 	}, nil
 }
 
-func (r *ThumbnailImageMongoDBRepository) find(filter bson.M, findOptions *options.FindOptions) ([]thumbnailImageDTO.ThumbnailImageDTO, *exception.ApiException) {
-	logger.Info(fmt.Sprintf("Searching for thumbnails with filter: %+v and options: %+v", filter, findOptions))
-	cursor, err := r.mongoThumbnailImage.Find(context.Background(), filter, findOptions)
+func (r *ThumbnailImageMongoDBRepository) find(ctx context.Context, filter bson.M, findOptions *options.FindOptions) ([]thumbnailImageDTO.ThumbnailImageDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	logger.Info("Searching for thumbnails", log.F("filter", filter))
+	cursor, err := r.mongoThumbnailImage.Find(ctx, filter, findOptions)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error searching for thumbnails: %s", err.Error()))
+		logger.Error("Error searching for thumbnails", log.F("error", err.Error()))
 		return nil, exception.NewApiException(500, "Error searching for thumbnails")
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var results []thumbnailImageDTO.ThumbnailImageDTO
-	for cursor.Next(context.Background()) {
+	for cursor.Next(ctx) {
 		var thumbnail thumbnailImageDTO.ThumbnailImageDTO
 		if err := cursor.Decode(&thumbnail); err != nil {
-			logger.Error(fmt.Sprintf("Error decoding thumbnail: %s", err.Error()))
+			logger.Error("Error decoding thumbnail", log.F("error", err.Error()))
 			return nil, exception.NewApiException(500, "Error decoding thumbnails")
 		}
 		results = append(results, thumbnail)
@@ -131,11 +121,17 @@ func (r *ThumbnailImageMongoDBRepository) find(filter bson.M, findOptions *optio
 		return nil, exception.NewApiException(404, "Thumbnail not found")
 	}
 
-	logger.Info(fmt.Sprintf("Thumbnails found: %v", len(results)))
+	logger.Info("Thumbnails found", log.F("count", len(results)))
 	return results, nil
 }
 
-func (r *ThumbnailImageMongoDBRepository) Insert(dto *imageDTO.ImageDTO, rawContentFile []byte) (*imageDTO.ImageUploadResponseDTO, *exception.ApiException) {
+func (r *ThumbnailImageMongoDBRepository) Insert(ctx context.Context, dto *imageDTO.ImageDTO, rawContentFile []byte) (inserted *imageDTO.ImageUploadResponseDTO, errInsertThumbnail *exception.ApiException) {
+	ctx, span := startSpan(ctx, "Insert")
+	defer func() { tracing.End(span, errInsertThumbnail) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
 	// Validamos que la miniatura no esta insertada en base de datos
 	filter := bson.M{
 		NAME:      strings.TrimSpace(dto.Name),
@@ -143,9 +139,9 @@ func (r *ThumbnailImageMongoDBRepository) Insert(dto *imageDTO.ImageDTO, rawCont
 		EXTENSION: strings.TrimSpace(dto.Extension),
 	}
 
-	logger.Info(fmt.Sprintf("Attempting to insert thumbnail for image: Name=%s, Owner=%s", dto.Name, dto.Owner))
+	logger.Info("Attempting to insert thumbnail", log.F("owner", dto.Owner), log.F("name", dto.Name))
 
-	results, err := r.find(filter, nil)
+	results, err := r.find(ctx, filter, nil)
 	if err != nil && err.Status != 404 {
 		return nil, err
 	}
@@ -155,11 +151,10 @@ func (r *ThumbnailImageMongoDBRepository) Insert(dto *imageDTO.ImageDTO, rawCont
 		return nil, exception.NewApiException(409, "Thumbnail already exists")
 	}
 
-	resizedBytes, errResize := utilsImage.ResizeImage(rawContentFile, constants.THUMBNAIL_WIDTH, constants.THUMBNAIL_HEIGHT)
+	resizedBytes, errResize := resize(ctx, rawContentFile)
 	if errResize != nil {
-		errorMessage := fmt.Sprintf("Error generating thumbnail: %s", errResize.Error())
-		logger.Error(errorMessage)
-		return nil, exception.NewApiException(500, errorMessage)
+		logger.Error("Error generating thumbnail", log.F("error", errResize.Error()))
+		return nil, exception.NewApiException(500, fmt.Sprintf("Error generating thumbnail: %s", errResize.Error()))
 	}
 
 	sizeInBytes := len(resizedBytes)
@@ -176,21 +171,20 @@ func (r *ThumbnailImageMongoDBRepository) Insert(dto *imageDTO.ImageDTO, rawCont
 		BuildNew()
 
 	if errBuilder != nil {
-		errorMessage := fmt.Sprintf("Error building thumbnail: %s", errBuilder.Error())
-		logger.Error(errorMessage)
-		return nil, exception.NewApiException(404, errorMessage)
+		logger.Error("Error building thumbnail", log.F("error", errBuilder.Error()))
+		return nil, exception.NewApiException(404, fmt.Sprintf("Error building thumbnail: %s", errBuilder.Error()))
 	}
 
 	dtoThumbnailImage := thumbnailImageDTO.FromThumbnailImage(thumbnailImage)
 
-	thumbnailId, errInsert := r.mongoThumbnailImage.InsertOne(context.Background(), dtoThumbnailImage)
+	thumbnailId, errInsert := r.mongoThumbnailImage.InsertOne(ctx, dtoThumbnailImage)
 	if errInsert != nil {
-		logger.Error(fmt.Sprintf("Error inserting thumbnail: %s", errInsert.Error()))
+		logger.Error("Error inserting thumbnail", log.F("error", errInsert.Error()))
 		return nil, exception.NewApiException(500, "Error inserting document")
 	}
 
 	idHex := thumbnailId.InsertedID.(primitive.ObjectID).Hex()
-	logger.Info(fmt.Sprintf("Thumbnail successfully inserted with ID: %s", idHex))
+	logger.Info("Thumbnail successfully inserted", log.F("id", idHex))
 
 	return &imageDTO.ImageUploadResponseDTO{
 		Id:          *dto.Id,
@@ -201,8 +195,14 @@ func (r *ThumbnailImageMongoDBRepository) Insert(dto *imageDTO.ImageDTO, rawCont
 	}, nil
 }
 
-func (r *ThumbnailImageMongoDBRepository) Update(dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException) {
-	objectID, errObjectID := getObjectID(&dto.ThumbnailID)
+func (r *ThumbnailImageMongoDBRepository) Update(ctx context.Context, dto *imageDTO.ImageUpdateRequestDTO) (updated *imageDTO.ImageUpdateResponseDTO, errUpdateThumbnail *exception.ApiException) {
+	ctx, span := startSpan(ctx, "Update")
+	defer func() { tracing.End(span, errUpdateThumbnail) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
+	objectID, errObjectID := getObjectID(ctx, &dto.ThumbnailID)
 	if errObjectID != nil {
 		return nil, errObjectID
 	}
@@ -217,7 +217,7 @@ func (r *ThumbnailImageMongoDBRepository) Update(dto *imageDTO.ImageUpdateReques
 	}
 
 	if len(updateFields) == 0 {
-		logger.Warning(fmt.Sprintf("No fields to update for thumbnail with Id '%s' and Owner '%s'", dto.Id, dto.Owner))
+		logger.Warning("No fields to update for thumbnail", log.F("id", dto.Id), log.F("owner", dto.Owner))
 		return nil, exception.NewApiException(400, "No fields to update")
 	}
 
@@ -225,20 +225,20 @@ func (r *ThumbnailImageMongoDBRepository) Update(dto *imageDTO.ImageUpdateReques
 		"$set": updateFields,
 	}
 
-	logger.Info(fmt.Sprintf("Updating thumbnail with filter: %+v and update: %+v", filter, update))
+	logger.Info("Updating thumbnail", log.F("id", dto.ThumbnailID), log.F("owner", dto.Owner), log.F("update", update))
 
-	result, errUpdate := r.mongoThumbnailImage.UpdateOne(context.Background(), filter, update)
+	result, errUpdate := r.mongoThumbnailImage.UpdateOne(ctx, filter, update)
 	if errUpdate != nil {
-		logger.Error(fmt.Sprintf("Error updating thumbnail: %s", errUpdate.Error()))
+		logger.Error("Error updating thumbnail", log.F("id", dto.ThumbnailID), log.F("error", errUpdate.Error()))
 		return nil, exception.NewApiException(500, "Error updating the thumbnail")
 	}
 
 	if result.MatchedCount == 0 {
-		logger.Warning(fmt.Sprintf("No thumbnail found to update with Id '%s' and Owner '%s'", dto.Id, dto.Owner))
+		logger.Warning("No thumbnail found to update", log.F("id", dto.Id), log.F("owner", dto.Owner))
 		return nil, exception.NewApiException(404, "Thumbnail not found")
 	}
 
-	logger.Info(fmt.Sprintf("Thumbnail successfully updated: %s", dto.Id))
+	logger.Info("Thumbnail successfully updated", log.F("id", dto.Id))
 
 	return &imageDTO.ImageUpdateResponseDTO{
 		Id:            dto.Id,
@@ -247,8 +247,14 @@ func (r *ThumbnailImageMongoDBRepository) Update(dto *imageDTO.ImageUpdateReques
 	}, nil
 }
 
-func (r *ThumbnailImageMongoDBRepository) Delete(dtoR *imageDTO.ImageDeleteRequestDTO) (*dto.MessageResponseDTO, *exception.ApiException) {
-	objectID, errObjectID := getObjectID(&dtoR.ThumbnailID)
+func (r *ThumbnailImageMongoDBRepository) Delete(ctx context.Context, dtoR *imageDTO.ImageDeleteRequestDTO) (deleted *dto.MessageResponseDTO, errDeleteThumbnail *exception.ApiException) {
+	ctx, span := startSpan(ctx, "Delete")
+	defer func() { tracing.End(span, errDeleteThumbnail) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
+	objectID, errObjectID := getObjectID(ctx, &dtoR.ThumbnailID)
 	if errObjectID != nil {
 		return nil, errObjectID
 	}
@@ -258,48 +264,119 @@ func (r *ThumbnailImageMongoDBRepository) Delete(dtoR *imageDTO.ImageDeleteReque
 		OWNER: dtoR.Owner,
 	}
 
-	logger.Info(fmt.Sprintf("Attempting to delete thumbnail with filter: %+v", filter))
+	logger.Info("Attempting to delete thumbnail", log.F("id", dtoR.ThumbnailID), log.F("owner", dtoR.Owner))
 
-	foundThumbnails, err := r.find(filter, nil)
+	foundThumbnails, err := r.find(ctx, filter, nil)
 	if err != nil {
-		logger.Warning(fmt.Sprintf("Thumbnail not found for deletion with Id '%v' and Owner '%v'", dtoR.Id, dtoR.Owner))
+		logger.Warning("Thumbnail not found for deletion", log.F("id", dtoR.Id), log.F("owner", dtoR.Owner))
 		return nil, err
 	}
 
-	_, errDelete := r.mongoThumbnailImage.DeleteOne(context.Background(), filter)
+	_, errDelete := r.mongoThumbnailImage.DeleteOne(ctx, filter)
 	if errDelete != nil {
-		logger.Error(fmt.Sprintf("Error deleting thumbnail: %s", errDelete.Error()))
+		logger.Error("Error deleting thumbnail", log.F("id", dtoR.ThumbnailID), log.F("error", errDelete.Error()))
 		return nil, exception.NewApiException(500, "Error deleting the thumbnail")
 	}
 
-	logger.Info(fmt.Sprintf("Thumbnail successfully deleted: %+v", *foundThumbnails[0].Id))
+	logger.Info("Thumbnail successfully deleted", log.F("id", *foundThumbnails[0].Id))
 
 	return &dto.MessageResponseDTO{
 		Message: fmt.Sprintf("The image %s has been successfully deleted.", foundThumbnails[0].Name),
 	}, nil
 }
 
-func (r *ThumbnailImageMongoDBRepository) DeleteAll(dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
+func (r *ThumbnailImageMongoDBRepository) DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (deletedCount int64, errDeleteAll *exception.ApiException) {
+	ctx, span := startSpan(ctx, "DeleteAll")
+	defer func() { tracing.End(span, errDeleteAll) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
 	filter := bson.M{
 		OWNER: dto.Owner,
 	}
 
-	logger.Info(fmt.Sprintf("Attempting to delete all thumbnails with owner: '%s'", dto.Owner))
+	logger.Info("Attempting to delete all thumbnails", log.F("owner", dto.Owner))
 
-	result, err := r.mongoThumbnailImage.DeleteMany(context.Background(), filter)
+	result, err := r.mongoThumbnailImage.DeleteMany(ctx, filter)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error deleting thumbnails for owner '%s': %s", dto.Owner, err.Error()))
+		logger.Error("Error deleting thumbnails", log.F("owner", dto.Owner), log.F("error", err.Error()))
 		return 0, exception.NewApiException(500, "Error deleting thumbnails by owner")
 	}
 
-	logger.Info(fmt.Sprintf("Successfully deleted %d thumbnails for owner '%s'", result.DeletedCount, dto.Owner))
+	logger.Info("Thumbnails successfully deleted", log.F("owner", dto.Owner), log.F("count", result.DeletedCount))
 	return result.DeletedCount, nil
 }
 
-func getObjectID(id *string) (primitive.ObjectID, *exception.ApiException) {
+// Groups the thumbnails by owner, an empty owner returns the usage of every user
+func (r *ThumbnailImageMongoDBRepository) StorageUsage(ctx context.Context, owner string) (usage []imageDTO.StorageUsageDTO, errUsage *exception.ApiException) {
+	ctx, span := startSpan(ctx, "StorageUsage")
+	defer func() { tracing.End(span, errUsage) }()
+	ctx, cancel := r.provider.WithTimeout(ctx)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
+	logger.Info("Calculating thumbnail storage usage", log.F("owner", owner))
+
+	match := bson.M{}
+	if owner != "" {
+		match[OWNER] = owner
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			ID:                 "$" + OWNER,
+			"thumbnails_bytes": bson.M{"$sum": bson.M{"$bsonSize": "$$ROOT"}},
+		}}},
+	}
+
+	cursor, err := r.mongoThumbnailImage.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error("Error calculating thumbnail storage usage", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error calculating storage usage")
+	}
+	defer cursor.Close(ctx)
+
+	var results []imageDTO.StorageUsageDTO
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.Error("Error decoding thumbnail storage usage", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error calculating storage usage")
+	}
+
+	return results, nil
+}
+
+// The resize step gets its own span, decoding and encoding the image is usually the slowest part of an upload
+func resize(ctx context.Context, rawContentFile []byte) ([]byte, error) {
+	_, span := tracing.Start(ctx, "thumbnail.resize",
+		attribute.Int("thumbnail.width", constants.THUMBNAIL_WIDTH),
+		attribute.Int("thumbnail.height", constants.THUMBNAIL_HEIGHT),
+		attribute.Int("thumbnail.input_bytes", len(rawContentFile)),
+	)
+
+	start := time.Now()
+	resizedBytes, err := utilsImage.ResizeImage(rawContentFile, constants.THUMBNAIL_WIDTH, constants.THUMBNAIL_HEIGHT)
+	metrics.ObserveThumbnailGeneration(start, len(rawContentFile), len(resizedBytes), err)
+
+	span.SetAttributes(attribute.Int("thumbnail.output_bytes", len(resizedBytes)))
+	tracing.EndWithError(span, err)
+	return resizedBytes, err
+}
+
+// Each operation opens a span named after the repository with the attributes of the MongoDB semantic conventions
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "ThumbnailImageMongoDBRepository."+operation,
+		semconv.DBSystemNameMongoDB,
+		semconv.DBCollectionName(THUMBNAIL_IMAGE_COLLECTION),
+		semconv.DBOperationName(operation),
+	)
+}
+
+func getObjectID(ctx context.Context, id *string) (primitive.ObjectID, *exception.ApiException) {
 	objectID, errObjectID := primitive.ObjectIDFromHex(*id)
 	if errObjectID != nil {
-		logger.Error(fmt.Sprintf("Invalid ObjectID: %v", *id))
+		log.FromContext(ctx).Error("Invalid ObjectID", log.F("id", *id))
 		return primitive.NilObjectID, exception.NewApiException(400, "Invalid image ID format")
 	}
 	return objectID, nil
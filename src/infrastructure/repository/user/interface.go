@@ -3,6 +3,7 @@ package userRepository
 import (
 	"go-gallery/src/commons/exception"
 	userDTO "go-gallery/src/infrastructure/dto/user"
+	"time"
 )
 
 type UserRepository interface {
@@ -11,5 +12,23 @@ type UserRepository interface {
 	FindAndCheckJWT(claims *userDTO.JwtClaimsDTO) (*userDTO.UserDTO, *exception.ApiException)
 	Insert(userDTO *userDTO.UserDTO) (*userDTO.UserDTO, *exception.ApiException)
 	Update(userDTO *userDTO.UserDTO) (int64, *exception.ApiException)
+	Verify(username string) (int64, *exception.ApiException)
 	Delete(userDTO *userDTO.UserDTO) (int64, *exception.ApiException)
+	InsertEmailChange(emailChangeDTO *userDTO.EmailChangeDTO) (*userDTO.EmailChangeDTO, *exception.ApiException)
+	FindPendingEmailChange(username string) (*userDTO.EmailChangeDTO, *exception.ApiException)
+	ConfirmEmailChange(emailChangeDTO *userDTO.EmailChangeDTO) (int64, *exception.ApiException)
+	FindEmailChangeByRevertToken(revertTokenHash string) (*userDTO.EmailChangeDTO, *exception.ApiException)
+	DeleteEmailChange(revertTokenHash string) (int64, *exception.ApiException)
+	FindByIdentity(provider, subject string) (*userDTO.UserDTO, *exception.ApiException)
+	InsertIdentity(userIdentityDTO *userDTO.UserIdentityDTO) (*userDTO.UserIdentityDTO, *exception.ApiException)
+	FindByUsername(username string) (*userDTO.UserDTO, *exception.ApiException)
+	FindAll(search, lastUsername string, pageSize int64) (*userDTO.UserCursorDTO, *exception.ApiException)
+	UpdateRole(username, role string) (int64, *exception.ApiException)
+	SetDisabled(username string, disabled bool) (int64, *exception.ApiException)
+	DeleteByUsername(username string) (int64, *exception.ApiException)
+	RevokeSessions(username string) (int64, *exception.ApiException)
+	SetDeletionSchedule(username string, deletionScheduledAt *time.Time) (int64, *exception.ApiException)
+	FindScheduledForDeletion(before time.Time) ([]string, *exception.ApiException)
+	ClaimForPurge(username string, before time.Time) (int64, *exception.ApiException)
+	CancelDeletion(username string, deletionScheduledAt time.Time) (int64, *exception.ApiException)
 }
@@ -0,0 +1,200 @@
+package userRepository
+
+import (
+	"go-gallery/src/commons/exception"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	"go-gallery/src/infrastructure/metrics"
+	"time"
+)
+
+const USER_METRICS_LABEL string = "user"
+
+// UserMetricsRepository envuelve otro UserRepository y registra la duración y los errores de cada operación
+type UserMetricsRepository struct {
+	repository UserRepository
+}
+
+func NewUserMetricsRepository(repository UserRepository) UserRepository {
+	return &UserMetricsRepository{repository: repository}
+}
+
+// Unwrap devuelve el repositorio decorado
+func (r *UserMetricsRepository) Unwrap() any {
+	return r.repository
+}
+
+func (r *UserMetricsRepository) Find(userLoginRequestDTO *userDTO.LoginRequestDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Find(userLoginRequestDTO)
+	observeUserOperation("find", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) FindByEmail(email string) (*userDTO.UserDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindByEmail(email)
+	observeUserOperation("find_by_email", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) FindAndCheckJWT(claims *userDTO.JwtClaimsDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindAndCheckJWT(claims)
+	observeUserOperation("find_and_check_j_w_t", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) Insert(userDTO *userDTO.UserDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Insert(userDTO)
+	observeUserOperation("insert", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) Update(userDTO *userDTO.UserDTO) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Update(userDTO)
+	observeUserOperation("update", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) Verify(username string) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Verify(username)
+	observeUserOperation("verify", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) Delete(userDTO *userDTO.UserDTO) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.Delete(userDTO)
+	observeUserOperation("delete", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) InsertEmailChange(emailChangeDTO *userDTO.EmailChangeDTO) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.InsertEmailChange(emailChangeDTO)
+	observeUserOperation("insert_email_change", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) FindPendingEmailChange(username string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindPendingEmailChange(username)
+	observeUserOperation("find_pending_email_change", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) ConfirmEmailChange(emailChangeDTO *userDTO.EmailChangeDTO) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.ConfirmEmailChange(emailChangeDTO)
+	observeUserOperation("confirm_email_change", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) FindEmailChangeByRevertToken(revertTokenHash string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindEmailChangeByRevertToken(revertTokenHash)
+	observeUserOperation("find_email_change_by_revert_token", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) DeleteEmailChange(revertTokenHash string) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.DeleteEmailChange(revertTokenHash)
+	observeUserOperation("delete_email_change", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) FindByIdentity(provider, subject string) (*userDTO.UserDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindByIdentity(provider, subject)
+	observeUserOperation("find_by_identity", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) InsertIdentity(userIdentityDTO *userDTO.UserIdentityDTO) (*userDTO.UserIdentityDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.InsertIdentity(userIdentityDTO)
+	observeUserOperation("insert_identity", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) FindByUsername(username string) (*userDTO.UserDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindByUsername(username)
+	observeUserOperation("find_by_username", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) FindAll(search, lastUsername string, pageSize int64) (*userDTO.UserCursorDTO, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindAll(search, lastUsername, pageSize)
+	observeUserOperation("find_all", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) UpdateRole(username, role string) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.UpdateRole(username, role)
+	observeUserOperation("update_role", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) SetDisabled(username string, disabled bool) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.SetDisabled(username, disabled)
+	observeUserOperation("set_disabled", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) DeleteByUsername(username string) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.DeleteByUsername(username)
+	observeUserOperation("delete_by_username", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) RevokeSessions(username string) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.RevokeSessions(username)
+	observeUserOperation("revoke_sessions", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) SetDeletionSchedule(username string, deletionScheduledAt *time.Time) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.SetDeletionSchedule(username, deletionScheduledAt)
+	observeUserOperation("set_deletion_schedule", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) FindScheduledForDeletion(before time.Time) ([]string, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.FindScheduledForDeletion(before)
+	observeUserOperation("find_scheduled_for_deletion", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) ClaimForPurge(username string, before time.Time) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.ClaimForPurge(username, before)
+	observeUserOperation("claim_for_purge", start, err)
+	return result, err
+}
+
+func (r *UserMetricsRepository) CancelDeletion(username string, deletionScheduledAt time.Time) (int64, *exception.ApiException) {
+	start := time.Now()
+	result, err := r.repository.CancelDeletion(username, deletionScheduledAt)
+	observeUserOperation("cancel_deletion", start, err)
+	return result, err
+}
+
+func observeUserOperation(operation string, start time.Time, err *exception.ApiException) {
+	errorStatus := 0
+	if err != nil {
+		errorStatus = err.Status
+	}
+	metrics.ObserveRepositoryOperation(USER_METRICS_LABEL, operation, start, errorStatus)
+}
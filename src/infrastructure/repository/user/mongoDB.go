@@ -2,61 +2,223 @@ package userRepository
 
 import (
 	"context"
+	"crypto/md5"
 	"fmt"
 	"go-gallery/src/commons/exception"
 	userBuilder "go-gallery/src/domain/entities/builder/user"
 	userEntity "go-gallery/src/domain/entities/user"
 	userDTO "go-gallery/src/infrastructure/dto/user"
 	log "go-gallery/src/infrastructure/logger"
+	mongoClient "go-gallery/src/infrastructure/mongo"
+	"regexp"
+	"sort"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 const UserMongoDBRepositoryKey = "UserMongoDBRepository"
 
 const (
-	USER_COLLECTION = "User"
-	USERNAME        = "username"
-	EMAIL           = "email"
+	USER_COLLECTION                = "User"
+	EMAIL_CHANGE_COLLECTION        = "EmailChange"
+	IDENTITY_COLLECTION            = "UserIdentity"
+	USER_EMAIL_CONFLICT_COLLECTION = "UserEmailConflict"
+	USERNAME                       = "username"
+	EMAIL                          = "email"
+	VERIFIED                       = "verified"
+	ROLE                           = "role"
+	DISABLED                       = "disabled"
+	SESSION_VERSION                = "session_version"
+	DELETION_SCHEDULED_AT          = "deletion_scheduled_at"
+	PURGING                        = "purging"
 )
 
 type UserMongoDBRepository struct {
-	mongo *mongo.Collection
+	provider           *mongoClient.Provider
+	mongo              *mongo.Collection
+	mongoEmailChange   *mongo.Collection
+	mongoIdentity      *mongo.Collection
+	mongoEmailConflict *mongo.Collection
 }
 
-func NewUserMongoDBRepository(args map[string]string) UserRepository {
-	urlConnection := args["MONGODB_URL_CONNECTION"]
-	databaseName := args["MONGODB_DATABASE"]
-
+func NewUserMongoDBRepository(provider *mongoClient.Provider) UserRepository {
 	logger = log.Instance()
 
-	db := connect(urlConnection, databaseName)
-
 	repo := &UserMongoDBRepository{
-		mongo: db.Collection(USER_COLLECTION),
+		provider:           provider,
+		mongo:              provider.Collection(USER_COLLECTION),
+		mongoEmailChange:   provider.Collection(EMAIL_CHANGE_COLLECTION),
+		mongoIdentity:      provider.Collection(IDENTITY_COLLECTION),
+		mongoEmailConflict: provider.Collection(USER_EMAIL_CONFLICT_COLLECTION),
 	}
+
+	repo.backfillVerified()
+	repo.normalizeEmails()
+	repo.createIndexes()
 	return repo
 }
 
-func connect(urlConnection, databaseName string) *mongo.Database {
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(urlConnection))
-	if err != nil {
-		panicMessage := fmt.Sprintf("Unable to connect to MongoDB: %s", err.Error())
+// createIndexes impide que dos cuentas compartan correo, la recuperación y el inicio de sesión social buscan por él
+func (r *UserMongoDBRepository) createIndexes() {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: EMAIL, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	if _, err := r.mongo.Indexes().CreateOne(ctx, index); err != nil {
+		panicMessage := fmt.Sprintf("Unable to create the unique email index of the users: %s", err.Error())
 		logger.Panic(panicMessage)
 		panic(panicMessage)
 	}
+}
+
+// backfillVerified marca como verificadas las cuentas creadas antes de la verificación por correo,
+// igual que el DDL de PostgreSQL. Sin el campo se decodificarían como no verificadas
+func (r *UserMongoDBRepository) backfillVerified() {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
 
-	err = client.Ping(context.Background(), readpref.Primary())
+	result, err := r.mongo.UpdateMany(ctx, bson.M{VERIFIED: bson.M{"$exists": false}}, bson.M{"$set": bson.M{VERIFIED: true}})
 	if err != nil {
-		panicMessage := fmt.Sprintf("Unable to ping MongoDB: %s", err.Error())
+		panicMessage := fmt.Sprintf("Unable to mark the existing users as verified: %s", err.Error())
 		logger.Panic(panicMessage)
 		panic(panicMessage)
 	}
+	if result.ModifiedCount > 0 {
+		logger.Info(fmt.Sprintf("%d existing users created before email verification have been marked as verified", result.ModifiedCount))
+	}
+}
+
+// emailOwner es la parte del usuario que necesita la normalización de correos
+type emailOwner struct {
+	Username string `bson:"username"`
+	Email    string `bson:"email"`
+	Verified bool   `bson:"verified"`
+}
+
+// emailConflictLosers agrupa las cuentas por su correo normalizado y devuelve las que lo pierden, igual que el DDL
+// de PostgreSQL: se queda la verificada, después la que ya estaba en minúsculas y por último el menor nombre de usuario
+func emailConflictLosers(owners []emailOwner) []emailOwner {
+	groups := make(map[string][]emailOwner)
+	for _, owner := range owners {
+		email := userEntity.NormalizeEmail(owner.Email)
+		groups[email] = append(groups[email], owner)
+	}
+
+	var losers []emailOwner
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Verified != group[j].Verified {
+				return group[i].Verified
+			}
+			iLower := group[i].Email == userEntity.NormalizeEmail(group[i].Email)
+			jLower := group[j].Email == userEntity.NormalizeEmail(group[j].Email)
+			if iLower != jLower {
+				return iLower
+			}
+			return group[i].Username < group[j].Username
+		})
+		losers = append(losers, group[1:]...)
+	}
+	sort.Slice(losers, func(i, j int) bool { return losers[i].Username < losers[j].Username })
+	return losers
+}
+
+// conflictEmail es el correo que recibe la cuenta que pierde un conflicto, único y que nunca recibe correo
+func conflictEmail(username string) string {
+	return fmt.Sprintf("conflict+%x@invalid", md5.Sum([]byte(username)))
+}
+
+// normalizeEmails guarda los correos en minúsculas antes de crear el índice único. Las cuentas que solo se diferencian
+// en mayúsculas se registran en USER_EMAIL_CONFLICT_COLLECTION y reciben un correo de conflicto para que un
+// administrador decida cuál se queda con la dirección
+func (r *UserMongoDBRepository) normalizeEmails() {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	opts := options.Find().SetProjection(bson.M{USERNAME: 1, EMAIL: 1, VERIFIED: 1})
+	cursor, err := r.mongo.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		r.panicOnEmailNormalization(err)
+	}
+	var owners []emailOwner
+	if err := cursor.All(ctx, &owners); err != nil {
+		r.panicOnEmailNormalization(err)
+	}
+
+	// The losers are renamed first so lowercasing the winners never hits the unique index
+	losers := emailConflictLosers(owners)
+	renamed := make(map[string]bool, len(losers))
+	for _, loser := range losers {
+		conflict := bson.M{"$set": bson.M{"original_email": loser.Email, "detected_at": time.Now().UTC()}}
+		upsert := options.Update().SetUpsert(true)
+		if _, err := r.mongoEmailConflict.UpdateOne(ctx, bson.M{USERNAME: loser.Username}, conflict, upsert); err != nil {
+			r.panicOnEmailNormalization(err)
+		}
+		if _, err := r.mongo.UpdateOne(ctx, bson.M{USERNAME: loser.Username}, bson.M{"$set": bson.M{EMAIL: conflictEmail(loser.Username)}}); err != nil {
+			r.panicOnEmailNormalization(err)
+		}
+		renamed[loser.Username] = true
+		logger.Warning("Email conflict recorded while normalising emails", log.F("username", loser.Username), log.F("email", loser.Email))
+	}
 
-	return client.Database(databaseName)
+	normalized := 0
+	for _, owner := range owners {
+		email := userEntity.NormalizeEmail(owner.Email)
+		if renamed[owner.Username] || owner.Email == email {
+			continue
+		}
+		if _, err := r.mongo.UpdateOne(ctx, bson.M{USERNAME: owner.Username}, bson.M{"$set": bson.M{EMAIL: email}}); err != nil {
+			r.panicOnEmailNormalization(err)
+		}
+		normalized++
+	}
+	if normalized > 0 {
+		logger.Info("Existing user emails have been normalised to lowercase", log.F("count", normalized))
+	}
+
+	r.normalizeEmailChanges(ctx)
+}
+
+// normalizeEmailChanges pasa a minúsculas los correos de los cambios pendientes, que se comparan con los de los usuarios
+func (r *UserMongoDBRepository) normalizeEmailChanges(ctx context.Context) {
+	cursor, err := r.mongoEmailChange.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"old_email": 1, "new_email": 1}))
+	if err != nil {
+		r.panicOnEmailNormalization(err)
+	}
+	var changes []struct {
+		ID       any    `bson:"_id"`
+		OldEmail string `bson:"old_email"`
+		NewEmail string `bson:"new_email"`
+	}
+	if err := cursor.All(ctx, &changes); err != nil {
+		r.panicOnEmailNormalization(err)
+	}
+	for _, change := range changes {
+		oldEmail, newEmail := userEntity.NormalizeEmail(change.OldEmail), userEntity.NormalizeEmail(change.NewEmail)
+		if oldEmail == change.OldEmail && newEmail == change.NewEmail {
+			continue
+		}
+		update := bson.M{"$set": bson.M{"old_email": oldEmail, "new_email": newEmail}}
+		if _, err := r.mongoEmailChange.UpdateByID(ctx, change.ID, update); err != nil {
+			r.panicOnEmailNormalization(err)
+		}
+	}
+}
+
+func (r *UserMongoDBRepository) panicOnEmailNormalization(err error) {
+	panicMessage := "Unable to normalise the emails of the users"
+	logger.Panic(panicMessage, log.F("error", err.Error()))
+	panic(fmt.Sprintf("%s: %s", panicMessage, err.Error()))
 }
 
 func (r *UserMongoDBRepository) Find(dtoUserFind *userDTO.LoginRequestDTO) (*userDTO.UserDTO, *exception.ApiException) {
@@ -74,6 +236,10 @@ func (r *UserMongoDBRepository) Find(dtoUserFind *userDTO.LoginRequestDTO) (*use
 		return nil, exception.NewApiException(404, "Incorrect password")
 	}
 
+	if user[0].NeedsPasswordRehash() {
+		r.rehashPassword(user[0].GetUsername(), dtoUserFind.Password)
+	}
+
 	dto := userDTO.FromUser(user[0])
 
 	logger.Info(fmt.Sprintf("User found: %s", user[0].GetUsername()))
@@ -81,8 +247,37 @@ func (r *UserMongoDBRepository) Find(dtoUserFind *userDTO.LoginRequestDTO) (*use
 	return dto, nil
 }
 
+// Replaces a hash with outdated parameters, the login is not affected if it fails
+func (r *UserMongoDBRepository) rehashPassword(username, password string) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	hashedPassword, err := userEntity.HashPassword(password)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error rehashing password for user %s: %s", username, err.Error()))
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"password": hashedPassword}}
+	if _, err := r.mongo.UpdateOne(ctx, bson.M{USERNAME: username}, update); err != nil {
+		logger.Error(fmt.Sprintf("Error storing rehashed password for user %s: %s", username, err.Error()))
+		return
+	}
+
+	logger.Info(fmt.Sprintf("Password hash of user %s upgraded to the current parameters", username))
+}
+
 func (r *UserMongoDBRepository) FindByEmail(email string) (*userDTO.UserDTO, *exception.ApiException) {
-	panic("method not implemented FindByEmail in UserMongoDBRepository")
+	logger.Info(fmt.Sprintf("Searching for user by email: %s", email))
+
+	filter := bson.M{EMAIL: userEntity.NormalizeEmail(email)}
+	user, err := r.find(filter)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("User not found with email: %s", email))
+		return nil, err
+	}
+
+	return userDTO.FromUser(user[0]), nil
 }
 
 func (r *UserMongoDBRepository) FindAndCheckJWT(claims *userDTO.JwtClaimsDTO) (*userDTO.UserDTO, *exception.ApiException) {
@@ -112,6 +307,9 @@ func (r *UserMongoDBRepository) FindAndCheckJWT(claims *userDTO.JwtClaimsDTO) (*
 }
 
 func (r *UserMongoDBRepository) Insert(dtoInsertUser *userDTO.UserDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
 	logger.Info(fmt.Sprintf("Attempting to register user: %s", dtoInsertUser.Username))
 	err := r.checkUserIsCreated(dtoInsertUser)
 	if err != nil {
@@ -129,7 +327,7 @@ func (r *UserMongoDBRepository) Insert(dtoInsertUser *userDTO.UserDTO) (*userDTO
 
 	dto := userDTO.FromUser(user)
 
-	_, errInsert := r.mongo.InsertOne(context.Background(), dto)
+	_, errInsert := r.mongo.InsertOne(ctx, dto)
 	if errInsert != nil {
 		logger.Error(fmt.Sprintf("Error inserting user %s: %s", user.GetUsername(), errInsert.Error()))
 		return nil, exception.NewApiException(500, "Error inserting document")
@@ -140,6 +338,9 @@ func (r *UserMongoDBRepository) Insert(dtoInsertUser *userDTO.UserDTO) (*userDTO
 }
 
 func (r *UserMongoDBRepository) Update(dtoUpdateUser *userDTO.UserDTO) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
 	logger.Info(fmt.Sprintf("Attempting to update user: %s", dtoUpdateUser.Username))
 
 	filter := bson.M{"username": dtoUpdateUser.Username}
@@ -162,7 +363,12 @@ func (r *UserMongoDBRepository) Update(dtoUpdateUser *userDTO.UserDTO) (int64, *
 		updateFields["lastname"] = dtoUpdateUser.Lastname
 	}
 	if dtoUpdateUser.Password != "" {
-		updateFields["password"] = dtoUpdateUser.Password
+		password, errHash := userEntity.HashPassword(dtoUpdateUser.Password)
+		if errHash != nil {
+			logger.Error(fmt.Sprintf("Error hashing password for user %s: %s", dtoUpdateUser.Username, errHash.Error()))
+			return 0, exception.NewApiException(500, "Internal server error")
+		}
+		updateFields["password"] = password
 	}
 
 	if len(updateFields) == 0 {
@@ -172,7 +378,7 @@ func (r *UserMongoDBRepository) Update(dtoUpdateUser *userDTO.UserDTO) (int64, *
 
 	update := bson.M{"$set": updateFields}
 	// Update the user
-	result, errUpdate := r.mongo.UpdateOne(context.Background(), filter, update)
+	result, errUpdate := r.mongo.UpdateOne(ctx, filter, update)
 	if errUpdate != nil {
 		logger.Error(fmt.Sprintf("Error updating user %s: %s", dtoUpdateUser.Username, errUpdate.Error()))
 		return 0, exception.NewApiException(500, "Error updating the user in the database")
@@ -186,7 +392,34 @@ func (r *UserMongoDBRepository) Update(dtoUpdateUser *userDTO.UserDTO) (int64, *
 	return result.ModifiedCount, nil
 }
 
+func (r *UserMongoDBRepository) Verify(username string) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Attempting to verify email of user: %s", username))
+
+	filter := bson.M{USERNAME: username}
+	update := bson.M{"$set": bson.M{VERIFIED: true}}
+
+	result, err := r.mongo.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error verifying user %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error verifying the user")
+	}
+
+	if result.MatchedCount == 0 {
+		logger.Warning(fmt.Sprintf("User not found for verification: %s", username))
+		return 0, exception.NewApiException(404, "User not found for verification")
+	}
+
+	logger.Info(fmt.Sprintf("User email successfully verified: %s", username))
+	return result.MatchedCount, nil
+}
+
 func (r *UserMongoDBRepository) Delete(dtoDeleteUser *userDTO.UserDTO) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
 	logger.Info(fmt.Sprintf("Attempting to delete user: %s", dtoDeleteUser.Username))
 
 	filter := bson.M{USERNAME: dtoDeleteUser.Username}
@@ -201,7 +434,7 @@ func (r *UserMongoDBRepository) Delete(dtoDeleteUser *userDTO.UserDTO) (int64, *
 		return 0, exception.NewApiException(404, "Incorrect password")
 	}
 
-	result, errDelete := r.mongo.DeleteOne(context.Background(), filter)
+	result, errDelete := r.mongo.DeleteOne(ctx, filter)
 	if errDelete != nil {
 		logger.Error(fmt.Sprintf("Error deleting user %s: %s", dtoDeleteUser.Username, errDelete.Error()))
 		return 0, exception.NewApiException(500, "Error deleting the user")
@@ -214,13 +447,383 @@ func (r *UserMongoDBRepository) Delete(dtoDeleteUser *userDTO.UserDTO) (int64, *
 		return 0, exception.NewApiException(404, "No user deleted")
 	}
 
+	r.deleteLinkedData(dtoDeleteUser.Username)
+
 	return deleteCount, nil
 }
 
+func (r *UserMongoDBRepository) InsertEmailChange(dto *userDTO.EmailChangeDTO) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Registering pending email change for user: %s", dto.Username))
+
+	// Only one pending change is kept per user, the previous one is replaced
+	filter := bson.M{USERNAME: dto.Username, "confirmed": false}
+	pending := &userDTO.EmailChangeDTO{
+		Username: dto.Username,
+		OldEmail: dto.OldEmail,
+		NewEmail: dto.NewEmail,
+	}
+
+	_, err := r.mongoEmailChange.ReplaceOne(ctx, filter, pending, options.Replace().SetUpsert(true))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error registering email change of %s: %s", dto.Username, err.Error()))
+		return nil, exception.NewApiException(500, "Error registering email change")
+	}
+
+	logger.Info(fmt.Sprintf("Pending email change registered for user: %s", dto.Username))
+	return pending, nil
+}
+
+func (r *UserMongoDBRepository) FindPendingEmailChange(username string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Searching for pending email change of user: %s", username))
+
+	filter := bson.M{USERNAME: username, "confirmed": false}
+	return r.findEmailChange(filter)
+}
+
+func (r *UserMongoDBRepository) ConfirmEmailChange(dto *userDTO.EmailChangeDTO) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Confirming email change of user: %s", dto.Username))
+
+	filter := bson.M{USERNAME: dto.Username, "confirmed": false}
+	update := bson.M{"$set": bson.M{
+		"confirmed":         true,
+		"revert_token_hash": dto.RevertTokenHash,
+		"revert_expiration": dto.RevertExpiration,
+	}}
+
+	result, err := r.mongoEmailChange.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error confirming email change of %s: %s", dto.Username, err.Error()))
+		return 0, exception.NewApiException(500, "Error confirming email change")
+	}
+
+	if result.MatchedCount == 0 {
+		logger.Warning(fmt.Sprintf("No pending email change to confirm for user: %s", dto.Username))
+		return 0, exception.NewApiException(404, "No pending email change found")
+	}
+
+	return result.MatchedCount, nil
+}
+
+func (r *UserMongoDBRepository) FindEmailChangeByRevertToken(revertTokenHash string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	filter := bson.M{"revert_token_hash": revertTokenHash, "confirmed": true}
+	return r.findEmailChange(filter)
+}
+
+func (r *UserMongoDBRepository) DeleteEmailChange(revertTokenHash string) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	filter := bson.M{"revert_token_hash": revertTokenHash}
+
+	result, err := r.mongoEmailChange.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error deleting email change: %s", err.Error()))
+		return 0, exception.NewApiException(500, "Error deleting email change")
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (r *UserMongoDBRepository) findEmailChange(filter bson.M) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	dto := new(userDTO.EmailChangeDTO)
+	err := r.mongoEmailChange.FindOne(ctx, filter).Decode(dto)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, exception.NewApiException(404, "No email change found")
+		}
+		logger.Error(fmt.Sprintf("Error retrieving email change: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error retrieving email change")
+	}
+
+	return dto, nil
+}
+
+func (r *UserMongoDBRepository) FindByIdentity(provider, subject string) (*userDTO.UserDTO, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Searching for user linked to %s identity", provider))
+
+	identity := new(userDTO.UserIdentityDTO)
+	err := r.mongoIdentity.FindOne(ctx, bson.M{"provider": provider, "subject": subject}).Decode(identity)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, exception.NewApiException(404, "No user linked to this identity")
+		}
+		logger.Error(fmt.Sprintf("Error retrieving %s identity: %s", provider, err.Error()))
+		return nil, exception.NewApiException(500, "Error retrieving identity")
+	}
+
+	user, errFind := r.find(bson.M{USERNAME: identity.Username})
+	if errFind != nil {
+		logger.Error(fmt.Sprintf("Error searching for user %s linked to %s identity: %s", identity.Username, provider, errFind.Message))
+		return nil, errFind
+	}
+
+	return userDTO.FromUser(user[0]), nil
+}
+
+func (r *UserMongoDBRepository) InsertIdentity(dto *userDTO.UserIdentityDTO) (*userDTO.UserIdentityDTO, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Linking %s identity to user: %s", dto.Provider, dto.Username))
+
+	// The identity is only inserted when it is not linked yet
+	filter := bson.M{"provider": dto.Provider, "subject": dto.Subject}
+	update := bson.M{"$setOnInsert": dto}
+
+	result, err := r.mongoIdentity.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error linking %s identity to %s: %s", dto.Provider, dto.Username, err.Error()))
+		return nil, exception.NewApiException(500, "Error linking identity")
+	}
+
+	if result.UpsertedCount == 0 {
+		logger.Warning(fmt.Sprintf("The %s identity is already linked to another user", dto.Provider))
+		return nil, exception.NewApiException(409, "The identity is already linked to a user")
+	}
+
+	logger.Info(fmt.Sprintf("%s identity linked to user: %s", dto.Provider, dto.Username))
+	return dto, nil
+}
+
+func (r *UserMongoDBRepository) FindByUsername(username string) (*userDTO.UserDTO, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Searching for user by username: %s", username))
+
+	user, err := r.find(bson.M{USERNAME: username})
+	if err != nil {
+		logger.Warning(fmt.Sprintf("User not found with username: %s", username))
+		return nil, err
+	}
+
+	return userDTO.FromUser(user[0]), nil
+}
+
+// Lists the users ordered by username, the search matches any part of the username, email or name ignoring case
+func (r *UserMongoDBRepository) FindAll(search, lastUsername string, pageSize int64) (*userDTO.UserCursorDTO, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Listing users matching '%s' after '%s', pageSize=%d", search, lastUsername, pageSize))
+
+	filter := bson.M{USERNAME: bson.M{"$gt": lastUsername}}
+	if search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+		filter["$or"] = []bson.M{
+			{USERNAME: pattern},
+			{EMAIL: pattern},
+			{"firstname": pattern},
+			{"lastname": pattern},
+		}
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: USERNAME, Value: 1}}).
+		SetLimit(pageSize).
+		SetProjection(bson.M{"password": 0})
+
+	cursor, err := r.mongo.Find(ctx, filter, findOptions)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error listing users: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error listing users")
+	}
+	defer cursor.Close(ctx)
+
+	var users []*userDTO.UserDTO
+	if err := cursor.All(ctx, &users); err != nil {
+		logger.Error(fmt.Sprintf("Error decoding users: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error decoding users")
+	}
+
+	result := &userDTO.UserCursorDTO{Users: make([]userDTO.UserSummaryDTO, 0, len(users))}
+	for _, user := range users {
+		// Users stored before roles existed get the default role
+		if user.Role == "" {
+			user.Role = userEntity.ROLE_USER
+		}
+		result.Users = append(result.Users, userDTO.ToUserSummary(user))
+	}
+
+	if len(result.Users) > 0 {
+		result.LastUsername = result.Users[len(result.Users)-1].Username
+	}
+
+	return result, nil
+}
+
+func (r *UserMongoDBRepository) UpdateRole(username, role string) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to change role of user %s to %s", username, role))
+	return r.updateAccountField(ROLE, role, username)
+}
+
+func (r *UserMongoDBRepository) SetDisabled(username string, disabled bool) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to set disabled=%t for user: %s", disabled, username))
+	return r.updateAccountField(DISABLED, disabled, username)
+}
+
+// Increases the session version so every JWT issued until now is rejected
+func (r *UserMongoDBRepository) RevokeSessions(username string) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Attempting to revoke the sessions of user: %s", username))
+
+	update := bson.M{"$inc": bson.M{SESSION_VERSION: 1}}
+	result, err := r.mongo.UpdateOne(ctx, bson.M{USERNAME: username}, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error revoking the sessions of user %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error updating the user in the database")
+	}
+
+	if result.MatchedCount == 0 {
+		logger.Warning(fmt.Sprintf("User not found to revoke sessions: %s", username))
+		return 0, exception.NewApiException(404, "User not found for update")
+	}
+
+	logger.Info(fmt.Sprintf("Sessions of user %s revoked", username))
+	return result.MatchedCount, nil
+}
+
+// Schedules the purge of the account, a nil date cancels a pending deletion
+func (r *UserMongoDBRepository) SetDeletionSchedule(username string, deletionScheduledAt *time.Time) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to set deletion schedule %v for user: %s", deletionScheduledAt, username))
+	if deletionScheduledAt == nil {
+		return r.updateAccountField(DELETION_SCHEDULED_AT, nil, username)
+	}
+	return r.updateAccountField(DELETION_SCHEDULED_AT, *deletionScheduledAt, username)
+}
+
+func (r *UserMongoDBRepository) FindScheduledForDeletion(before time.Time) ([]string, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	filter := bson.M{DELETION_SCHEDULED_AT: bson.M{"$ne": nil, "$lte": before}}
+	findOptions := options.Find().SetProjection(bson.M{USERNAME: 1})
+
+	cursor, err := r.mongo.Find(ctx, filter, findOptions)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error searching users scheduled for deletion: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error searching users scheduled for deletion")
+	}
+	defer cursor.Close(ctx)
+
+	usernames := make([]string, 0)
+	for cursor.Next(ctx) {
+		var user userDTO.UserDTO
+		if err := cursor.Decode(&user); err != nil {
+			logger.Error(fmt.Sprintf("Error decoding user scheduled for deletion: %s", err.Error()))
+			return nil, exception.NewApiException(500, "Error decoding users")
+		}
+		usernames = append(usernames, user.Username)
+	}
+
+	return usernames, nil
+}
+
+func (r *UserMongoDBRepository) DeleteByUsername(username string) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	logger.Info(fmt.Sprintf("Attempting to delete user without credentials check: %s", username))
+
+	result, err := r.mongo.DeleteOne(ctx, bson.M{USERNAME: username})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error deleting user %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error deleting the user")
+	}
+
+	if result.DeletedCount == 0 {
+		logger.Warning(fmt.Sprintf("User not found to delete: %s", username))
+		return 0, exception.NewApiException(404, "No user deleted")
+	}
+
+	r.deleteLinkedData(username)
+
+	logger.Info(fmt.Sprintf("User successfully deleted: %s", username))
+	return result.DeletedCount, nil
+}
+
+// MongoDB has no cascading deletes, so the identities and email changes of a deleted user are removed here
+func (r *UserMongoDBRepository) deleteLinkedData(username string) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	filter := bson.M{USERNAME: username}
+
+	if _, err := r.mongoIdentity.DeleteMany(ctx, filter); err != nil {
+		logger.Error(fmt.Sprintf("Error deleting identities of user %s: %s", username, err.Error()))
+	}
+
+	if _, err := r.mongoEmailChange.DeleteMany(ctx, filter); err != nil {
+		logger.Error(fmt.Sprintf("Error deleting email changes of user %s: %s", username, err.Error()))
+	}
+}
+
+// ClaimForPurge marca la cuenta como en purga solo si su borrado sigue vencido, devuelve 0 si se canceló o reprogramó.
+// Una cuenta marcada ya no puede cancelarse
+func (r *UserMongoDBRepository) ClaimForPurge(username string, before time.Time) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	filter := bson.M{USERNAME: username, DELETION_SCHEDULED_AT: bson.M{"$ne": nil, "$lte": before}}
+	result, err := r.mongo.UpdateOne(ctx, filter, bson.M{"$set": bson.M{PURGING: true}})
+	if err != nil {
+		logger.Error("Error claiming user for purge", log.F("username", username), log.F("error", err.Error()))
+		return 0, exception.NewApiException(500, "Error updating the user in the database")
+	}
+	return result.MatchedCount, nil
+}
+
+// CancelDeletion quita el borrado programado para esa fecha salvo que la purga ya haya reclamado la cuenta,
+// devuelve 0 en ese caso
+func (r *UserMongoDBRepository) CancelDeletion(username string, deletionScheduledAt time.Time) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	filter := bson.M{USERNAME: username, DELETION_SCHEDULED_AT: deletionScheduledAt, PURGING: bson.M{"$ne": true}}
+	result, err := r.mongo.UpdateOne(ctx, filter, bson.M{"$set": bson.M{DELETION_SCHEDULED_AT: nil}})
+	if err != nil {
+		logger.Error("Error cancelling the deletion of user", log.F("username", username), log.F("error", err.Error()))
+		return 0, exception.NewApiException(500, "Error updating the user in the database")
+	}
+	return result.MatchedCount, nil
+}
+
+func (r *UserMongoDBRepository) updateAccountField(field string, value any, username string) (int64, *exception.ApiException) {
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	filter := bson.M{USERNAME: username}
+	update := bson.M{"$set": bson.M{field: value}}
+
+	result, err := r.mongo.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error updating %s of user %s: %s", field, username, err.Error()))
+		return 0, exception.NewApiException(500, "Error updating the user in the database")
+	}
+
+	if result.MatchedCount == 0 {
+		logger.Warning(fmt.Sprintf("User not found for update: %s", username))
+		return 0, exception.NewApiException(404, "User not found for update")
+	}
+
+	logger.Info(fmt.Sprintf("Field %s successfully updated for user: %s", field, username))
+	return result.MatchedCount, nil
+}
+
 func (r *UserMongoDBRepository) checkUserIsCreated(dtoInsertUser *userDTO.UserDTO) *exception.ApiException {
 	filter := bson.M{
 		"$or": []bson.M{
-			{EMAIL: dtoInsertUser.Email},
+			{EMAIL: userEntity.NormalizeEmail(dtoInsertUser.Email)},
 			{USERNAME: dtoInsertUser.Username},
 		},
 	}
@@ -243,14 +846,17 @@ func (r *UserMongoDBRepository) checkUserIsCreated(dtoInsertUser *userDTO.UserDT
 }
 
 func (r *UserMongoDBRepository) find(filter bson.M) ([]*userEntity.User, *exception.ApiException) {
-	cursor, err := r.mongo.Find(context.Background(), filter)
+	ctx, cancel := r.provider.WithTimeout(context.Background())
+	defer cancel()
+
+	cursor, err := r.mongo.Find(ctx, filter)
 	if err != nil {
 		return nil, exception.NewApiException(500, "Error searching for users")
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var users []*userDTO.UserDTO
-	if err := cursor.All(context.Background(), &users); err != nil {
+	if err := cursor.All(ctx, &users); err != nil {
 		return nil, exception.NewApiException(500, "Error decoding users")
 	}
 
@@ -262,6 +868,7 @@ func (r *UserMongoDBRepository) find(filter bson.M) ([]*userEntity.User, *except
 	for _, userDTO := range users {
 		user, errBuilder := userBuilder.NewUserBuilder().
 			FromDTO(userDTO).
+			SetPasswordHashed(true).
 			Build()
 		if errBuilder != nil {
 			return nil, exception.NewApiException(500, errBuilder.Error())
@@ -0,0 +1,32 @@
+package userRepository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailConflictLosersKeepsTheVerifiedAccount(t *testing.T) {
+	losers := emailConflictLosers([]emailOwner{
+		{Username: "alice", Email: "alice@example.com", Verified: false},
+		{Username: "Alice2", Email: "Alice@Example.com", Verified: true},
+		{Username: "bob", Email: "bob@example.com", Verified: true},
+	})
+
+	assert.Equal(t, []emailOwner{{Username: "alice", Email: "alice@example.com", Verified: false}}, losers)
+}
+
+func TestEmailConflictLosersPrefersTheLowercaseEmailAndThenTheUsername(t *testing.T) {
+	losers := emailConflictLosers([]emailOwner{
+		{Username: "a", Email: "CAROL@example.com", Verified: true},
+		{Username: "c", Email: "carol@example.com", Verified: true},
+		{Username: "b", Email: "Carol@example.com", Verified: true},
+	})
+
+	assert.Equal(t, []string{"a", "b"}, []string{losers[0].Username, losers[1].Username})
+}
+
+func TestConflictEmailIsUniquePerUsername(t *testing.T) {
+	assert.Equal(t, "conflict+6384e2b2184bcbf58eccf10ca7a6563c@invalid", conflictEmail("alice"))
+	assert.NotEqual(t, conflictEmail("alice"), conflictEmail("bob"))
+}
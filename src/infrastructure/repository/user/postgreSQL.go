@@ -10,10 +10,9 @@ import (
 
 	userDTO "go-gallery/src/infrastructure/dto/user"
 	log "go-gallery/src/infrastructure/logger"
-	"os"
+	postgresClient "go-gallery/src/infrastructure/postgres"
+	"strings"
 	"time"
-
-	_ "github.com/lib/pq"
 )
 
 const UserPostgreSQLRepositoryKey = "UserPostgreSQLRepository"
@@ -24,63 +23,14 @@ type UserPostgreSQLRepository struct {
 	db *sql.DB
 }
 
-const (
-	retries uint = 5
-)
-
-func NewUserPostgreSQLRepository(args map[string]string) UserRepository {
-	user := args["POSTGRESQL_USER"]
-	password := args["POSTGRESQL_PASSWORD"]
-	dbName := args["POSTGRESQL_DB"]
-	host := args["POSTGRESQL_HOST"]
-	port := args["POSTGRESQL_PORT"]
-
-	// Construimos la URL de conexión
-	urlConnection := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", user, password, host, port, dbName)
-
+func NewUserPostgreSQLRepository(provider *postgresClient.Provider) UserRepository {
 	logger = log.Instance()
 
-	db, err := sql.Open("postgres", urlConnection)
-	if err != nil {
-		panic(fmt.Sprintf("Could not connect to PostgreSQL: %s", err.Error()))
-	}
-
-	// Comprobamos si la base de datos realmente está disponible
-	for i := range retries {
-		err = db.Ping()
-		if err == nil {
-			logger.Info("Successfully connected to PostgreSQL user database")
-			break
-		}
-
-		// Si hemos llegado al último intento, mostramos un mensaje de error
-		if i == retries-1 {
-			logger.Error("Could not connect to PostgreSQL after several attempts.")
-			panicMessage := fmt.Sprintf("Error trying to connect to PostgreSQL: %s", err.Error())
-			logger.Panic(panicMessage)
-			panic(panicMessage)
-		}
-
-		logger.Warning(fmt.Sprintf("Attempt %d of %d. Retrying...\n", i+1, retries))
-		time.Sleep(5 * time.Second)
-	}
-
 	// Ejecutar el DDL para crear la tabla si no existe
-	ddl, err := os.ReadFile("sql/userTableDDL.sql")
-	if err != nil {
-		panicMessage := fmt.Sprintf("Could not read the DDL file: %s", err.Error())
-		logger.Panic(panicMessage)
-		panic(panicMessage)
-	}
-
-	_, err = db.Exec(string(ddl))
-	if err != nil {
-		panicMessage := fmt.Sprintf("Error executing DDL creation: %s", err.Error())
-		logger.Panic(panicMessage)
-		panic(panicMessage)
-	}
+	provider.ExecFile("sql/userTableDDL.sql")
+	logger.Info("User repository initialized with the shared PostgreSQL connection pool")
 
-	return &UserPostgreSQLRepository{db: db}
+	return &UserPostgreSQLRepository{db: provider.DB()}
 }
 
 func (u *UserPostgreSQLRepository) Find(dtoLoginRequest *userDTO.LoginRequestDTO) (*userDTO.UserDTO, *exception.ApiException) {
@@ -92,6 +42,10 @@ func (u *UserPostgreSQLRepository) Find(dtoLoginRequest *userDTO.LoginRequestDTO
 		return nil, err
 	}
 
+	if user.NeedsPasswordRehash() {
+		u.rehashPassword(user.GetUsername(), dtoLoginRequest.Password)
+	}
+
 	logger.Info(fmt.Sprintf("User found: %s", user.GetUsername()))
 
 	return userDTO.FromUser(user), nil
@@ -110,10 +64,27 @@ func (u *UserPostgreSQLRepository) find(username, password string) (*userEntity.
 	return user, nil
 }
 
+// Replaces a hash with outdated parameters, the login is not affected if it fails
+func (u *UserPostgreSQLRepository) rehashPassword(username, password string) {
+	hashedPassword, err := userEntity.HashPassword(password)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error rehashing password for user %s: %s", username, err.Error()))
+		return
+	}
+
+	if _, err := u.db.Exec("UPDATE users SET password = $1 WHERE username = $2", hashedPassword, username); err != nil {
+		logger.Error(fmt.Sprintf("Error storing rehashed password for user %s: %s", username, err.Error()))
+		return
+	}
+
+	logger.Info(fmt.Sprintf("Password hash of user %s upgraded to the current parameters", username))
+}
+
 func (u *UserPostgreSQLRepository) FindByEmail(email string) (*userDTO.UserDTO, *exception.ApiException) {
 	logger.Info(fmt.Sprintf("Searching for user by email: %s", email))
 
-	user, err := u.findBy("email", email)
+	// Matches the expression of the unique index, so the lookup uses it
+	user, err := u.findBy("LOWER(email)", userEntity.NormalizeEmail(email))
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error searching for user with email %s: %s", email, err.Message))
 		return nil, err
@@ -123,19 +94,23 @@ func (u *UserPostgreSQLRepository) FindByEmail(email string) (*userDTO.UserDTO,
 }
 
 func (u *UserPostgreSQLRepository) findBy(field, value string) (*userEntity.User, *exception.ApiException) {
-	query := fmt.Sprintf("SELECT username, email, firstname, lastname, password FROM users WHERE %s = $1", field)
+	query := fmt.Sprintf("SELECT username, email, firstname, lastname, password, role, verified, disabled, session_version, deletion_scheduled_at FROM users WHERE %s = $1", field)
 	row := u.db.QueryRow(query, value)
 
 	userDTO := new(userDTO.UserDTO)
-	if err := row.Scan(&userDTO.Username, &userDTO.Email, &userDTO.Firstname, &userDTO.Lastname, &userDTO.Password); err != nil {
+	var deletionScheduledAt sql.NullTime
+	if err := row.Scan(&userDTO.Username, &userDTO.Email, &userDTO.Firstname, &userDTO.Lastname, &userDTO.Password,
+		&userDTO.Role, &userDTO.Verified, &userDTO.Disabled, &userDTO.SessionVersion, &deletionScheduledAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, exception.NewApiException(404, "User not found")
 		}
 		return nil, exception.NewApiException(500, "Error retrieving user")
 	}
+	userDTO.DeletionScheduledAt = nullTimePointer(deletionScheduledAt)
 
 	user, errBuilder := userBuilder.NewUserBuilder().
 		FromDTO(userDTO).
+		SetPasswordHashed(true).
 		Build()
 
 	if errBuilder != nil {
@@ -148,14 +123,16 @@ func (u *UserPostgreSQLRepository) findBy(field, value string) (*userEntity.User
 func (u *UserPostgreSQLRepository) FindAndCheckJWT(claims *userDTO.JwtClaimsDTO) (*userDTO.UserDTO, *exception.ApiException) {
 	logger.Info(fmt.Sprintf("Verifying JWT for user: %s", claims.Username))
 
-	query := "SELECT username, email FROM users WHERE username = $1"
+	query := "SELECT username, email, role, verified, disabled, session_version, deletion_scheduled_at FROM users WHERE username = $1"
 	row := u.db.QueryRow(query, claims.Username)
 
 	userDTO := new(userDTO.UserDTO)
-	if err := row.Scan(&userDTO.Username, &userDTO.Email); err != nil {
+	var deletionScheduledAt sql.NullTime
+	if err := row.Scan(&userDTO.Username, &userDTO.Email, &userDTO.Role, &userDTO.Verified, &userDTO.Disabled, &userDTO.SessionVersion, &deletionScheduledAt); err != nil {
 		logger.Warning(fmt.Sprintf("User not found when verifying JWT: %s", claims.Username))
 		return nil, exception.NewApiException(404, "User not found")
 	}
+	userDTO.DeletionScheduledAt = nullTimePointer(deletionScheduledAt)
 
 	if userDTO.Username != claims.Username || userDTO.Email != claims.Email {
 		logger.Warning(fmt.Sprintf(
@@ -187,8 +164,8 @@ func (u *UserPostgreSQLRepository) Insert(dtoRegisterRequest *userDTO.UserDTO) (
 		return nil, exception.NewApiException(500, err.Error())
 	}
 
-	query := "INSERT INTO users (username, email, firstname, lastname, password) VALUES ($1, $2, $3, $4, $5)"
-	_, errDb := u.db.Exec(query, user.GetUsername(), user.GetEmail(), user.GetFirstname(), user.GetLastname(), user.GetPassword())
+	query := "INSERT INTO users (username, email, firstname, lastname, password, role, verified) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	_, errDb := u.db.Exec(query, user.GetUsername(), user.GetEmail(), user.GetFirstname(), user.GetLastname(), user.GetPassword(), user.GetRole(), user.IsVerified())
 	if errDb != nil {
 		logger.Error(fmt.Sprintf("Error inserting user %s: %s", user.GetUsername(), errDb.Error()))
 		return nil, exception.NewApiException(500, "Error inserting user")
@@ -263,6 +240,31 @@ func (u *UserPostgreSQLRepository) Update(dtoUpdateUser *userDTO.UserDTO) (int64
 	return rowsAffected, nil
 }
 
+func (u *UserPostgreSQLRepository) Verify(username string) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to verify email of user: %s", username))
+
+	query := "UPDATE users SET verified = TRUE WHERE username = $1"
+	result, err := u.db.Exec(query, username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error verifying user %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error verifying user")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting affected rows when verifying %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error getting affected rows")
+	}
+
+	if rowsAffected == 0 {
+		logger.Warning(fmt.Sprintf("User not found for verification: %s", username))
+		return 0, exception.NewApiException(404, "User not found for verification")
+	}
+
+	logger.Info(fmt.Sprintf("User email successfully verified: %s", username))
+	return rowsAffected, nil
+}
+
 func (u *UserPostgreSQLRepository) Delete(dtoDeleteUser *userDTO.UserDTO) (int64, *exception.ApiException) {
 	logger.Info(fmt.Sprintf("Attempting to delete user: %s", dtoDeleteUser.Username))
 	// Check if the user exists and that the password is correct before deleting the user
@@ -294,10 +296,367 @@ func (u *UserPostgreSQLRepository) Delete(dtoDeleteUser *userDTO.UserDTO) (int64
 	return rowsAffected, nil
 }
 
+func (u *UserPostgreSQLRepository) InsertEmailChange(dto *userDTO.EmailChangeDTO) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Registering pending email change for user: %s", dto.Username))
+
+	tx, err := u.db.Begin()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error starting transaction for email change of %s: %s", dto.Username, err.Error()))
+		return nil, exception.NewApiException(500, "Error registering email change")
+	}
+	defer tx.Rollback()
+
+	// Only one pending change is kept per user, the previous one is replaced
+	_, err = tx.Exec("DELETE FROM email_changes WHERE username = $1 AND confirmed = FALSE", dto.Username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error removing previous email change of %s: %s", dto.Username, err.Error()))
+		return nil, exception.NewApiException(500, "Error registering email change")
+	}
+
+	query := "INSERT INTO email_changes (username, old_email, new_email) VALUES ($1, $2, $3)"
+	_, err = tx.Exec(query, dto.Username, dto.OldEmail, dto.NewEmail)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error inserting email change of %s: %s", dto.Username, err.Error()))
+		return nil, exception.NewApiException(500, "Error registering email change")
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error(fmt.Sprintf("Error committing email change of %s: %s", dto.Username, err.Error()))
+		return nil, exception.NewApiException(500, "Error registering email change")
+	}
+
+	logger.Info(fmt.Sprintf("Pending email change registered for user: %s", dto.Username))
+	return dto, nil
+}
+
+func (u *UserPostgreSQLRepository) FindPendingEmailChange(username string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Searching for pending email change of user: %s", username))
+
+	query := "SELECT username, old_email, new_email, confirmed FROM email_changes WHERE username = $1 AND confirmed = FALSE"
+	row := u.db.QueryRow(query, username)
+
+	dto := new(userDTO.EmailChangeDTO)
+	if err := row.Scan(&dto.Username, &dto.OldEmail, &dto.NewEmail, &dto.Confirmed); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewApiException(404, "No pending email change found")
+		}
+		logger.Error(fmt.Sprintf("Error retrieving pending email change of %s: %s", username, err.Error()))
+		return nil, exception.NewApiException(500, "Error retrieving email change")
+	}
+
+	return dto, nil
+}
+
+func (u *UserPostgreSQLRepository) ConfirmEmailChange(dto *userDTO.EmailChangeDTO) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Confirming email change of user: %s", dto.Username))
+
+	query := "UPDATE email_changes SET confirmed = TRUE, revert_token_hash = $1, revert_expiration = $2 WHERE username = $3 AND confirmed = FALSE"
+	result, err := u.db.Exec(query, dto.RevertTokenHash, dto.RevertExpiration, dto.Username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error confirming email change of %s: %s", dto.Username, err.Error()))
+		return 0, exception.NewApiException(500, "Error confirming email change")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting affected rows when confirming email change of %s: %s", dto.Username, err.Error()))
+		return 0, exception.NewApiException(500, "Error getting affected rows")
+	}
+
+	if rowsAffected == 0 {
+		logger.Warning(fmt.Sprintf("No pending email change to confirm for user: %s", dto.Username))
+		return 0, exception.NewApiException(404, "No pending email change found")
+	}
+
+	return rowsAffected, nil
+}
+
+func (u *UserPostgreSQLRepository) FindEmailChangeByRevertToken(revertTokenHash string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	query := "SELECT username, old_email, new_email, confirmed, revert_expiration FROM email_changes WHERE revert_token_hash = $1 AND confirmed = TRUE"
+	row := u.db.QueryRow(query, revertTokenHash)
+
+	dto := new(userDTO.EmailChangeDTO)
+	if err := row.Scan(&dto.Username, &dto.OldEmail, &dto.NewEmail, &dto.Confirmed, &dto.RevertExpiration); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewApiException(404, "Email change not found")
+		}
+		logger.Error(fmt.Sprintf("Error retrieving email change by revert token: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error retrieving email change")
+	}
+	dto.RevertTokenHash = revertTokenHash
+
+	return dto, nil
+}
+
+func (u *UserPostgreSQLRepository) DeleteEmailChange(revertTokenHash string) (int64, *exception.ApiException) {
+	result, err := u.db.Exec("DELETE FROM email_changes WHERE revert_token_hash = $1", revertTokenHash)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error deleting email change: %s", err.Error()))
+		return 0, exception.NewApiException(500, "Error deleting email change")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting affected rows when deleting email change: %s", err.Error()))
+		return 0, exception.NewApiException(500, "Error getting affected rows")
+	}
+
+	return rowsAffected, nil
+}
+
+func (u *UserPostgreSQLRepository) FindByIdentity(provider, subject string) (*userDTO.UserDTO, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Searching for user linked to %s identity", provider))
+
+	var username string
+	query := "SELECT username FROM user_identities WHERE provider = $1 AND subject = $2"
+	if err := u.db.QueryRow(query, provider, subject).Scan(&username); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.NewApiException(404, "No user linked to this identity")
+		}
+		logger.Error(fmt.Sprintf("Error retrieving %s identity: %s", provider, err.Error()))
+		return nil, exception.NewApiException(500, "Error retrieving identity")
+	}
+
+	user, err := u.findBy("username", username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error searching for user %s linked to %s identity: %s", username, provider, err.Message))
+		return nil, err
+	}
+
+	return userDTO.FromUser(user), nil
+}
+
+func (u *UserPostgreSQLRepository) InsertIdentity(dto *userDTO.UserIdentityDTO) (*userDTO.UserIdentityDTO, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Linking %s identity to user: %s", dto.Provider, dto.Username))
+
+	query := "INSERT INTO user_identities (provider, subject, username) VALUES ($1, $2, $3) ON CONFLICT (provider, subject) DO NOTHING"
+	result, err := u.db.Exec(query, dto.Provider, dto.Subject, dto.Username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error linking %s identity to %s: %s", dto.Provider, dto.Username, err.Error()))
+		return nil, exception.NewApiException(500, "Error linking identity")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting affected rows when linking identity to %s: %s", dto.Username, err.Error()))
+		return nil, exception.NewApiException(500, "Error getting affected rows")
+	}
+
+	if rowsAffected == 0 {
+		logger.Warning(fmt.Sprintf("The %s identity is already linked to another user", dto.Provider))
+		return nil, exception.NewApiException(409, "The identity is already linked to a user")
+	}
+
+	logger.Info(fmt.Sprintf("%s identity linked to user: %s", dto.Provider, dto.Username))
+	return dto, nil
+}
+
+func (u *UserPostgreSQLRepository) FindByUsername(username string) (*userDTO.UserDTO, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Searching for user by username: %s", username))
+
+	user, err := u.findBy("username", username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error searching for user %s: %s", username, err.Message))
+		return nil, err
+	}
+
+	return userDTO.FromUser(user), nil
+}
+
+// Lists the users ordered by username, the search matches any part of the username, email or name ignoring case
+func (u *UserPostgreSQLRepository) FindAll(search, lastUsername string, pageSize int64) (*userDTO.UserCursorDTO, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Listing users matching '%s' after '%s', pageSize=%d", search, lastUsername, pageSize))
+
+	query := `SELECT username, email, firstname, lastname, role, verified, disabled, deletion_scheduled_at FROM users
+		WHERE username > $1 AND ($2 = '' OR username ILIKE $3 OR email ILIKE $3 OR firstname ILIKE $3 OR lastname ILIKE $3)
+		ORDER BY username LIMIT $4`
+	rows, err := u.db.Query(query, lastUsername, search, "%"+escapeLike(search)+"%", pageSize)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error listing users: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error listing users")
+	}
+	defer rows.Close()
+
+	cursor := &userDTO.UserCursorDTO{Users: []userDTO.UserSummaryDTO{}}
+	for rows.Next() {
+		var user userDTO.UserSummaryDTO
+		var firstname, lastname sql.NullString
+		var deletionScheduledAt sql.NullTime
+		if err := rows.Scan(&user.Username, &user.Email, &firstname, &lastname, &user.Role, &user.Verified, &user.Disabled, &deletionScheduledAt); err != nil {
+			logger.Error(fmt.Sprintf("Error decoding user: %s", err.Error()))
+			return nil, exception.NewApiException(500, "Error decoding users")
+		}
+		user.Firstname = firstname.String
+		user.Lastname = lastname.String
+		user.DeletionScheduledAt = nullTimePointer(deletionScheduledAt)
+		cursor.Users = append(cursor.Users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(fmt.Sprintf("Error iterating users: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error listing users")
+	}
+
+	if len(cursor.Users) > 0 {
+		cursor.LastUsername = cursor.Users[len(cursor.Users)-1].Username
+	}
+
+	return cursor, nil
+}
+
+func (u *UserPostgreSQLRepository) UpdateRole(username, role string) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to change role of user %s to %s", username, role))
+	return u.updateAccountField("role", role, username)
+}
+
+func (u *UserPostgreSQLRepository) SetDisabled(username string, disabled bool) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to set disabled=%t for user: %s", disabled, username))
+	return u.updateAccountField("disabled", disabled, username)
+}
+
+// Increases the session version so every JWT issued until now is rejected
+func (u *UserPostgreSQLRepository) RevokeSessions(username string) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to revoke the sessions of user: %s", username))
+
+	result, err := u.db.Exec("UPDATE users SET session_version = session_version + 1 WHERE username = $1", username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error revoking the sessions of user %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error updating user")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting affected rows when revoking the sessions of %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error getting affected rows")
+	}
+
+	if rowsAffected == 0 {
+		logger.Warning(fmt.Sprintf("User not found to revoke sessions: %s", username))
+		return 0, exception.NewApiException(404, "User not found for update")
+	}
+
+	logger.Info(fmt.Sprintf("Sessions of user %s revoked", username))
+	return rowsAffected, nil
+}
+
+// Schedules the purge of the account, a nil date cancels a pending deletion
+func (u *UserPostgreSQLRepository) SetDeletionSchedule(username string, deletionScheduledAt *time.Time) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to set deletion schedule %v for user: %s", deletionScheduledAt, username))
+	if deletionScheduledAt == nil {
+		return u.updateAccountField("deletion_scheduled_at", nil, username)
+	}
+	return u.updateAccountField("deletion_scheduled_at", *deletionScheduledAt, username)
+}
+
+// ClaimForPurge marca la cuenta como en purga solo si su borrado sigue vencido, devuelve 0 si se canceló o reprogramó.
+// Una cuenta marcada ya no puede cancelarse
+func (u *UserPostgreSQLRepository) ClaimForPurge(username string, before time.Time) (int64, *exception.ApiException) {
+	query := "UPDATE users SET purging = TRUE WHERE username = $1 AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $2"
+	return u.execConditional(query, "claiming user for purge", username, before)
+}
+
+// CancelDeletion quita el borrado programado para esa fecha salvo que la purga ya haya reclamado la cuenta,
+// devuelve 0 en ese caso
+func (u *UserPostgreSQLRepository) CancelDeletion(username string, deletionScheduledAt time.Time) (int64, *exception.ApiException) {
+	query := "UPDATE users SET deletion_scheduled_at = NULL WHERE username = $1 AND deletion_scheduled_at = $2 AND NOT purging"
+	return u.execConditional(query, "cancelling the deletion of user", username, deletionScheduledAt)
+}
+
+// execConditional ejecuta una actualización cuyo filtro puede no coincidir, lo que no es un error
+func (u *UserPostgreSQLRepository) execConditional(query, operation, username string, args ...any) (int64, *exception.ApiException) {
+	result, err := u.db.Exec(query, append([]any{username}, args...)...)
+	if err != nil {
+		logger.Error("Error "+operation, log.F("username", username), log.F("error", err.Error()))
+		return 0, exception.NewApiException(500, "Error updating user")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error("Error getting affected rows "+operation, log.F("username", username), log.F("error", err.Error()))
+		return 0, exception.NewApiException(500, "Error getting affected rows")
+	}
+	return rowsAffected, nil
+}
+
+func (u *UserPostgreSQLRepository) FindScheduledForDeletion(before time.Time) ([]string, *exception.ApiException) {
+	rows, err := u.db.Query("SELECT username FROM users WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $1", before)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error searching users scheduled for deletion: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error searching users scheduled for deletion")
+	}
+	defer rows.Close()
+
+	usernames := make([]string, 0)
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			logger.Error(fmt.Sprintf("Error decoding user scheduled for deletion: %s", err.Error()))
+			return nil, exception.NewApiException(500, "Error decoding users")
+		}
+		usernames = append(usernames, username)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(fmt.Sprintf("Error iterating users scheduled for deletion: %s", err.Error()))
+		return nil, exception.NewApiException(500, "Error searching users scheduled for deletion")
+	}
+
+	return usernames, nil
+}
+
+func (u *UserPostgreSQLRepository) DeleteByUsername(username string) (int64, *exception.ApiException) {
+	logger.Info(fmt.Sprintf("Attempting to delete user without credentials check: %s", username))
+
+	// Identities and email changes are removed by the ON DELETE CASCADE constraints
+	result, err := u.db.Exec("DELETE FROM users WHERE username = $1", username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error deleting user %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error deleting user")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting affected rows when deleting %s: %s", username, err.Error()))
+		return 0, exception.NewApiException(500, "Error getting affected rows")
+	}
+
+	if rowsAffected == 0 {
+		logger.Warning(fmt.Sprintf("User not found for deletion: %s", username))
+		return 0, exception.NewApiException(404, "User not found for deletion")
+	}
+
+	logger.Info(fmt.Sprintf("User successfully deleted: %s", username))
+	return rowsAffected, nil
+}
+
+// Updates a field managed by the administrators, the field name is never taken from the request
+func (u *UserPostgreSQLRepository) updateAccountField(field string, value any, username string) (int64, *exception.ApiException) {
+	query := fmt.Sprintf("UPDATE users SET %s = $1 WHERE username = $2", field)
+	result, err := u.db.Exec(query, value, username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error updating %s of user %s: %s", field, username, err.Error()))
+		return 0, exception.NewApiException(500, "Error updating user")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error getting affected rows when updating %s of %s: %s", field, username, err.Error()))
+		return 0, exception.NewApiException(500, "Error getting affected rows")
+	}
+
+	if rowsAffected == 0 {
+		logger.Warning(fmt.Sprintf("User not found for update: %s", username))
+		return 0, exception.NewApiException(404, "User not found for update")
+	}
+
+	logger.Info(fmt.Sprintf("Field %s successfully updated for user: %s", field, username))
+	return rowsAffected, nil
+}
+
 func (r *UserPostgreSQLRepository) checkUserIsCreated(dto *userDTO.UserDTO) *exception.ApiException {
-	query := "SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 OR email = $2)"
+	query := "SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 OR LOWER(email) = $2)"
 	var exists bool
-	err := r.db.QueryRow(query, dto.Username, dto.Email).Scan(&exists)
+	err := r.db.QueryRow(query, dto.Username, userEntity.NormalizeEmail(dto.Email)).Scan(&exists)
 
 	if err != nil {
 		return exception.NewApiException(500, "Error verifying user existence")
@@ -309,3 +668,15 @@ func (r *UserPostgreSQLRepository) checkUserIsCreated(dto *userDTO.UserDTO) *exc
 
 	return nil
 }
+
+// Escapes the wildcards of a LIKE pattern so the search is matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func nullTimePointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
@@ -0,0 +1,388 @@
+package userTest
+
+import (
+	"go-gallery/src/commons/exception"
+	userEntity "go-gallery/src/domain/entities/user"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	userRepository "go-gallery/src/infrastructure/repository/user"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repository es un repositorio de usuarios en memoria para probar los controladores y servicios
+// sin una base de datos real. Las contraseñas se guardan y comparan en claro
+type Repository struct {
+	mu           sync.Mutex
+	users        map[string]userDTO.UserDTO
+	identities   map[string]userDTO.UserIdentityDTO
+	emailChanges []userDTO.EmailChangeDTO
+	purging      map[string]bool
+	jwtChecks    int
+}
+
+var _ userRepository.UserRepository = (*Repository)(nil)
+
+func NewRepository(users ...*userDTO.UserDTO) *Repository {
+	r := &Repository{
+		users:      make(map[string]userDTO.UserDTO),
+		identities: make(map[string]userDTO.UserIdentityDTO),
+		purging:    make(map[string]bool),
+	}
+	for _, user := range users {
+		r.users[user.Username] = *user
+	}
+	return r
+}
+
+// User devuelve una copia del usuario guardado, útil para comprobar lo que se ha persistido
+func (r *Repository) User(username string) (*userDTO.UserDTO, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	return &user, ok
+}
+
+// Identities devuelve las identidades externas vinculadas
+func (r *Repository) Identities() []userDTO.UserIdentityDTO {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identities := make([]userDTO.UserIdentityDTO, 0, len(r.identities))
+	for _, identity := range r.identities {
+		identities = append(identities, identity)
+	}
+	return identities
+}
+
+// JWTChecks devuelve cuántas veces se ha consultado el repositorio para validar un JWT
+func (r *Repository) JWTChecks() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.jwtChecks
+}
+
+func (r *Repository) Find(loginRequestDTO *userDTO.LoginRequestDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[loginRequestDTO.Username]
+	if !ok || user.Password != loginRequestDTO.Password {
+		return nil, exception.NewApiException(404, "User not found")
+	}
+	return &user, nil
+}
+
+func (r *Repository) FindByEmail(email string) (*userDTO.UserDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == userEntity.NormalizeEmail(email) {
+			return &user, nil
+		}
+	}
+	return nil, exception.NewApiException(404, "User not found")
+}
+
+func (r *Repository) FindAndCheckJWT(claims *userDTO.JwtClaimsDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jwtChecks++
+	user, ok := r.users[claims.Username]
+	if !ok {
+		return nil, exception.NewApiException(404, "User not found")
+	}
+	if user.Email != claims.Email {
+		return nil, exception.NewApiException(403, "The provided data does not match the authenticated user")
+	}
+	return &user, nil
+}
+
+func (r *Repository) Insert(dto *userDTO.UserDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == userEntity.NormalizeEmail(dto.Email) {
+			return nil, exception.NewApiException(400, "Email is already registered")
+		}
+	}
+	if _, ok := r.users[dto.Username]; ok {
+		return nil, exception.NewApiException(400, "Username is already registered")
+	}
+
+	user := *dto
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	r.users[user.Username] = user
+	return &user, nil
+}
+
+func (r *Repository) Update(dto *userDTO.UserDTO) (int64, *exception.ApiException) {
+	return r.update(dto.Username, func(user *userDTO.UserDTO) {
+		if dto.Email != "" {
+			user.Email = dto.Email
+		}
+		if dto.Firstname != "" {
+			user.Firstname = dto.Firstname
+		}
+		if dto.Lastname != "" {
+			user.Lastname = dto.Lastname
+		}
+		if dto.Password != "" {
+			user.Password = dto.Password
+		}
+	})
+}
+
+func (r *Repository) Verify(username string) (int64, *exception.ApiException) {
+	return r.update(username, func(user *userDTO.UserDTO) { user.Verified = true })
+}
+
+func (r *Repository) Delete(dto *userDTO.UserDTO) (int64, *exception.ApiException) {
+	r.mu.Lock()
+	user, ok := r.users[dto.Username]
+	r.mu.Unlock()
+
+	if !ok || user.Password != dto.Password {
+		return 0, exception.NewApiException(404, "No user deleted")
+	}
+	return r.DeleteByUsername(dto.Username)
+}
+
+func (r *Repository) InsertEmailChange(dto *userDTO.EmailChangeDTO) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeEmailChanges(func(change userDTO.EmailChangeDTO) bool {
+		return change.Username == dto.Username && !change.Confirmed
+	})
+	pending := userDTO.EmailChangeDTO{Username: dto.Username, OldEmail: dto.OldEmail, NewEmail: dto.NewEmail}
+	r.emailChanges = append(r.emailChanges, pending)
+	return &pending, nil
+}
+
+func (r *Repository) FindPendingEmailChange(username string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	return r.findEmailChange(func(change userDTO.EmailChangeDTO) bool {
+		return change.Username == username && !change.Confirmed
+	})
+}
+
+func (r *Repository) ConfirmEmailChange(dto *userDTO.EmailChangeDTO) (int64, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, change := range r.emailChanges {
+		if change.Username == dto.Username && !change.Confirmed {
+			r.emailChanges[i].Confirmed = true
+			r.emailChanges[i].RevertTokenHash = dto.RevertTokenHash
+			r.emailChanges[i].RevertExpiration = dto.RevertExpiration
+			return 1, nil
+		}
+	}
+	return 0, exception.NewApiException(404, "No pending email change found")
+}
+
+func (r *Repository) FindEmailChangeByRevertToken(revertTokenHash string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	return r.findEmailChange(func(change userDTO.EmailChangeDTO) bool {
+		return change.RevertTokenHash == revertTokenHash && change.Confirmed
+	})
+}
+
+func (r *Repository) DeleteEmailChange(revertTokenHash string) (int64, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.removeEmailChanges(func(change userDTO.EmailChangeDTO) bool {
+		return change.RevertTokenHash == revertTokenHash
+	}), nil
+}
+
+func (r *Repository) FindByIdentity(provider, subject string) (*userDTO.UserDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identity, ok := r.identities[provider+"|"+subject]
+	if !ok {
+		return nil, exception.NewApiException(404, "No user linked to this identity")
+	}
+	user, ok := r.users[identity.Username]
+	if !ok {
+		return nil, exception.NewApiException(404, "User not found")
+	}
+	return &user, nil
+}
+
+func (r *Repository) InsertIdentity(dto *userDTO.UserIdentityDTO) (*userDTO.UserIdentityDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := dto.Provider + "|" + dto.Subject
+	if _, ok := r.identities[key]; ok {
+		return nil, exception.NewApiException(409, "The identity is already linked to a user")
+	}
+	r.identities[key] = *dto
+	return dto, nil
+}
+
+func (r *Repository) FindByUsername(username string) (*userDTO.UserDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return nil, exception.NewApiException(404, "User not found")
+	}
+	return &user, nil
+}
+
+func (r *Repository) FindAll(search, lastUsername string, pageSize int64) (*userDTO.UserCursorDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	search = strings.ToLower(search)
+	usernames := make([]string, 0, len(r.users))
+	for username, user := range r.users {
+		if username <= lastUsername {
+			continue
+		}
+		fields := strings.ToLower(strings.Join([]string{user.Username, user.Email, user.Firstname, user.Lastname}, " "))
+		if strings.Contains(fields, search) {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+
+	cursor := &userDTO.UserCursorDTO{Users: make([]userDTO.UserSummaryDTO, 0)}
+	for _, username := range usernames {
+		if int64(len(cursor.Users)) == pageSize {
+			break
+		}
+		user := r.users[username]
+		cursor.Users = append(cursor.Users, userDTO.ToUserSummary(&user))
+		cursor.LastUsername = username
+	}
+	return cursor, nil
+}
+
+func (r *Repository) UpdateRole(username, role string) (int64, *exception.ApiException) {
+	return r.update(username, func(user *userDTO.UserDTO) { user.Role = role })
+}
+
+func (r *Repository) SetDisabled(username string, disabled bool) (int64, *exception.ApiException) {
+	return r.update(username, func(user *userDTO.UserDTO) { user.Disabled = disabled })
+}
+
+func (r *Repository) DeleteByUsername(username string) (int64, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[username]; !ok {
+		return 0, exception.NewApiException(404, "No user deleted")
+	}
+	delete(r.users, username)
+	for key, identity := range r.identities {
+		if identity.Username == username {
+			delete(r.identities, key)
+		}
+	}
+	r.removeEmailChanges(func(change userDTO.EmailChangeDTO) bool { return change.Username == username })
+	return 1, nil
+}
+
+func (r *Repository) RevokeSessions(username string) (int64, *exception.ApiException) {
+	return r.update(username, func(user *userDTO.UserDTO) { user.SessionVersion++ })
+}
+
+func (r *Repository) SetDeletionSchedule(username string, deletionScheduledAt *time.Time) (int64, *exception.ApiException) {
+	return r.update(username, func(user *userDTO.UserDTO) { user.DeletionScheduledAt = deletionScheduledAt })
+}
+
+func (r *Repository) FindScheduledForDeletion(before time.Time) ([]string, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usernames := make([]string, 0)
+	for username, user := range r.users {
+		if user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(before) {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+	return usernames, nil
+}
+
+func (r *Repository) ClaimForPurge(username string, before time.Time) (int64, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok || user.DeletionScheduledAt == nil || user.DeletionScheduledAt.After(before) {
+		return 0, nil
+	}
+	r.purging[username] = true
+	return 1, nil
+}
+
+func (r *Repository) CancelDeletion(username string, deletionScheduledAt time.Time) (int64, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok || r.purging[username] || user.DeletionScheduledAt == nil || !user.DeletionScheduledAt.Equal(deletionScheduledAt) {
+		return 0, nil
+	}
+	user.DeletionScheduledAt = nil
+	r.users[username] = user
+	return 1, nil
+}
+
+// Purging indica si la purga ha reclamado la cuenta
+func (r *Repository) Purging(username string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.purging[username]
+}
+
+func (r *Repository) update(username string, apply func(user *userDTO.UserDTO)) (int64, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return 0, exception.NewApiException(404, "User not found for update")
+	}
+	apply(&user)
+	r.users[username] = user
+	return 1, nil
+}
+
+func (r *Repository) findEmailChange(match func(change userDTO.EmailChangeDTO) bool) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, change := range r.emailChanges {
+		if match(change) {
+			return &change, nil
+		}
+	}
+	return nil, exception.NewApiException(404, "No email change found")
+}
+
+// removeEmailChanges elimina los cambios de correo que cumplen la condición, debe llamarse con el mutex adquirido
+func (r *Repository) removeEmailChanges(match func(change userDTO.EmailChangeDTO) bool) int64 {
+	kept := r.emailChanges[:0]
+	for _, change := range r.emailChanges {
+		if !match(change) {
+			kept = append(kept, change)
+		}
+	}
+	removed := int64(len(r.emailChanges) - len(kept))
+	r.emailChanges = kept
+	return removed
+}
@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go-gallery/src/commons/exception"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TRACING_EXPORTER_NONE string = "none"
+	TRACING_EXPORTER_OTLP string = "otlp"
+
+	DEFAULT_TRACING_OTLP_ENDPOINT string  = "http://localhost:4318/v1/traces"
+	DEFAULT_TRACING_SAMPLE_RATIO  float64 = 1
+
+	TRACER_NAME string = "go-gallery"
+)
+
+// TracingSettings agrupa la configuración del exportador de trazas
+type TracingSettings struct {
+	Exporter       string
+	Endpoint       string
+	SampleRatio    float64
+	ServiceName    string
+	ServiceVersion string
+}
+
+func ParseTracingSettings(args map[string]string) TracingSettings {
+	// Traces are only recorded when an exporter is configured
+	exporter := strings.ToLower(strings.TrimSpace(args["TRACING_EXPORTER"]))
+	if exporter == "" {
+		exporter = TRACING_EXPORTER_NONE
+	}
+
+	endpoint := strings.TrimSpace(args["TRACING_OTLP_ENDPOINT"])
+	if endpoint == "" {
+		endpoint = DEFAULT_TRACING_OTLP_ENDPOINT
+	}
+
+	// Fraction of the new traces that are recorded, the decision of the caller is kept for propagated ones
+	sampleRatio, err := strconv.ParseFloat(args["TRACING_SAMPLE_RATIO"], 64)
+	if err != nil || sampleRatio < 0 || sampleRatio > 1 {
+		sampleRatio = DEFAULT_TRACING_SAMPLE_RATIO
+	}
+
+	return TracingSettings{
+		Exporter:    exporter,
+		Endpoint:    endpoint,
+		SampleRatio: sampleRatio,
+	}
+}
+
+// Init configura el propagador W3C traceparent y, si hay un exportador configurado, el proveedor
+// de trazas global. Sin exportador se mantiene el proveedor no-op de OpenTelemetry
+func Init(settings TracingSettings) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	switch settings.Exporter {
+	case TRACING_EXPORTER_NONE:
+		return nil
+	case TRACING_EXPORTER_OTLP:
+		exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(settings.Endpoint))
+		if err != nil {
+			return err
+		}
+		otel.SetTracerProvider(NewTracerProvider(settings, sdktrace.WithBatcher(exporter)))
+		return nil
+	default:
+		return fmt.Errorf("unknown tracing exporter %s", settings.Exporter)
+	}
+}
+
+// NewTracerProvider crea un proveedor con el muestreo y el recurso del servicio, las pruebas lo usan con un exportador en memoria
+func NewTracerProvider(settings TracingSettings, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	serviceResource := resource.NewSchemaless(
+		semconv.ServiceName(settings.ServiceName),
+		semconv.ServiceVersion(settings.ServiceVersion),
+	)
+
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.SampleRatio))),
+	}, options...)
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// Shutdown exporta las trazas pendientes y detiene el proveedor global, no hace nada con el no-op
+func Shutdown(ctx context.Context) error {
+	if provider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok {
+		return provider.Shutdown(ctx)
+	}
+	return nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(TRACER_NAME)
+}
+
+// Start abre un span hijo del que lleve el contexto
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End cierra el span marcándolo como fallido si la operación devolvió un error
+func End(span trace.Span, err *exception.ApiException) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Message)
+		span.SetAttributes(attribute.Int("error.status", err.Status))
+	}
+	span.End()
+}
+
+// EndWithError cierra el span registrando el error si lo hay
+func EndWithError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID devuelve el identificador de la traza del contexto, vacío si no hay ninguna
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"go-gallery/src/commons/exception"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestProvider(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(TracingSettings{ServiceName: "go-gallery", SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+	return exporter
+}
+
+func TestParseTracingSettings(t *testing.T) {
+	settings := ParseTracingSettings(map[string]string{})
+	assert.Equal(t, TRACING_EXPORTER_NONE, settings.Exporter)
+	assert.Equal(t, DEFAULT_TRACING_OTLP_ENDPOINT, settings.Endpoint)
+	assert.Equal(t, DEFAULT_TRACING_SAMPLE_RATIO, settings.SampleRatio)
+
+	settings = ParseTracingSettings(map[string]string{
+		"TRACING_EXPORTER":      "OTLP",
+		"TRACING_OTLP_ENDPOINT": "http://collector:4318/v1/traces",
+		"TRACING_SAMPLE_RATIO":  "0.25",
+	})
+	assert.Equal(t, TRACING_EXPORTER_OTLP, settings.Exporter)
+	assert.Equal(t, "http://collector:4318/v1/traces", settings.Endpoint)
+	assert.Equal(t, 0.25, settings.SampleRatio)
+
+	settings = ParseTracingSettings(map[string]string{"TRACING_SAMPLE_RATIO": "2"})
+	assert.Equal(t, DEFAULT_TRACING_SAMPLE_RATIO, settings.SampleRatio)
+}
+
+func TestInitRejectsUnknownExporter(t *testing.T) {
+	assert.Error(t, Init(TracingSettings{Exporter: "zipkin"}))
+	assert.NoError(t, Init(TracingSettings{Exporter: TRACING_EXPORTER_NONE}))
+}
+
+func TestStartCreatesChildSpans(t *testing.T) {
+	exporter := newTestProvider(t)
+
+	ctx, parent := Start(context.Background(), "parent")
+	assert.NotEmpty(t, TraceID(ctx))
+	_, child := Start(ctx, "child")
+	End(child, nil)
+	End(parent, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, spans[1].SpanContext.TraceID(), spans[0].SpanContext.TraceID())
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+}
+
+func TestEndMarksFailedSpans(t *testing.T) {
+	exporter := newTestProvider(t)
+
+	_, span := Start(context.Background(), "api-error")
+	End(span, exception.NewApiException(500, "Error inserting the document"))
+	_, span = Start(context.Background(), "error")
+	EndWithError(span, errors.New("invalid image"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "Error inserting the document", spans[0].Status.Description)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Len(t, spans[1].Events, 1)
+}
+
+func TestTraceIDWithoutSpan(t *testing.T) {
+	assert.Empty(t, TraceID(context.Background()))
+}
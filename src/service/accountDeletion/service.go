@@ -0,0 +1,222 @@
+package accountDeletionService
+
+import (
+	"context"
+	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
+	"go-gallery/src/commons/exception"
+	"net/url"
+	"sync"
+	"time"
+
+	"go-gallery/src/infrastructure/auth"
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	emailTemplate "go-gallery/src/infrastructure/repository/emailSender/template"
+	avatarService "go-gallery/src/service/avatar"
+	emailService "go-gallery/src/service/email"
+	imageService "go-gallery/src/service/image"
+	userService "go-gallery/src/service/user"
+)
+
+const (
+	INVALID_CANCEL_LINK_MSG string = "The cancel link is invalid, has expired or has already been used"
+	PURGE_IN_PROGRESS_MSG   string = "The account is already being deleted"
+)
+
+// NowFunc allows tests to control the current time
+var NowFunc = time.Now
+
+var logger log.Logger
+
+// AccountDeletionService programa el borrado de las cuentas tras un periodo de gracia y las purga al terminar
+type AccountDeletionService struct {
+	userService        *userService.UserService
+	imageService       *imageService.ImageService
+	avatarService      *avatarService.AvatarService
+	emailSenderService *emailService.EmailSenderService
+	cancelManager      *auth.DeletionCancelManager
+	configuration      configuration.AccountDeletionConfiguration
+	stop               chan struct{}
+	stopOnce           sync.Once
+	purging            sync.WaitGroup
+}
+
+func NewAccountDeletionService(userService *userService.UserService, imageService *imageService.ImageService, avatarService *avatarService.AvatarService,
+	emailSenderService *emailService.EmailSenderService, cancelManager *auth.DeletionCancelManager,
+	configuration configuration.AccountDeletionConfiguration) *AccountDeletionService {
+	logger = log.Instance()
+
+	service := &AccountDeletionService{
+		userService:        userService,
+		imageService:       imageService,
+		avatarService:      avatarService,
+		emailSenderService: emailSenderService,
+		cancelManager:      cancelManager,
+		configuration:      configuration,
+	}
+
+	service.StartPurgeWorker()
+
+	return service
+}
+
+// Schedule marca la cuenta para borrarse al terminar el periodo de gracia, cierra sus sesiones y envía el enlace para cancelarlo
+func (s *AccountDeletionService) Schedule(ctx context.Context, user *userDTO.UserDTO) (time.Time, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	// The cancel link keeps whole seconds, so the stored date is truncated to match it
+	deletionScheduledAt := NowFunc().Add(s.configuration.GracePeriod).Truncate(time.Second)
+
+	if _, err := s.userService.SetDeletionSchedule(user.Username, &deletionScheduledAt); err != nil {
+		logger.Error("Error scheduling the deletion of user", log.F("username", user.Username), log.F("error", err.Message))
+		return time.Time{}, err
+	}
+
+	if _, err := s.userService.RevokeSessions(user.Username); err != nil {
+		logger.Error("Error revoking the sessions of user", log.F("username", user.Username), log.F("error", err.Message))
+	}
+
+	token, err := s.cancelManager.Create(user.Username, deletionScheduledAt)
+	if err != nil {
+		logger.Error("Error signing the deletion cancel link", log.F("username", user.Username), log.F("error", err.Error()))
+		return deletionScheduledAt, nil
+	}
+
+	link := s.configuration.CancelURL + "?token=" + url.QueryEscape(token)
+	template := emailTemplate.DeletionScheduledTemplate{DeletionScheduledAt: deletionScheduledAt}
+	if err := s.emailSenderService.SendEmail(link, user.Email, template); err != nil {
+		logger.Error("Error sending the deletion scheduled email", log.F("username", user.Username), log.F("error", err.Error()))
+	}
+
+	logger.Info("Deletion of user scheduled", log.F("username", user.Username), log.F("deletion_scheduled_at", deletionScheduledAt.Format(time.RFC3339)))
+	return deletionScheduledAt, nil
+}
+
+// CheckCancel comprueba que el enlace firmado corresponde al borrado pendiente de la cuenta sin modificarla
+func (s *AccountDeletionService) CheckCancel(ctx context.Context, token string) (*userDTO.UserDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	invalidLink := exception.NewApiException(401, INVALID_CANCEL_LINK_MSG)
+
+	claims, err := s.cancelManager.Verify(token)
+	if err != nil {
+		logger.Warning("Invalid deletion cancel link", log.F("error", err.Error()))
+		return nil, invalidLink
+	}
+
+	user, errFind := s.userService.FindByUsername(claims.Username)
+	if errFind != nil {
+		logger.Warning("Deletion cancel link of unknown user", log.F("username", claims.Username), log.F("error", errFind.Message))
+		return nil, invalidLink
+	}
+
+	if user.DeletionScheduledAt == nil || user.DeletionScheduledAt.Unix() != claims.DeletionScheduledAt {
+		logger.Warning("Deletion cancel link does not match a pending deletion", log.F("username", claims.Username))
+		return nil, invalidLink
+	}
+
+	return user, nil
+}
+
+// Cancel restaura la cuenta del enlace firmado. El enlace solo coincide con el borrado que lo generó,
+// así que deja de servir una vez cancelado
+func (s *AccountDeletionService) Cancel(ctx context.Context, token string) (*userDTO.UserDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	user, errCheck := s.CheckCancel(ctx, token)
+	if errCheck != nil {
+		return nil, errCheck
+	}
+
+	// The purge may have claimed the account since it was read, the conditional update refuses it then
+	cancelled, errCancel := s.userService.CancelDeletion(user.Username, *user.DeletionScheduledAt)
+	if errCancel != nil {
+		logger.Error("Error cancelling the deletion of user", log.F("username", user.Username), log.F("error", errCancel.Message))
+		return nil, errCancel
+	}
+	if cancelled == 0 {
+		logger.Warning("Deletion of user could not be cancelled, the purge has already started", log.F("username", user.Username))
+		return nil, exception.NewApiException(409, PURGE_IN_PROGRESS_MSG)
+	}
+
+	logger.Info("Deletion of user cancelled", log.F("username", user.Username))
+	return user, nil
+}
+
+// StartPurgeWorker borra periódicamente las cuentas cuyo periodo de gracia ha terminado
+func (s *AccountDeletionService) StartPurgeWorker() {
+	s.stop = make(chan struct{})
+	s.purging.Add(1)
+	go func() {
+		defer s.purging.Done()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(s.configuration.PurgeInterval):
+				s.purgeExpiredAccounts()
+			}
+		}
+	}()
+}
+
+// Close detiene el borrado periódico y espera a que termine la purga en curso, como mucho hasta que venza el contexto
+func (s *AccountDeletionService) Close(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+		}
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		s.purging.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("account purge still running: %w", ctx.Err())
+	}
+}
+
+func (s *AccountDeletionService) purgeExpiredAccounts() {
+	usernames, err := s.userService.FindScheduledForDeletion(NowFunc())
+	if err != nil {
+		logger.Error("Error searching users scheduled for deletion", log.F("error", err.Message))
+		return
+	}
+
+	for _, username := range usernames {
+		// The list may be outdated by the time the user is reached. Claiming the account atomically makes a cancellation
+		// either win before anything is removed or be rejected afterwards
+		claimed, err := s.userService.ClaimForPurge(username, NowFunc())
+		if err != nil {
+			logger.Error("Error claiming user for purge", log.F("username", username), log.F("error", err.Message))
+			continue
+		}
+		if claimed == 0 {
+			logger.Info("Deletion of user was cancelled or rescheduled, it is not purged", log.F("username", username))
+			continue
+		}
+
+		// A claimed user is kept when its images cannot be removed, the next run claims it again and retries
+		if _, err := s.imageService.DeleteAll(context.Background(), &imageDTO.ImageDeleteRequestDTO{Owner: username}); err != nil {
+			logger.Error("Error deleting all images of user", log.F("username", username), log.F("error", err.Message))
+			continue
+		}
+
+		if _, err := s.avatarService.Delete(username); err != nil {
+			logger.Error("Error deleting avatar of user", log.F("username", username), log.F("error", err.Message))
+			continue
+		}
+
+		if _, err := s.userService.DeleteByUsername(username); err != nil {
+			logger.Error("Error deleting user", log.F("username", username), log.F("error", err.Message))
+			continue
+		}
+
+		logger.Info("User, their images and their avatar have been purged after the grace period", log.F("username", username))
+	}
+}
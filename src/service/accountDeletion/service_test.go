@@ -0,0 +1,258 @@
+package accountDeletionService
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gallery/src/commons/configurator/configuration"
+	"go-gallery/src/commons/exception"
+	"go-gallery/src/infrastructure/auth"
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	avatarRepository "go-gallery/src/infrastructure/repository/avatar"
+	emailTemplate "go-gallery/src/infrastructure/repository/emailSender/template"
+	imageRepository "go-gallery/src/infrastructure/repository/image"
+	thumbnailImageRepository "go-gallery/src/infrastructure/repository/image/thumbnailImage"
+	"go-gallery/src/infrastructure/repository/user/userTest"
+	avatarService "go-gallery/src/service/avatar"
+	emailService "go-gallery/src/service/email"
+	imageService "go-gallery/src/service/image"
+	userService "go-gallery/src/service/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const CANCEL_URL = "https://gallery.example.com/api/auth/cancel-delete"
+
+// stubImageRepository registra los propietarios cuyas imágenes se han borrado
+type stubImageRepository struct {
+	imageRepository.ImageRepository
+	deletedOwners []string
+	onDeleteAll   func(owner string)
+}
+
+func (r *stubImageRepository) DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
+	r.deletedOwners = append(r.deletedOwners, dto.Owner)
+	if r.onDeleteAll != nil {
+		r.onDeleteAll(dto.Owner)
+	}
+	return 1, nil
+}
+
+type stubThumbnailRepository struct {
+	thumbnailImageRepository.ThumbnailImageRepository
+}
+
+func (r *stubThumbnailRepository) DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
+	return 1, nil
+}
+
+// stubEmailSender guarda los enlaces enviados para poder usarlos en las pruebas
+type stubEmailSender struct {
+	links []string
+}
+
+func (s *stubEmailSender) SendEmail(code, email string, template emailTemplate.EmailTemplate) error {
+	s.links = append(s.links, code)
+	return nil
+}
+
+type testEnvironment struct {
+	service     *AccountDeletionService
+	users       *userTest.Repository
+	images      *stubImageRepository
+	emailSender *stubEmailSender
+	now         time.Time
+}
+
+func newTestEnvironment(t *testing.T, users ...*userDTO.UserDTO) *testEnvironment {
+	log.Init(log.NewConsoleLogger())
+
+	env := &testEnvironment{
+		users:       userTest.NewRepository(users...),
+		images:      &stubImageRepository{},
+		emailSender: &stubEmailSender{},
+		now:         time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+	NowFunc = func() time.Time { return env.now }
+	auth.NowFunc = func() time.Time { return env.now }
+	t.Cleanup(func() {
+		NowFunc = time.Now
+		auth.NowFunc = time.Now
+	})
+
+	images := imageService.NewImageService(env.images, &stubThumbnailRepository{})
+	env.service = NewAccountDeletionService(
+		userService.NewUserService(env.users, 0),
+		images,
+		avatarService.NewAvatarService(avatarRepository.NewAvatarMemoryRepository(nil), images, "https://gallery.example.com"),
+		emailService.NewEmailSenderService(env.emailSender),
+		auth.NewDeletionCancelManager("mySecretKey"),
+		configuration.AccountDeletionConfiguration{GracePeriod: 7 * 24 * time.Hour, PurgeInterval: time.Hour, CancelURL: CANCEL_URL},
+	)
+	t.Cleanup(func() { env.service.Close(context.Background()) })
+
+	return env
+}
+
+func newUser(username string) *userDTO.UserDTO {
+	return &userDTO.UserDTO{Username: username, Email: username + "@example.com", Firstname: username, Verified: true}
+}
+
+func (env *testEnvironment) lastCancelToken(t *testing.T) string {
+	require.NotEmpty(t, env.emailSender.links)
+	link := env.emailSender.links[len(env.emailSender.links)-1]
+	require.True(t, strings.HasPrefix(link, CANCEL_URL+"?token="))
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+func TestScheduleMarksTheAccountAndSendsTheCancelLink(t *testing.T) {
+	env := newTestEnvironment(t, newUser("alice"))
+
+	deletionScheduledAt, err := env.service.Schedule(context.Background(), newUser("alice"))
+	require.Nil(t, err)
+	assert.Equal(t, env.now.Add(7*24*time.Hour), deletionScheduledAt)
+
+	user, _ := env.users.User("alice")
+	require.NotNil(t, user.DeletionScheduledAt)
+	assert.Equal(t, deletionScheduledAt, *user.DeletionScheduledAt)
+	assert.Equal(t, int64(1), user.SessionVersion, "The sessions must be revoked")
+	assert.Len(t, env.emailSender.links, 1)
+}
+
+func TestCancelRestoresTheAccountOnlyOnce(t *testing.T) {
+	env := newTestEnvironment(t, newUser("alice"))
+	_, err := env.service.Schedule(context.Background(), newUser("alice"))
+	require.Nil(t, err)
+	token := env.lastCancelToken(t)
+
+	user, err := env.service.Cancel(context.Background(), token)
+	require.Nil(t, err)
+	assert.Equal(t, "alice", user.Username)
+
+	stored, _ := env.users.User("alice")
+	assert.Nil(t, stored.DeletionScheduledAt)
+
+	_, err = env.service.Cancel(context.Background(), token)
+	require.NotNil(t, err)
+	assert.Equal(t, 401, err.Status)
+}
+
+func TestCheckCancelDoesNotRestoreTheAccount(t *testing.T) {
+	env := newTestEnvironment(t, newUser("alice"))
+	deletionScheduledAt, err := env.service.Schedule(context.Background(), newUser("alice"))
+	require.Nil(t, err)
+
+	user, err := env.service.CheckCancel(context.Background(), env.lastCancelToken(t))
+	require.Nil(t, err)
+	assert.Equal(t, "alice", user.Username)
+
+	stored, _ := env.users.User("alice")
+	require.NotNil(t, stored.DeletionScheduledAt)
+	assert.Equal(t, deletionScheduledAt, *stored.DeletionScheduledAt)
+}
+
+func TestCancelRejectsLinksOfPreviousSchedules(t *testing.T) {
+	env := newTestEnvironment(t, newUser("alice"))
+	_, err := env.service.Schedule(context.Background(), newUser("alice"))
+	require.Nil(t, err)
+	oldToken := env.lastCancelToken(t)
+	_, err = env.service.Cancel(context.Background(), oldToken)
+	require.Nil(t, err)
+
+	env.now = env.now.Add(time.Hour)
+	_, err = env.service.Schedule(context.Background(), newUser("alice"))
+	require.Nil(t, err)
+
+	_, err = env.service.Cancel(context.Background(), oldToken)
+	require.NotNil(t, err)
+	assert.Equal(t, 401, err.Status)
+
+	_, err = env.service.Cancel(context.Background(), env.lastCancelToken(t))
+	assert.Nil(t, err)
+}
+
+func TestCancelRejectsExpiredLinks(t *testing.T) {
+	env := newTestEnvironment(t, newUser("alice"))
+	_, err := env.service.Schedule(context.Background(), newUser("alice"))
+	require.Nil(t, err)
+
+	env.now = env.now.Add(7 * 24 * time.Hour)
+	_, err = env.service.Cancel(context.Background(), env.lastCancelToken(t))
+	require.NotNil(t, err)
+	assert.Equal(t, 401, err.Status)
+}
+
+func TestPurgeDeletesOnlyExpiredAccounts(t *testing.T) {
+	env := newTestEnvironment(t, newUser("alice"), newUser("bob"), newUser("carol"))
+	_, err := env.service.Schedule(context.Background(), newUser("alice"))
+	require.Nil(t, err)
+
+	env.now = env.now.Add(24 * time.Hour)
+	_, err = env.service.Schedule(context.Background(), newUser("bob"))
+	require.Nil(t, err)
+
+	// Only the grace period of alice has finished
+	env.now = env.now.Add(6 * 24 * time.Hour)
+	env.service.purgeExpiredAccounts()
+
+	_, found := env.users.User("alice")
+	assert.False(t, found)
+	_, found = env.users.User("bob")
+	assert.True(t, found)
+	_, found = env.users.User("carol")
+	assert.True(t, found)
+	assert.Equal(t, []string{"alice"}, env.images.deletedOwners)
+}
+
+func TestPurgeSkipsAccountsCancelledDuringTheRun(t *testing.T) {
+	env := newTestEnvironment(t, newUser("alice"), newUser("bob"))
+	for _, username := range []string{"alice", "bob"} {
+		_, err := env.service.Schedule(context.Background(), newUser(username))
+		require.Nil(t, err)
+	}
+
+	// The deletion of bob is cancelled while the images of alice are being removed
+	env.images.onDeleteAll = func(owner string) {
+		if owner == "alice" {
+			_, err := env.users.SetDeletionSchedule("bob", nil)
+			require.Nil(t, err)
+		}
+	}
+
+	env.now = env.now.Add(7 * 24 * time.Hour)
+	env.service.purgeExpiredAccounts()
+
+	_, found := env.users.User("alice")
+	assert.False(t, found)
+	_, found = env.users.User("bob")
+	assert.True(t, found)
+	assert.Equal(t, []string{"alice"}, env.images.deletedOwners)
+}
+
+func TestCancelIsRejectedOnceThePurgeClaimedTheAccount(t *testing.T) {
+	env := newTestEnvironment(t, newUser("alice"))
+	deletionScheduledAt, err := env.service.Schedule(context.Background(), newUser("alice"))
+	require.Nil(t, err)
+
+	// The purge of a worker whose clock is ahead claims the account while the link is still valid
+	claimed, err := env.users.ClaimForPurge("alice", deletionScheduledAt)
+	require.Nil(t, err)
+	require.Equal(t, int64(1), claimed)
+
+	_, err = env.service.Cancel(context.Background(), env.lastCancelToken(t))
+	require.NotNil(t, err)
+	assert.Equal(t, 409, err.Status)
+
+	stored, _ := env.users.User("alice")
+	require.NotNil(t, stored.DeletionScheduledAt)
+	assert.Equal(t, deletionScheduledAt, *stored.DeletionScheduledAt)
+}
@@ -0,0 +1,115 @@
+package attemptService
+
+import (
+	"go-gallery/src/commons/configurator/configuration"
+	attemptRepository "go-gallery/src/infrastructure/repository/attempt"
+	"strings"
+	"time"
+)
+
+const (
+	IP_KEY_SEGMENT      = ":ip:"
+	ACCOUNT_KEY_SEGMENT = ":account:"
+)
+
+type AttemptService struct {
+	repository    attemptRepository.AttemptRepository
+	configuration configuration.BruteForceConfiguration
+}
+
+var NowFunc = time.Now
+
+func NewAttemptService(repository attemptRepository.AttemptRepository, configuration configuration.BruteForceConfiguration) *AttemptService {
+	return &AttemptService{
+		repository:    repository,
+		configuration: configuration,
+	}
+}
+
+// IPKey devuelve la clave que cuenta los fallos de una dirección IP en una operación
+func IPKey(scope, ip string) string {
+	return scope + IP_KEY_SEGMENT + ip
+}
+
+// AccountKey devuelve la clave que cuenta los fallos de una cuenta en una operación
+func AccountKey(scope, account string) string {
+	return scope + ACCOUNT_KEY_SEGMENT + strings.ToLower(account)
+}
+
+// Check devuelve el tiempo que falta para poder reintentar, cero si ninguna de las claves está bloqueada
+func (s *AttemptService) Check(keys ...string) (time.Duration, error) {
+	var retryAfter time.Duration
+	now := NowFunc()
+
+	for _, key := range keys {
+		attempt, err := s.repository.Find(key)
+		if err != nil {
+			return 0, err
+		}
+
+		if remaining := attempt.BlockedUntil.Sub(now); remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// RegisterFailure incrementa los contadores de las claves y las bloquea con backoff exponencial
+func (s *AttemptService) RegisterFailure(keys ...string) (time.Duration, error) {
+	var retryAfter time.Duration
+	now := NowFunc()
+
+	for _, key := range keys {
+		failures, err := s.repository.Increment(key, s.configuration.LockoutDuration)
+		if err != nil {
+			return 0, err
+		}
+
+		blockDuration := s.blockDuration(key, failures)
+		if blockDuration <= 0 {
+			continue
+		}
+
+		if err := s.repository.Block(key, now.Add(blockDuration)); err != nil {
+			return 0, err
+		}
+
+		if blockDuration > retryAfter {
+			retryAfter = blockDuration
+		}
+	}
+
+	return retryAfter, nil
+}
+
+func (s *AttemptService) Reset(keys ...string) error {
+	for _, key := range keys {
+		if err := s.repository.Reset(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AttemptService) blockDuration(key string, failures int) time.Duration {
+	// Users behind a shared NAT or proxy would block each other with the backoff, so an IP is only
+	// locked out once it reaches its own higher threshold
+	if strings.Contains(key, IP_KEY_SEGMENT) {
+		if failures >= s.configuration.IPMaxFailures {
+			return s.configuration.LockoutDuration
+		}
+		return 0
+	}
+
+	if failures >= s.configuration.MaxFailures {
+		return s.configuration.LockoutDuration
+	}
+
+	delay := s.configuration.BaseDelay << (failures - 1)
+	if delay <= 0 || delay > s.configuration.MaxDelay {
+		return s.configuration.MaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,101 @@
+package attemptService
+
+import (
+	"go-gallery/src/commons/configurator/configuration"
+	attemptRepository "go-gallery/src/infrastructure/repository/attempt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ACCOUNT_KEY = "login:account:sanbricio"
+	IP_KEY      = "login:ip:127.0.0.1"
+)
+
+func newService() *AttemptService {
+	conf := configuration.BruteForceConfiguration{
+		MaxFailures:     4,
+		IPMaxFailures:   10,
+		BaseDelay:       time.Second,
+		MaxDelay:        3 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+	}
+	return NewAttemptService(attemptRepository.NewAttemptMemoryRepository(map[string]string{}), conf)
+}
+
+func TestBlockDurationBackoff(t *testing.T) {
+	service := newService()
+
+	assert.Equal(t, time.Second, service.blockDuration(ACCOUNT_KEY, 1))
+	assert.Equal(t, 2*time.Second, service.blockDuration(ACCOUNT_KEY, 2))
+	assert.Equal(t, 3*time.Second, service.blockDuration(ACCOUNT_KEY, 3), "expected delay to be capped by the max delay")
+	assert.Equal(t, 15*time.Minute, service.blockDuration(ACCOUNT_KEY, 4), "expected lockout after max failures")
+}
+
+func TestKeys(t *testing.T) {
+	assert.Equal(t, ACCOUNT_KEY, AccountKey("login", "SanBricio"))
+	assert.Equal(t, IP_KEY, IPKey("login", "127.0.0.1"))
+}
+
+func TestRegisterFailureBlocksAccountKey(t *testing.T) {
+	service := newService()
+
+	retryAfter, err := service.RegisterFailure(ACCOUNT_KEY, IP_KEY)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, retryAfter)
+
+	remaining, err := service.Check(ACCOUNT_KEY)
+	assert.NoError(t, err)
+	assert.Greater(t, remaining, time.Duration(0))
+
+	// A few failures from a shared IP must not block the other users behind it
+	remaining, _ = service.Check(IP_KEY)
+	assert.Equal(t, time.Duration(0), remaining)
+}
+
+func TestIPKeyLockoutAfterItsThreshold(t *testing.T) {
+	service := newService()
+
+	for range 9 {
+		retryAfter, err := service.RegisterFailure(IP_KEY)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), retryAfter)
+	}
+
+	retryAfter, _ := service.RegisterFailure(IP_KEY)
+	assert.Equal(t, 15*time.Minute, retryAfter)
+
+	remaining, _ := service.Check(IP_KEY)
+	assert.Greater(t, remaining, time.Duration(0))
+}
+
+func TestCheckAfterBlockExpires(t *testing.T) {
+	service := newService()
+	service.RegisterFailure(ACCOUNT_KEY)
+
+	NowFunc = func() time.Time {
+		return time.Now().Add(2 * time.Second)
+	}
+	defer func() { NowFunc = time.Now }()
+
+	remaining, err := service.Check(ACCOUNT_KEY)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), remaining)
+}
+
+func TestLockoutAndReset(t *testing.T) {
+	service := newService()
+
+	var retryAfter time.Duration
+	for range 4 {
+		retryAfter, _ = service.RegisterFailure(ACCOUNT_KEY)
+	}
+	assert.Equal(t, 15*time.Minute, retryAfter)
+
+	service.Reset(ACCOUNT_KEY)
+
+	remaining, _ := service.Check(ACCOUNT_KEY)
+	assert.Equal(t, time.Duration(0), remaining)
+}
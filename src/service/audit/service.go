@@ -0,0 +1,104 @@
+package auditService
+
+import (
+	"context"
+	"fmt"
+	"go-gallery/src/commons/exception"
+	utilsToken "go-gallery/src/commons/utils/token"
+	"strings"
+	"time"
+
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+	log "go-gallery/src/infrastructure/logger"
+	auditRepository "go-gallery/src/infrastructure/repository/audit"
+)
+
+const (
+	DEFAULT_PAGE_SIZE int64 = 20
+	MAX_PAGE_SIZE     int64 = 100
+	MAX_USER_AGENT    int   = 512
+	ID_RANDOM_BYTES   int   = 4
+)
+
+// NowFunc allows tests to control the current time
+var NowFunc = time.Now
+
+// AuditService registra las acciones relevantes para la seguridad de las cuentas y permite consultarlas
+type AuditService struct {
+	repository auditRepository.AuditRepository
+}
+
+func NewAuditService(repository auditRepository.AuditRepository) *AuditService {
+	return &AuditService{repository: repository}
+}
+
+// Record guarda el evento sin interrumpir la petición si falla, el error solo se registra en el log
+func (s *AuditService) Record(ctx context.Context, actor, action, target, ip, userAgent string, success bool) {
+	now := NowFunc().UTC()
+
+	// The values usually come from Fiber, whose strings point to request buffers that are reused once the handler
+	// returns. They are copied so the stored event, or a slice of it, never shares those bytes
+	actor, action, target = strings.Clone(actor), strings.Clone(action), strings.Clone(target)
+	ip, userAgent = strings.Clone(ip), strings.Clone(userAgent)
+
+	id, err := newEventId(now)
+	if err != nil {
+		log.FromContext(ctx).Error("Error generating the id of audit event", log.F("action", action), log.F("actor", actor), log.F("error", err.Error()))
+		return
+	}
+
+	outcome := auditDTO.AUDIT_OUTCOME_SUCCESS
+	if !success {
+		outcome = auditDTO.AUDIT_OUTCOME_FAILURE
+	}
+
+	// The header is controlled by the client, it is truncated so it cannot fill the storage
+	if len(userAgent) > MAX_USER_AGENT {
+		userAgent = userAgent[:MAX_USER_AGENT]
+	}
+
+	event := &auditDTO.AuditEventDTO{
+		Id:        id,
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		IP:        ip,
+		UserAgent: userAgent,
+		Outcome:   outcome,
+		Timestamp: now,
+	}
+	if errInsert := s.repository.Insert(event); errInsert != nil {
+		log.FromContext(ctx).Error("Audit event could not be stored", log.F("action", action), log.F("actor", actor), log.F("error", errInsert.Message))
+	}
+}
+
+// RecentActivity devuelve los eventos de un usuario del más reciente al más antiguo
+func (s *AuditService) RecentActivity(username, lastId string, pageSize int64) (*auditDTO.AuditCursorDTO, *exception.ApiException) {
+	return s.Find(&auditDTO.AuditFilterDTO{Actor: username, LastId: lastId, PageSize: pageSize})
+}
+
+func (s *AuditService) Find(filter *auditDTO.AuditFilterDTO) (*auditDTO.AuditCursorDTO, *exception.ApiException) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, exception.NewApiException(400, "The start of the period must be before its end")
+	}
+
+	filter.PageSize = normalizePageSize(filter.PageSize)
+	return s.repository.Find(filter)
+}
+
+// The page size must be positive, by default DEFAULT_PAGE_SIZE and never above MAX_PAGE_SIZE
+func normalizePageSize(pageSize int64) int64 {
+	if pageSize <= 0 {
+		return DEFAULT_PAGE_SIZE
+	}
+	return min(pageSize, MAX_PAGE_SIZE)
+}
+
+// The zero padded timestamp keeps the ids sorted by date, the random suffix separates events of the same nanosecond
+func newEventId(now time.Time) (string, error) {
+	suffix, err := utilsToken.GenerateToken(ID_RANDOM_BYTES)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%019d-%s", now.UnixNano(), suffix), nil
+}
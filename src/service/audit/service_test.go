@@ -0,0 +1,101 @@
+package auditService
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	auditDTO "go-gallery/src/infrastructure/dto/audit"
+	log "go-gallery/src/infrastructure/logger"
+	auditRepository "go-gallery/src/infrastructure/repository/audit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *AuditService {
+	log.Init(log.NewConsoleLogger())
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	NowFunc = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	t.Cleanup(func() { NowFunc = time.Now })
+
+	return NewAuditService(auditRepository.NewAuditMemoryRepository(nil))
+}
+
+func TestRecordAndRecentActivity(t *testing.T) {
+	service := newTestService(t)
+
+	service.Record(context.Background(), "alice", auditDTO.AUDIT_ACTION_LOGIN, "", "203.0.113.7", "Mozilla/5.0", false)
+	service.Record(context.Background(), "alice", auditDTO.AUDIT_ACTION_LOGIN, "", "203.0.113.7", "Mozilla/5.0", true)
+	service.Record(context.Background(), "bob", auditDTO.AUDIT_ACTION_LOGIN, "", "198.51.100.1", "curl/8.0", true)
+	service.Record(context.Background(), "alice", auditDTO.AUDIT_ACTION_IMAGE_DELETE, "image-1", "203.0.113.7", strings.Repeat("a", 1000), true)
+
+	activity, err := service.RecentActivity("alice", "", 0)
+	require.Nil(t, err)
+	require.Len(t, activity.Events, 3)
+
+	latest := activity.Events[0]
+	assert.Equal(t, auditDTO.AUDIT_ACTION_IMAGE_DELETE, latest.Action)
+	assert.Equal(t, "image-1", latest.Target)
+	assert.Len(t, latest.UserAgent, MAX_USER_AGENT)
+	assert.Equal(t, auditDTO.AUDIT_OUTCOME_FAILURE, activity.Events[2].Outcome)
+	assert.Equal(t, activity.Events[2].Id, activity.LastId)
+
+	// Later events always get greater ids
+	assert.Greater(t, activity.Events[0].Id, activity.Events[1].Id)
+}
+
+func TestRecordCopiesTheRequestValues(t *testing.T) {
+	service := newTestService(t)
+
+	// Fiber returns strings that point to buffers it reuses for the next request
+	buffer := []byte("alice" + strings.Repeat("a", 1000))
+	actor := unsafe.String(&buffer[0], 5)
+	userAgent := unsafe.String(&buffer[0], len(buffer))
+	service.Record(context.Background(), actor, auditDTO.AUDIT_ACTION_LOGIN, "", "203.0.113.7", userAgent, true)
+	copy(buffer, strings.Repeat("z", len(buffer)))
+
+	activity, err := service.RecentActivity("alice", "", 0)
+	require.Nil(t, err)
+	require.Len(t, activity.Events, 1)
+	assert.Equal(t, "alice", activity.Events[0].Actor)
+	assert.Equal(t, "alice"+strings.Repeat("a", MAX_USER_AGENT-5), activity.Events[0].UserAgent)
+}
+
+func TestFindPagination(t *testing.T) {
+	service := newTestService(t)
+	for range 3 {
+		service.Record(context.Background(), "alice", auditDTO.AUDIT_ACTION_LOGIN, "", "", "", true)
+	}
+
+	page, err := service.Find(&auditDTO.AuditFilterDTO{PageSize: 2})
+	require.Nil(t, err)
+	assert.Len(t, page.Events, 2)
+
+	next, err := service.Find(&auditDTO.AuditFilterDTO{LastId: page.LastId, PageSize: 2})
+	require.Nil(t, err)
+	assert.Len(t, next.Events, 1)
+}
+
+func TestFindRejectsInvertedPeriod(t *testing.T) {
+	service := newTestService(t)
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	_, err := service.Find(&auditDTO.AuditFilterDTO{From: &from, To: &to})
+	require.NotNil(t, err)
+	assert.Equal(t, 400, err.Status)
+}
+
+func TestNormalizePageSize(t *testing.T) {
+	assert.Equal(t, DEFAULT_PAGE_SIZE, normalizePageSize(0))
+	assert.Equal(t, DEFAULT_PAGE_SIZE, normalizePageSize(-5))
+	assert.Equal(t, int64(7), normalizePageSize(7))
+	assert.Equal(t, MAX_PAGE_SIZE, normalizePageSize(1000))
+}
@@ -0,0 +1,136 @@
+package avatarService
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"go-gallery/src/commons/constants"
+	"go-gallery/src/commons/exception"
+	utilsImage "go-gallery/src/commons/utils/image"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	avatarDTO "go-gallery/src/infrastructure/dto/avatar"
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	log "go-gallery/src/infrastructure/logger"
+	avatarRepository "go-gallery/src/infrastructure/repository/avatar"
+	imageService "go-gallery/src/service/image"
+)
+
+const (
+	INVALID_AVATAR_SIZE_MSG string = "Unsupported avatar size"
+	INVALID_CROP_BOX_MSG    string = "The crop box must be a square inside the image"
+	INVALID_AVATAR_MSG      string = "The image could not be processed"
+)
+
+// AVATAR_SIZES son los lados en píxeles en los que se genera cada avatar
+var AVATAR_SIZES = []int{constants.AVATAR_SMALL_SIZE, constants.AVATAR_MEDIUM_SIZE, constants.AVATAR_LARGE_SIZE}
+
+// NowFunc allows tests to control the current time
+var NowFunc = time.Now
+
+var logger log.Logger
+
+// AvatarService genera los avatares de los usuarios a partir de un fichero o de una imagen de su galería
+type AvatarService struct {
+	repository   avatarRepository.AvatarRepository
+	imageService *imageService.ImageService
+	avatarURL    string
+}
+
+func NewAvatarService(repository avatarRepository.AvatarRepository, imageService *imageService.ImageService, publicURL string) *AvatarService {
+	logger = log.Instance()
+	return &AvatarService{
+		repository:   repository,
+		imageService: imageService,
+		avatarURL:    publicURL + "/api/avatar/",
+	}
+}
+
+// SetFromUpload genera el avatar a partir del contenido de un fichero subido
+func (s *AvatarService) SetFromUpload(ctx context.Context, username string, content []byte, crop avatarDTO.AvatarCropDTO) (*avatarDTO.AvatarResponseDTO, *exception.ApiException) {
+	return s.render(ctx, username, content, crop)
+}
+
+// SetFromImage genera el avatar a partir de una imagen de la galería del usuario
+func (s *AvatarService) SetFromImage(ctx context.Context, username string, request *avatarDTO.AvatarFromImageRequestDTO) (*avatarDTO.AvatarResponseDTO, *exception.ApiException) {
+	image, errFind := s.imageService.Find(ctx, &imageDTO.ImageDTO{Id: &request.ImageID, Owner: username})
+	if errFind != nil {
+		return nil, errFind
+	}
+
+	content, err := base64.StdEncoding.DecodeString(image.ContentFile)
+	if err != nil {
+		log.FromContext(ctx).Error("Error decoding image", log.F("image_id", request.ImageID), log.F("username", username), log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error reading the image")
+	}
+
+	return s.render(ctx, username, content, request.AvatarCropDTO)
+}
+
+// Find devuelve el avatar del usuario en uno de los tamaños disponibles
+func (s *AvatarService) Find(username string, size int) ([]byte, time.Time, *exception.ApiException) {
+	if !slices.Contains(AVATAR_SIZES, size) {
+		return nil, time.Time{}, exception.NewApiException(400, INVALID_AVATAR_SIZE_MSG)
+	}
+
+	avatar, err := s.repository.Find(username)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	image, found := avatar.Image(size)
+	if !found {
+		return nil, time.Time{}, exception.NewApiException(404, "Avatar not found")
+	}
+
+	return image, avatar.UpdatedAt, nil
+}
+
+// Delete elimina el avatar del usuario, no falla si no tenía ninguno
+func (s *AvatarService) Delete(username string) (int64, *exception.ApiException) {
+	return s.repository.Delete(username)
+}
+
+func (s *AvatarService) render(ctx context.Context, username string, content []byte, crop avatarDTO.AvatarCropDTO) (*avatarDTO.AvatarResponseDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	rendered, err := utilsImage.CropSquareImage(content, utilsImage.CropBox{X: crop.X, Y: crop.Y, Size: crop.Size}, AVATAR_SIZES)
+	if errors.Is(err, utilsImage.ErrInvalidCropBox) {
+		return nil, exception.NewApiException(400, INVALID_CROP_BOX_MSG)
+	}
+	if err != nil {
+		logger.Warning("Error rendering the avatar", log.F("username", username), log.F("error", err.Error()))
+		return nil, exception.NewApiException(400, INVALID_AVATAR_MSG)
+	}
+
+	avatar := &avatarDTO.AvatarDTO{
+		Username:  username,
+		Images:    make(map[string][]byte, len(rendered)),
+		UpdatedAt: NowFunc().UTC().Truncate(time.Millisecond),
+	}
+	for size, image := range rendered {
+		avatar.Images[strconv.Itoa(size)] = image
+	}
+
+	if errUpsert := s.repository.Upsert(avatar); errUpsert != nil {
+		return nil, errUpsert
+	}
+
+	logger.Info("Avatar updated", log.F("username", username))
+	return s.response(avatar), nil
+}
+
+// The version parameter changes with every update, so the URLs can be cached without expiring
+func (s *AvatarService) response(avatar *avatarDTO.AvatarDTO) *avatarDTO.AvatarResponseDTO {
+	urls := make(map[string]string, len(AVATAR_SIZES))
+	for _, size := range AVATAR_SIZES {
+		query := url.Values{}
+		query.Set("size", strconv.Itoa(size))
+		query.Set("v", strconv.FormatInt(avatar.UpdatedAt.UnixMilli(), 10))
+		urls[strconv.Itoa(size)] = s.avatarURL + url.PathEscape(avatar.Username) + "?" + query.Encode()
+	}
+
+	return &avatarDTO.AvatarResponseDTO{URLs: urls, UpdatedAt: avatar.UpdatedAt}
+}
@@ -0,0 +1,79 @@
+package avatarService
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	avatarDTO "go-gallery/src/infrastructure/dto/avatar"
+	log "go-gallery/src/infrastructure/logger"
+	avatarRepository "go-gallery/src/infrastructure/repository/avatar"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *AvatarService {
+	log.Init(log.NewConsoleLogger())
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	NowFunc = func() time.Time { return now }
+	t.Cleanup(func() { NowFunc = time.Now })
+
+	return NewAvatarService(avatarRepository.NewAvatarMemoryRepository(nil), nil, "https://gallery.example.com")
+}
+
+func encodedImage(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestSetFromUploadRendersEverySize(t *testing.T) {
+	service := newTestService(t)
+
+	response, err := service.SetFromUpload(context.Background(), "alice", encodedImage(t, 400, 300), avatarDTO.AvatarCropDTO{X: 10, Y: 10, Size: 200})
+	require.Nil(t, err)
+	assert.Len(t, response.URLs, len(AVATAR_SIZES))
+	assert.Equal(t, "https://gallery.example.com/api/avatar/alice?size=64&v=1704103200000", response.URLs["64"])
+
+	for _, size := range AVATAR_SIZES {
+		content, updatedAt, err := service.Find("alice", size)
+		require.Nil(t, err)
+		assert.Equal(t, response.UpdatedAt, updatedAt)
+
+		img, _, errDecode := image.Decode(bytes.NewReader(content))
+		require.NoError(t, errDecode)
+		assert.Equal(t, size, img.Bounds().Dx())
+		assert.Equal(t, size, img.Bounds().Dy())
+	}
+}
+
+func TestSetFromUploadRejectsInvalidInput(t *testing.T) {
+	service := newTestService(t)
+
+	_, err := service.SetFromUpload(context.Background(), "alice", encodedImage(t, 100, 100), avatarDTO.AvatarCropDTO{X: 50, Y: 0, Size: 100})
+	assert.Equal(t, 400, err.Status)
+	assert.Equal(t, INVALID_CROP_BOX_MSG, err.Message)
+
+	_, err = service.SetFromUpload(context.Background(), "alice", []byte("not an image"), avatarDTO.AvatarCropDTO{})
+	assert.Equal(t, 400, err.Status)
+	assert.Equal(t, INVALID_AVATAR_MSG, err.Message)
+}
+
+func TestFindAvatar(t *testing.T) {
+	service := newTestService(t)
+
+	_, _, err := service.Find("alice", 64)
+	assert.Equal(t, 404, err.Status)
+
+	service.SetFromUpload(context.Background(), "alice", encodedImage(t, 100, 100), avatarDTO.AvatarCropDTO{})
+	_, _, err = service.Find("alice", 65)
+	assert.Equal(t, 400, err.Status)
+
+	service.Delete("alice")
+	_, _, err = service.Find("alice", 64)
+	assert.Equal(t, 404, err.Status)
+}
@@ -1,6 +1,10 @@
 package emailService
 
 import (
+	"fmt"
+	"go-gallery/src/infrastructure/metrics"
+	"strings"
+
 	emailSenderRepository "go-gallery/src/infrastructure/repository/emailSender"
 	emailTemplate "go-gallery/src/infrastructure/repository/emailSender/template"
 )
@@ -16,5 +20,13 @@ func NewEmailSenderService(repository emailSenderRepository.EmailSenderRepositor
 }
 
 func (s *EmailSenderService) SendEmail(code, email string, template emailTemplate.EmailTemplate) error {
-	return s.repository.SendEmail(code, email, template)
+	err := s.repository.SendEmail(code, email, template)
+	metrics.ObserveEmail(templateName(template), err)
+	return err
+}
+
+// The metrics are labelled with the name of the template type, e.g. VerificationTemplate
+func templateName(template emailTemplate.EmailTemplate) string {
+	name := fmt.Sprintf("%T", template)
+	return name[strings.LastIndex(name, ".")+1:]
 }
@@ -0,0 +1,152 @@
+package exportService
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	thumbnailImageDTO "go-gallery/src/infrastructure/dto/image/thumbnailImage"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+)
+
+const (
+	ARCHIVE_PROFILE_FILE     = "profile.json"
+	ARCHIVE_METADATA_FILE    = "metadata.json"
+	ARCHIVE_IMAGES_DIR       = "images"
+	ARCHIVE_THUMBNAILS_DIR   = "thumbnails"
+	ARCHIVE_UNNAMED_FILENAME = "image"
+)
+
+// archiveMetadata es el contenido de metadata.json. La galería no tiene álbumes, así que
+// la estructura es la lista plana de imágenes con la ruta de cada fichero dentro del zip
+type archiveMetadata struct {
+	ExportedAt time.Time       `json:"exported_at"`
+	Images     []imageMetadata `json:"images"`
+}
+
+type imageMetadata struct {
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	Extension     string `json:"extension"`
+	Size          string `json:"size"`
+	File          string `json:"file"`
+	Thumbnail     string `json:"thumbnail,omitempty"`
+	ThumbnailSize string `json:"thumbnail_size,omitempty"`
+}
+
+// archiveWriter escribe el zip de forma incremental para no tener todas las imágenes en memoria
+type archiveWriter struct {
+	zip      *zip.Writer
+	used     map[string]bool
+	metadata archiveMetadata
+}
+
+func newArchiveWriter(w io.Writer, exportedAt time.Time) *archiveWriter {
+	return &archiveWriter{
+		zip:      zip.NewWriter(w),
+		used:     make(map[string]bool),
+		metadata: archiveMetadata{ExportedAt: exportedAt, Images: make([]imageMetadata, 0)},
+	}
+}
+
+func (a *archiveWriter) addProfile(profile userDTO.UserSummaryDTO) error {
+	return a.addJSON(ARCHIVE_PROFILE_FILE, profile)
+}
+
+// addImage guarda el original con su nombre de fichero y, si existe, su miniatura
+func (a *archiveWriter) addImage(image *imageDTO.ImageDTO, thumbnail *thumbnailImageDTO.ThumbnailImageDTO) error {
+	entry := imageMetadata{
+		Name:      image.Name,
+		Extension: image.Extension,
+		Size:      image.Size,
+		File:      a.uniquePath(ARCHIVE_IMAGES_DIR, image.Name, image.Extension),
+	}
+	if image.Id != nil {
+		entry.Id = *image.Id
+	}
+
+	if err := a.addBase64(entry.File, image.ContentFile); err != nil {
+		return err
+	}
+
+	if thumbnail != nil {
+		entry.Thumbnail = a.uniquePath(ARCHIVE_THUMBNAILS_DIR, thumbnail.Name, thumbnail.Extension)
+		entry.ThumbnailSize = thumbnail.Size
+		if err := a.addBase64(entry.Thumbnail, thumbnail.ContentFile); err != nil {
+			return err
+		}
+	}
+
+	a.metadata.Images = append(a.metadata.Images, entry)
+	return nil
+}
+
+// close escribe metadata.json y el directorio central del zip
+func (a *archiveWriter) close() error {
+	if err := a.addJSON(ARCHIVE_METADATA_FILE, a.metadata); err != nil {
+		return err
+	}
+	return a.zip.Close()
+}
+
+func (a *archiveWriter) addJSON(name string, value any) error {
+	w, err := a.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func (a *archiveWriter) addBase64(name, content string) error {
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return fmt.Errorf("invalid content of %s: %w", name, err)
+	}
+
+	w, err := a.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// uniquePath evita que dos imágenes con el mismo nombre se sobrescriban dentro del zip
+func (a *archiveWriter) uniquePath(dir, name, extension string) string {
+	name = sanitizeFilename(name)
+	if extension = strings.TrimSpace(extension); extension != "" {
+		extension = sanitizeFilename(extension)
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+	}
+
+	candidate := path.Join(dir, name+extension)
+	for i := 2; a.used[candidate]; i++ {
+		candidate = path.Join(dir, fmt.Sprintf("%s (%d)%s", name, i, extension))
+	}
+	a.used[candidate] = true
+	return candidate
+}
+
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < 32 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+
+	if name == "" || name == "." || name == ".." {
+		return ARCHIVE_UNNAMED_FILENAME
+	}
+	return name
+}
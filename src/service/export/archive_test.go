@@ -0,0 +1,110 @@
+package exportService
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	thumbnailImageDTO "go-gallery/src/infrastructure/dto/image/thumbnailImage"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[file.Name] = content
+	}
+	return files
+}
+
+func encoded(content string) string {
+	return base64.StdEncoding.EncodeToString([]byte(content))
+}
+
+func TestArchiveWriter(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	exportedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	archive := newArchiveWriter(buffer, exportedAt)
+
+	id := "64a1f8b8e4b0c10d3c5b2e75"
+	require.NoError(t, archive.addProfile(userDTO.UserSummaryDTO{Username: "alice", Email: "alice@example.com"}))
+	require.NoError(t, archive.addImage(
+		&imageDTO.ImageDTO{Id: &id, Name: "beach", Extension: ".jpg", ContentFile: encoded("original"), Size: "8 B"},
+		&thumbnailImageDTO.ThumbnailImageDTO{Name: "beach", Extension: ".webp", ContentFile: encoded("thumb"), Size: "5 B"},
+	))
+	require.NoError(t, archive.close())
+
+	files := readArchive(t, buffer.Bytes())
+	assert.Equal(t, []byte("original"), files["images/beach.jpg"])
+	assert.Equal(t, []byte("thumb"), files["thumbnails/beach.webp"])
+
+	var profile userDTO.UserSummaryDTO
+	require.NoError(t, json.Unmarshal(files[ARCHIVE_PROFILE_FILE], &profile))
+	assert.Equal(t, "alice", profile.Username)
+
+	var metadata archiveMetadata
+	require.NoError(t, json.Unmarshal(files[ARCHIVE_METADATA_FILE], &metadata))
+	assert.True(t, exportedAt.Equal(metadata.ExportedAt))
+	require.Len(t, metadata.Images, 1)
+	assert.Equal(t, imageMetadata{
+		Id:            id,
+		Name:          "beach",
+		Extension:     ".jpg",
+		Size:          "8 B",
+		File:          "images/beach.jpg",
+		Thumbnail:     "thumbnails/beach.webp",
+		ThumbnailSize: "5 B",
+	}, metadata.Images[0])
+}
+
+func TestArchiveWriterDuplicatedNames(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	archive := newArchiveWriter(buffer, time.Now())
+
+	for range 3 {
+		require.NoError(t, archive.addImage(&imageDTO.ImageDTO{Name: "beach", Extension: ".jpg", ContentFile: encoded("x")}, nil))
+	}
+	require.NoError(t, archive.close())
+
+	files := readArchive(t, buffer.Bytes())
+	assert.Contains(t, files, "images/beach.jpg")
+	assert.Contains(t, files, "images/beach (2).jpg")
+	assert.Contains(t, files, "images/beach (3).jpg")
+}
+
+func TestArchiveWriterInvalidContent(t *testing.T) {
+	archive := newArchiveWriter(new(bytes.Buffer), time.Now())
+
+	err := archive.addImage(&imageDTO.ImageDTO{Name: "beach", Extension: ".jpg", ContentFile: "not base64!"}, nil)
+	assert.ErrorContains(t, err, "invalid content of images/beach.jpg")
+}
+
+func TestUniquePathSanitizesNames(t *testing.T) {
+	archive := newArchiveWriter(new(bytes.Buffer), time.Now())
+
+	assert.Equal(t, "images/.._.._etc_passwd.jpg", archive.uniquePath(ARCHIVE_IMAGES_DIR, "../../etc/passwd", ".jpg"))
+	assert.Equal(t, "images/image.png", archive.uniquePath(ARCHIVE_IMAGES_DIR, "  ", "png"))
+	assert.Equal(t, "images/noextension", archive.uniquePath(ARCHIVE_IMAGES_DIR, "noextension", ""))
+}
+
+func TestProgress(t *testing.T) {
+	assert.Equal(t, 0, progress(5, 0))
+	assert.Equal(t, 25, progress(1, 4))
+	assert.Equal(t, 99, progress(4, 4), "expected progress not to reach 100 until the file is ready")
+}
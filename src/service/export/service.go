@@ -0,0 +1,378 @@
+package exportService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-gallery/src/commons/configurator/configuration"
+	"go-gallery/src/commons/exception"
+	utilsToken "go-gallery/src/commons/utils/token"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-gallery/src/infrastructure/auth"
+	exportDTO "go-gallery/src/infrastructure/dto/export"
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	log "go-gallery/src/infrastructure/logger"
+	emailTemplate "go-gallery/src/infrastructure/repository/emailSender/template"
+	exportRepository "go-gallery/src/infrastructure/repository/export"
+	emailService "go-gallery/src/service/email"
+	imageService "go-gallery/src/service/image"
+	userService "go-gallery/src/service/user"
+)
+
+const (
+	EXPORT_ID_BYTES          int   = 16
+	EXPORT_PAGE_SIZE         int64 = 50
+	EXPORT_CLEANUP_INTERVAL        = 10 * time.Minute
+	EXPORT_IN_PROGRESS_MSG         = "There is already an export in progress or ready to download"
+	EXPORT_NOT_FOUND_MSG           = "Export not found"
+	INVALID_EXPORT_LINK_MSG        = "The download link is invalid, has expired or has already been used"
+	EXPORT_FILE_EXTENSION          = ".zip"
+	EXPORT_PARTIAL_EXTENSION       = ".partial"
+)
+
+// NowFunc allows tests to control the current time
+var NowFunc = time.Now
+
+var logger log.Logger
+
+// ExportService genera en segundo plano un zip con los datos de la cuenta y lo notifica por correo
+type ExportService struct {
+	repository         exportRepository.ExportRepository
+	userService        *userService.UserService
+	imageService       *imageService.ImageService
+	emailSenderService *emailService.EmailSenderService
+	linkManager        *auth.ExportLinkManager
+	configuration      configuration.ExportConfiguration
+	jobs               sync.WaitGroup
+	stop               chan struct{}
+	stopOnce           sync.Once
+}
+
+func NewExportService(repository exportRepository.ExportRepository, userService *userService.UserService, imageService *imageService.ImageService,
+	emailSenderService *emailService.EmailSenderService, linkManager *auth.ExportLinkManager, configuration configuration.ExportConfiguration) *ExportService {
+	logger = log.Instance()
+
+	if err := os.MkdirAll(configuration.Directory, 0o700); err != nil {
+		logger.Panic("Could not create the export directory", log.F("directory", configuration.Directory), log.F("error", err.Error()))
+		panic(fmt.Sprintf("Could not create the export directory %s: %s", configuration.Directory, err.Error()))
+	}
+
+	service := &ExportService{
+		repository:         repository,
+		userService:        userService,
+		imageService:       imageService,
+		emailSenderService: emailSenderService,
+		linkManager:        linkManager,
+		configuration:      configuration,
+	}
+
+	service.removeOrphanFiles()
+	service.StartAutoCleanup()
+
+	return service
+}
+
+// Request registra una nueva exportación y la genera en segundo plano
+func (s *ExportService) Request(ctx context.Context, username string) (*exportDTO.ExportJobDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	id, err := utilsToken.GenerateToken(EXPORT_ID_BYTES)
+	if err != nil {
+		logger.Error("Error generating export id", log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error creating the export")
+	}
+
+	job := &exportDTO.ExportJobDTO{
+		Id:        id,
+		Username:  username,
+		Status:    exportDTO.EXPORT_STATUS_PENDING,
+		CreatedAt: NowFunc(),
+	}
+	if err := s.repository.Insert(job); err != nil {
+		if errors.Is(err, exportRepository.ErrExportInProgress) {
+			return nil, exception.NewApiException(409, EXPORT_IN_PROGRESS_MSG)
+		}
+		logger.Error("Error storing export", log.F("export_id", id), log.F("error", err.Error()))
+		return nil, exception.NewApiException(500, "Error creating the export")
+	}
+
+	logger = logger.With(log.F("export_id", id))
+	logger.Info("Export requested")
+
+	// The job outlives the request, it keeps the request logger but not its cancellation or its span
+	jobCtx := log.WithContext(context.Background(), logger)
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		s.run(jobCtx, *job)
+	}()
+
+	return job, nil
+}
+
+// Find devuelve una exportación solo si pertenece al usuario indicado
+func (s *ExportService) Find(id, username string) (*exportDTO.ExportJobDTO, *exception.ApiException) {
+	job, err := s.repository.Find(id)
+	if err != nil || job.Username != username {
+		return nil, exception.NewApiException(404, EXPORT_NOT_FOUND_MSG)
+	}
+	return job, nil
+}
+
+// Download comprueba el enlace firmado y marca la exportación como descargada, así el enlace
+// solo sirve una vez. El llamante es responsable de eliminar el fichero tras enviarlo
+func (s *ExportService) Download(ctx context.Context, token string) (*exportDTO.ExportJobDTO, *exception.ApiException) {
+	logger := log.FromContext(ctx)
+	claims, err := s.linkManager.Verify(token)
+	if err != nil {
+		logger.Warning("Invalid export download link", log.F("error", err.Error()))
+		return nil, exception.NewApiException(401, INVALID_EXPORT_LINK_MSG)
+	}
+
+	job, err := s.repository.Find(claims.ExportID)
+	if err != nil || job.Username != claims.Username {
+		return nil, exception.NewApiException(401, INVALID_EXPORT_LINK_MSG)
+	}
+
+	job, err = s.repository.ClaimDownload(claims.ExportID)
+	if err != nil {
+		logger.Warning("Export is not available for download", log.F("export_id", claims.ExportID), log.F("error", err.Error()))
+		return nil, exception.NewApiException(401, INVALID_EXPORT_LINK_MSG)
+	}
+
+	logger.Info("Export downloaded", log.F("export_id", job.Id), log.F("username", job.Username))
+	return job, nil
+}
+
+func (s *ExportService) run(ctx context.Context, job exportDTO.ExportJobDTO) {
+	logger := log.FromContext(ctx)
+	job.Status = exportDTO.EXPORT_STATUS_RUNNING
+	s.update(ctx, &job)
+
+	user, errUser := s.userService.FindByUsername(job.Username)
+	if errUser != nil {
+		s.fail(ctx, &job, "", "Error reading the user profile: "+errUser.Message)
+		return
+	}
+
+	usage, errUsage := s.imageService.StorageUsage(ctx, job.Username)
+	if errUsage != nil {
+		s.fail(ctx, &job, "", "Error counting the images: "+errUsage.Message)
+		return
+	}
+	job.Total = usage.TotalImages
+	s.update(ctx, &job)
+
+	partialPath := filepath.Join(s.configuration.Directory, job.Id+EXPORT_FILE_EXTENSION+EXPORT_PARTIAL_EXTENSION)
+	if err := s.writeArchive(ctx, &job, user, partialPath); err != nil {
+		s.fail(ctx, &job, partialPath, err.Error())
+		return
+	}
+
+	filePath := filepath.Join(s.configuration.Directory, job.Id+EXPORT_FILE_EXTENSION)
+	if err := os.Rename(partialPath, filePath); err != nil {
+		s.fail(ctx, &job, partialPath, "Error storing the export file: "+err.Error())
+		return
+	}
+
+	completedAt := NowFunc()
+	expiresAt := completedAt.Add(s.configuration.LinkExpiration)
+	token, err := s.linkManager.Create(job.Id, job.Username, expiresAt)
+	if err != nil {
+		s.fail(ctx, &job, filePath, "Error signing the download link: "+err.Error())
+		return
+	}
+
+	job.Status = exportDTO.EXPORT_STATUS_READY
+	job.Progress = 100
+	job.CompletedAt = &completedAt
+	job.ExpiresAt = &expiresAt
+	job.FilePath = filePath
+	s.update(ctx, &job)
+
+	logger.Info("Export is ready", log.F("images", job.Processed))
+
+	link := s.configuration.DownloadURL + "?token=" + url.QueryEscape(token)
+	template := emailTemplate.ExportReadyTemplate{ExpiresIn: s.configuration.LinkExpiration}
+	if err := s.emailSenderService.SendEmail(link, user.Email, template); err != nil {
+		logger.Error("Error sending the export ready email", log.F("error", err.Error()))
+	}
+}
+
+func (s *ExportService) writeArchive(ctx context.Context, job *exportDTO.ExportJobDTO, user *userDTO.UserDTO, filePath string) error {
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("error creating the export file: %w", err)
+	}
+	defer file.Close()
+
+	archive := newArchiveWriter(file, NowFunc())
+	if err := archive.addProfile(userDTO.ToUserSummary(user)); err != nil {
+		return fmt.Errorf("error writing the user profile: %w", err)
+	}
+
+	// Las miniaturas enlazan con su imagen original, así que se recorren paginadas
+	lastID := ""
+	for {
+		page, errPage := s.imageService.FindAllThumbnails(ctx, job.Username, lastID, EXPORT_PAGE_SIZE)
+		if errPage != nil {
+			if errPage.Status == 404 {
+				break
+			}
+			return fmt.Errorf("error reading the thumbnails: %s", errPage.Message)
+		}
+
+		for i := range page.Thumbnails {
+			thumbnail := &page.Thumbnails[i]
+			original, errImage := s.imageService.Find(ctx, &imageDTO.ImageDTO{Id: thumbnail.ImageID, Owner: job.Username})
+			if errImage != nil {
+				log.FromContext(ctx).Warning("Skipping thumbnail without original image", log.F("thumbnail_id", thumbnail.Id), log.F("error", errImage.Message))
+				continue
+			}
+
+			if err := archive.addImage(original, thumbnail); err != nil {
+				return fmt.Errorf("error writing image %s: %w", original.Name, err)
+			}
+
+			job.Processed++
+			job.Progress = progress(job.Processed, job.Total)
+			s.update(ctx, job)
+		}
+
+		if int64(len(page.Thumbnails)) < EXPORT_PAGE_SIZE {
+			break
+		}
+		lastID = page.LastID
+	}
+
+	if err := archive.close(); err != nil {
+		return fmt.Errorf("error closing the export file: %w", err)
+	}
+	return file.Sync()
+}
+
+func (s *ExportService) fail(ctx context.Context, job *exportDTO.ExportJobDTO, filePath, message string) {
+	log.FromContext(ctx).Error("Export failed", log.F("error", message))
+	if filePath != "" {
+		os.Remove(filePath)
+	}
+
+	job.Status = exportDTO.EXPORT_STATUS_FAILED
+	job.Error = message
+	job.FilePath = ""
+	s.update(ctx, job)
+}
+
+func (s *ExportService) update(ctx context.Context, job *exportDTO.ExportJobDTO) {
+	if err := s.repository.Update(job); err != nil {
+		log.FromContext(ctx).Error("Error updating export", log.F("export_id", job.Id), log.F("error", err.Error()))
+	}
+}
+
+// StartAutoCleanup elimina los ficheros de las exportaciones cuyo enlace ha caducado sin descargarse
+func (s *ExportService) StartAutoCleanup() {
+	s.stop = make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(EXPORT_CLEANUP_INTERVAL):
+				s.cleanupExpiredExports()
+			}
+		}
+	}()
+}
+
+// Close detiene la limpieza periódica y espera a que terminen las exportaciones en curso,
+// como mucho hasta que venza el contexto
+func (s *ExportService) Close(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+		}
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("exports still running: %w", ctx.Err())
+	}
+}
+
+func (s *ExportService) cleanupExpiredExports() {
+	expired, err := s.repository.FindExpired(NowFunc())
+	if err != nil {
+		logger.Error("Error searching expired exports", log.F("error", err.Error()))
+		return
+	}
+
+	for i := range expired {
+		job := &expired[i]
+		if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Error("Error removing file of expired export", log.F("export_id", job.Id), log.F("error", err.Error()))
+		}
+		job.Status = exportDTO.EXPORT_STATUS_EXPIRED
+		job.FilePath = ""
+		s.update(context.Background(), job)
+		logger.Info("Export expired without being downloaded", log.F("export_id", job.Id), log.F("username", job.Username))
+	}
+}
+
+// removeOrphanFiles elimina los ficheros del directorio que no pertenecen a ninguna exportación activa.
+// Las exportaciones solo se guardan en memoria, así que tras un reinicio sus ficheros quedarían sin borrar
+func (s *ExportService) removeOrphanFiles() {
+	entries, err := os.ReadDir(s.configuration.Directory)
+	if err != nil {
+		logger.Error("Error reading the export directory", log.F("directory", s.configuration.Directory), log.F("error", err.Error()))
+		return
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, EXPORT_FILE_EXTENSION) || strings.HasSuffix(name, EXPORT_FILE_EXTENSION+EXPORT_PARTIAL_EXTENSION)) {
+			continue
+		}
+
+		id := strings.TrimSuffix(strings.TrimSuffix(name, EXPORT_PARTIAL_EXTENSION), EXPORT_FILE_EXTENSION)
+		if job, err := s.repository.Find(id); err == nil && job.Active() {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.configuration.Directory, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Error("Error removing orphan export file", log.F("file", name), log.F("error", err.Error()))
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		logger.Info("Orphan export files removed", log.F("count", removed))
+	}
+}
+
+// progress devuelve el porcentaje completado, sin llegar a 100 hasta que el fichero esté listo
+func progress(processed, total int64) int {
+	if total <= 0 {
+		return 0
+	}
+	percentage := int(processed * 100 / total)
+	if percentage > 99 {
+		return 99
+	}
+	return percentage
+}
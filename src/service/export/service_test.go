@@ -0,0 +1,41 @@
+package exportService
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-gallery/src/commons/configurator/configuration"
+	exportDTO "go-gallery/src/infrastructure/dto/export"
+	log "go-gallery/src/infrastructure/logger"
+	exportRepository "go-gallery/src/infrastructure/repository/export"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveOrphanFilesKeepsOnlyActiveExports(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	logger = log.Instance()
+
+	directory := t.TempDir()
+	repository := exportRepository.NewExportMemoryRepository(nil)
+	require.NoError(t, repository.Insert(&exportDTO.ExportJobDTO{Id: "ready", Username: "alice", Status: exportDTO.EXPORT_STATUS_READY}))
+	require.NoError(t, repository.Insert(&exportDTO.ExportJobDTO{Id: "downloaded", Username: "bob", Status: exportDTO.EXPORT_STATUS_DOWNLOADED}))
+
+	files := []string{"ready.zip", "downloaded.zip", "orphan.zip", "orphan.zip.partial", "notes.txt"}
+	for _, name := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(directory, name), []byte("content"), 0o600))
+	}
+
+	service := &ExportService{repository: repository, configuration: configuration.ExportConfiguration{Directory: directory}}
+	service.removeOrphanFiles()
+
+	entries, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	remaining := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"ready.zip", "notes.txt"}, remaining)
+}
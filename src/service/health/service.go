@@ -0,0 +1,92 @@
+package healthService
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-gallery/src/commons/configurator/version"
+	dependency_container "go-gallery/src/commons/dependency-container"
+	healthDTO "go-gallery/src/infrastructure/dto/health"
+	log "go-gallery/src/infrastructure/logger"
+)
+
+const (
+	DEPENDENCY_UNAVAILABLE_MSG string = "Dependency unavailable"
+	DEPENDENCY_TIMEOUT_MSG     string = "Health check timed out"
+)
+
+// HealthService comprueba si las dependencias externas del servicio están disponibles
+type HealthService struct {
+	checkers map[string]dependency_container.HealthChecker
+	timeout  time.Duration
+}
+
+func NewHealthService(checkers map[string]dependency_container.HealthChecker, timeout time.Duration) *HealthService {
+	return &HealthService{checkers: checkers, timeout: timeout}
+}
+
+// Readiness comprueba todas las dependencias a la vez, cada una con su propio límite de tiempo
+func (s *HealthService) Readiness(ctx context.Context) *healthDTO.ReadinessDTO {
+	readiness := &healthDTO.ReadinessDTO{
+		Status:       healthDTO.HEALTH_STATUS_UP,
+		Dependencies: make(map[string]healthDTO.DependencyHealthDTO, len(s.checkers)),
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for name, checker := range s.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dependency := s.check(ctx, name, checker)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			readiness.Dependencies[name] = dependency
+			if dependency.Status != healthDTO.HEALTH_STATUS_UP {
+				readiness.Status = healthDTO.HEALTH_STATUS_DOWN
+			}
+		}()
+	}
+	wg.Wait()
+
+	return readiness
+}
+
+func (s *HealthService) check(ctx context.Context, name string, checker dependency_container.HealthChecker) healthDTO.DependencyHealthDTO {
+	checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.HealthCheck(checkCtx)
+	if err == nil {
+		// A check that ignores the context may answer after the deadline, it is not considered ready
+		err = checkCtx.Err()
+	}
+	dependency := healthDTO.DependencyHealthDTO{
+		Status:     healthDTO.HEALTH_STATUS_UP,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		// The readiness endpoint is public, the cause is only logged because it may reveal hosts or credentials
+		log.FromContext(ctx).Warning("Health check failed", log.F("dependency", name), log.F("error", err.Error()))
+		dependency.Status = healthDTO.HEALTH_STATUS_DOWN
+		dependency.Error = DEPENDENCY_UNAVAILABLE_MSG
+		if errors.Is(err, context.DeadlineExceeded) {
+			dependency.Error = DEPENDENCY_TIMEOUT_MSG
+		}
+	}
+	return dependency
+}
+
+// Version devuelve la versión, el commit y la fecha de compilación del binario
+func (s *HealthService) Version() *healthDTO.VersionDTO {
+	return &healthDTO.VersionDTO{
+		AppVersion: version.AppVersion,
+		CommitHash: version.CommitHash,
+		BuildDate:  version.BuildDate,
+	}
+}
@@ -0,0 +1,76 @@
+package healthService
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dependency_container "go-gallery/src/commons/dependency-container"
+	healthDTO "go-gallery/src/infrastructure/dto/health"
+	log "go-gallery/src/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type checkerFunc func(ctx context.Context) error
+
+func (f checkerFunc) HealthCheck(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestReadinessAllUp(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	service := NewHealthService(map[string]dependency_container.HealthChecker{
+		"UserRepository":  checkerFunc(func(ctx context.Context) error { return nil }),
+		"ImageRepository": checkerFunc(func(ctx context.Context) error { return nil }),
+	}, time.Second)
+
+	readiness := service.Readiness(context.Background())
+	assert.Equal(t, healthDTO.HEALTH_STATUS_UP, readiness.Status)
+	assert.Len(t, readiness.Dependencies, 2)
+	assert.Equal(t, healthDTO.HEALTH_STATUS_UP, readiness.Dependencies["UserRepository"].Status)
+	assert.Empty(t, readiness.Dependencies["UserRepository"].Error)
+}
+
+func TestReadinessWithFailingDependency(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	service := NewHealthService(map[string]dependency_container.HealthChecker{
+		"UserRepository":  checkerFunc(func(ctx context.Context) error { return nil }),
+		"ImageRepository": checkerFunc(func(ctx context.Context) error { return errors.New("connection refused") }),
+	}, time.Second)
+
+	readiness := service.Readiness(context.Background())
+	assert.Equal(t, healthDTO.HEALTH_STATUS_DOWN, readiness.Status)
+	assert.Equal(t, healthDTO.HEALTH_STATUS_UP, readiness.Dependencies["UserRepository"].Status)
+	assert.Equal(t, healthDTO.HEALTH_STATUS_DOWN, readiness.Dependencies["ImageRepository"].Status)
+	assert.Equal(t, DEPENDENCY_UNAVAILABLE_MSG, readiness.Dependencies["ImageRepository"].Error)
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	service := NewHealthService(map[string]dependency_container.HealthChecker{
+		"UserRepository": checkerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+		"CodeGeneratorRepository": checkerFunc(func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}),
+	}, 10*time.Millisecond)
+
+	readiness := service.Readiness(context.Background())
+	assert.Equal(t, healthDTO.HEALTH_STATUS_DOWN, readiness.Status)
+	assert.Equal(t, DEPENDENCY_TIMEOUT_MSG, readiness.Dependencies["UserRepository"].Error)
+	assert.Equal(t, healthDTO.HEALTH_STATUS_DOWN, readiness.Dependencies["CodeGeneratorRepository"].Status)
+}
+
+func TestReadinessWithoutDependencies(t *testing.T) {
+	log.Init(log.NewConsoleLogger())
+	service := NewHealthService(map[string]dependency_container.HealthChecker{}, time.Second)
+
+	readiness := service.Readiness(context.Background())
+	assert.Equal(t, healthDTO.HEALTH_STATUS_UP, readiness.Status)
+	assert.Empty(t, readiness.Dependencies)
+}
@@ -1,13 +1,18 @@
 package imageService
 
 import (
+	"context"
 	"go-gallery/src/commons/exception"
+	"sort"
 
 	"go-gallery/src/infrastructure/dto"
 	imageDTO "go-gallery/src/infrastructure/dto/image"
 	thumbnailImageDTO "go-gallery/src/infrastructure/dto/image/thumbnailImage"
 	imageRepository "go-gallery/src/infrastructure/repository/image"
 	thumbnailImageRepository "go-gallery/src/infrastructure/repository/image/thumbnailImage"
+	"go-gallery/src/infrastructure/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ImageService struct {
@@ -22,25 +27,35 @@ func NewImageService(imageRepository imageRepository.ImageRepository, thumbnailI
 	}
 }
 
-func (s *ImageService) Find(dto *imageDTO.ImageDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
-	return s.imageRepository.Find(dto)
+func (s *ImageService) Find(ctx context.Context, dto *imageDTO.ImageDTO) (image *imageDTO.ImageDTO, err *exception.ApiException) {
+	ctx, span := tracing.Start(ctx, "ImageService.Find")
+	defer func() { tracing.End(span, err) }()
+
+	return s.imageRepository.Find(ctx, dto)
 }
 
-func (s *ImageService) Insert(dto *imageDTO.ImageUploadRequestDTO) (*imageDTO.ImageUploadResponseDTO, *exception.ApiException) {
-	imageDTO, err := s.imageRepository.Insert(dto)
+// Insert guarda la imagen original y después genera y guarda su miniatura
+func (s *ImageService) Insert(ctx context.Context, dto *imageDTO.ImageUploadRequestDTO) (response *imageDTO.ImageUploadResponseDTO, err *exception.ApiException) {
+	ctx, span := tracing.Start(ctx, "ImageService.Insert", attribute.Int("image.size_bytes", len(dto.RawContentFile)))
+	defer func() { tracing.End(span, err) }()
+
+	imageDTO, err := s.imageRepository.Insert(ctx, dto)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.thumbnailImageRepository.Insert(imageDTO, dto.RawContentFile)
+	return s.thumbnailImageRepository.Insert(ctx, imageDTO, dto.RawContentFile)
 }
 
-func (s *ImageService) Update(dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException) {
-	imageDTO, err := s.imageRepository.Update(dto)
+func (s *ImageService) Update(ctx context.Context, dto *imageDTO.ImageUpdateRequestDTO) (response *imageDTO.ImageUpdateResponseDTO, err *exception.ApiException) {
+	ctx, span := tracing.Start(ctx, "ImageService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	imageDTO, err := s.imageRepository.Update(ctx, dto)
 	if err != nil {
 		return nil, err
 	}
-	_, err = s.thumbnailImageRepository.Update(dto)
+	_, err = s.thumbnailImageRepository.Update(ctx, dto)
 	if err != nil {
 		return nil, err
 	}
@@ -48,24 +63,89 @@ func (s *ImageService) Update(dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.Im
 	return imageDTO, nil
 }
 
-func (s *ImageService) Delete(dto *imageDTO.ImageDeleteRequestDTO) (*dto.MessageResponseDTO, *exception.ApiException) {
-	_, err := s.imageRepository.Delete(dto)
+func (s *ImageService) Delete(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (response *dto.MessageResponseDTO, err *exception.ApiException) {
+	ctx, span := tracing.Start(ctx, "ImageService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	_, err = s.imageRepository.Delete(ctx, dto)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.thumbnailImageRepository.Delete(dto)
+	return s.thumbnailImageRepository.Delete(ctx, dto)
 }
 
-func (s *ImageService) DeleteAll(dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
-	_, err := s.imageRepository.DeleteAll(dto)
+func (s *ImageService) DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (deleted int64, err *exception.ApiException) {
+	ctx, span := tracing.Start(ctx, "ImageService.DeleteAll")
+	defer func() { tracing.End(span, err) }()
+
+	_, err = s.imageRepository.DeleteAll(ctx, dto)
 	if err != nil {
 		return 0, err
 	}
 
-	return s.thumbnailImageRepository.DeleteAll(dto)
+	return s.thumbnailImageRepository.DeleteAll(ctx, dto)
+}
+
+func (s *ImageService) FindAllThumbnails(ctx context.Context, owner, lastID string, pageSize int64) (page *thumbnailImageDTO.ThumbnailImageCursorDTO, err *exception.ApiException) {
+	ctx, span := tracing.Start(ctx, "ImageService.FindAllThumbnails")
+	defer func() { tracing.End(span, err) }()
+
+	return s.thumbnailImageRepository.FindAll(ctx, owner, lastID, pageSize)
+}
+
+// Merges the usage of images and thumbnails per owner, an empty owner returns the usage of every user
+func (s *ImageService) StorageUsage(ctx context.Context, owner string) (report *imageDTO.StorageUsageReportDTO, err *exception.ApiException) {
+	ctx, span := tracing.Start(ctx, "ImageService.StorageUsage")
+	defer func() { tracing.End(span, err) }()
+
+	images, err := s.imageRepository.StorageUsage(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnails, err := s.thumbnailImageRepository.StorageUsage(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeStorageUsage(images, thumbnails), nil
 }
 
-func (s *ImageService) FindAllThumbnails(owner, lastID string, pageSize int64) (*thumbnailImageDTO.ThumbnailImageCursorDTO, *exception.ApiException) {
-	return s.thumbnailImageRepository.FindAll(owner, lastID, pageSize)
+func mergeStorageUsage(images, thumbnails []imageDTO.StorageUsageDTO) *imageDTO.StorageUsageReportDTO {
+	owners := make(map[string]*imageDTO.StorageUsageDTO)
+	get := func(owner string) *imageDTO.StorageUsageDTO {
+		usage, found := owners[owner]
+		if !found {
+			usage = &imageDTO.StorageUsageDTO{Owner: owner}
+			owners[owner] = usage
+		}
+		return usage
+	}
+
+	for _, image := range images {
+		usage := get(image.Owner)
+		usage.Images += image.Images
+		usage.ImagesBytes += image.ImagesBytes
+	}
+	for _, thumbnail := range thumbnails {
+		get(thumbnail.Owner).ThumbnailsBytes += thumbnail.ThumbnailsBytes
+	}
+
+	report := &imageDTO.StorageUsageReportDTO{Owners: make([]imageDTO.StorageUsageDTO, 0, len(owners))}
+	for _, usage := range owners {
+		usage.TotalBytes = usage.ImagesBytes + usage.ThumbnailsBytes
+		report.TotalImages += usage.Images
+		report.TotalBytes += usage.TotalBytes
+		report.Owners = append(report.Owners, *usage)
+	}
+
+	sort.Slice(report.Owners, func(i, j int) bool {
+		if report.Owners[i].TotalBytes != report.Owners[j].TotalBytes {
+			return report.Owners[i].TotalBytes > report.Owners[j].TotalBytes
+		}
+		return report.Owners[i].Owner < report.Owners[j].Owner
+	})
+
+	return report
 }
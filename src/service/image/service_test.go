@@ -0,0 +1,43 @@
+package imageService
+
+import (
+	"testing"
+
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeStorageUsage(t *testing.T) {
+	images := []imageDTO.StorageUsageDTO{
+		{Owner: "alice", Images: 2, ImagesBytes: 1000},
+		{Owner: "bob", Images: 1, ImagesBytes: 3000},
+	}
+	thumbnails := []imageDTO.StorageUsageDTO{
+		{Owner: "alice", ThumbnailsBytes: 100},
+		{Owner: "bob", ThumbnailsBytes: 50},
+		{Owner: "orphan", ThumbnailsBytes: 10},
+	}
+
+	report := mergeStorageUsage(images, thumbnails)
+
+	assert.Equal(t, int64(3), report.TotalImages)
+	assert.Equal(t, int64(4160), report.TotalBytes)
+	assert.Len(t, report.Owners, 3)
+
+	// Sorted by total bytes, largest first
+	assert.Equal(t, "bob", report.Owners[0].Owner)
+	assert.Equal(t, int64(3050), report.Owners[0].TotalBytes)
+	assert.Equal(t, "alice", report.Owners[1].Owner)
+	assert.Equal(t, int64(2), report.Owners[1].Images)
+	assert.Equal(t, int64(1100), report.Owners[1].TotalBytes)
+	assert.Equal(t, "orphan", report.Owners[2].Owner)
+}
+
+func TestMergeStorageUsageEmpty(t *testing.T) {
+	report := mergeStorageUsage(nil, nil)
+
+	assert.NotNil(t, report.Owners)
+	assert.Empty(t, report.Owners)
+	assert.Zero(t, report.TotalBytes)
+}
@@ -0,0 +1,153 @@
+package imageService
+
+import (
+	"context"
+	"go-gallery/src/commons/exception"
+	"testing"
+
+	"go-gallery/src/infrastructure/dto"
+	imageDTO "go-gallery/src/infrastructure/dto/image"
+	thumbnailImageDTO "go-gallery/src/infrastructure/dto/image/thumbnailImage"
+	"go-gallery/src/infrastructure/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanRecorder keeps the span found in the context of each repository call
+type spanRecorder struct {
+	spans []trace.SpanContext
+}
+
+func (r *spanRecorder) record(ctx context.Context) {
+	r.spans = append(r.spans, trace.SpanContextFromContext(ctx))
+}
+
+type stubImageRepository struct {
+	*spanRecorder
+	insertErr *exception.ApiException
+}
+
+func (r *stubImageRepository) Find(ctx context.Context, dto *imageDTO.ImageDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
+	r.record(ctx)
+	return dto, nil
+}
+
+func (r *stubImageRepository) Insert(ctx context.Context, dto *imageDTO.ImageUploadRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
+	r.record(ctx)
+	if r.insertErr != nil {
+		return nil, r.insertErr
+	}
+	id := "65f1c2"
+	return &imageDTO.ImageDTO{Id: &id, Name: dto.Name, Owner: dto.Owner}, nil
+}
+
+func (r *stubImageRepository) Update(ctx context.Context, dto *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException) {
+	r.record(ctx)
+	return &imageDTO.ImageUpdateResponseDTO{Id: dto.Id}, nil
+}
+
+func (r *stubImageRepository) Delete(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (*imageDTO.ImageDTO, *exception.ApiException) {
+	r.record(ctx)
+	return &imageDTO.ImageDTO{}, nil
+}
+
+func (r *stubImageRepository) DeleteAll(ctx context.Context, dto *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
+	r.record(ctx)
+	return 0, nil
+}
+
+func (r *stubImageRepository) StorageUsage(ctx context.Context, owner string) ([]imageDTO.StorageUsageDTO, *exception.ApiException) {
+	r.record(ctx)
+	return nil, nil
+}
+
+type stubThumbnailImageRepository struct {
+	*spanRecorder
+}
+
+func (r *stubThumbnailImageRepository) Insert(ctx context.Context, image *imageDTO.ImageDTO, rawContentFile []byte) (*imageDTO.ImageUploadResponseDTO, *exception.ApiException) {
+	r.record(ctx)
+	return &imageDTO.ImageUploadResponseDTO{Id: *image.Id, Name: image.Name}, nil
+}
+
+func (r *stubThumbnailImageRepository) Update(ctx context.Context, request *imageDTO.ImageUpdateRequestDTO) (*imageDTO.ImageUpdateResponseDTO, *exception.ApiException) {
+	r.record(ctx)
+	return &imageDTO.ImageUpdateResponseDTO{}, nil
+}
+
+func (r *stubThumbnailImageRepository) Delete(ctx context.Context, request *imageDTO.ImageDeleteRequestDTO) (*dto.MessageResponseDTO, *exception.ApiException) {
+	r.record(ctx)
+	return &dto.MessageResponseDTO{}, nil
+}
+
+func (r *stubThumbnailImageRepository) DeleteAll(ctx context.Context, request *imageDTO.ImageDeleteRequestDTO) (int64, *exception.ApiException) {
+	r.record(ctx)
+	return 0, nil
+}
+
+func (r *stubThumbnailImageRepository) FindAll(ctx context.Context, owner, lastID string, pageSize int64) (*thumbnailImageDTO.ThumbnailImageCursorDTO, *exception.ApiException) {
+	r.record(ctx)
+	return &thumbnailImageDTO.ThumbnailImageCursorDTO{}, nil
+}
+
+func (r *stubThumbnailImageRepository) StorageUsage(ctx context.Context, owner string) ([]imageDTO.StorageUsageDTO, *exception.ApiException) {
+	r.record(ctx)
+	return nil, nil
+}
+
+func newTracedService(t *testing.T) (*ImageService, *stubImageRepository, *spanRecorder, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(tracing.TracingSettings{SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+
+	recorder := &spanRecorder{}
+	images := &stubImageRepository{spanRecorder: recorder}
+	service := NewImageService(images, &stubThumbnailImageRepository{spanRecorder: recorder})
+	return service, images, recorder, exporter
+}
+
+func TestInsertPropagatesTheServiceSpanToTheRepositories(t *testing.T) {
+	service, _, recorder, exporter := newTracedService(t)
+
+	ctx, request := tracing.Start(context.Background(), "POST /api/image/upload")
+	_, err := service.Insert(ctx, &imageDTO.ImageUploadRequestDTO{Name: "cat", Owner: "alice", RawContentFile: []byte("raw")})
+	request.End()
+	require.Nil(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	serviceSpan, requestSpan := spans[0], spans[1]
+	assert.Equal(t, "ImageService.Insert", serviceSpan.Name)
+	assert.Equal(t, requestSpan.SpanContext.SpanID(), serviceSpan.Parent.SpanID())
+
+	// Both the image and the thumbnail repository run inside the service span
+	require.Len(t, recorder.spans, 2)
+	for _, repositorySpan := range recorder.spans {
+		assert.Equal(t, serviceSpan.SpanContext.SpanID(), repositorySpan.SpanID())
+	}
+}
+
+func TestInsertMarksTheServiceSpanAsFailed(t *testing.T) {
+	service, images, recorder, exporter := newTracedService(t)
+	images.insertErr = exception.NewApiException(409, "Image already exists")
+
+	_, err := service.Insert(context.Background(), &imageDTO.ImageUploadRequestDTO{Name: "cat", Owner: "alice"})
+	require.NotNil(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "Image already exists", spans[0].Status.Description)
+	assert.Len(t, recorder.spans, 1)
+}
@@ -0,0 +1,77 @@
+package userService
+
+import (
+	userDTO "go-gallery/src/infrastructure/dto/user"
+	"sync"
+	"time"
+)
+
+var NowFunc = time.Now
+
+type cachedUser struct {
+	user       userDTO.UserDTO
+	expiration time.Time
+}
+
+// userCache guarda durante un tiempo corto los usuarios validados en cada petición autenticada, un ttl de cero lo desactiva
+type userCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	users map[string]cachedUser
+}
+
+func newUserCache(ttl time.Duration) *userCache {
+	return &userCache{
+		ttl:   ttl,
+		users: make(map[string]cachedUser),
+	}
+}
+
+// get devuelve una copia del usuario para que quien la recibe no pueda modificar la entrada guardada
+func (c *userCache) get(username string) (*userDTO.UserDTO, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.users[username]
+	if !ok {
+		return nil, false
+	}
+
+	if !NowFunc().Before(entry.expiration) {
+		delete(c.users, username)
+		return nil, false
+	}
+
+	user := entry.user
+	return &user, true
+}
+
+func (c *userCache) set(user *userDTO.UserDTO) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop the expired entries on every insertion so users who never come back do not stay in memory
+	now := NowFunc()
+	for username, entry := range c.users {
+		if !now.Before(entry.expiration) {
+			delete(c.users, username)
+		}
+	}
+
+	c.users[user.Username] = cachedUser{user: *user, expiration: now.Add(c.ttl)}
+}
+
+func (c *userCache) invalidate(username string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.users, username)
+}
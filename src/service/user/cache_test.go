@@ -0,0 +1,59 @@
+package userService
+
+import (
+	"testing"
+	"time"
+
+	userDTO "go-gallery/src/infrastructure/dto/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCacheExpiration(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	NowFunc = func() time.Time { return now }
+	defer func() { NowFunc = time.Now }()
+
+	cache := newUserCache(30 * time.Second)
+	cache.set(&userDTO.UserDTO{Username: "alice", Email: "alice@example.com"})
+
+	user, ok := cache.get("alice")
+	assert.True(t, ok)
+	assert.Equal(t, "alice@example.com", user.Email)
+
+	now = now.Add(30 * time.Second)
+	_, ok = cache.get("alice")
+	assert.False(t, ok)
+}
+
+func TestUserCacheInvalidate(t *testing.T) {
+	cache := newUserCache(time.Minute)
+	cache.set(&userDTO.UserDTO{Username: "alice"})
+	cache.set(&userDTO.UserDTO{Username: "bob"})
+
+	cache.invalidate("alice")
+
+	_, ok := cache.get("alice")
+	assert.False(t, ok)
+	_, ok = cache.get("bob")
+	assert.True(t, ok)
+}
+
+func TestUserCacheReturnsCopy(t *testing.T) {
+	cache := newUserCache(time.Minute)
+	cache.set(&userDTO.UserDTO{Username: "alice", Role: "user"})
+
+	user, _ := cache.get("alice")
+	user.Role = "admin"
+
+	user, _ = cache.get("alice")
+	assert.Equal(t, "user", user.Role)
+}
+
+func TestUserCacheDisabled(t *testing.T) {
+	cache := newUserCache(0)
+	cache.set(&userDTO.UserDTO{Username: "alice"})
+
+	_, ok := cache.get("alice")
+	assert.False(t, ok)
+}
@@ -2,6 +2,8 @@ package userService
 
 import (
 	"go-gallery/src/commons/exception"
+	userEntity "go-gallery/src/domain/entities/user"
+	"time"
 
 	userDTO "go-gallery/src/infrastructure/dto/user"
 	repository "go-gallery/src/infrastructure/repository/user"
@@ -9,15 +11,18 @@ import (
 
 type UserService struct {
 	repository repository.UserRepository
+	cache      *userCache
 }
 
-func NewUserService(repository repository.UserRepository) *UserService {
+func NewUserService(repository repository.UserRepository, validationCacheTTL time.Duration) *UserService {
 	return &UserService{
 		repository: repository,
+		cache:      newUserCache(validationCacheTTL),
 	}
 }
 
 func (s *UserService) Insert(userDTO *userDTO.UserDTO) (*userDTO.UserDTO, *exception.ApiException) {
+	userDTO.Email = userEntity.NormalizeEmail(userDTO.Email)
 	return s.repository.Insert(userDTO)
 }
 
@@ -26,17 +31,122 @@ func (s *UserService) Find(loginRequestDTO *userDTO.LoginRequestDTO) (*userDTO.U
 }
 
 func (s *UserService) FindByEmail(email string) (*userDTO.UserDTO, *exception.ApiException) {
-	return s.repository.FindByEmail(email)
+	return s.repository.FindByEmail(userEntity.NormalizeEmail(email))
 }
 
+// FindAndCheckJWT reutiliza la validación de las últimas peticiones, cualquier cambio del usuario la invalida
 func (s *UserService) FindAndCheckJWT(claimsDTO *userDTO.JwtClaimsDTO) (*userDTO.UserDTO, *exception.ApiException) {
-	return s.repository.FindAndCheckJWT(claimsDTO)
+	// Tokens issued before the emails were normalised keep the original case
+	normalizedClaims := *claimsDTO
+	normalizedClaims.Email = userEntity.NormalizeEmail(claimsDTO.Email)
+	claimsDTO = &normalizedClaims
+
+	user, ok := s.cache.get(claimsDTO.Username)
+	if !ok || user.Email != claimsDTO.Email {
+		var err *exception.ApiException
+		user, err = s.repository.FindAndCheckJWT(claimsDTO)
+		if err != nil {
+			return nil, err
+		}
+		s.cache.set(user)
+	}
+
+	if user.SessionVersion != claimsDTO.SessionVersion {
+		return nil, exception.NewApiException(401, "The session has been revoked")
+	}
+
+	return user, nil
 }
 
 func (s *UserService) Update(userDTO *userDTO.UserDTO) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(userDTO.Username)
+	userDTO.Email = userEntity.NormalizeEmail(userDTO.Email)
 	return s.repository.Update(userDTO)
 }
 
+func (s *UserService) Verify(username string) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(username)
+	return s.repository.Verify(username)
+}
+
 func (s *UserService) Delete(userDTO *userDTO.UserDTO) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(userDTO.Username)
 	return s.repository.Delete(userDTO)
 }
+
+func (s *UserService) InsertEmailChange(emailChangeDTO *userDTO.EmailChangeDTO) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	emailChangeDTO.OldEmail = userEntity.NormalizeEmail(emailChangeDTO.OldEmail)
+	emailChangeDTO.NewEmail = userEntity.NormalizeEmail(emailChangeDTO.NewEmail)
+	return s.repository.InsertEmailChange(emailChangeDTO)
+}
+
+func (s *UserService) FindPendingEmailChange(username string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	return s.repository.FindPendingEmailChange(username)
+}
+
+func (s *UserService) ConfirmEmailChange(emailChangeDTO *userDTO.EmailChangeDTO) (int64, *exception.ApiException) {
+	return s.repository.ConfirmEmailChange(emailChangeDTO)
+}
+
+func (s *UserService) FindEmailChangeByRevertToken(revertTokenHash string) (*userDTO.EmailChangeDTO, *exception.ApiException) {
+	return s.repository.FindEmailChangeByRevertToken(revertTokenHash)
+}
+
+func (s *UserService) DeleteEmailChange(revertTokenHash string) (int64, *exception.ApiException) {
+	return s.repository.DeleteEmailChange(revertTokenHash)
+}
+
+func (s *UserService) FindByIdentity(provider, subject string) (*userDTO.UserDTO, *exception.ApiException) {
+	return s.repository.FindByIdentity(provider, subject)
+}
+
+func (s *UserService) InsertIdentity(userIdentityDTO *userDTO.UserIdentityDTO) (*userDTO.UserIdentityDTO, *exception.ApiException) {
+	return s.repository.InsertIdentity(userIdentityDTO)
+}
+
+func (s *UserService) FindByUsername(username string) (*userDTO.UserDTO, *exception.ApiException) {
+	return s.repository.FindByUsername(username)
+}
+
+func (s *UserService) FindAll(search, lastUsername string, pageSize int64) (*userDTO.UserCursorDTO, *exception.ApiException) {
+	return s.repository.FindAll(search, lastUsername, pageSize)
+}
+
+func (s *UserService) UpdateRole(username, role string) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(username)
+	return s.repository.UpdateRole(username, role)
+}
+
+func (s *UserService) SetDisabled(username string, disabled bool) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(username)
+	return s.repository.SetDisabled(username, disabled)
+}
+
+func (s *UserService) DeleteByUsername(username string) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(username)
+	return s.repository.DeleteByUsername(username)
+}
+
+func (s *UserService) RevokeSessions(username string) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(username)
+	return s.repository.RevokeSessions(username)
+}
+
+func (s *UserService) SetDeletionSchedule(username string, deletionScheduledAt *time.Time) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(username)
+	return s.repository.SetDeletionSchedule(username, deletionScheduledAt)
+}
+
+func (s *UserService) FindScheduledForDeletion(before time.Time) ([]string, *exception.ApiException) {
+	return s.repository.FindScheduledForDeletion(before)
+}
+
+func (s *UserService) ClaimForPurge(username string, before time.Time) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(username)
+	return s.repository.ClaimForPurge(username, before)
+}
+
+func (s *UserService) CancelDeletion(username string, deletionScheduledAt time.Time) (int64, *exception.ApiException) {
+	defer s.cache.invalidate(username)
+	return s.repository.CancelDeletion(username, deletionScheduledAt)
+}